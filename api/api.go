@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/nano"
@@ -36,10 +37,12 @@ type VersionResponse struct {
 }
 
 type PoolPostRequest struct {
-	Name       string        `json:"name"`
-	SortKey    order.SortKey `json:"layout"`
-	SeekStride int           `json:"seek_stride"`
-	Thresh     int64         `json:"thresh"`
+	Name        string        `json:"name"`
+	SortKey     order.SortKey `json:"layout"`
+	SeekStride  int           `json:"seek_stride"`
+	Thresh      int64         `json:"thresh"`
+	Compression string        `json:"compression"`
+	Schema      *pools.Schema `json:"schema"`
 }
 
 type PoolPutRequest struct {
@@ -51,6 +54,13 @@ type BranchPostRequest struct {
 	Commit string `json:"commit"`
 }
 
+// TagPostRequest creates a tag named Name of the commit of Commit, which is
+// a commit ID, branch name, or tag name.
+type TagPostRequest struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
 type BranchMergeRequest struct {
 	At string `json:"at"`
 }
@@ -112,9 +122,29 @@ type EventBranch struct {
 	Branch string      `zed:"branch"`
 }
 
+type EventTag struct {
+	PoolID ksuid.KSUID `zed:"pool_id"`
+	Tag    string      `zed:"tag"`
+}
+
 type QueryRequest struct {
 	Query string              `json:"query"`
 	Head  lakeparse.Commitish `json:"head"`
+	// SummarizeMemMax, if nonzero, limits the memory used by each
+	// summarize operator in the query before it spills to disk.
+	SummarizeMemMax int `json:"summarize_mem_max,omitempty"`
+	// Limits, if non-nil, lowers the service's resource limits for the
+	// query.
+	Limits *QueryLimits `json:"limits,omitempty"`
+}
+
+// QueryLimits bounds the resources that a query may consume.  A zero value
+// for any field means the service's limit applies.
+type QueryLimits struct {
+	MemMax  int64         `json:"mem_max,omitempty"`
+	RowsMax int64         `json:"rows_max,omitempty"`
+	Timeout nano.Duration `json:"timeout,omitempty"`
+	ScanMax int64         `json:"scan_max,omitempty"`
 }
 
 type QueryChannelSet struct {
@@ -127,6 +157,10 @@ type QueryChannelEnd struct {
 
 type QueryError struct {
 	Error string `json:"error" zed:"error"`
+	// Limit and Max are set when a query exceeds one of its resource
+	// limits and name the limit and its value.
+	Limit string `json:"limit,omitempty" zed:"limit"`
+	Max   int64  `json:"max,omitempty" zed:"max"`
 }
 
 type QueryStats struct {
@@ -135,6 +169,16 @@ type QueryStats struct {
 	zbuf.Progress
 }
 
+// QueryInfo describes a running query.
+type QueryInfo struct {
+	RequestID string  `json:"request_id" zed:"request_id"`
+	TenantID  string  `json:"tenant_id" zed:"tenant_id"`
+	UserID    string  `json:"user_id" zed:"user_id"`
+	StartTime nano.Ts `json:"start_time" zed:"start_time"`
+	Query     string  `json:"query" zed:"query"`
+	zbuf.Progress
+}
+
 type QueryWarning struct {
 	Warning string `json:"warning" zed:"warning"`
 }
@@ -143,6 +187,10 @@ type VacuumResponse struct {
 	ObjectIDs []ksuid.KSUID `zed:"object_ids"`
 }
 
+type CheckpointResponse struct {
+	Commits []ksuid.KSUID `zed:"commits"`
+}
+
 type VectorRequest struct {
 	ObjectIDs []ksuid.KSUID `zed:"object_ids"`
 }
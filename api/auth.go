@@ -1,8 +1,61 @@
 package api
 
+import (
+	"github.com/brimdata/zed/lake/grants"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+)
+
 type AuthIdentityResponse struct {
-	TenantID string `json:"tenant_id" zed:"tenant_id"`
-	UserID   string `json:"user_id" zed:"user_id"`
+	TenantID string   `json:"tenant_id" zed:"tenant_id"`
+	UserID   string   `json:"user_id" zed:"user_id"`
+	Roles    []string `json:"roles,omitempty" zed:"roles"`
+}
+
+// GrantRequest grants Role to Subject for the pool with ID Pool, or the
+// entire lake if Pool is nil, and, if Branch is not empty, only for that
+// branch of the pool.
+type GrantRequest struct {
+	Subject string      `json:"subject" zed:"subject"`
+	Pool    ksuid.KSUID `json:"pool" zed:"pool"`
+	Branch  string      `json:"branch" zed:"branch"`
+	Role    string      `json:"role" zed:"role"`
+}
+
+type GrantsResponse struct {
+	Grants []grants.Grant `json:"grants" zed:"grants"`
+}
+
+// APIKeyRequest creates an API key named Name that authenticates as the
+// identity of the request.  If Pools is not empty, the key may only access
+// those pools, and if Expires is not zero, the key expires then.
+type APIKeyRequest struct {
+	Name    string        `json:"name" zed:"name"`
+	Pools   []ksuid.KSUID `json:"pools" zed:"pools"`
+	Expires nano.Ts       `json:"expires" zed:"expires"`
+}
+
+// APIKey describes an API key.  The secret of the key is only available
+// when it is created.
+type APIKey struct {
+	ID       ksuid.KSUID   `json:"id" zed:"id"`
+	Name     string        `json:"name" zed:"name"`
+	TenantID string        `json:"tenant_id" zed:"tenant_id"`
+	UserID   string        `json:"user_id" zed:"user_id"`
+	Pools    []ksuid.KSUID `json:"pools" zed:"pools"`
+	Created  nano.Ts       `json:"created" zed:"created"`
+	Expires  nano.Ts       `json:"expires" zed:"expires"`
+}
+
+// APIKeyResponse describes a new API key and contains its secret, which is
+// presented as a bearer token to authenticate.
+type APIKeyResponse struct {
+	Key    APIKey `json:"key" zed:"key"`
+	Secret string `json:"secret" zed:"secret"`
+}
+
+type APIKeysResponse struct {
+	Keys []APIKey `json:"keys" zed:"keys"`
 }
 
 type AuthMethod string
@@ -10,11 +63,13 @@ type AuthMethod string
 const (
 	AuthMethodNone  AuthMethod = ""
 	AuthMethodAuth0 AuthMethod = "auth0"
+	AuthMethodOIDC  AuthMethod = "oidc"
 )
 
 type AuthMethodResponse struct {
 	Kind  AuthMethod              `json:"kind" zed:"kind"`
 	Auth0 *AuthMethodAuth0Details `json:"auth0,omitempty" zed:"auth0,omitempty"`
+	OIDC  *AuthMethodOIDCDetails  `json:"oidc,omitempty" zed:"oidc,omitempty"`
 }
 
 type AuthMethodAuth0Details struct {
@@ -28,3 +83,15 @@ type AuthMethodAuth0Details struct {
 	// for any oauth flows.
 	Domain string `json:"domain"`
 }
+
+type AuthMethodOIDCDetails struct {
+	// Audience is the value to use for the "aud" standard claim when
+	// requesting an access token for this service.
+	Audience string `json:"audience"`
+	// ClientID is the public client id to use when interacting with
+	// the issuer below.
+	ClientID string `json:"client_id"`
+	// Issuer is the URL of the OIDC issuer, whose discovery document
+	// supplies the endpoints to use for any oauth flows.
+	Issuer string `json:"issuer"`
+}
@@ -1,7 +1,6 @@
 package auth0
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/brimdata/zed/api"
@@ -27,7 +27,7 @@ type APIError struct {
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("auth0 error: %s: %s", e.Kind, e.ErrorDescription)
+	return fmt.Sprintf("auth error: %s: %s", e.Kind, e.ErrorDescription)
 }
 
 type DeviceCodeResponse struct {
@@ -39,13 +39,6 @@ type DeviceCodeResponse struct {
 	VerificationURIComplete string `json:"verification_uri_complete"`
 }
 
-type tokenRequest struct {
-	ClientID     string `json:"client_id"`
-	DeviceCode   string `json:"device_code,omitempty"`
-	GrantType    string `json:"grant_type"`
-	RefreshToken string `json:"refresh_token,omitempty"`
-}
-
 type tokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	ExpiresIn    int    `json:"expires_in"`
@@ -54,9 +47,13 @@ type tokenResponse struct {
 	TokenType    string `json:"token_type"`
 }
 
+// Client obtains tokens with the OAuth device authorization flow from Auth0
+// or from another OIDC issuer.
 type Client struct {
-	config api.AuthMethodAuth0Details
-	url    url.URL
+	audience      string
+	clientID      string
+	deviceCodeURL string
+	tokenURL      string
 }
 
 func NewClient(config api.AuthMethodAuth0Details) (*Client, error) {
@@ -64,20 +61,70 @@ func NewClient(config api.AuthMethodAuth0Details) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Client{config: config, url: *u}, nil
+	deviceCodeURL, tokenURL := *u, *u
+	deviceCodeURL.Path = "/oauth/device/code"
+	tokenURL.Path = "/oauth/token"
+	return &Client{
+		audience:      config.Audience,
+		clientID:      config.ClientID,
+		deviceCodeURL: deviceCodeURL.String(),
+		tokenURL:      tokenURL.String(),
+	}, nil
 }
 
-func (c *Client) GetDeviceCode(ctx context.Context, scope string) (DeviceCodeResponse, error) {
-	type deviceCodeRequest struct {
-		Audience string `json:"audience"`
-		ClientID string `json:"client_id"`
-		Scope    string `json:"scope"`
+// NewOIDCClient returns a Client for the OIDC issuer of config, whose
+// endpoints are taken from its discovery document.
+func NewOIDCClient(ctx context.Context, config api.AuthMethodOIDCDetails) (*Client, error) {
+	u := strings.TrimRight(config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %s: %s", u, res.Status)
+	}
+	var doc struct {
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+		TokenEndpoint               string `json:"token_endpoint"`
 	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC discovery document: %w", err)
+	}
+	if doc.DeviceAuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, fmt.Errorf("OIDC issuer %s does not support the device authorization flow", config.Issuer)
+	}
+	return &Client{
+		audience:      config.Audience,
+		clientID:      config.ClientID,
+		deviceCodeURL: doc.DeviceAuthorizationEndpoint,
+		tokenURL:      doc.TokenEndpoint,
+	}, nil
+}
+
+// NewClientForMethod returns a Client for the authentication method of a
+// Zed lake service.  It returns a nil Client if the method is neither Auth0
+// nor OIDC.
+func NewClientForMethod(ctx context.Context, method api.AuthMethodResponse) (*Client, error) {
+	switch {
+	case method.Auth0 != nil:
+		return NewClient(*method.Auth0)
+	case method.OIDC != nil:
+		return NewOIDCClient(ctx, *method.OIDC)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetDeviceCode(ctx context.Context, scope string) (DeviceCodeResponse, error) {
 	var res DeviceCodeResponse
-	err := c.post(ctx, "/oauth/device/code", deviceCodeRequest{
-		Audience: c.config.Audience,
-		ClientID: c.config.ClientID,
-		Scope:    scope,
+	err := c.post(ctx, c.deviceCodeURL, url.Values{
+		"audience":  {c.audience},
+		"client_id": {c.clientID},
+		"scope":     {scope},
 	}, &res)
 	return res, err
 }
@@ -96,8 +143,15 @@ func (c *Client) PollDeviceCodeTokens(ctx context.Context, dcr DeviceCodeRespons
 		tokens, err := c.getDeviceCodeTokens(ctx, dcr)
 		if err != nil {
 			var aerr *APIError
-			if errors.As(err, &aerr) && aerr.Kind == "authorization_pending" {
-				continue
+			if errors.As(err, &aerr) {
+				switch aerr.Kind {
+				case "authorization_pending":
+					continue
+				case "slow_down":
+					// See RFC 8628, section 3.5.
+					delay += 5 * time.Second
+					continue
+				}
 			}
 		}
 		return tokens, err
@@ -106,10 +160,10 @@ func (c *Client) PollDeviceCodeTokens(ctx context.Context, dcr DeviceCodeRespons
 
 func (c *Client) getDeviceCodeTokens(ctx context.Context, dcr DeviceCodeResponse) (Tokens, error) {
 	var res tokenResponse
-	err := c.post(ctx, "/oauth/token", tokenRequest{
-		ClientID:   c.config.ClientID,
-		DeviceCode: dcr.DeviceCode,
-		GrantType:  "urn:ietf:params:oauth:grant-type:device_code",
+	err := c.post(ctx, c.tokenURL, url.Values{
+		"client_id":   {c.clientID},
+		"device_code": {dcr.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
 	}, &res)
 	if err != nil {
 		return Tokens{}, err
@@ -124,17 +178,17 @@ func (c *Client) getDeviceCodeTokens(ctx context.Context, dcr DeviceCodeResponse
 
 func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (Tokens, error) {
 	var res tokenResponse
-	err := c.post(ctx, "/oauth/token", tokenRequest{
-		ClientID:     c.config.ClientID,
-		GrantType:    "refresh_token",
-		RefreshToken: refreshToken,
+	err := c.post(ctx, c.tokenURL, url.Values{
+		"client_id":     {c.clientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
 	}, &res)
 	if err != nil {
 		return Tokens{}, err
 	}
 	if res.RefreshToken != "" {
 		// res.RefreshToken is only set when refresh token rotation is
-		// enabled for the Auth0 application specified by c.config.ClientID.
+		// enabled for the application specified by c.clientID.
 		refreshToken = res.RefreshToken
 	}
 	return Tokens{
@@ -145,18 +199,14 @@ func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (Tokens,
 	}, nil
 }
 
-func (c *Client) post(ctx context.Context, path string, body, out interface{}) error {
-	b, err := json.Marshal(body)
-	if err != nil {
-		return err
-	}
-	u := c.url
-	u.Path = path
-	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(b))
+// post sends form as a request body encoded as RFC 6749 requires of
+// requests to a token endpoint, which Auth0 also accepts.
+func (c *Client) post(ctx context.Context, endpoint string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
@@ -0,0 +1,71 @@
+package auth0
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brimdata/zed/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCDeviceFlow(t *testing.T) {
+	var polls int
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                        ts.URL,
+			"device_authorization_endpoint": ts.URL + "/device",
+			"token_endpoint":                ts.URL + "/token",
+		})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "testclient", r.PostFormValue("client_id"))
+		json.NewEncoder(w).Encode(DeviceCodeResponse{
+			DeviceCode:      "devicecode",
+			UserCode:        "usercode",
+			VerificationURI: ts.URL + "/verify",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PostFormValue("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			assert.Equal(t, "devicecode", r.PostFormValue("device_code"))
+			if polls++; polls == 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(APIError{Kind: "authorization_pending"})
+				return
+			}
+			json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access1", RefreshToken: "refresh", ExpiresIn: 60})
+		case "refresh_token":
+			assert.Equal(t, "refresh", r.PostFormValue("refresh_token"))
+			json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access2", ExpiresIn: 60})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(APIError{Kind: "unsupported_grant_type"})
+		}
+	})
+
+	ctx := context.Background()
+	client, err := NewClientForMethod(ctx, api.AuthMethodResponse{
+		Kind: api.AuthMethodOIDC,
+		OIDC: &api.AuthMethodOIDCDetails{ClientID: "testclient", Issuer: ts.URL},
+	})
+	require.NoError(t, err)
+	dcr, err := client.GetDeviceCode(ctx, "openid")
+	require.NoError(t, err)
+	assert.Equal(t, "usercode", dcr.UserCode)
+	tokens, err := client.PollDeviceCodeTokens(ctx, dcr)
+	require.NoError(t, err)
+	assert.Equal(t, 2, polls)
+	assert.Equal(t, "access1", tokens.Access)
+	tokens, err = client.RefreshToken(ctx, tokens.Refresh)
+	require.NoError(t, err)
+	assert.Equal(t, "access2", tokens.Access)
+	assert.Equal(t, "refresh", tokens.Refresh)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/lake/branches"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/tags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/runtime/exec"
 	"github.com/brimdata/zed/zio/zngio"
@@ -42,6 +43,16 @@ var (
 	ErrBranchNotFound = errors.New("branch not found")
 	// ErrBranchExists is returned when the specified the branch already exists.
 	ErrBranchExists = errors.New("branch exists")
+	// ErrTagNotFound is returned when the specified tag does not exist.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists is returned when the specified tag already exists.
+	ErrTagExists = errors.New("tag exists")
+	// ErrAPIKeyNotFound is returned when the specified API key does not exist.
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrGrantNotFound is returned when the specified grant does not exist.
+	ErrGrantNotFound = errors.New("grant not found")
+	// ErrQueryNotFound is returned when the specified query is not running.
+	ErrQueryNotFound = errors.New("query not found")
 )
 
 type Connection struct {
@@ -271,6 +282,105 @@ func (c *Connection) CreateBranch(ctx context.Context, poolID ksuid.KSUID, paylo
 	return branch, err
 }
 
+func (c *Connection) TagGet(ctx context.Context, poolID ksuid.KSUID, tagName string) (tags.Config, error) {
+	path := urlPath("pool", poolID.String(), "tag", tagName)
+	req := c.NewRequest(ctx, http.MethodGet, path, nil)
+	var tag tags.Config
+	err := c.doAndUnmarshal(req, &tag)
+	if errIsStatus(err, http.StatusNotFound) {
+		err = ErrTagNotFound
+	}
+	return tag, err
+}
+
+func (c *Connection) CreateTag(ctx context.Context, poolID ksuid.KSUID, payload api.TagPostRequest) (tags.Config, error) {
+	req := c.NewRequest(ctx, http.MethodPost, urlPath("pool", poolID.String(), "tag"), payload)
+	var tag tags.Config
+	err := c.doAndUnmarshal(req, &tag)
+	if errIsStatus(err, http.StatusConflict) {
+		err = ErrTagExists
+	}
+	return tag, err
+}
+
+func (c *Connection) RemoveTag(ctx context.Context, poolID ksuid.KSUID, tagName string) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("pool", poolID.String(), "tag", tagName), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		if errIsStatus(err, http.StatusNotFound) {
+			return ErrTagNotFound
+		}
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) ListAPIKeys(ctx context.Context) (api.APIKeysResponse, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/auth/apikey", nil)
+	var res api.APIKeysResponse
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
+func (c *Connection) CreateAPIKey(ctx context.Context, payload api.APIKeyRequest) (api.APIKeyResponse, error) {
+	req := c.NewRequest(ctx, http.MethodPost, "/auth/apikey", payload)
+	var res api.APIKeyResponse
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
+func (c *Connection) RevokeAPIKey(ctx context.Context, id ksuid.KSUID) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("auth", "apikey", id.String()), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		if errIsStatus(err, http.StatusNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) ListGrants(ctx context.Context) (api.GrantsResponse, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/auth/grant", nil)
+	var res api.GrantsResponse
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
+func (c *Connection) Grant(ctx context.Context, payload api.GrantRequest) error {
+	req := c.NewRequest(ctx, http.MethodPost, "/auth/grant", payload)
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
+func (c *Connection) Revoke(ctx context.Context, subject string, poolID ksuid.KSUID, branch string) error {
+	query := url.Values{}
+	query.Set("subject", subject)
+	if poolID != ksuid.Nil {
+		query.Set("pool", poolID.String())
+	}
+	if branch != "" {
+		query.Set("branch", branch)
+	}
+	req := c.NewRequest(ctx, http.MethodDelete, "/auth/grant?"+query.Encode(), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		if errIsStatus(err, http.StatusNotFound) {
+			return ErrGrantNotFound
+		}
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
 func (c *Connection) MergeBranch(ctx context.Context, poolID ksuid.KSUID, childBranch, parentBranch string, message api.CommitMessage) (api.CommitResponse, error) {
 	path := urlPath("pool", poolID.String(), "branch", parentBranch, "merge", childBranch)
 	req := c.NewRequest(ctx, http.MethodPost, path, nil)
@@ -319,6 +429,28 @@ func (c *Connection) Query(ctx context.Context, head *lakeparse.Commitish, src s
 	return res, err
 }
 
+// ListQueries returns the queries running on the service.
+func (c *Connection) ListQueries(ctx context.Context) ([]api.QueryInfo, error) {
+	req := c.NewRequest(ctx, http.MethodGet, "/query", nil)
+	var infos []api.QueryInfo
+	err := c.doAndUnmarshal(req, &infos)
+	return infos, err
+}
+
+// KillQuery cancels the running query whose request ID is id.
+func (c *Connection) KillQuery(ctx context.Context, id string) error {
+	req := c.NewRequest(ctx, http.MethodDelete, urlPath("query", id), nil)
+	res, err := c.Do(req)
+	if err != nil {
+		if errIsStatus(err, http.StatusNotFound) {
+			return ErrQueryNotFound
+		}
+		return err
+	}
+	res.Body.Close()
+	return nil
+}
+
 func (c *Connection) Compact(ctx context.Context, poolID ksuid.KSUID, branchName string, objects []ksuid.KSUID, writeVectors bool, message api.CommitMessage) (api.CommitResponse, error) {
 	path := urlPath("pool", poolID.String(), "branch", branchName, "compact")
 	if writeVectors {
@@ -435,6 +567,24 @@ func (c *Connection) Vacuum(ctx context.Context, pool, revision string, dryrun b
 	return res, err
 }
 
+func (c *Connection) Checkpoint(ctx context.Context, pool, revision string, interval int, verify bool) (api.CheckpointResponse, error) {
+	query := url.Values{}
+	if interval > 0 {
+		query.Set("interval", strconv.Itoa(interval))
+	}
+	if verify {
+		query.Set("verify", "true")
+	}
+	path := urlPath("pool", pool, "revision", revision, "checkpoint")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req := c.NewRequest(ctx, http.MethodPost, path, nil)
+	var res api.CheckpointResponse
+	err := c.doAndUnmarshal(req, &res)
+	return res, err
+}
+
 func (c *Connection) AddVectors(ctx context.Context, pool, revision string, objectIDs []ksuid.KSUID, message api.CommitMessage) (api.CommitResponse, error) {
 	return c.doVector(ctx, pool, revision, objectIDs, message, http.MethodPost)
 }
@@ -483,7 +633,11 @@ func (c *Connection) refreshAuthToken(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if method.Auth0 == nil {
+	client, err := auth0.NewClientForMethod(ctx, method)
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
 		return "", fmt.Errorf("auth not available on lake: %s", c.hostURL)
 	}
 	tokens, err := c.auth.Tokens(c.hostURL)
@@ -493,10 +647,6 @@ func (c *Connection) refreshAuthToken(ctx context.Context) (string, error) {
 	if tokens == nil {
 		return "", fmt.Errorf("auth credentials not set for lake: %s", c.hostURL)
 	}
-	client, err := auth0.NewClient(*method.Auth0)
-	if err != nil {
-		return "", err
-	}
 	refreshed, err := client.RefreshToken(ctx, tokens.Refresh)
 	if err != nil {
 		return "", err
@@ -10,6 +10,7 @@ import (
 const (
 	MediaTypeAny         = "*/*"
 	MediaTypeArrowStream = "application/vnd.apache.arrow.stream"
+	MediaTypeAvro        = "application/x-avro"
 	MediaTypeCSV         = "text/csv"
 	MediaTypeJSON        = "application/json"
 	MediaTypeLine        = "application/x-line"
@@ -46,6 +47,8 @@ func MediaTypeToFormat(s string, dflt string) (string, error) {
 		return dflt, nil
 	case MediaTypeArrowStream:
 		return "arrows", nil
+	case MediaTypeAvro:
+		return "avro", nil
 	case MediaTypeCSV:
 		return "csv", nil
 	case MediaTypeJSON:
@@ -76,6 +79,8 @@ func FormatToMediaType(format string) (string, error) {
 	switch format {
 	case "arrows":
 		return MediaTypeArrowStream, nil
+	case "avro":
+		return MediaTypeAvro, nil
 	case "csv":
 		return MediaTypeCSV, nil
 	case "json":
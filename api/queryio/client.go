@@ -7,6 +7,7 @@ import (
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zson"
@@ -60,6 +61,9 @@ func controlToError(ctrl interface{}) error {
 	case *api.QueryStats:
 		return &zbuf.Control{Message: ctrl.Progress}
 	case *api.QueryError:
+		if ctrl.Limit != "" {
+			return &op.LimitError{Limit: ctrl.Limit, Max: ctrl.Max}
+		}
 		return errors.New(ctrl.Error)
 	default:
 		return fmt.Errorf("unsupported control message: %T", ctrl)
@@ -1,11 +1,13 @@
 package queryio
 
 import (
+	"errors"
 	"io"
 	"net/http"
 
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/anyio"
@@ -76,7 +78,13 @@ func (w *Writer) WriteProgress(stats zbuf.Progress) error {
 }
 
 func (w *Writer) WriteError(err error) {
-	w.WriteControl(api.QueryError{Error: err.Error()})
+	qerr := api.QueryError{Error: err.Error()}
+	var limitErr *op.LimitError
+	if errors.As(err, &limitErr) {
+		qerr.Limit = limitErr.Limit
+		qerr.Max = limitErr.Max
+	}
+	w.WriteControl(qerr)
 }
 
 func (w *Writer) WriteControl(value interface{}) error {
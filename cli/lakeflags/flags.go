@@ -20,6 +20,9 @@ import (
 var ErrNoHEAD = errors.New("HEAD not specified: indicate with -use or run the \"use\" command")
 
 type Flags struct {
+	// APIKey, if set, authenticates connections to a lake service in
+	// place of the credentials stored in ConfigDir.
+	APIKey    string
 	ConfigDir string
 	Lake      string
 	Quiet     bool
@@ -34,6 +37,7 @@ func (l *Flags) SetFlags(fs *flag.FlagSet) {
 		dir = filepath.Join(dir, ".zed")
 	}
 	fs.StringVar(&l.ConfigDir, "configdir", dir, "configuration and credentials directory")
+	l.APIKey = os.Getenv("ZED_API_KEY")
 	if s, ok := os.LookupEnv("ZED_LAKE"); ok {
 		l.Lake, l.lakeSpecified = s, true
 	}
@@ -52,6 +56,10 @@ func (l *Flags) Connection() (*client.Connection, error) {
 		return nil, errors.New("cannot open connection on local lake")
 	}
 	conn := client.NewConnectionTo(uri.String())
+	if l.APIKey != "" {
+		conn.SetAuthToken(l.APIKey)
+		return conn, nil
+	}
 	if err := conn.SetAuthStore(l.AuthStore()); err != nil {
 		return nil, err
 	}
@@ -48,8 +48,12 @@ func (f *Flags) setFlags(fs *flag.FlagSet) {
 	}
 	fs.Var(&f.VNG.ColumnThresh, "vng.colthresh", "minimum VNG frame size")
 	fs.Var(&f.VNG.SkewThresh, "vng.skewthresh", "minimum VNG skew size")
+	fs.Var(&f.VNG.Compression, "vng.compression", "compression format for VNG segments [lz4,zstd]")
+	fs.IntVar(&f.VNG.CompressionLevel, "vng.compressionlevel", 0, "zstd compression level for VNG segments (0 for default)")
 	f.ZNG = &zngio.WriterOpts{}
 	fs.BoolVar(&f.ZNG.Compress, "zng.compress", true, "compress ZNG frames")
+	fs.Var(&f.ZNG.CompressionFormat, "zng.compression", "compression format for ZNG frames [lz4,zstd]")
+	fs.IntVar(&f.ZNG.CompressionLevel, "zng.compressionlevel", 0, "zstd compression level for ZNG frames (0 for default)")
 	fs.IntVar(&f.ZNG.FrameThresh, "zng.framethresh", zngio.DefaultFrameThresh,
 		"minimum ZNG frame size in uncompressed bytes")
 	fs.IntVar(&f.ZSON.Pretty, "pretty", 4,
@@ -80,7 +84,7 @@ func (f *Flags) SetFormatFlags(fs *flag.FlagSet) {
 	if f.DefaultFormat == "" {
 		f.DefaultFormat = "zng"
 	}
-	fs.StringVar(&f.Format, "f", f.DefaultFormat, "format for output data [arrows,csv,json,lake,parquet,table,text,tsv,vng,zeek,zjson,zng,zson]")
+	fs.StringVar(&f.Format, "f", f.DefaultFormat, "format for output data [arrows,avro,csv,json,lake,parquet,table,text,tsv,vng,zeek,zjson,zng,zson]")
 	fs.BoolVar(&f.jsonShortcut, "j", false, "use line-oriented JSON output independent of -f option")
 	fs.BoolVar(&f.zsonShortcut, "z", false, "use line-oriented ZSON output independent of -f option")
 	fs.BoolVar(&f.zsonPretty, "Z", false, "use formatted ZSON output independent of -f option")
@@ -7,6 +7,8 @@ import (
 	"github.com/brimdata/zed/cli/auto"
 	"github.com/brimdata/zed/runtime/expr/agg"
 	"github.com/brimdata/zed/runtime/op/fuse"
+	"github.com/brimdata/zed/runtime/op/groupby"
+	"github.com/brimdata/zed/runtime/op/join"
 	"github.com/brimdata/zed/runtime/op/sort"
 	"github.com/pbnjay/memory"
 )
@@ -30,9 +32,11 @@ func defaultMemMaxBytes() uint64 {
 
 type Flags struct {
 	// these memory limits should be based on a shared resource model
-	aggMemMax  auto.Bytes
-	sortMemMax auto.Bytes
-	fuseMemMax auto.Bytes
+	aggMemMax       auto.Bytes
+	sortMemMax      auto.Bytes
+	fuseMemMax      auto.Bytes
+	summarizeMemMax auto.Bytes
+	hashJoinMemMax  auto.Bytes
 }
 
 func (f *Flags) SetFlags(fs *flag.FlagSet) {
@@ -43,6 +47,10 @@ func (f *Flags) SetFlags(fs *flag.FlagSet) {
 	fs.Var(&f.sortMemMax, "sortmem", "maximum memory used by sort in MiB, MB, etc")
 	f.fuseMemMax = auto.NewBytes(def)
 	fs.Var(&f.fuseMemMax, "fusemem", "maximum memory used by fuse in MiB, MB, etc")
+	f.summarizeMemMax = auto.NewBytes(def)
+	fs.Var(&f.summarizeMemMax, "summarizemem", "maximum memory used by summarize in MiB, MB, etc")
+	f.hashJoinMemMax = auto.NewBytes(uint64(join.HashMemMaxBytes))
+	fs.Var(&f.hashJoinMemMax, "hashjoinmem", "maximum memory used by hash join in MiB, MB, etc (0 disables hash join)")
 }
 
 func (f *Flags) Init() error {
@@ -58,5 +66,13 @@ func (f *Flags) Init() error {
 		return errors.New("fusemem value must be greater than zero")
 	}
 	fuse.MemMaxBytes = int(f.fuseMemMax.Bytes)
+	if f.summarizeMemMax.Bytes <= 0 {
+		return errors.New("summarizemem value must be greater than zero")
+	}
+	groupby.MemMaxBytes = int(f.summarizeMemMax.Bytes)
+	if f.hashJoinMemMax.Bytes < 0 {
+		return errors.New("hashjoinmem value must not be negative")
+	}
+	join.HashMemMaxBytes = int(f.hashJoinMemMax.Bytes)
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/brimdata/zed/pkg/charm"
@@ -26,6 +27,7 @@ type Command struct {
 	expiration     time.Duration
 	privateKeyFile string
 	keyID          string
+	roles          string
 	tenantID       string
 	userID         string
 }
@@ -37,6 +39,7 @@ func New(_ charm.Command, fs *flag.FlagSet) (charm.Command, error) {
 	fs.DurationVar(&c.expiration, "expiration", 4*time.Hour, "expiry duration for generated token")
 	fs.StringVar(&c.privateKeyFile, "privatekeyfile", "", "path of file containing private key (required)")
 	fs.StringVar(&c.keyID, "keyid", "", "key identifier")
+	fs.StringVar(&c.roles, "roles", "", "comma-separated roles claim in generated token")
 	fs.StringVar(&c.tenantID, "tenantid", "", "tenant ID claim in generated token")
 	fs.StringVar(&c.userID, "userid", "", "user ID claim in generated token")
 	return c, nil
@@ -49,8 +52,12 @@ func (c *Command) Run(args []string) error {
 	if c.privateKeyFile == "" {
 		return errors.New("must specify a keyfile")
 	}
-	token, err := auth.GenerateAccessToken(
-		c.keyID, c.privateKeyFile, c.expiration, c.audience, c.domain, auth.TenantID(c.tenantID), auth.UserID(c.userID))
+	var roles []string
+	if c.roles != "" {
+		roles = strings.Split(c.roles, ",")
+	}
+	token, err := auth.GenerateAccessTokenWithRoles(
+		c.keyID, c.privateKeyFile, c.expiration, c.audience, c.domain, auth.TenantID(c.tenantID), auth.UserID(c.userID), roles)
 	if err != nil {
 		return fmt.Errorf("GenerateAccessToken failed: %w", err)
 	}
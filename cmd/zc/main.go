@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	_ "time/tzdata" // Embed time zones for hosts without a zoneinfo database.
 
 	"github.com/brimdata/zed/cmd/zed/dev/compile"
 	"github.com/brimdata/zed/cmd/zed/root"
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/brimdata/zed/api"
+	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+)
+
+var APIKey = &charm.Spec{
+	Name:  "apikey",
+	Usage: "auth apikey [subcommand]",
+	Short: "create, list, and revoke API keys",
+	Long: `
+An API key authenticates a client of a Zed lake service without the
+interactive login flow and is thus suited to batch jobs and automation.
+A key authenticates as the user who created it and is presented to the
+service by setting the ZED_API_KEY environment variable.
+
+The apikey subcommands create, list, and revoke the API keys of a
+Zed lake service started with -auth.enabled.
+`,
+	New: NewAPIKey,
+}
+
+func init() {
+	APIKey.Add(APIKeyCreate)
+	APIKey.Add(APIKeyList)
+	APIKey.Add(APIKeyRevoke)
+}
+
+func NewAPIKey(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return parent.(*Command), nil
+}
+
+var APIKeyCreate = &charm.Spec{
+	Name:  "create",
+	Usage: "auth apikey create [-expires duration] [-pool pool ...] name",
+	Short: "create an API key",
+	Long: `
+The create command creates an API key with the given name and prints its
+secret, which cannot be retrieved again.  The key authenticates as the
+caller, who must have logged in with "zed auth login" as a key cannot
+create other keys.
+
+If -pool is given, the key may access only the named pools and none of the
+lake's other pools or lake-wide operations.  The flag may be repeated.
+If -expires is given, the key expires after the given duration (e.g., 90d).
+`,
+	New: NewAPIKeyCreate,
+}
+
+type APIKeyCreateCommand struct {
+	*Command
+	expires string
+	pools   []string
+}
+
+func NewAPIKeyCreate(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &APIKeyCreateCommand{Command: parent.(*Command)}
+	f.StringVar(&c.expires, "expires", "", "duration after which the key expires (default never)")
+	f.Func("pool", "name or ID of a pool the key is limited to (may be repeated)", func(s string) error {
+		c.pools = append(c.pools, s)
+		return nil
+	})
+	return c, nil
+}
+
+func (c *APIKeyCreateCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 1 {
+		return errors.New("apikey create command requires a name")
+	}
+	var expires nano.Ts
+	if c.expires != "" {
+		d, err := nano.ParseDuration(c.expires)
+		if err != nil {
+			return err
+		}
+		if d <= 0 {
+			return errors.New("expiration duration must be positive")
+		}
+		expires = nano.Now().Add(d)
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	lake := lakeapi.NewRemoteLake(conn)
+	var pools []ksuid.KSUID
+	for _, name := range c.pools {
+		id, err := lakeparse.ParseID(name)
+		if err != nil {
+			if id, err = lake.PoolID(ctx, name); err != nil {
+				return err
+			}
+		}
+		pools = append(pools, id)
+	}
+	res, err := conn.CreateAPIKey(ctx, api.APIKeyRequest{
+		Name:    args[0],
+		Pools:   pools,
+		Expires: expires,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(res.Secret)
+	return nil
+}
+
+var APIKeyList = &charm.Spec{
+	Name:  "list",
+	Usage: "auth apikey list",
+	Short: "list API keys",
+	Long: `
+The list command lists the API keys created by the caller.  A Zed lake
+service started with -auth.rbac also lists the keys of other users to
+an admin of the lake.  For each key, the command prints its ID, name,
+user, the pools it is limited to or "lake" if it is not limited, and
+its expiration time or "never".
+`,
+	New: NewAPIKeyList,
+}
+
+type APIKeyListCommand struct {
+	*Command
+}
+
+func NewAPIKeyList(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &APIKeyListCommand{Command: parent.(*Command)}, nil
+}
+
+func (c *APIKeyListCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 0 {
+		return errors.New("apikey list command takes no arguments")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	res, err := conn.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+	pools, err := lakeapi.GetPools(ctx, lakeapi.NewRemoteLake(conn))
+	if err != nil {
+		return err
+	}
+	names := make(map[ksuid.KSUID]string)
+	for _, p := range pools {
+		names[p.ID] = p.Name
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range res.Keys {
+		scope := "lake"
+		if len(key.Pools) > 0 {
+			var list []string
+			for _, id := range key.Pools {
+				name := names[id]
+				if name == "" {
+					name = id.String()
+				}
+				list = append(list, name)
+			}
+			scope = strings.Join(list, ",")
+		}
+		expires := "never"
+		if key.Expires != 0 {
+			expires = key.Expires.Time().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.UserID, scope, expires)
+	}
+	return w.Flush()
+}
+
+var APIKeyRevoke = &charm.Spec{
+	Name:  "revoke",
+	Usage: "auth apikey revoke id",
+	Short: "revoke an API key",
+	Long: `
+The revoke command revokes the API key with the given ID, after which
+the key no longer authenticates.
+`,
+	New: NewAPIKeyRevoke,
+}
+
+type APIKeyRevokeCommand struct {
+	*Command
+}
+
+func NewAPIKeyRevoke(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &APIKeyRevokeCommand{Command: parent.(*Command)}, nil
+}
+
+func (c *APIKeyRevokeCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 1 {
+		return errors.New("apikey revoke command requires an API key ID")
+	}
+	id, err := ksuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid API key ID %q", args[0])
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	if err := conn.RevokeAPIKey(ctx, id); err != nil {
+		return err
+	}
+	if !c.LakeFlags.Quiet {
+		fmt.Printf("revoked API key %s\n", id)
+	}
+	return nil
+}
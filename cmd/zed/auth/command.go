@@ -16,9 +16,13 @@ var Cmd = &charm.Spec{
 }
 
 func init() {
+	Cmd.Add(APIKey)
+	Cmd.Add(Grant)
+	Cmd.Add(List)
 	Cmd.Add(Login)
 	Cmd.Add(Logout)
 	Cmd.Add(Method)
+	Cmd.Add(Revoke)
 	Cmd.Add(Store)
 	Cmd.Add(Verify)
 }
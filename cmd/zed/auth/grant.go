@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/grants"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
+)
+
+var Grant = &charm.Spec{
+	Name:  "grant",
+	Usage: "auth grant -role reader|writer|admin user:<id>|group:<name> [pool[@branch]]",
+	Short: "grant a role on the lake, a pool, or a branch",
+	Long: `
+The grant command confers a role on a user or group for the entire lake or,
+if a pool is given, for that pool or, if a branch is also given, for that
+branch of the pool.  A user is identified by the user ID claim of its access
+token and a group by a value of its roles claim.  A subject holds at most one
+role for each scope so a grant replaces the role previously granted
+to the subject for the same scope.
+
+The roles are reader, writer, and admin, and each includes the permissions
+of the roles before it.  Roles are enforced by a Zed lake service started
+with -auth.rbac.
+`,
+	New: NewGrant,
+}
+
+type GrantCommand struct {
+	*Command
+	role string
+}
+
+func NewGrant(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &GrantCommand{Command: parent.(*Command)}
+	f.StringVar(&c.role, "role", "", "role to grant (reader, writer, or admin)")
+	return c, nil
+}
+
+func (c *GrantCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("grant command requires a subject and an optional pool")
+	}
+	role, err := grants.ParseRole(c.role)
+	if err != nil {
+		return err
+	}
+	subject, err := grants.ParseSubject(args[0])
+	if err != nil {
+		return err
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	poolID, branch, err := parseScope(ctx, lake, args[1:])
+	if err != nil {
+		return err
+	}
+	if err := lake.Grant(ctx, subject, poolID, branch, role); err != nil {
+		return err
+	}
+	if !c.LakeFlags.Quiet {
+		fmt.Printf("granted %s to %s\n", role, subject)
+	}
+	return nil
+}
+
+// parseScope returns the pool ID and branch named by the optional
+// pool[@branch] argument.  No argument denotes the entire lake.
+func parseScope(ctx context.Context, lake api.Interface, args []string) (ksuid.KSUID, string, error) {
+	if len(args) == 0 {
+		return ksuid.Nil, "", nil
+	}
+	commitish, err := lakeparse.ParseCommitish(args[0])
+	if err != nil {
+		return ksuid.Nil, "", err
+	}
+	poolID, err := lakeparse.ParseID(commitish.Pool)
+	if err != nil {
+		poolID, err = lake.PoolID(ctx, commitish.Pool)
+		if err != nil {
+			return ksuid.Nil, "", err
+		}
+	}
+	return poolID, commitish.Branch, nil
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/segmentio/ksuid"
+)
+
+var List = &charm.Spec{
+	Name:  "list",
+	Usage: "auth list",
+	Short: "list granted roles",
+	Long: `
+The list command lists the roles granted in the lake.  A Zed lake service
+started with -auth.rbac lists only the grants the caller could revoke.
+`,
+	New: NewList,
+}
+
+type ListCommand struct {
+	*Command
+}
+
+func NewList(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &ListCommand{Command: parent.(*Command)}, nil
+}
+
+func (c *ListCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 0 {
+		return errors.New("list command takes no arguments")
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	list, err := lake.ListGrants(ctx)
+	if err != nil {
+		return err
+	}
+	pools, err := api.GetPools(ctx, lake)
+	if err != nil {
+		return err
+	}
+	names := make(map[ksuid.KSUID]string)
+	for _, p := range pools {
+		names[p.ID] = p.Name
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, g := range list {
+		scope := "lake"
+		if g.Pool != ksuid.Nil {
+			scope = names[g.Pool]
+			if scope == "" {
+				scope = g.Pool.String()
+			}
+			if g.Branch != "" {
+				scope += "@" + g.Branch
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", g.Subject, g.Role, scope)
+	}
+	return w.Flush()
+}
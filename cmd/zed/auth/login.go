@@ -49,28 +49,38 @@ func (c *LoginCommand) Run(args []string) error {
 	}
 	switch method.Kind {
 	case api.AuthMethodAuth0:
+		fmt.Println("method", method.Auth0.ClientID)
+		fmt.Println("domain", method.Auth0.Domain)
+		fmt.Println("audience", method.Auth0.Audience)
+	case api.AuthMethodOIDC:
+		fmt.Println("method", method.OIDC.ClientID)
+		fmt.Println("issuer", method.OIDC.Issuer)
+		fmt.Println("audience", method.OIDC.Audience)
 	case api.AuthMethodNone:
 		return fmt.Errorf("Zed lake service at %s does not support authentication", c.LakeFlags.Lake)
 	default:
 		return fmt.Errorf("Zed lake service at %s requires unknown authentication method %s", c.LakeFlags.Lake, method.Kind)
 	}
-	fmt.Println("method", method.Auth0.ClientID)
-	fmt.Println("domain", method.Auth0.Domain)
-	fmt.Println("audience", method.Auth0.Audience)
-	auth0client, err := auth0.NewClient(*method.Auth0)
+	client, err := auth0.NewClientForMethod(ctx, method)
 	if err != nil {
 		return err
 	}
-	dcr, err := auth0client.GetDeviceCode(ctx, "openid email profile offline_access")
+	dcr, err := client.GetDeviceCode(ctx, "openid email profile offline_access")
 	if err != nil {
 		return err
 	}
-	fmt.Println("Complete authentication at", dcr.VerificationURIComplete)
+	// The complete verification URI, which includes the user code, is
+	// optional for issuers other than Auth0.
+	verificationURI := dcr.VerificationURIComplete
+	if verificationURI == "" {
+		verificationURI = dcr.VerificationURI
+	}
+	fmt.Println("Complete authentication at", verificationURI)
 	fmt.Println("Verification code:", dcr.UserCode)
 	if c.launchBrowser {
-		browser.OpenURL(dcr.VerificationURIComplete)
+		browser.OpenURL(verificationURI)
 	}
-	tokens, err := auth0client.PollDeviceCodeTokens(ctx, dcr)
+	tokens, err := client.PollDeviceCodeTokens(ctx, dcr)
 	if err != nil {
 		return err
 	}
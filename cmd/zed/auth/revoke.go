@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/lake/grants"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Revoke = &charm.Spec{
+	Name:  "revoke",
+	Usage: "auth revoke user:<id>|group:<name> [pool[@branch]]",
+	Short: "revoke a role on the lake, a pool, or a branch",
+	Long: `
+The revoke command removes the role granted to a user or group for the entire
+lake or, if a pool is given, for that pool or, if a branch is also given,
+for that branch of the pool.
+`,
+	New: NewRevoke,
+}
+
+type RevokeCommand struct {
+	*Command
+}
+
+func NewRevoke(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &RevokeCommand{Command: parent.(*Command)}, nil
+}
+
+func (c *RevokeCommand) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("revoke command requires a subject and an optional pool")
+	}
+	subject, err := grants.ParseSubject(args[0])
+	if err != nil {
+		return err
+	}
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	poolID, branch, err := parseScope(ctx, lake, args[1:])
+	if err != nil {
+		return err
+	}
+	if err := lake.Revoke(ctx, subject, poolID, branch); err != nil {
+		return err
+	}
+	if !c.LakeFlags.Quiet {
+		fmt.Printf("revoked role of %s\n", subject)
+	}
+	return nil
+}
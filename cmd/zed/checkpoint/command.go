@@ -0,0 +1,84 @@
+package checkpoint
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/cli/poolflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/plural"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "checkpoint",
+	Usage: "checkpoint [options]",
+	Short: "write or verify commit history checkpoints",
+	Long: `
+"zed checkpoint" writes checkpoints of the commit history of a pool's branch
+or commit so that opening the pool replays fewer commit objects.  A checkpoint
+is written for every interval-th commit from the beginning of the history and
+for the commit at its tip.  Checkpoints of the lake's pool journal and of the
+pool's branch and tag journals are written as well.
+
+Checkpoints are also written automatically as commit histories and journals
+grow, so this command is needed only to checkpoint a history in advance
+or with a different interval.
+
+With -verify, the existing checkpoints are instead compared with the state
+obtained by replaying the history and an error is reported for the first
+checkpoint that does not match.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	poolFlags poolflags.Flags
+	interval  int
+	verify    bool
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.poolFlags.SetFlags(f)
+	f.IntVar(&c.interval, "interval", commits.DefaultCheckpointInterval, "number of commits between checkpoints")
+	f.BoolVar(&c.verify, "verify", false, "verify existing checkpoints instead of writing new ones")
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 0 {
+		return errors.New("too many arguments")
+	}
+	if c.interval <= 0 {
+		return errors.New("checkpoint interval must be positive")
+	}
+	at, err := c.poolFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	lk, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	ids, err := lk.Checkpoint(ctx, at.Pool, at.Branch, c.interval, c.verify)
+	if err != nil {
+		return err
+	}
+	if !c.LakeFlags.Quiet {
+		verb := "wrote"
+		if c.verify {
+			verb = "verified"
+		}
+		fmt.Printf("%s %d checkpoint%s\n", verb, len(ids), plural.Slice(ids, "s"))
+	}
+	return nil
+}
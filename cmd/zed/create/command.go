@@ -8,6 +8,7 @@ import (
 	"github.com/brimdata/zed/cli/poolflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/charm"
 	"github.com/brimdata/zed/pkg/units"
@@ -31,6 +32,16 @@ range scans with respect to the pool key using the
 naturally for such scans.
 
 By default, a branch called "main" is initialized in the newly created pool.
+
+The -schema flag attaches a schema, given as a ZSON type, to the pool.
+Every value loaded into the pool is checked against the schema and
+values of any other type are handled according to -schema.mode:
+"reject" (the default) drops them, "coerce" shapes them to the schema
+or, if -schema.shaper is given, passes every value through the shaper
+script, and "quarantine" loads them into the branch named by
+-schema.quarantine (default "quarantine"), which is created with the pool.
+The number of non-conforming values is recorded in the metadata of
+each load commit.
 `,
 	HiddenFlags: "seekstride",
 	New:         New,
@@ -38,10 +49,15 @@ By default, a branch called "main" is initialized in the newly created pool.
 
 type Command struct {
 	*root.Command
-	sortKey    string
-	thresh     units.Bytes
-	seekStride units.Bytes
-	use        bool
+	sortKey     string
+	thresh      units.Bytes
+	seekStride  units.Bytes
+	compression string
+	use         bool
+	schema      string
+	schemaMode  string
+	shaper      string
+	quarantine  string
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
@@ -52,8 +68,13 @@ func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	f.Var(&c.seekStride, "seekstride", "size of seek-index unit for ZNG data, as '32KB', '1MB', etc.")
 	c.thresh = data.DefaultThreshold
 	f.Var(&c.thresh, "S", "target size of pool data objects, as '10MB' or '4GiB', etc.")
+	f.StringVar(&c.compression, "compression", "lz4", "compression format for pool data objects [lz4,zstd]")
 	f.BoolVar(&c.use, "use", false, "set created pool as the current pool")
 	f.StringVar(&c.sortKey, "orderby", "ts:desc", "pool key with optional :asc or :desc suffix to organize data in pool (cannot be changed)")
+	f.StringVar(&c.schema, "schema", "", "ZSON type that values loaded into the pool must conform to")
+	f.StringVar(&c.schemaMode, "schema.mode", "", "handling of values not conforming to -schema [reject,coerce,quarantine] (default reject)")
+	f.StringVar(&c.shaper, "schema.shaper", "", "Zed script shaping loaded values to -schema in coerce mode")
+	f.StringVar(&c.quarantine, "schema.quarantine", "", "branch receiving values not conforming to -schema in quarantine mode (default quarantine)")
 	return c, nil
 }
 
@@ -74,8 +95,17 @@ func (c *Command) Run(args []string) error {
 	if err != nil {
 		return err
 	}
+	var schema *pools.Schema
+	if c.schema != "" {
+		schema, err = pools.NewSchema(c.schema, c.schemaMode, c.shaper, c.quarantine)
+		if err != nil {
+			return err
+		}
+	} else if c.schemaMode != "" || c.shaper != "" || c.quarantine != "" {
+		return errors.New("schema flags require -schema")
+	}
 	poolName := args[0]
-	id, err := lake.CreatePool(ctx, poolName, sortKey, int(c.seekStride), int64(c.thresh))
+	id, err := lake.CreatePool(ctx, poolName, sortKey, int(c.seekStride), int64(c.thresh), c.compression, schema)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,149 @@
+package segments
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/dev/dig"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/vng"
+	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zson"
+)
+
+var Segments = &charm.Spec{
+	Name:  "segments",
+	Usage: "segments file",
+	Short: "read VNG file and output metadata for each primitive segment",
+	Long: `
+The segments command takes one file argument which must be a VNG file
+and outputs a record describing each segment of the file's primitive columns
+in any Zed format.  The column field holds the number of the column's type
+and the path of the column within the type ("root" for the column of type
+numbers).  Array and set elements are denoted by "[]", map keys and values
+by "{key}" and "{value}", and union values by "<n>" where n is the union tag.
+The compression and encoding fields show how the segment was stored.`,
+	New: New,
+}
+
+func init() {
+	dig.Cmd.Add(Segments)
+}
+
+type Command struct {
+	*dig.Command
+	outputFlags outputflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*dig.Command)}
+	c.outputFlags.SetFlags(f)
+	return c, nil
+}
+
+type Segment struct {
+	Column      string `zed:"column"`
+	Offset      int64  `zed:"offset"`
+	Length      int32  `zed:"length"`
+	MemLength   int32  `zed:"mem_length"`
+	Count       uint32 `zed:"count"`
+	Compression string `zed:"compression"`
+	Encoding    string `zed:"encoding"`
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) != 1 {
+		return errors.New("a single file required")
+	}
+	engine := storage.NewLocalEngine()
+	o, err := vng.NewObjectFromPath(ctx, zed.NewContext(), engine, args[0])
+	if err != nil {
+		return err
+	}
+	defer o.Close()
+	var segments []Segment
+	segments = appendSegments(segments, "root", o.Root)
+	for k, meta := range o.Maps {
+		segments = appendColumn(segments, strconv.Itoa(k), meta)
+	}
+	m := zson.NewZNGMarshaler()
+	var vals []zed.Value
+	for _, s := range segments {
+		val, err := m.Marshal(s)
+		if err != nil {
+			return err
+		}
+		vals = append(vals, *val)
+	}
+	writer, err := c.outputFlags.Open(ctx, engine)
+	if err != nil {
+		return err
+	}
+	if err := zio.Copy(writer, zbuf.NewArray(vals)); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func appendColumn(segments []Segment, path string, meta vector.Metadata) []Segment {
+	switch meta := vector.Under(meta).(type) {
+	case *vector.Record:
+		for _, f := range meta.Fields {
+			segments = appendColumn(segments, path+"."+f.Name, f.Values)
+		}
+	case *vector.Array:
+		segments = appendColumn(segments, path+"[]", meta.Values)
+	case *vector.Set:
+		segments = appendColumn(segments, path+"[]", meta.Values)
+	case *vector.Map:
+		segments = appendColumn(segments, path+"{key}", meta.Keys)
+		segments = appendColumn(segments, path+"{value}", meta.Values)
+	case *vector.Union:
+		for k, values := range meta.Values {
+			segments = appendColumn(segments, fmt.Sprintf("%s<%d>", path, k), values)
+		}
+	case *vector.Primitive:
+		segments = appendSegments(segments, path, meta.Segmap)
+	}
+	return segments
+}
+
+func appendSegments(segments []Segment, column string, segmap []vector.Segment) []Segment {
+	for _, s := range segmap {
+		segments = append(segments, Segment{
+			Column:      column,
+			Offset:      s.Offset,
+			Length:      s.Length,
+			MemLength:   s.MemLength,
+			Count:       s.Count,
+			Compression: compressionName(s.CompressionFormat),
+			Encoding:    vector.EncodingName(s.Encoding),
+		})
+	}
+	return segments
+}
+
+func compressionName(format uint8) string {
+	switch format {
+	case vector.CompressionFormatNone:
+		return "none"
+	case vector.CompressionFormatLZ4:
+		return "lz4"
+	case vector.CompressionFormatZstd:
+		return "zstd"
+	}
+	return "unknown"
+}
@@ -0,0 +1,54 @@
+package kill
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/pkg/charm"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "kill",
+	Usage: "kill request-id ...",
+	Short: "cancel queries running on a Zed lake service",
+	Long: `
+The kill command cancels each running query whose request ID is given.
+The request IDs of running queries are listed by "zed ps".
+A canceled query ends with a "query killed" error.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	return &Command{Command: parent.(*root.Command)}, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) == 0 {
+		return errors.New("kill command requires one or more request IDs")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	for _, id := range args {
+		if err := conn.KillQuery(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		if !c.LakeFlags.Quiet {
+			fmt.Printf("query killed: %s\n", id)
+		}
+	}
+	return nil
+}
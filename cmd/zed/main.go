@@ -3,9 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	_ "time/tzdata" // Embed time zones for hosts without a zoneinfo database.
 
 	"github.com/brimdata/zed/cmd/zed/auth"
 	"github.com/brimdata/zed/cmd/zed/branch"
+	"github.com/brimdata/zed/cmd/zed/checkpoint"
 	"github.com/brimdata/zed/cmd/zed/compact"
 	"github.com/brimdata/zed/cmd/zed/create"
 	zeddelete "github.com/brimdata/zed/cmd/zed/delete"
@@ -13,6 +15,7 @@ import (
 	_ "github.com/brimdata/zed/cmd/zed/dev/compile"
 	_ "github.com/brimdata/zed/cmd/zed/dev/dig/frames"
 	_ "github.com/brimdata/zed/cmd/zed/dev/dig/section"
+	_ "github.com/brimdata/zed/cmd/zed/dev/dig/segments"
 	_ "github.com/brimdata/zed/cmd/zed/dev/dig/slice"
 	_ "github.com/brimdata/zed/cmd/zed/dev/dig/trailer"
 	_ "github.com/brimdata/zed/cmd/zed/dev/indexfile"
@@ -23,16 +26,19 @@ import (
 	"github.com/brimdata/zed/cmd/zed/drop"
 	"github.com/brimdata/zed/cmd/zed/index"
 	zedinit "github.com/brimdata/zed/cmd/zed/init"
+	"github.com/brimdata/zed/cmd/zed/kill"
 	"github.com/brimdata/zed/cmd/zed/load"
 	"github.com/brimdata/zed/cmd/zed/log"
 	"github.com/brimdata/zed/cmd/zed/ls"
 	"github.com/brimdata/zed/cmd/zed/manage"
 	"github.com/brimdata/zed/cmd/zed/merge"
+	"github.com/brimdata/zed/cmd/zed/ps"
 	"github.com/brimdata/zed/cmd/zed/query"
 	"github.com/brimdata/zed/cmd/zed/rename"
 	"github.com/brimdata/zed/cmd/zed/revert"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/cmd/zed/serve"
+	"github.com/brimdata/zed/cmd/zed/tag"
 	"github.com/brimdata/zed/cmd/zed/use"
 	"github.com/brimdata/zed/cmd/zed/vacate"
 	"github.com/brimdata/zed/cmd/zed/vacuum"
@@ -43,21 +49,25 @@ func main() {
 	zed := root.Zed
 	zed.Add(auth.Cmd)
 	zed.Add(branch.Cmd)
+	zed.Add(checkpoint.Cmd)
 	zed.Add(compact.Cmd)
 	zed.Add(create.Cmd)
 	zed.Add(zeddelete.Cmd)
 	zed.Add(drop.Cmd)
 	zed.Add(index.Cmd)
 	zed.Add(zedinit.Cmd)
+	zed.Add(kill.Cmd)
 	zed.Add(load.Cmd)
 	zed.Add(log.Cmd)
 	zed.Add(ls.Cmd)
 	zed.Add(manage.Cmd)
 	zed.Add(merge.Cmd)
+	zed.Add(ps.Cmd)
 	zed.Add(query.Cmd)
 	zed.Add(rename.Cmd)
 	zed.Add(revert.Cmd)
 	zed.Add(serve.Cmd)
+	zed.Add(tag.Cmd)
 	zed.Add(use.Cmd)
 	zed.Add(vacate.Cmd)
 	zed.Add(vacuum.Cmd)
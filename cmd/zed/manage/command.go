@@ -15,7 +15,7 @@ import (
 var Cmd = &charm.Spec{
 	Name:  "manage",
 	Usage: "manage",
-	Short: "run compaction and other maintenance tasks on a lake",
+	Short: "run compaction, retention, and other maintenance tasks on a lake",
 	New:   New,
 }
 
@@ -39,6 +39,7 @@ func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 		return d.Decode(&c.config)
 	})
 	c.config.Interval = f.Duration("interval", lakemanage.DefaultInterval, "interval between updates (only applicable with -monitor")
+	f.BoolVar(&c.config.DryRun, "dryrun", false, "log compactions, vectors, and retention deletions without committing them")
 	f.BoolVar(&c.monitor, "monitor", false, "continuously monitor the lake for updates")
 	f.BoolVar(&c.config.Vectors, "vectors", false, "create vectors for objects")
 	return c, nil
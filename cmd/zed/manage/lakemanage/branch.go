@@ -14,6 +14,7 @@ import (
 
 type branch struct {
 	config PoolConfig
+	dryrun bool
 	lake   lakeapi.Interface
 	logger *zap.Logger
 	pool   *pools.Config
@@ -27,6 +28,7 @@ func newBranch(c Config, pool *pools.Config, lake lakeapi.Interface, logger *zap
 		zap.String("branch", config.Branch),
 		zap.Duration("interval", config.interval()),
 		zap.Bool("vectors", config.Vectors),
+		zap.Bool("retention", config.Retention != nil),
 	)
 	if config.interval() == 0 {
 		logger.Info("Manage disabled for branch")
@@ -34,6 +36,7 @@ func newBranch(c Config, pool *pools.Config, lake lakeapi.Interface, logger *zap
 	}
 	return &branch{
 		config: config,
+		dryrun: c.DryRun,
 		lake:   lake,
 		logger: logger,
 		pool:   pool,
@@ -41,8 +44,12 @@ func newBranch(c Config, pool *pools.Config, lake lakeapi.Interface, logger *zap
 }
 
 func (b *branch) run(ctx context.Context) error {
+	// Enforce retention first so data about to be deleted isn't compacted.
+	if err := b.retain(ctx); err != nil {
+		return err
+	}
 	b.logger.Debug("compaction started")
-	head := lakeparse.Commitish{Pool: b.pool.Name, Branch: b.config.Branch}
+	head := b.head()
 	it, err := newObjectIterator(ctx, b.lake, &head)
 	if err != nil {
 		return err
@@ -62,6 +69,11 @@ func (b *branch) run(ctx context.Context) error {
 	var vectors int
 	group.Go(func() error {
 		for run := range runCh {
+			if b.dryrun {
+				found++
+				compacted += len(run)
+				continue
+			}
 			commit, err := b.lake.Compact(ctx, b.pool.ID, b.config.Branch, run, b.config.Vectors, api.CommitMessage{})
 			if err != nil {
 				return err
@@ -82,6 +94,10 @@ func (b *branch) run(ctx context.Context) error {
 		if len(oids) == 0 {
 			return nil
 		}
+		if b.dryrun {
+			vectors += len(oids)
+			return nil
+		}
 		_, err := b.lake.AddVectors(ctx, head.Pool, head.Branch, oids, api.CommitMessage{})
 		if err == nil {
 			vectors += len(oids)
@@ -90,9 +106,14 @@ func (b *branch) run(ctx context.Context) error {
 	})
 	err = group.Wait()
 	b.logger.Info("compaction completed",
+		zap.Bool("dryrun", b.dryrun),
 		zap.Int("runs_found", found),
 		zap.Int("objects_compacted", compacted),
 		zap.Int("vectors_created", vectors),
 	)
 	return err
 }
+
+func (b *branch) head() lakeparse.Commitish {
+	return lakeparse.Commitish{Pool: b.pool.Name, Branch: b.config.Branch}
+}
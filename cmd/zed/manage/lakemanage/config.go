@@ -9,9 +9,11 @@ import (
 const DefaultInterval = time.Minute
 
 type Config struct {
-	Interval *time.Duration `yaml:"interval"`
-	Vectors  bool           `yaml:"vectors"`
-	Pools    []PoolConfig   `yaml:"pools"`
+	Interval  *time.Duration   `yaml:"interval"`
+	Vectors   bool             `yaml:"vectors"`
+	Retention *RetentionConfig `yaml:"retention"`
+	DryRun    bool             `yaml:"dryrun"`
+	Pools     []PoolConfig     `yaml:"pools"`
 }
 
 func (c *Config) poolConfig(p *pools.Config) PoolConfig {
@@ -25,21 +27,41 @@ func (c *Config) poolConfig(p *pools.Config) PoolConfig {
 		if pconf.Branch == "" {
 			pconf.Branch = "main"
 		}
+		if pconf.Retention == nil {
+			pconf.Retention = c.Retention
+		}
 		return pconf
 	}
 	return PoolConfig{
-		Pool:     p.Name,
-		Branch:   "main",
-		Interval: c.Interval,
-		Vectors:  c.Vectors,
+		Pool:      p.Name,
+		Branch:    "main",
+		Interval:  c.Interval,
+		Vectors:   c.Vectors,
+		Retention: c.Retention,
 	}
 }
 
 type PoolConfig struct {
-	Pool     string         `yaml:"pool"`
-	Branch   string         `yaml:"branch"`
-	Interval *time.Duration `yaml:"interval"`
-	Vectors  bool           `yaml:"vectors"`
+	Pool      string           `yaml:"pool"`
+	Branch    string           `yaml:"branch"`
+	Interval  *time.Duration   `yaml:"interval"`
+	Vectors   bool             `yaml:"vectors"`
+	Retention *RetentionConfig `yaml:"retention"`
+}
+
+// RetentionConfig limits the data kept in a pool.  Data exceeding a limit is
+// deleted, oldest first according to the pool key, and the objects no
+// longer referenced by the branch are then vacuumed.  A zero limit is no
+// limit.
+type RetentionConfig struct {
+	// MaxAge is the maximum age of a value as given by its pool key, which
+	// must be a time.
+	MaxAge *time.Duration `yaml:"max_age"`
+	// MaxSize is the maximum total size in bytes of the data objects of
+	// the branch.
+	MaxSize int64 `yaml:"max_size"`
+	// MaxObjects is the maximum number of data objects of the branch.
+	MaxObjects int `yaml:"max_objects"`
 }
 
 func (c *PoolConfig) interval() time.Duration {
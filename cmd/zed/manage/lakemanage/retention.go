@@ -0,0 +1,153 @@
+package lakemanage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+// retain enforces the retention policy of the branch by deleting the data
+// exceeding its limits and then vacuuming the deleted objects.
+func (b *branch) retain(ctx context.Context) error {
+	r := b.config.Retention
+	if r == nil {
+		return nil
+	}
+	var deleted bool
+	if r.MaxAge != nil && *r.MaxAge > 0 {
+		ok, err := b.retainAge(ctx, *r.MaxAge)
+		if err != nil {
+			return err
+		}
+		deleted = deleted || ok
+	}
+	if r.MaxSize > 0 || r.MaxObjects > 0 {
+		ok, err := b.retainSize(ctx, r)
+		if err != nil {
+			return err
+		}
+		deleted = deleted || ok
+	}
+	if !deleted || b.dryrun {
+		return nil
+	}
+	vacuumed, err := b.lake.Vacuum(ctx, b.pool.Name, b.config.Branch, false)
+	if err != nil {
+		return err
+	}
+	b.logger.Info("retention vacuumed", zap.Int("objects_vacuumed", len(vacuumed)))
+	return nil
+}
+
+// retainAge deletes the values whose pool key is older than maxAge.
+func (b *branch) retainAge(ctx context.Context, maxAge time.Duration) (bool, error) {
+	key := b.pool.SortKey.Primary()
+	if len(key) == 0 {
+		b.logger.Warn("retention max_age requires a pool key")
+		return false, nil
+	}
+	objects, err := b.objects(ctx)
+	if err != nil {
+		return false, err
+	}
+	cutoff := nano.Now().Sub(nano.Duration(maxAge))
+	var n int
+	for _, o := range objects {
+		// Objects are sorted by their minimum key so the expired
+		// objects come first.
+		if o.Min.Type != zed.TypeTime || zed.DecodeTime(o.Min.Bytes()) >= cutoff {
+			break
+		}
+		n++
+	}
+	if n == 0 {
+		return false, nil
+	}
+	summary := fmt.Sprintf("retention: delete values with %s before %s (max_age %s) from %d object%s",
+		key, cutoff.Time().Format(time.RFC3339Nano), maxAge, n, plural(n))
+	if b.dryrun {
+		b.logger.Info("retention dry run", zap.String("summary", summary))
+		return false, nil
+	}
+	where := fmt.Sprintf("%s < %s", key, cutoff.Time().Format(time.RFC3339Nano))
+	commit, err := b.lake.DeleteWhere(ctx, b.pool.ID, b.config.Branch, where, api.CommitMessage{Body: summary})
+	if err != nil {
+		return false, err
+	}
+	b.logger.Info("retention deleted", zap.Stringer("commit", commit), zap.String("summary", summary))
+	return true, nil
+}
+
+// retainSize deletes the oldest objects until the size and number of the
+// branch's objects are within the limits of r.
+func (b *branch) retainSize(ctx context.Context, r *RetentionConfig) (bool, error) {
+	objects, err := b.objects(ctx)
+	if err != nil {
+		return false, err
+	}
+	var size int64
+	for _, o := range objects {
+		size += o.Size
+	}
+	var ids []ksuid.KSUID
+	var bytes int64
+	var count uint64
+	for _, o := range objects {
+		if (r.MaxSize <= 0 || size <= r.MaxSize) && (r.MaxObjects <= 0 || len(objects)-len(ids) <= r.MaxObjects) {
+			break
+		}
+		ids = append(ids, o.ID)
+		size -= o.Size
+		bytes += o.Size
+		count += o.Count
+	}
+	if len(ids) == 0 {
+		return false, nil
+	}
+	summary := fmt.Sprintf("retention: delete %d object%s (%d bytes, %d values) exceeding max_size %d and max_objects %d",
+		len(ids), plural(len(ids)), bytes, count, r.MaxSize, r.MaxObjects)
+	if b.dryrun {
+		b.logger.Info("retention dry run", zap.String("summary", summary))
+		return false, nil
+	}
+	commit, err := b.lake.Delete(ctx, b.pool.ID, b.config.Branch, ids, api.CommitMessage{Body: summary})
+	if err != nil {
+		return false, err
+	}
+	b.logger.Info("retention deleted", zap.Stringer("commit", commit), zap.String("summary", summary))
+	return true, nil
+}
+
+// objects returns the objects of the branch sorted by their minimum key.
+func (b *branch) objects(ctx context.Context) ([]*object, error) {
+	head := b.head()
+	it, err := newObjectIterator(ctx, b.lake, &head)
+	if err != nil {
+		return nil, err
+	}
+	defer it.close()
+	var objects []*object
+	for {
+		o, err := it.next()
+		if err != nil {
+			return nil, err
+		}
+		if o == nil {
+			return objects, nil
+		}
+		objects = append(objects, o)
+	}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,73 @@
+package ps
+
+import (
+	"errors"
+	"flag"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "ps",
+	Usage: "ps [options]",
+	Short: "list queries running on a Zed lake service",
+	Long: `
+The ps command lists the queries running on the Zed lake service,
+showing for each its request ID, the tenant and user that issued it,
+its start time, its source text, and its progress so far.
+A running query may be canceled with "zed kill".
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	outputFlags outputflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	c.outputFlags.DefaultFormat = "table"
+	c.outputFlags.SetFlags(f)
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if len(args) > 0 {
+		return errors.New("ps command takes no arguments")
+	}
+	conn, err := c.LakeFlags.Connection()
+	if err != nil {
+		return err
+	}
+	infos, err := conn.ListQueries(ctx)
+	if err != nil {
+		return err
+	}
+	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	m := zson.NewZNGMarshaler()
+	for _, info := range infos {
+		val, err := m.Marshal(info)
+		if err != nil {
+			w.Close()
+			return err
+		}
+		if err := w.Write(val); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
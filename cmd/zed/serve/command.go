@@ -10,9 +10,12 @@ import (
 	"os/signal"
 	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/brimdata/zed/cli"
+	"github.com/brimdata/zed/cli/auto"
 	"github.com/brimdata/zed/cli/logflags"
+	"github.com/brimdata/zed/cli/runtimeflags"
 	"github.com/brimdata/zed/cmd/zed/root"
 	"github.com/brimdata/zed/lake/api"
 	"github.com/brimdata/zed/pkg/charm"
@@ -37,15 +40,19 @@ Requests may be issued to this service via the "zed api" command.
 
 type Command struct {
 	*root.Command
-	conf     service.Config
-	logflags logflags.Flags
+	conf         service.Config
+	logflags     logflags.Flags
+	runtimeFlags runtimeflags.Flags
 
 	// brimfd is a file descriptor passed through by Zui desktop. If set the
 	// command will exit if the fd is closed.
 	brimfd          int
 	listenAddr      string
 	portFile        string
+	queryMemMax     auto.Bytes
+	queryScanMax    auto.Bytes
 	rootContentFile string
+	vcacheMemMax    auto.Bytes
 }
 
 func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
@@ -53,6 +60,7 @@ func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	c.conf.Auth.SetFlags(f)
 	c.conf.Version = cli.Version()
 	c.logflags.SetFlags(f)
+	c.runtimeFlags.SetFlags(f)
 	f.IntVar(&c.brimfd, "brimfd", -1, "pipe read fd passed by Zui to signal Zui closure")
 	f.Func("cors.origin", "CORS allowed origin (may be repeated)", func(s string) error {
 		c.conf.CORSAllowedOrigins = append(c.conf.CORSAllowedOrigins, s)
@@ -61,18 +69,33 @@ func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
 	f.StringVar(&c.conf.DefaultResponseFormat, "defaultfmt", service.DefaultZedFormat, "default response format")
 	f.StringVar(&c.listenAddr, "l", ":9867", "[addr]:port to listen on")
 	f.StringVar(&c.portFile, "portfile", "", "write listen port to file")
+	f.Var(&c.queryMemMax, "query.maxmem", "maximum memory used by sort, summarize, and join in each query in MiB, MB, etc (0 for no limit)")
+	f.Int64Var(&c.conf.QueryLimits.RowsMax, "query.maxrows", 0, "maximum number of values output by each query (0 for no limit)")
+	f.Var(&c.queryScanMax, "query.maxscan", "maximum data read from the lake by each query in MiB, MB, etc (0 for no limit)")
+	f.DurationVar(&c.conf.QueryLimits.Timeout, "query.timeout", 0, "maximum duration of each query (0 for no limit)")
 	f.StringVar(&c.rootContentFile, "rootcontentfile", "", "file to serve for GET /")
+	f.DurationVar(&c.conf.VectorCache.IdleTimeout, "vcache.idletimeout", time.Minute, "duration after which an idle vector cache object closes its file (0 to never close)")
+	f.Var(&c.vcacheMemMax, "vcache.maxmem", "maximum memory held by the vector cache in MiB, MB, etc (0 for no limit)")
 	return c, nil
 }
 
 func (c *Command) Run(args []string) error {
 	// Don't include SIGPIPE here or else a write to a closed socket (i.e.,
 	// a broken network connection) will cancel the context on Linux.
-	ctx, cleanup, err := c.InitWithSignals(nil, syscall.SIGINT, syscall.SIGTERM)
+	ctx, cleanup, err := c.InitWithSignals([]cli.Initializer{&c.runtimeFlags}, syscall.SIGINT, syscall.SIGTERM)
 	if err != nil {
 		return err
 	}
 	defer cleanup()
+	if c.conf.QueryLimits.RowsMax < 0 || c.conf.QueryLimits.Timeout < 0 {
+		return errors.New("query limits must not be negative")
+	}
+	if c.conf.VectorCache.IdleTimeout < 0 {
+		return errors.New("vector cache idle timeout must not be negative")
+	}
+	c.conf.QueryLimits.MemMaxBytes = int64(c.queryMemMax.Bytes)
+	c.conf.QueryLimits.ScanMaxBytes = int64(c.queryScanMax.Bytes)
+	c.conf.VectorCache.MaxBytes = int64(c.vcacheMemMax.Bytes)
 	if c.conf.Root, err = c.LakeFlags.URI(); err != nil {
 		return err
 	}
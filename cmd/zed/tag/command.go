@@ -0,0 +1,139 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/brimdata/zed/cli/outputflags"
+	"github.com/brimdata/zed/cli/poolflags"
+	"github.com/brimdata/zed/cmd/zed/root"
+	"github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/charm"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio"
+)
+
+var Cmd = &charm.Spec{
+	Name:  "tag",
+	Usage: "tag [-d] new-tag",
+	Short: "create a tag naming a commit",
+	Long: `
+The lake tag command creates a tag with the indicated name for the commit
+at HEAD.  Unlike a branch, a tag never moves so a query of the tag,
+e.g., "from pool@tag", always sees the same data.  Tag and branch names
+share a namespace within a pool.
+
+If no branch is currently checked out or another commit is desired,
+then "-use pool@base" can be supplied, where base is a branch name,
+tag name, or commit ID.
+
+If the -d option is specified, then the tag is deleted.  No data is
+deleted by this operation.
+
+With no arguments, the tags of the pool are listed.
+`,
+	New: New,
+}
+
+type Command struct {
+	*root.Command
+	delete      bool
+	outputFlags outputflags.Flags
+	poolFlags   poolflags.Flags
+}
+
+func New(parent charm.Command, f *flag.FlagSet) (charm.Command, error) {
+	c := &Command{Command: parent.(*root.Command)}
+	f.BoolVar(&c.delete, "d", false, "delete the tag instead of creating it")
+	c.outputFlags.DefaultFormat = "lake"
+	c.outputFlags.SetFlags(f)
+	c.poolFlags.SetFlags(f)
+	return c, nil
+}
+
+func (c *Command) Run(args []string) error {
+	ctx, cleanup, err := c.Init(&c.outputFlags)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return errors.New("too many arguments")
+	}
+	defer cleanup()
+	lake, err := c.LakeFlags.Open(ctx)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return c.list(ctx, lake)
+	}
+	tagName := args[0]
+	head, err := c.poolFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	poolName := head.Pool
+	if poolName == "" {
+		return errors.New("a pool name must be included: pool@base")
+	}
+	poolID, err := lakeparse.ParseID(poolName)
+	if err != nil {
+		poolID, err = lake.PoolID(ctx, poolName)
+		if err != nil {
+			return err
+		}
+	}
+	if c.delete {
+		if err := lake.RemoveTag(ctx, poolID, tagName); err != nil {
+			return err
+		}
+		if !c.LakeFlags.Quiet {
+			fmt.Printf("tag deleted: %s\n", tagName)
+		}
+		return nil
+	}
+	commit, err := lakeparse.ParseID(head.Branch)
+	if err != nil {
+		commit, err = lake.CommitObject(ctx, poolID, head.Branch)
+		if err != nil {
+			return err
+		}
+	}
+	if err := lake.CreateTag(ctx, poolID, tagName, commit); err != nil {
+		return err
+	}
+	if !c.LakeFlags.Quiet {
+		fmt.Printf("%q: tag created at commit %s\n", tagName, commit)
+	}
+	return nil
+}
+
+func (c *Command) list(ctx context.Context, lake api.Interface) error {
+	head, err := c.poolFlags.HEAD()
+	if err != nil {
+		return err
+	}
+	poolName := head.Pool
+	if poolName == "" {
+		return errors.New("must be on a checked out branch to list the tags in the same pool")
+	}
+	query := fmt.Sprintf("from '%s':tags | sort tag.name", poolName)
+	w, err := c.outputFlags.Open(ctx, storage.NewLocalEngine())
+	if err != nil {
+		return err
+	}
+	q, err := lake.Query(ctx, nil, query)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	defer q.Close()
+	err = zio.Copy(w, q)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -331,6 +331,17 @@ type (
 		Kind string `json:"kind" unpack:""`
 		Expr Expr   `json:"expr"`
 	}
+	// A Window computes window functions over partitions of its input
+	// given by Keys, with each partition processed in the order given by
+	// OrderBy and Order.  Each function in Funcs is a Call to a window or
+	// aggregate function.
+	Window struct {
+		Kind    string       `json:"kind" unpack:""`
+		Funcs   []Assignment `json:"funcs"`
+		Keys    []Expr       `json:"keys"`
+		OrderBy []Expr       `json:"order_by"`
+		Order   order.Which  `json:"order"`
+	}
 	Yield struct {
 		Kind  string `json:"kind" unpack:""`
 		Exprs []Expr `json:"exprs"`
@@ -360,8 +371,8 @@ type (
 		Kind       string       `json:"kind" unpack:""`
 		Style      string       `json:"style"`
 		RightInput Seq          `json:"right_input"`
-		LeftKey    Expr         `json:"left_key"`
-		RightKey   Expr         `json:"right_key"`
+		LeftKeys   []Expr       `json:"left_keys"`
+		RightKeys  []Expr       `json:"right_keys"`
 		Args       []Assignment `json:"args"`
 	}
 	Sample struct {
@@ -521,6 +532,7 @@ func (*Over) OpAST()         {}
 func (*Search) OpAST()       {}
 func (*Where) OpAST()        {}
 func (*Yield) OpAST()        {}
+func (*Window) OpAST()       {}
 func (*Sample) OpAST()       {}
 func (*Load) OpAST()         {}
 
@@ -535,10 +547,12 @@ func (seq *Seq) Prepend(front Op) {
 // an expression applied to the incoming records that is operated upon by them
 // aggregate function.  If Expr isn't present, then the aggregator doesn't act
 // upon a function of the record, e.g., count() counts up records without
-// looking into them.
+// looking into them.  Aggregate functions that take more than one argument,
+// e.g., quantile(x, q), carry the arguments following Expr in the Args field.
 type Agg struct {
 	Kind  string `json:"kind" unpack:""`
 	Name  string `json:"name"`
 	Expr  Expr   `json:"expr"`
+	Args  []Expr `json:"args"`
 	Where Expr   `json:"where"`
 }
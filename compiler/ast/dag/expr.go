@@ -19,6 +19,7 @@ type (
 		Kind  string `json:"kind" unpack:""`
 		Name  string `json:"name"`
 		Expr  Expr   `json:"expr"`
+		Args  []Expr `json:"args"`
 		Where Expr   `json:"where"`
 	}
 	ArrayExpr struct {
@@ -60,13 +60,13 @@ type (
 		Count int    `json:"count"`
 	}
 	Join struct {
-		Kind     string          `json:"kind" unpack:""`
-		Style    string          `json:"style"`
-		LeftKey  Expr            `json:"left_key"`
-		LeftDir  order.Direction `json:"left_dir"`
-		RightKey Expr            `json:"right_key"`
-		RightDir order.Direction `json:"right_dir"`
-		Args     []Assignment    `json:"args"`
+		Kind      string          `json:"kind" unpack:""`
+		Style     string          `json:"style"`
+		LeftKeys  []Expr          `json:"left_keys"`
+		LeftDir   order.Direction `json:"left_dir"`
+		RightKeys []Expr          `json:"right_keys"`
+		RightDir  order.Direction `json:"right_dir"`
+		Args      []Assignment    `json:"args"`
 	}
 	Load struct {
 		Kind    string      `json:"kind" unpack:""`
@@ -146,6 +146,14 @@ type (
 		Kind  string `json:"kind" unpack:""`
 		Cflag bool   `json:"cflag"`
 	}
+	Window struct {
+		Kind         string       `json:"kind" unpack:""`
+		Funcs        []Assignment `json:"funcs"`
+		Keys         []Expr       `json:"keys"`
+		OrderBy      []Expr       `json:"order_by"`
+		Order        order.Which  `json:"order"`
+		InputSortDir int          `json:"input_sort_dir,omitempty"`
+	}
 	Yield struct {
 		Kind  string `json:"kind" unpack:""`
 		Exprs []Expr `json:"exprs"`
@@ -167,8 +175,15 @@ type (
 	SeqScan struct {
 		Kind      string      `json:"kind" unpack:""`
 		Pool      ksuid.KSUID `json:"pool"`
+		Commit    ksuid.KSUID `json:"commit"`
 		Filter    Expr        `json:"filter"`
 		KeyPruner Expr        `json:"key_pruner"`
+		// If Fields is non-nil, objects with vectors are read from
+		// the vector cache with each record cut to these top-level
+		// fields.  If Summarize is also non-nil, the scan emits its
+		// partial aggregations in place of values.
+		Fields    []string   `json:"fields"`
+		Summarize *Summarize `json:"summarize"`
 	}
 	Deleter struct {
 		Kind      string      `json:"kind" unpack:""`
@@ -231,13 +246,16 @@ type (
 )
 
 var LakeMetas = map[string]struct{}{
+	"audit":       {},
 	"branches":    {},
 	"index_rules": {},
 	"pools":       {},
+	"tags":        {},
 }
 
 var PoolMetas = map[string]struct{}{
 	"branches": {},
+	"tags":     {},
 }
 
 var CommitMetas = map[string]struct{}{
@@ -301,6 +319,7 @@ func (*Merge) OpNode()     {}
 func (*Combine) OpNode()   {}
 func (*Scope) OpNode()     {}
 func (*Load) OpNode()      {}
+func (*Window) OpNode()    {}
 
 // NewFilter returns a filter node for e.
 func NewFilter(e Expr) *Filter {
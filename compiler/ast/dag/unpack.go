@@ -61,6 +61,7 @@ var unpacker = unpack.New(
 	Uniq{},
 	Var{},
 	VectorValue{},
+	Window{},
 	Yield{},
 )
 
@@ -88,6 +88,7 @@ var unpacker = unpack.New(
 	Uniq{},
 	VectorValue{},
 	Where{},
+	Window{},
 	Yield{},
 	Sample{},
 )
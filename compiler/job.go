@@ -46,7 +46,7 @@ func NewJob(octx *op.Context, in ast.Seq, src *data.Source, head *lakeparse.Comm
 	}
 	return &Job{
 		octx:      octx,
-		builder:   kernel.NewBuilder(octx, src),
+		builder:   kernel.NewBuilder(octx, src, &anyCompiler{}),
 		optimizer: optimizer.New(octx.Context, src),
 		entry:     entry,
 	}, nil
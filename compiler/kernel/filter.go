@@ -2,6 +2,7 @@ package kernel
 
 import (
 	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/compiler/optimizer"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/zbuf"
 )
@@ -27,6 +28,17 @@ func (f *Filter) AsBufferFilter() (*expr.BufferFilter, error) {
 	return CompileBufferFilter(f.builder.octx.Zctx, f.pushdown)
 }
 
+func (f *Filter) AsPruner() (expr.Evaluator, error) {
+	if f == nil {
+		return nil, nil
+	}
+	e := optimizer.NewStatsPruner(f.pushdown)
+	if e == nil {
+		return nil, nil
+	}
+	return f.builder.compileExpr(e)
+}
+
 type DeleteFilter struct {
 	*Filter
 }
@@ -58,3 +70,7 @@ func (f *DeleteFilter) AsEvaluator() (expr.Evaluator, error) {
 func (f *DeleteFilter) AsBufferFilter() (*expr.BufferFilter, error) {
 	return nil, nil
 }
+
+func (f *DeleteFilter) AsPruner() (expr.Evaluator, error) {
+	return nil, nil
+}
@@ -62,6 +62,10 @@ func (b *Builder) compileAgg(agg *dag.Agg) (*expr.Aggregator, error) {
 			return nil, err
 		}
 	}
+	args, err := b.compileExprs(agg.Args)
+	if err != nil {
+		return nil, err
+	}
 	var where expr.Evaluator
 	if agg.Where != nil {
 		where, err = b.compileExpr(agg.Where)
@@ -69,5 +73,5 @@ func (b *Builder) compileAgg(agg *dag.Agg) (*expr.Aggregator, error) {
 			return nil, err
 		}
 	}
-	return expr.NewAggregator(name, arg, where)
+	return expr.NewAggregator(name, arg, args, where)
 }
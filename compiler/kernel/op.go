@@ -15,6 +15,7 @@ import (
 	"github.com/brimdata/zed/lake"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/runtime/op/combine"
@@ -48,17 +49,19 @@ type Builder struct {
 	octx     *op.Context
 	mctx     *zed.Context
 	source   *data.Source
+	compiler runtime.Compiler
 	readers  []zio.Reader
 	progress *zbuf.Progress
 	deletes  *sync.Map
 	funcs    map[string]expr.Function
 }
 
-func NewBuilder(octx *op.Context, source *data.Source) *Builder {
+func NewBuilder(octx *op.Context, source *data.Source, compiler runtime.Compiler) *Builder {
 	return &Builder{
-		octx:   octx,
-		mctx:   zed.NewContext(),
-		source: source,
+		octx:     octx,
+		mctx:     zed.NewContext(),
+		source:   source,
+		compiler: compiler,
 		progress: &zbuf.Progress{
 			BytesRead:      0,
 			BytesMatched:   0,
@@ -95,6 +98,8 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 	switch v := o.(type) {
 	case *dag.Summarize:
 		return b.compileGroupBy(parent, v)
+	case *dag.Window:
+		return b.compileWindow(parent, v)
 	case *dag.Cut:
 		assignments, err := b.compileAssignments(v.Args)
 		if err != nil {
@@ -276,6 +281,13 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 				return nil, err
 			}
 		}
+		if v.Fields != nil {
+			vectors, err := b.compileVectorScan(pool, v)
+			if err != nil {
+				return nil, err
+			}
+			return meta.NewVectorSequenceScanner(b.octx, parent, pool, b.PushdownOf(v.Filter), pruner, b.progress, vectors), nil
+		}
 		return meta.NewSequenceScanner(b.octx, parent, pool, b.PushdownOf(v.Filter), pruner, b.progress), nil
 	case *dag.Deleter:
 		pool, err := b.lookupPool(v.Pool)
@@ -298,7 +310,7 @@ func (b *Builder) compileLeaf(o dag.Op, parent zbuf.Puller) (zbuf.Puller, error)
 		}
 		return meta.NewDeleter(b.octx, parent, pool, filter, pruner, b.progress, b.deletes), nil
 	case *dag.Load:
-		return load.New(b.octx, b.source.Lake(), parent, v.Pool, v.Branch, v.Author, v.Message, v.Meta), nil
+		return load.New(b.octx, b.source.Lake(), b.compiler, parent, v.Pool, v.Branch, v.Author, v.Message, v.Meta), nil
 	default:
 		return nil, fmt.Errorf("unknown DAG operator type: %v", v)
 	}
@@ -373,6 +385,42 @@ func splitAssignments(assignments []expr.Assignment) ([]*expr.Lval, []expr.Evalu
 	return lhs, rhs
 }
 
+// compileJoinKeyLvals returns for each left key of a full join the lval of
+// the field that holds the right key of a right value without a match.  The
+// lval is nil if the key is not a field or overlaps an assigned field or an
+// earlier key.
+func (b *Builder) compileJoinKeyLvals(keys []dag.Expr, args []dag.Assignment) ([]*expr.Lval, error) {
+	var paths []field.Path
+	for _, a := range args {
+		if this, ok := a.LHS.(*dag.This); ok {
+			paths = append(paths, this.Path)
+		}
+	}
+	lvals := make([]*expr.Lval, len(keys))
+	for k, key := range keys {
+		this, ok := key.(*dag.This)
+		if !ok || overlaps(this.Path, paths) {
+			continue
+		}
+		lval, err := b.compileLval(this)
+		if err != nil {
+			return nil, err
+		}
+		lvals[k] = lval
+		paths = append(paths, this.Path)
+	}
+	return lvals, nil
+}
+
+func overlaps(path field.Path, paths []field.Path) bool {
+	for _, p := range paths {
+		if path.HasPrefix(p) || p.HasPrefix(path) {
+			return true
+		}
+	}
+	return false
+}
+
 func (b *Builder) compileSeq(seq dag.Seq, parents []zbuf.Puller) ([]zbuf.Puller, error) {
 	for _, o := range seq {
 		var err error
@@ -534,31 +582,46 @@ func (b *Builder) compile(o dag.Op, parents []zbuf.Puller) ([]zbuf.Puller, error
 			return nil, err
 		}
 		lhs, rhs := splitAssignments(assignments)
-		leftKey, err := b.compileExpr(o.LeftKey)
+		leftKeys, err := b.compileExprs(o.LeftKeys)
 		if err != nil {
 			return nil, err
 		}
-		rightKey, err := b.compileExpr(o.RightKey)
+		rightKeys, err := b.compileExprs(o.RightKeys)
 		if err != nil {
 			return nil, err
 		}
 		leftParent, rightParent := parents[0], parents[1]
 		leftDir, rightDir := o.LeftDir, o.RightDir
-		var anti, inner bool
+		var anti, inner, full bool
+		var keys []*expr.Lval
 		switch o.Style {
 		case "anti":
 			anti = true
+		case "full":
+			full = true
+			if keys, err = b.compileJoinKeyLvals(o.LeftKeys, o.Args); err != nil {
+				return nil, err
+			}
 		case "inner":
 			inner = true
 		case "left":
 		case "right":
-			leftKey, rightKey = rightKey, leftKey
+			leftKeys, rightKeys = rightKeys, leftKeys
 			leftParent, rightParent = rightParent, leftParent
 			leftDir, rightDir = rightDir, leftDir
 		default:
 			return nil, fmt.Errorf("unknown kind of join: '%s'", o.Style)
 		}
-		join, err := join.New(b.octx, anti, inner, leftParent, rightParent, leftKey, rightKey, leftDir, rightDir, lhs, rhs)
+		if join.HashMemMaxBytes > 0 && (leftDir == order.Unknown || rightDir == order.Unknown) {
+			// At least one input is not known to be sorted by
+			// its join key, so a hash join avoids sorting.
+			join, err := join.NewHash(b.octx, anti, inner, full, leftParent, rightParent, leftKeys, rightKeys, leftDir, lhs, rhs, keys, join.HashMemMaxBytes)
+			if err != nil {
+				return nil, err
+			}
+			return []zbuf.Puller{join}, nil
+		}
+		join, err := join.New(b.octx, anti, inner, full, leftParent, rightParent, leftKeys, rightKeys, leftDir, rightDir, lhs, rhs, keys)
 		if err != nil {
 			return nil, err
 		}
@@ -637,14 +700,14 @@ func (b *Builder) evalAtCompileTime(in dag.Expr) (val *zed.Value, err error) {
 }
 
 func compileExpr(in dag.Expr) (expr.Evaluator, error) {
-	b := NewBuilder(op.NewContext(context.Background(), zed.NewContext(), nil), nil)
+	b := NewBuilder(op.NewContext(context.Background(), zed.NewContext(), nil), nil, nil)
 	return b.compileExpr(in)
 }
 
 func EvalAtCompileTime(zctx *zed.Context, in dag.Expr) (val *zed.Value, err error) {
 	// We pass in a nil adaptor, which causes a panic for anything adaptor
 	// related, which is not currently allowed in an expression sub-query.
-	b := NewBuilder(op.NewContext(context.Background(), zctx, nil), nil)
+	b := NewBuilder(op.NewContext(context.Background(), zctx, nil), nil, nil)
 	return b.evalAtCompileTime(in)
 }
 
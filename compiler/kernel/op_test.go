@@ -0,0 +1,34 @@
+package kernel_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/compiler/data"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/op/join"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/stretchr/testify/require"
+)
+
+func buildJoin(t *testing.T, query string) zbuf.Puller {
+	zctx := zed.NewContext()
+	octx := op.NewContext(context.Background(), zctx, nil)
+	job, err := compiler.NewJob(octx, compiler.MustParse(query), data.NewSource(nil, nil), nil)
+	require.NoError(t, err)
+	require.NoError(t, job.Optimize())
+	r := zsonio.NewReader(zctx, strings.NewReader("{a:1}"))
+	outputs, err := job.Builder().Build(job.Entry(), r)
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	return outputs[0]
+}
+
+func TestJoinUnsortedUsesHash(t *testing.T) {
+	require.IsType(t, &join.HashOp{}, buildJoin(t, "fork (=> pass => pass) | join on a=a"))
+	require.IsType(t, &join.Op{}, buildJoin(t, "sort a | fork (=> pass => pass) | join on a=a"))
+}
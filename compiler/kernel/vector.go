@@ -0,0 +1,168 @@
+package kernel
+
+import (
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/compiler/optimizer"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op/groupby"
+	"github.com/brimdata/zed/runtime/op/meta"
+	"github.com/brimdata/zed/runtime/vcache"
+	"github.com/brimdata/zed/zbuf"
+)
+
+func (b *Builder) compileVectorScan(pool *lake.Pool, scan *dag.SeqScan) (*meta.VectorScan, error) {
+	snap, err := pool.Snapshot(b.octx.Context, scan.Commit)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := b.compileVectorFilter(scan.Filter)
+	if err != nil {
+		return nil, err
+	}
+	vectors := &meta.VectorScan{
+		Cache:    b.source.Lake().VectorCache(),
+		Snapshot: snap,
+		Fields:   scan.Fields,
+		Filter:   filter,
+	}
+	if summarize := scan.Summarize; summarize != nil {
+		if vectors.Summarize, err = b.compileVectorSummarize(summarize); err != nil {
+			return nil, err
+		}
+		// Objects without vectors are aggregated by a group-by
+		// operator built for each object from these expressions, which
+		// we compile once since a scanner reads one object at a time.
+		keys, err := b.compileAssignments(summarize.Keys)
+		if err != nil {
+			return nil, err
+		}
+		names, aggs, err := b.compileAggAssignments(summarize.Aggs)
+		if err != nil {
+			return nil, err
+		}
+		vectors.GroupBy = func(parent zbuf.Puller) (zbuf.Puller, error) {
+			return groupby.New(b.octx, parent, keys, names, aggs, summarize.Limit, 0, false, true)
+		}
+	}
+	return vectors, nil
+}
+
+// compileVectorFilter splits the conjunction e into the terms that depend
+// on a single top-level field, which are evaluated a column at a time, and
+// the remaining terms, which are evaluated for each value.
+func (b *Builder) compileVectorFilter(e dag.Expr) (*vcache.Filter, error) {
+	if e == nil {
+		return nil, nil
+	}
+	var columns []vcache.ColumnFilter
+	var residual dag.Expr
+	for _, term := range splitConjunction(e) {
+		if field, columnExpr, ok := columnOf(term); ok {
+			evaluator, err := b.compileExpr(columnExpr)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, vcache.ColumnFilter{Field: field, Expr: evaluator})
+			continue
+		}
+		if residual == nil {
+			residual = term
+		} else {
+			residual = dag.NewBinaryExpr("and", residual, term)
+		}
+	}
+	var residualEvaluator expr.Evaluator
+	if residual != nil {
+		var err error
+		if residualEvaluator, err = b.compileExpr(residual); err != nil {
+			return nil, err
+		}
+	}
+	full, err := b.compileExpr(e)
+	if err != nil {
+		return nil, err
+	}
+	var pruner expr.Evaluator
+	if p := optimizer.NewStatsPruner(e); p != nil {
+		if pruner, err = b.compileExpr(p); err != nil {
+			return nil, err
+		}
+	}
+	return vcache.NewFilter(columns, residualEvaluator, full, pruner), nil
+}
+
+func (b *Builder) compileVectorSummarize(summarize *dag.Summarize) (*vcache.Summarize, error) {
+	var s vcache.Summarize
+	for _, key := range summarize.Keys {
+		s.Keys = append(s.Keys, vcache.SummarizeKey{
+			Name:  key.LHS.(*dag.This).Path,
+			Field: key.RHS.(*dag.This).Path[0],
+		})
+	}
+	for _, assignment := range summarize.Aggs {
+		name, agg, err := b.compileAggAssignment(assignment)
+		if err != nil {
+			return nil, err
+		}
+		var field string
+		if this, ok := assignment.RHS.(*dag.Agg).Expr.(*dag.This); ok {
+			field = this.Path[0]
+		}
+		s.Aggs = append(s.Aggs, vcache.SummarizeAgg{
+			Name:  name,
+			Agg:   agg,
+			Field: field,
+		})
+	}
+	return &s, nil
+}
+
+func splitConjunction(e dag.Expr) []dag.Expr {
+	if b, ok := e.(*dag.BinaryExpr); ok && b.Op == "and" {
+		return append(splitConjunction(b.LHS), splitConjunction(b.RHS)...)
+	}
+	return []dag.Expr{e}
+}
+
+// columnOf determines whether e depends only on the value of a single
+// top-level field.  If so, it returns the name of the field and a copy of e
+// that refers to the field's value as "this".
+func columnOf(e dag.Expr) (string, dag.Expr, bool) {
+	var field string
+	var rewrite func(dag.Expr) (dag.Expr, bool)
+	rewrite = func(e dag.Expr) (dag.Expr, bool) {
+		switch e := e.(type) {
+		case *dag.This:
+			if len(e.Path) == 0 || (field != "" && e.Path[0] != field) {
+				return nil, false
+			}
+			field = e.Path[0]
+			return &dag.This{Kind: "This", Path: e.Path[1:]}, true
+		case *dag.Dot:
+			lhs, ok := rewrite(e.LHS)
+			if !ok {
+				return nil, false
+			}
+			return &dag.Dot{Kind: "Dot", LHS: lhs, RHS: e.RHS}, true
+		case *dag.BinaryExpr:
+			lhs, ok := rewrite(e.LHS)
+			if !ok {
+				return nil, false
+			}
+			rhs, ok := rewrite(e.RHS)
+			if !ok {
+				return nil, false
+			}
+			return dag.NewBinaryExpr(e.Op, lhs, rhs), true
+		case *dag.Literal:
+			return e, true
+		}
+		return nil, false
+	}
+	columnExpr, ok := rewrite(e)
+	if !ok || field == "" {
+		return "", nil, false
+	}
+	return field, columnExpr, true
+}
@@ -0,0 +1,79 @@
+package kernel
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op/sort"
+	"github.com/brimdata/zed/runtime/op/window"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zson"
+)
+
+func (b *Builder) compileWindow(parent zbuf.Puller, w *dag.Window) (zbuf.Puller, error) {
+	keys, err := b.compileExprs(w.Keys)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := b.compileExprs(w.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	lvals := make([]*expr.Lval, 0, len(w.Funcs))
+	funcs := make([]window.Func, 0, len(w.Funcs))
+	for _, assignment := range w.Funcs {
+		lval, err := b.compileLval(assignment.LHS)
+		if err != nil {
+			return nil, err
+		}
+		f, err := b.compileWindowFunc(assignment.RHS)
+		if err != nil {
+			return nil, fmt.Errorf("window: %w", err)
+		}
+		lvals = append(lvals, lval)
+		funcs = append(funcs, f)
+	}
+	if len(orderBy) > 0 && !order.Direction(w.InputSortDir).HasOrder(w.Order) {
+		// The input isn't known to be in window order so sort it.
+		parent, err = sort.New(b.octx, parent, orderBy, w.Order, false)
+		if err != nil {
+			return nil, fmt.Errorf("compiling window: %w", err)
+		}
+	}
+	return window.New(b.octx, parent, keys, orderBy, lvals, funcs), nil
+}
+
+func (b *Builder) compileWindowFunc(e dag.Expr) (window.Func, error) {
+	switch e := e.(type) {
+	case *dag.Agg:
+		agg, err := b.compileAgg(e)
+		if err != nil {
+			return nil, err
+		}
+		return window.NewAggFunc(agg), nil
+	case *dag.Call:
+		var offset int
+		args := e.Args
+		if (e.Name == "lag" || e.Name == "lead") && len(args) > 1 {
+			// The offset of lag and lead must be a constant.
+			val, err := b.evalAtCompileTime(args[1])
+			if err != nil {
+				return nil, err
+			}
+			if !zed.IsInteger(val.Type.ID()) || val.AsInt() < 1 {
+				return nil, fmt.Errorf("%s: offset is not a positive integer: %s", e.Name, zson.FormatValue(val))
+			}
+			offset = int(val.AsInt())
+			args = append([]dag.Expr{args[0]}, args[2:]...)
+		}
+		exprs, err := b.compileExprs(args)
+		if err != nil {
+			return nil, err
+		}
+		return window.NewFunc(e.Name, exprs, offset)
+	}
+	return nil, fmt.Errorf("internal error: unknown window function expression %T", e)
+}
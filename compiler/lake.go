@@ -110,7 +110,7 @@ func newDeleteJob(octx *op.Context, in ast.Seq, src *data.Source, head *lakepars
 	}
 	return &Job{
 		octx:      octx,
-		builder:   kernel.NewBuilder(octx, src),
+		builder:   kernel.NewBuilder(octx, src, &anyCompiler{}),
 		optimizer: optimizer.New(octx.Context, src),
 		entry:     entry,
 	}, nil
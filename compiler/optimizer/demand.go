@@ -80,10 +80,13 @@ func inferDemandExprIn(demandOut demand.Demand, expr dag.Expr) demand.Demand {
 	switch expr := expr.(type) {
 	case *dag.Agg:
 		// Since we don't know how the expr.Name will transform the inputs, we have to assume demand.All.
-		return demand.Union(
+		demandIn = demand.Union(
 			inferDemandExprIn(demand.All(), expr.Expr),
 			inferDemandExprIn(demand.All(), expr.Where),
 		)
+		for _, arg := range expr.Args {
+			demandIn = demand.Union(demandIn, inferDemandExprIn(demand.All(), arg))
+		}
 	case *dag.BinaryExpr:
 		// Since we don't know how the expr.Op will transform the inputs, we have to assume demand.All.
 		demandIn = demand.Union(
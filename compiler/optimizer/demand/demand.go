@@ -1,5 +1,7 @@
 package demand
 
+import "sort"
+
 type Demand interface {
 	isDemand()
 }
@@ -98,3 +100,20 @@ func GetKey(demand Demand, key string) Demand {
 		panic("Unreachable")
 	}
 }
+
+// Keys returns the sorted keys of demand or nil if demand is All.
+func Keys(demand Demand) []string {
+	switch demand := demand.(type) {
+	case all:
+		return nil
+	case keys:
+		keys := make([]string, 0, len(demand))
+		for k := range demand {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	default:
+		panic("Unreachable")
+	}
+}
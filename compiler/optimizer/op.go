@@ -28,6 +28,8 @@ func (o *Optimizer) analyzeSortKey(op dag.Op, in order.SortKey) (order.SortKey,
 		return pool.SortKey, nil
 	case *dag.Sort:
 		return sortKeyOfSort(op), nil
+	case *dag.Window:
+		return sortKeyOfWindow(op, in), nil
 	}
 	// We should handle secondary keys at some point.
 	// See issue #2657.
@@ -35,12 +37,17 @@ func (o *Optimizer) analyzeSortKey(op dag.Op, in order.SortKey) (order.SortKey,
 	if key == nil {
 		return order.Nil, nil
 	}
-	switch op := op.(type) {
+	switch op.(type) {
 	case *dag.Lister:
 		// This shouldn't happen.
 		return order.Nil, errors.New("internal error: dag.Lister encountered in anaylzeSortKey")
 	case *dag.Filter, *dag.Head, *dag.Pass, *dag.Uniq, *dag.Tail, *dag.Fuse:
 		return in, nil
+	}
+	// The remaining operators may modify values so only the primary key
+	// is carried through.
+	in = order.NewSortKey(in.Order, field.List{key})
+	switch op := op.(type) {
 	case *dag.Cut:
 		return analyzeCuts(op.Args, in), nil
 	case *dag.Drop:
@@ -77,11 +84,18 @@ func (o *Optimizer) analyzeSortKey(op dag.Op, in order.SortKey) (order.SortKey,
 }
 
 func sortKeyOfSort(op *dag.Sort) order.SortKey {
-	// XXX Only single sort keys.  See issue #2657.
-	if len(op.Args) != 1 {
+	var keys field.List
+	for _, e := range op.Args {
+		key := fieldOf(e)
+		if key == nil {
+			return order.Nil
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
 		return order.Nil
 	}
-	return sortKeyOfExpr(op.Args[0], op.Order)
+	return order.NewSortKey(op.Order, keys)
 }
 
 func sortKeyOfExpr(e dag.Expr, o order.Which) order.SortKey {
@@ -92,6 +106,47 @@ func sortKeyOfExpr(e dag.Expr, o order.Which) order.SortKey {
 	return order.NewSortKey(o, field.List{key})
 }
 
+// isOrderOfWindow returns true iff the window's order keys are a prefix of
+// the given sort order and have the same direction.
+func isOrderOfWindow(window *dag.Window, in order.SortKey) bool {
+	if len(window.OrderBy) == 0 || len(window.OrderBy) > len(in.Keys) || window.Order != in.Order {
+		return false
+	}
+	for k, e := range window.OrderBy {
+		if !fieldOf(e).Equal(in.Keys[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKeyOfWindow returns the sort order of the output of a window operator
+// whose input has order in.  The window operator sorts its input by its
+// order expressions unless it's already in that order.  A partitioned window
+// may emit the values of each partition separately, so its output has no
+// known order.
+func sortKeyOfWindow(window *dag.Window, in order.SortKey) order.SortKey {
+	if len(window.Keys) > 0 {
+		return order.Nil
+	}
+	out := in
+	if len(window.OrderBy) > 0 && !isOrderOfWindow(window, in) {
+		// Input sorted by all of the order expressions is sorted by
+		// the first.  XXX Only single sort keys.  See issue #2657.
+		out = sortKeyOfExpr(window.OrderBy[0], window.Order)
+	}
+	key := out.Primary()
+	if key == nil {
+		return order.Nil
+	}
+	for _, assignment := range window.Funcs {
+		if fieldOf(assignment.LHS).Equal(key) {
+			return order.Nil
+		}
+	}
+	return out
+}
+
 // isKeyOfSummarize returns true iff its any of the groupby keys is the
 // same as the given primary-key sort order or an order-preserving function
 // thereof.
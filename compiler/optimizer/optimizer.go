@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/brimdata/zed/compiler/ast/dag"
 	"github.com/brimdata/zed/compiler/data"
@@ -226,12 +227,17 @@ func (o *Optimizer) optimizeSourcePaths(seq dag.Seq) (dag.Seq, error) {
 			if orderRequired {
 				seq = append(seq, &dag.Slicer{Kind: "Slicer"})
 			}
-			seq = append(seq, &dag.SeqScan{
+			scan := &dag.SeqScan{
 				Kind:      "SeqScan",
 				Pool:      op.ID,
+				Commit:    op.Commit,
 				Filter:    filter,
 				KeyPruner: lister.KeyPruner,
-			})
+			}
+			if err := o.vectorizeScan(scan, chain, sortKey); err != nil {
+				return nil, err
+			}
+			seq = append(seq, scan)
 			seq = append(seq, chain...)
 		case *dag.FileScan:
 			op.Filter = filter
@@ -280,10 +286,13 @@ func (o *Optimizer) propagateSortKeyOp(op dag.Op, parents []order.SortKey) ([]or
 		if len(parents) != 2 {
 			return nil, errors.New("internal error: join does not have two parents")
 		}
-		if fieldOf(join.LeftKey).Equal(parents[0].Primary()) {
+		// The join merges its inputs on the first key and matches any
+		// others within each run of equal first keys, so the direction
+		// of an input is that of its primary key.
+		if fieldOf(join.LeftKeys[0]).Equal(parents[0].Primary()) {
 			join.LeftDir = parents[0].Order.Direction()
 		}
-		if fieldOf(join.RightKey).Equal(parents[1].Primary()) {
+		if fieldOf(join.RightKeys[0]).Equal(parents[1].Primary()) {
 			join.RightDir = parents[1].Order.Direction()
 		}
 		// XXX There is definitely a way to propagate the sort key but there's
@@ -330,6 +339,14 @@ func (o *Optimizer) propagateSortKeyOp(op dag.Op, parents []order.SortKey) ([]or
 		// and not try to optimize downstream of the first groupby
 		// unless there is an excplicit sort encountered.
 		return nil, nil
+	case *dag.Window:
+		if isOrderOfWindow(op, parent) {
+			// The input is already in window order so the window
+			// operator doesn't need to sort it.
+			op.InputSortDir = orderAsDirection(parent.Order)
+		}
+		out, err := o.analyzeSortKey(op, parent)
+		return []order.SortKey{out}, err
 	case *dag.Fork:
 		var keys []order.SortKey
 		for _, seq := range op.Paths {
@@ -519,12 +536,59 @@ func maybeNewRangePruner(pred dag.Expr, sortKey order.SortKey) dag.Expr {
 	return nil
 }
 
+// NewStatsPruner returns a new predicate based on the input predicate pred
+// that when applied to a column statistics value (as returned by
+// vng.Object.Stats) returns true if comparisons in pred between fields and
+// literal values can for certain rule out that pred would be true for any
+// value described by the statistics.  For a field x, the statistics value
+// is expected to have fields x.min and x.max.  NewStatsPruner returns nil
+// if pred has no such comparisons.
+func NewStatsPruner(pred dag.Expr) dag.Expr {
+	if pred == nil {
+		return nil
+	}
+	e := buildPruner(pred, func(op string, this *dag.This, literal *dag.Literal) *dag.BinaryExpr {
+		if literal.Value == "null" {
+			return nil
+		}
+		min := &dag.This{Kind: "This", Path: append(slices.Clone(this.Path), "min")}
+		max := &dag.This{Kind: "This", Path: append(slices.Clone(this.Path), "max")}
+		// Columns without statistics are missing from the statistics
+		// value, and complex columns can't be compared meaningfully,
+		// so only primitive min/max values are used to prune.
+		kind := &dag.Call{Kind: "Call", Name: "kind", Args: []dag.Expr{min}}
+		primitive := &dag.Literal{Kind: "Literal", Value: `"primitive"`}
+		return dag.NewBinaryExpr("and",
+			dag.NewBinaryExpr("==", kind, primitive),
+			rangePrunerPred(op, literal, min, max))
+	})
+	if e == nil {
+		return nil
+	}
+	return e
+}
+
 // buildRangePruner creates a DAG comparison expression that can evalaute whether
 // a Zed value adhering to the from/to pattern can be excluded from a scan because
 // the expression pred would evaluate to false for all values of fld in the
 // from/to value range.  If a pruning decision cannot be reliably determined then
 // the return value is nil.
 func buildRangePruner(pred dag.Expr, fld field.Path, min, max *dag.This) *dag.BinaryExpr {
+	return buildPruner(pred, func(op string, this *dag.This, literal *dag.Literal) *dag.BinaryExpr {
+		if !fld.Equal(this.Path) {
+			return nil
+		}
+		// At this point, we know we can definitely run a pruning decision based
+		// on the literal value we found, the comparison op, and the lower/upper bounds.
+		return rangePrunerPred(op, literal, min, max)
+	})
+}
+
+// buildPruner creates a DAG expression that is true when pred can be ruled
+// out by the comparisons of a field and a literal value in pred.  The
+// pruning expression for each such comparison is built by cmp, which
+// returns nil if no pruning decision can be made for the comparison.
+func buildPruner(pred dag.Expr, cmp func(op string, this *dag.This, literal *dag.Literal) *dag.BinaryExpr) *dag.BinaryExpr {
 	e, ok := pred.(*dag.BinaryExpr)
 	if !ok {
 		// If this isn't a binary predicate composed of comparison operators, we
@@ -539,8 +603,8 @@ func buildRangePruner(pred dag.Expr, fld field.Path, min, max *dag.This) *dag.Bi
 		// For an "and", if we know either side is prunable, then we can prune
 		// because both conditions are required.  So we "or" together the result
 		// when both sub-expressions are valid.
-		lhs := buildRangePruner(e.LHS, fld, min, max)
-		rhs := buildRangePruner(e.RHS, fld, min, max)
+		lhs := buildPruner(e.LHS, cmp)
+		rhs := buildPruner(e.RHS, cmp)
 		if lhs == nil {
 			return rhs
 		}
@@ -552,26 +616,24 @@ func buildRangePruner(pred dag.Expr, fld field.Path, min, max *dag.This) *dag.Bi
 		// For an "or", if we know both sides are prunable, then we can prune
 		// because either condition is required.  So we "and" together the result
 		// when both sub-expressions are valid.
-		lhs := buildRangePruner(e.LHS, fld, min, max)
-		rhs := buildRangePruner(e.RHS, fld, min, max)
+		lhs := buildPruner(e.LHS, cmp)
+		rhs := buildPruner(e.RHS, cmp)
 		if lhs == nil || rhs == nil {
 			return nil
 		}
 		return dag.NewBinaryExpr("and", lhs, rhs)
 	case "==", "<", "<=", ">", ">=":
 		this, literal, op := literalComparison(e)
-		if this == nil || !fld.Equal(this.Path) {
+		if this == nil {
 			return nil
 		}
-		// At this point, we know we can definitely run a pruning decision based
-		// on the literal value we found, the comparison op, and the lower/upper bounds.
-		return rangePrunerPred(op, literal, min, max)
+		return cmp(op, this, literal)
 	default:
 		return nil
 	}
 }
 
-func rangePrunerPred(op string, literal *dag.Literal, min, max *dag.This) *dag.BinaryExpr {
+func rangePrunerPred(op string, literal *dag.Literal, min, max dag.Expr) *dag.BinaryExpr {
 	switch op {
 	case "<":
 		// key < CONST
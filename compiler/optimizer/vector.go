@@ -0,0 +1,92 @@
+package optimizer
+
+import (
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/compiler/optimizer/demand"
+	"github.com/brimdata/zed/order"
+	"github.com/segmentio/ksuid"
+)
+
+// vectorizeScan arranges for scan to read objects that have vectors through
+// the vector cache when the scanned pool has any vectors and chain, the
+// operators downstream of scan, demands only some top-level fields.  If
+// chain begins with a summarize that groups and aggregates top-level fields,
+// the aggregation is also pushed into scan, which computes partial results
+// for each object for the summarize to merge.
+func (o *Optimizer) vectorizeScan(scan *dag.SeqScan, chain dag.Seq, sortKey order.SortKey) error {
+	if len(chain) == 0 {
+		return nil
+	}
+	ok, err := o.hasVectors(scan.Pool, scan.Commit)
+	if !ok || err != nil {
+		return err
+	}
+	demands := InferDemandSeqOut(append(dag.Seq{scan}, chain...))
+	d := demand.Union(demands[scan], inferDemandExprIn(demand.All(), scan.Filter))
+	if demand.IsAll(d) {
+		return nil
+	}
+	fields := demand.Keys(d)
+	if key := sortKey.Primary(); len(key) > 0 {
+		// Keep the pool key so values from overlapping objects can be
+		// merged in order.
+		d = demand.Union(d, demand.Key(key[0], demand.All()))
+		fields = demand.Keys(d)
+	}
+	scan.Fields = fields
+	if summarize, ok := chain[0].(*dag.Summarize); ok && isVectorSummarize(summarize) {
+		partial := copyOp(summarize).(*dag.Summarize)
+		partial.PartialsOut = true
+		scan.Summarize = partial
+		summarize.PartialsIn = true
+		for k := range summarize.Keys {
+			summarize.Keys[k].RHS = summarize.Keys[k].LHS
+		}
+	}
+	return nil
+}
+
+func (o *Optimizer) hasVectors(id, commit ksuid.KSUID) (bool, error) {
+	pool, err := o.lookupPool(id)
+	if err != nil {
+		return false, err
+	}
+	snap, err := pool.Snapshot(o.ctx, commit)
+	if err != nil {
+		return false, err
+	}
+	for _, object := range snap.SelectAll() {
+		if snap.HasVector(object.ID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isVectorSummarize returns true if each key of summarize is a top-level
+// field and each aggregation is of a top-level field or has no argument.
+func isVectorSummarize(summarize *dag.Summarize) bool {
+	if summarize.PartialsIn || summarize.PartialsOut || summarize.InputSortDir != 0 {
+		return false
+	}
+	for _, key := range summarize.Keys {
+		if _, ok := key.LHS.(*dag.This); !ok || !isTopLevelField(key.RHS) {
+			return false
+		}
+	}
+	for _, assignment := range summarize.Aggs {
+		agg, ok := assignment.RHS.(*dag.Agg)
+		if !ok || agg.Where != nil || len(agg.Args) != 0 {
+			return false
+		}
+		if agg.Expr != nil && !isTopLevelField(agg.Expr) {
+			return false
+		}
+	}
+	return true
+}
+
+func isTopLevelField(e dag.Expr) bool {
+	this, ok := e.(*dag.This)
+	return ok && len(this.Path) == 1
+}
@@ -2201,6 +2201,35 @@ var g = &grammar{
 								},
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 235, col: 61, offset: 6752},
+							label: "args",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 235, col: 66, offset: 6757},
+								expr: &seqExpr{
+									pos: position{line: 235, col: 67, offset: 6758},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 235, col: 67, offset: 6758},
+											name: "__",
+										},
+										&litMatcher{
+											pos:        position{line: 235, col: 70, offset: 6761},
+											val:        ",",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 235, col: 74, offset: 6765},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 235, col: 77, offset: 6768},
+											name: "Exprs",
+										},
+									},
+								},
+							},
+						},
 						&ruleRefExpr{
 							pos:  position{line: 235, col: 61, offset: 6752},
 							name: "__",
@@ -2445,6 +2474,10 @@ var g = &grammar{
 						pos:  position{line: 284, col: 5, offset: 7629},
 						name: "LoadOp",
 					},
+					&ruleRefExpr{
+						pos:  position{line: 285, col: 5, offset: 7641},
+						name: "WindowOp",
+					},
 				},
 			},
 		},
@@ -3198,39 +3231,10 @@ var g = &grammar{
 						},
 						&labeledExpr{
 							pos:   position{line: 415, col: 59, offset: 12129},
-							label: "key",
+							label: "keys",
 							expr: &ruleRefExpr{
-								pos:  position{line: 415, col: 63, offset: 12133},
-								name: "JoinKey",
-							},
-						},
-						&labeledExpr{
-							pos:   position{line: 415, col: 71, offset: 12141},
-							label: "optKey",
-							expr: &zeroOrOneExpr{
-								pos: position{line: 415, col: 78, offset: 12148},
-								expr: &seqExpr{
-									pos: position{line: 415, col: 79, offset: 12149},
-									exprs: []interface{}{
-										&ruleRefExpr{
-											pos:  position{line: 415, col: 79, offset: 12149},
-											name: "__",
-										},
-										&litMatcher{
-											pos:        position{line: 415, col: 82, offset: 12152},
-											val:        "=",
-											ignoreCase: false,
-										},
-										&ruleRefExpr{
-											pos:  position{line: 415, col: 86, offset: 12156},
-											name: "__",
-										},
-										&ruleRefExpr{
-											pos:  position{line: 415, col: 89, offset: 12159},
-											name: "JoinKey",
-										},
-									},
-								},
+								pos:  position{line: 415, col: 64, offset: 12134},
+								name: "JoinKeys",
 							},
 						},
 						&labeledExpr{
@@ -3284,6 +3288,24 @@ var g = &grammar{
 					&actionExpr{
 						pos: position{line: 428, col: 5, offset: 12587},
 						run: (*parser).callonJoinStyle6,
+						expr: &seqExpr{
+							pos: position{line: 428, col: 5, offset: 12587},
+							exprs: []interface{}{
+								&litMatcher{
+									pos:        position{line: 428, col: 5, offset: 12587},
+									val:        "full",
+									ignoreCase: false,
+								},
+								&ruleRefExpr{
+									pos:  position{line: 428, col: 12, offset: 12594},
+									name: "_",
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 428, col: 5, offset: 12587},
+						run: (*parser).callonJoinStyle10,
 						expr: &seqExpr{
 							pos: position{line: 428, col: 5, offset: 12587},
 							exprs: []interface{}{
@@ -3301,7 +3323,7 @@ var g = &grammar{
 					},
 					&actionExpr{
 						pos: position{line: 429, col: 5, offset: 12625},
-						run: (*parser).callonJoinStyle10,
+						run: (*parser).callonJoinStyle14,
 						expr: &seqExpr{
 							pos: position{line: 429, col: 5, offset: 12625},
 							exprs: []interface{}{
@@ -3319,7 +3341,7 @@ var g = &grammar{
 					},
 					&actionExpr{
 						pos: position{line: 430, col: 5, offset: 12662},
-						run: (*parser).callonJoinStyle14,
+						run: (*parser).callonJoinStyle18,
 						expr: &seqExpr{
 							pos: position{line: 430, col: 5, offset: 12662},
 							exprs: []interface{}{
@@ -3337,7 +3359,7 @@ var g = &grammar{
 					},
 					&actionExpr{
 						pos: position{line: 431, col: 5, offset: 12700},
-						run: (*parser).callonJoinStyle18,
+						run: (*parser).callonJoinStyle22,
 						expr: &litMatcher{
 							pos:        position{line: 431, col: 5, offset: 12700},
 							val:        "",
@@ -3407,6 +3429,105 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "JoinKeys",
+			pos:  position{line: 437, col: 1, offset: 12825},
+			expr: &actionExpr{
+				pos: position{line: 438, col: 5, offset: 12838},
+				run: (*parser).callonJoinKeys1,
+				expr: &seqExpr{
+					pos: position{line: 438, col: 5, offset: 12838},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 438, col: 5, offset: 12838},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 438, col: 11, offset: 12844},
+								name: "JoinKeyPair",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 438, col: 23, offset: 12856},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 438, col: 28, offset: 12861},
+								expr: &seqExpr{
+									pos: position{line: 438, col: 29, offset: 12862},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 438, col: 29, offset: 12862},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 438, col: 31, offset: 12864},
+											name: "AndToken",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 438, col: 40, offset: 12873},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 438, col: 42, offset: 12875},
+											name: "JoinKeyPair",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "JoinKeyPair",
+			pos:  position{line: 449, col: 1, offset: 13145},
+			expr: &actionExpr{
+				pos: position{line: 450, col: 5, offset: 13161},
+				run: (*parser).callonJoinKeyPair1,
+				expr: &seqExpr{
+					pos: position{line: 450, col: 5, offset: 13161},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 450, col: 5, offset: 13161},
+							label: "key",
+							expr: &ruleRefExpr{
+								pos:  position{line: 450, col: 9, offset: 13165},
+								name: "JoinKey",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 450, col: 17, offset: 13173},
+							label: "optKey",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 450, col: 24, offset: 13180},
+								expr: &seqExpr{
+									pos: position{line: 450, col: 25, offset: 13181},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 450, col: 25, offset: 13181},
+											name: "__",
+										},
+										&litMatcher{
+											pos:        position{line: 450, col: 28, offset: 13184},
+											val:        "=",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 450, col: 32, offset: 13188},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 450, col: 35, offset: 13191},
+											name: "JoinKey",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "JoinKey",
 			pos:  position{line: 437, col: 1, offset: 12825},
@@ -5027,6 +5148,105 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "WindowOp",
+			pos:  position{line: 629, col: 1, offset: 18353},
+			expr: &actionExpr{
+				pos: position{line: 630, col: 5, offset: 18366},
+				run: (*parser).callonWindowOp1,
+				expr: &seqExpr{
+					pos: position{line: 630, col: 5, offset: 18366},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 629, col: 5, offset: 18353},
+							val:        "window",
+							ignoreCase: false,
+						},
+						&ruleRefExpr{
+							pos:  position{line: 629, col: 5, offset: 18353},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 629, col: 5, offset: 18353},
+							label: "funcs",
+							expr: &ruleRefExpr{
+								pos:  position{line: 629, col: 5, offset: 18353},
+								name: "FlexAssignments",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 629, col: 5, offset: 18353},
+							label: "keys",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 629, col: 5, offset: 18353},
+								expr: &seqExpr{
+									pos: position{line: 629, col: 5, offset: 18353},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "ByToken",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "Exprs",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 629, col: 5, offset: 18353},
+							label: "orderBy",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 629, col: 5, offset: 18353},
+								expr: &seqExpr{
+									pos: position{line: 629, col: 5, offset: 18353},
+									exprs: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 629, col: 5, offset: 18353},
+											val:        "order",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "ByToken",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "_",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "Exprs",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 629, col: 5, offset: 18353},
+											name: "SQLOrder",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "TypeArg",
 			pos:  position{line: 629, col: 1, offset: 18353},
@@ -13149,11 +13369,14 @@ func (p *parser) callonAggAssignment11() (interface{}, error) {
 	return p.cur.onAggAssignment11(stack["agg"])
 }
 
-func (c *current) onAgg1(op, expr, where interface{}) (interface{}, error) {
-	var r = map[string]interface{}{"kind": "Agg", "name": op, "expr": nil, "where": where}
+func (c *current) onAgg1(op, expr, args, where interface{}) (interface{}, error) {
+	var r = map[string]interface{}{"kind": "Agg", "name": op, "expr": nil, "args": nil, "where": where}
 	if expr != nil {
 		r["expr"] = expr
 	}
+	if args != nil {
+		r["args"] = args.([]interface{})[3]
+	}
 	return r, nil
 
 }
@@ -13161,7 +13384,7 @@ func (c *current) onAgg1(op, expr, where interface{}) (interface{}, error) {
 func (p *parser) callonAgg1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onAgg1(stack["op"], stack["expr"], stack["where"])
+	return p.cur.onAgg1(stack["op"], stack["expr"], stack["args"], stack["where"])
 }
 
 func (c *current) onWhereClause1(expr interface{}) (interface{}, error) {
@@ -13512,11 +13735,8 @@ func (p *parser) callonShapeOp1() (interface{}, error) {
 	return p.cur.onShapeOp1()
 }
 
-func (c *current) onJoinOp1(style, rightInput, key, optKey, optArgs interface{}) (interface{}, error) {
-	var m = map[string]interface{}{"kind": "Join", "style": style, "right_input": rightInput, "left_key": key, "right_key": key, "args": nil}
-	if optKey != nil {
-		m["right_key"] = optKey.([]interface{})[3]
-	}
+func (c *current) onJoinOp1(style, rightInput, keys, optArgs interface{}) (interface{}, error) {
+	var m = map[string]interface{}{"kind": "Join", "style": style, "right_input": rightInput, "left_keys": keys.([]interface{})[0], "right_keys": keys.([]interface{})[1], "args": nil}
 	if optArgs != nil {
 		m["args"] = optArgs.([]interface{})[1]
 	}
@@ -13527,7 +13747,7 @@ func (c *current) onJoinOp1(style, rightInput, key, optKey, optArgs interface{})
 func (p *parser) callonJoinOp1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onJoinOp1(stack["style"], stack["rightInput"], stack["key"], stack["optKey"], stack["optArgs"])
+	return p.cur.onJoinOp1(stack["style"], stack["rightInput"], stack["keys"], stack["optArgs"])
 }
 
 func (c *current) onJoinStyle2() (interface{}, error) {
@@ -13541,7 +13761,7 @@ func (p *parser) callonJoinStyle2() (interface{}, error) {
 }
 
 func (c *current) onJoinStyle6() (interface{}, error) {
-	return "inner", nil
+	return "full", nil
 }
 
 func (p *parser) callonJoinStyle6() (interface{}, error) {
@@ -13551,7 +13771,7 @@ func (p *parser) callonJoinStyle6() (interface{}, error) {
 }
 
 func (c *current) onJoinStyle10() (interface{}, error) {
-	return "left", nil
+	return "inner", nil
 }
 
 func (p *parser) callonJoinStyle10() (interface{}, error) {
@@ -13561,7 +13781,7 @@ func (p *parser) callonJoinStyle10() (interface{}, error) {
 }
 
 func (c *current) onJoinStyle14() (interface{}, error) {
-	return "right", nil
+	return "left", nil
 }
 
 func (p *parser) callonJoinStyle14() (interface{}, error) {
@@ -13571,7 +13791,7 @@ func (p *parser) callonJoinStyle14() (interface{}, error) {
 }
 
 func (c *current) onJoinStyle18() (interface{}, error) {
-	return "inner", nil
+	return "right", nil
 }
 
 func (p *parser) callonJoinStyle18() (interface{}, error) {
@@ -13580,6 +13800,16 @@ func (p *parser) callonJoinStyle18() (interface{}, error) {
 	return p.cur.onJoinStyle18()
 }
 
+func (c *current) onJoinStyle22() (interface{}, error) {
+	return "inner", nil
+}
+
+func (p *parser) callonJoinStyle22() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onJoinStyle22()
+}
+
 func (c *current) onJoinRightInput2(s interface{}) (interface{}, error) {
 	return s, nil
 }
@@ -13600,6 +13830,38 @@ func (p *parser) callonJoinRightInput12() (interface{}, error) {
 	return p.cur.onJoinRightInput12()
 }
 
+func (c *current) onJoinKeys1(first, rest interface{}) (interface{}, error) {
+	var left = []interface{}{first.([]interface{})[0]}
+	var right = []interface{}{first.([]interface{})[1]}
+	for _, r := range rest.([]interface{}) {
+		var pair = r.([]interface{})[3].([]interface{})
+		left = append(left, pair[0])
+		right = append(right, pair[1])
+	}
+	return []interface{}{left, right}, nil
+
+}
+
+func (p *parser) callonJoinKeys1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onJoinKeys1(stack["first"], stack["rest"])
+}
+
+func (c *current) onJoinKeyPair1(key, optKey interface{}) (interface{}, error) {
+	if optKey != nil {
+		return []interface{}{key, optKey.([]interface{})[3]}, nil
+	}
+	return []interface{}{key, key}, nil
+
+}
+
+func (p *parser) callonJoinKeyPair1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onJoinKeyPair1(stack["key"], stack["optKey"])
+}
+
 func (c *current) onJoinKey3(expr interface{}) (interface{}, error) {
 	return expr, nil
 }
@@ -14114,6 +14376,25 @@ func (p *parser) callonYieldOp1() (interface{}, error) {
 	return p.cur.onYieldOp1(stack["exprs"])
 }
 
+func (c *current) onWindowOp1(funcs, keys, orderBy interface{}) (interface{}, error) {
+	op := map[string]interface{}{"kind": "Window", "funcs": funcs, "keys": nil, "order_by": nil, "order": "asc"}
+	if keys != nil {
+		op["keys"] = keys.([]interface{})[3]
+	}
+	if orderBy != nil {
+		op["order_by"] = orderBy.([]interface{})[5]
+		op["order"] = orderBy.([]interface{})[6]
+	}
+	return op, nil
+
+}
+
+func (p *parser) callonWindowOp1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onWindowOp1(stack["funcs"], stack["keys"], stack["orderBy"])
+}
+
 func (c *current) onTypeArg1(typ interface{}) (interface{}, error) {
 	return typ, nil
 }
@@ -138,13 +138,19 @@ func (a *analyzer) semExpr(e ast.Expr) (dag.Expr, error) {
 			Value: "<" + typ + ">",
 		}, nil
 	case *ast.Agg:
-		expr, err := a.semExprNullable(e.Expr)
+		if e.Expr == nil && e.Name != "count" {
+			return nil, fmt.Errorf("aggregator '%s' requires argument", e.Name)
+		}
+		var args []ast.Expr
+		if e.Expr != nil {
+			args = append([]ast.Expr{e.Expr}, e.Args...)
+		} else if len(e.Args) > 0 {
+			return nil, fmt.Errorf("%s: wrong number of arguments", e.Name)
+		}
+		expr, rest, err := a.semAggArgs(e.Name, args)
 		if err != nil {
 			return nil, err
 		}
-		if expr == nil && e.Name != "count" {
-			return nil, fmt.Errorf("aggregator '%s' requires argument", e.Name)
-		}
 		where, err := a.semExprNullable(e.Where)
 		if err != nil {
 			return nil, err
@@ -153,6 +159,7 @@ func (a *analyzer) semExpr(e ast.Expr) (dag.Expr, error) {
 			Kind:  "Agg",
 			Name:  e.Name,
 			Expr:  expr,
+			Args:  rest,
 			Where: where,
 		}, nil
 	case *ast.RecordExpr:
@@ -584,6 +591,45 @@ func (a *analyzer) semAssignment(assign ast.Assignment) (dag.Assignment, error)
 	return dag.Assignment{Kind: "Assignment", LHS: lhs, RHS: rhs}, nil
 }
 
+// semWindowFunc analyzes an assignment of the window operator, whose
+// right-hand side is either an aggregate function or a window function
+// such as row_number() or lag().  Window functions are not known to the
+// expression evaluator, so their names are checked when the operator
+// is compiled.
+func (a *analyzer) semWindowFunc(assign ast.Assignment) (dag.Assignment, error) {
+	call, ok := assign.RHS.(*ast.Call)
+	if !ok {
+		return dag.Assignment{}, errors.New("right-hand side of assignment must be a function call")
+	}
+	rhs, err := a.maybeConvertAgg(call)
+	if err != nil {
+		return dag.Assignment{}, err
+	}
+	if rhs == nil {
+		if call.Where != nil {
+			return dag.Assignment{}, fmt.Errorf("'where' clause on non-aggregation function: %s", call.Name)
+		}
+		args, err := a.semExprs(call.Args)
+		if err != nil {
+			return dag.Assignment{}, fmt.Errorf("%s(): bad argument: %w", call.Name, err)
+		}
+		rhs = &dag.Call{Kind: "Call", Name: call.Name, Args: args}
+	}
+	var lhs dag.Expr
+	if assign.LHS == nil {
+		lhs = &dag.This{Kind: "This", Path: []string{call.Name}}
+	} else if lhs, err = a.semExpr(assign.LHS); err != nil {
+		return dag.Assignment{}, fmt.Errorf("left-hand side of assignment: %w", err)
+	}
+	if !isLval(lhs) {
+		return dag.Assignment{}, errors.New("illegal left-hand side of assignment")
+	}
+	if this, ok := lhs.(*dag.This); ok && len(this.Path) == 0 {
+		return dag.Assignment{}, errors.New("cannot assign to 'this'")
+	}
+	return dag.Assignment{Kind: "Assignment", LHS: lhs, RHS: rhs}, nil
+}
+
 func isLval(e dag.Expr) bool {
 	switch e := e.(type) {
 	case *dag.BinaryExpr:
@@ -652,25 +698,18 @@ func (a *analyzer) semField(f ast.Expr) (*dag.This, error) {
 }
 
 func (a *analyzer) maybeConvertAgg(call *ast.Call) (dag.Expr, error) {
-	if _, err := agg.NewPattern(call.Name, true); err != nil {
+	if _, _, err := agg.NewPattern(call.Name, true); err != nil {
 		return nil, nil
 	}
-	var e dag.Expr
-	if len(call.Args) > 1 {
-		if call.Name == "min" || call.Name == "max" {
-			// min and max are special cases as they are also functions. If the
-			// number of args is greater than 1 they're probably a function so do not
-			// return an error.
-			return nil, nil
-		}
-		return nil, fmt.Errorf("%s: wrong number of arguments", call.Name)
+	if len(call.Args) > 1 && (call.Name == "min" || call.Name == "max") {
+		// min and max are special cases as they are also functions. If the
+		// number of args is greater than 1 they're probably a function so do not
+		// return an error.
+		return nil, nil
 	}
-	if len(call.Args) == 1 {
-		var err error
-		e, err = a.semExpr(call.Args[0])
-		if err != nil {
-			return nil, err
-		}
+	e, args, err := a.semAggArgs(call.Name, call.Args)
+	if err != nil {
+		return nil, err
 	}
 	where, err := a.semExprNullable(call.Where)
 	if err != nil {
@@ -680,10 +719,51 @@ func (a *analyzer) maybeConvertAgg(call *ast.Call) (dag.Expr, error) {
 		Kind:  "Agg",
 		Name:  call.Name,
 		Expr:  e,
+		Args:  args,
 		Where: where,
 	}, nil
 }
 
+// semAggArgs analyzes the arguments of the aggregate function name and
+// returns the first argument separately from any arguments that follow it.
+func (a *analyzer) semAggArgs(name string, args []ast.Expr) (dag.Expr, []dag.Expr, error) {
+	if len(args) == 0 {
+		return nil, nil, nil
+	}
+	pattern, nargs, err := agg.NewPattern(name, true)
+	if err != nil {
+		// An unknown function is reported when the query is built.
+		nargs = 1
+	}
+	if len(args) != nargs {
+		return nil, nil, fmt.Errorf("%s: wrong number of arguments", name)
+	}
+	e, err := a.semExpr(args[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	var rest []dag.Expr
+	if len(args) > 1 {
+		rest, err = a.semExprs(args[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if pattern == nil {
+		return e, rest, nil
+	}
+	if _, ok := pattern().(agg.ConstArgsFunction); ok {
+		for k, arg := range rest {
+			val, err := kernel.EvalAtCompileTime(a.zctx, arg)
+			if err != nil || val.IsError() || !zed.IsNumber(val.Type.ID()) {
+				return nil, nil, fmt.Errorf("%s: argument must be a numeric constant", name)
+			}
+			rest[k] = &dag.Literal{Kind: "Literal", Value: zson.FormatValue(val)}
+		}
+	}
+	return e, rest, nil
+}
+
 func DotExprToFieldPath(e ast.Expr) *dag.This {
 	switch e := e.(type) {
 	case *ast.BinaryExpr:
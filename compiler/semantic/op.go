@@ -668,11 +668,14 @@ func (a *analyzer) semOp(o ast.Op, seq dag.Seq) (dag.Seq, error) {
 		if err != nil {
 			return nil, err
 		}
-		leftKey, err := a.semExpr(o.LeftKey)
+		if len(o.LeftKeys) == 0 || len(o.LeftKeys) != len(o.RightKeys) {
+			return nil, errors.New("join: left and right keys must be paired")
+		}
+		leftKeys, err := a.semExprs(o.LeftKeys)
 		if err != nil {
 			return nil, err
 		}
-		rightKey, err := a.semExpr(o.RightKey)
+		rightKeys, err := a.semExprs(o.RightKeys)
 		if err != nil {
 			return nil, err
 		}
@@ -681,13 +684,13 @@ func (a *analyzer) semOp(o ast.Op, seq dag.Seq) (dag.Seq, error) {
 			return nil, err
 		}
 		join := &dag.Join{
-			Kind:     "Join",
-			Style:    o.Style,
-			LeftDir:  order.Unknown,
-			LeftKey:  leftKey,
-			RightDir: order.Unknown,
-			RightKey: rightKey,
-			Args:     assignments,
+			Kind:      "Join",
+			Style:     o.Style,
+			LeftDir:   order.Unknown,
+			LeftKeys:  leftKeys,
+			RightDir:  order.Unknown,
+			RightKeys: rightKeys,
+			Args:      assignments,
 		}
 		if rightInput != nil {
 			par := &dag.Fork{
@@ -812,6 +815,33 @@ func (a *analyzer) semOp(o ast.Op, seq dag.Seq) (dag.Seq, error) {
 			Kind:  "Yield",
 			Exprs: exprs,
 		}), nil
+	case *ast.Window:
+		var funcs []dag.Assignment
+		for _, f := range o.Funcs {
+			assign, err := a.semWindowFunc(f)
+			if err != nil {
+				return nil, fmt.Errorf("window: %w", err)
+			}
+			funcs = append(funcs, assign)
+		}
+		if assignmentHasDynamicLHS(funcs) {
+			return nil, errors.New("window: output field must be static")
+		}
+		keys, err := a.semExprs(o.Keys)
+		if err != nil {
+			return nil, fmt.Errorf("window: %w", err)
+		}
+		orderBy, err := a.semExprs(o.OrderBy)
+		if err != nil {
+			return nil, fmt.Errorf("window: %w", err)
+		}
+		return append(seq, &dag.Window{
+			Kind:    "Window",
+			Funcs:   funcs,
+			Keys:    keys,
+			OrderBy: orderBy,
+			Order:   o.Order,
+		}), nil
 	case *ast.Load:
 		poolID, err := lakeparse.ParseID(o.Pool)
 		if err != nil {
@@ -1064,7 +1094,9 @@ func (a *analyzer) semCallOp(call *ast.Call, seq dag.Seq) (dag.Seq, error) {
 	} else if body != nil {
 		return append(seq, body...), nil
 	}
-	if agg, err := a.maybeConvertAgg(call); err == nil && agg != nil {
+	if agg, err := a.maybeConvertAgg(call); err != nil {
+		return nil, err
+	} else if agg != nil {
 		summarize := &dag.Summarize{
 			Kind: "Summarize",
 			Aggs: []dag.Assignment{
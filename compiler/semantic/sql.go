@@ -295,11 +295,11 @@ func (a *analyzer) convertSQLJoin(leftPath []dag.Op, sqlJoin ast.SQLJoin) ([]dag
 		RHS:  &dag.This{Kind: "This", Path: field.Path{aliasID}},
 	}
 	join := &dag.Join{
-		Kind:     "Join",
-		Style:    sqlJoin.Style,
-		LeftKey:  leftKey,
-		RightKey: rightKey,
-		Args:     []dag.Assignment{alias},
+		Kind:      "Join",
+		Style:     sqlJoin.Style,
+		LeftKeys:  []dag.Expr{leftKey},
+		RightKeys: []dag.Expr{rightKey},
+		Args:      []dag.Assignment{alias},
 	}
 	return []dag.Op{fork, join}, nil
 }
@@ -489,28 +489,18 @@ func (a *analyzer) isAgg(e ast.Expr) (*dag.Agg, error) {
 	if !ok {
 		return nil, nil
 	}
-	if _, err := agg.NewPattern(call.Name, true); err != nil {
+	if _, _, err := agg.NewPattern(call.Name, true); err != nil {
 		return nil, nil
 	}
-	var arg ast.Expr
-	if len(call.Args) > 1 {
-		return nil, fmt.Errorf("%s: wrong number of arguments", call.Name)
-	}
-	if len(call.Args) == 1 {
-		arg = call.Args[0]
-	}
-	var dagArg dag.Expr
-	if arg != nil {
-		var err error
-		dagArg, err = a.semExpr(arg)
-		if err != nil {
-			return nil, err
-		}
+	arg, args, err := a.semAggArgs(call.Name, call.Args)
+	if err != nil {
+		return nil, err
 	}
 	return &dag.Agg{
 		Kind: "Agg",
 		Name: call.Name,
-		Expr: dagArg,
+		Expr: arg,
+		Args: args,
 	}, nil
 }
 
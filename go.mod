@@ -15,6 +15,7 @@ require (
 	github.com/gorilla/mux v1.7.5-0.20200711200521-98cb6bf42e08
 	github.com/gosuri/uilive v0.0.4
 	github.com/hashicorp/golang-lru/v2 v2.0.1
+	github.com/klauspost/compress v1.16.7
 	github.com/kr/text v0.2.0
 	github.com/paulbellamy/ratecounter v0.2.0
 	github.com/pbnjay/memory v0.0.0-20190104145345-974d429e7ae4
@@ -27,6 +28,7 @@ require (
 	github.com/rs/cors v1.8.0
 	github.com/segmentio/ksuid v1.0.2
 	github.com/stretchr/testify v1.8.4
+	github.com/ulikunitz/xz v0.5.11
 	github.com/x448/float16 v0.8.4
 	github.com/yuin/goldmark v1.4.13
 	go.uber.org/multierr v1.8.0
@@ -54,7 +56,6 @@ require (
 	github.com/google/flatbuffers v23.5.26+incompatible // indirect
 	github.com/jmespath/go-jmespath v0.4.0 // indirect
 	github.com/klauspost/asmfmt v1.3.2 // indirect
-	github.com/klauspost/compress v1.16.7 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.5 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/mattn/go-runewidth v0.0.10 // indirect
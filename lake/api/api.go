@@ -10,6 +10,7 @@ import (
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/grants"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
@@ -27,12 +28,14 @@ type Interface interface {
 	QueryWithControl(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zbuf.ProgressReadCloser, error)
 	PoolID(ctx context.Context, poolName string) (ksuid.KSUID, error)
 	CommitObject(ctx context.Context, poolID ksuid.KSUID, branchName string) (ksuid.KSUID, error)
-	CreatePool(context.Context, string, order.SortKey, int, int64) (ksuid.KSUID, error)
+	CreatePool(context.Context, string, order.SortKey, int, int64, string, *pools.Schema) (ksuid.KSUID, error)
 	RemovePool(context.Context, ksuid.KSUID) error
 	RenamePool(context.Context, ksuid.KSUID, string) error
 	CreateBranch(ctx context.Context, pool ksuid.KSUID, name string, parent ksuid.KSUID) error
 	RemoveBranch(ctx context.Context, pool ksuid.KSUID, branchName string) error
 	MergeBranch(ctx context.Context, pool ksuid.KSUID, childBranch, parentBranch string, message api.CommitMessage) (ksuid.KSUID, error)
+	CreateTag(ctx context.Context, pool ksuid.KSUID, name string, commit ksuid.KSUID) error
+	RemoveTag(ctx context.Context, pool ksuid.KSUID, tagName string) error
 	Compact(ctx context.Context, pool ksuid.KSUID, branch string, objects []ksuid.KSUID, writeVectors bool, message api.CommitMessage) (ksuid.KSUID, error)
 	Load(ctx context.Context, zctx *zed.Context, pool ksuid.KSUID, branch string, r zio.Reader, message api.CommitMessage) (ksuid.KSUID, error)
 	Delete(ctx context.Context, poolID ksuid.KSUID, branchName string, tags []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error)
@@ -45,6 +48,10 @@ type Interface interface {
 	AddVectors(ctx context.Context, pool, revision string, objects []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error)
 	DeleteVectors(ctx context.Context, pool, revision string, objects []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error)
 	Vacuum(ctx context.Context, pool, revision string, dryrun bool) ([]ksuid.KSUID, error)
+	Checkpoint(ctx context.Context, pool, revision string, interval int, verify bool) ([]ksuid.KSUID, error)
+	ListGrants(context.Context) ([]grants.Grant, error)
+	Grant(ctx context.Context, subject string, pool ksuid.KSUID, branch string, role grants.Role) error
+	Revoke(ctx context.Context, subject string, pool ksuid.KSUID, branch string) error
 }
 
 func OpenLake(ctx context.Context, logger *zap.Logger, u string) (Interface, error) {
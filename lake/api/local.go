@@ -3,12 +3,16 @@ package api
 import (
 	"context"
 	"errors"
+	"os/user"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/compiler"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/audit"
+	"github.com/brimdata/zed/lake/grants"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/storage"
@@ -23,6 +27,7 @@ import (
 type local struct {
 	root     *lake.Root
 	compiler runtime.Compiler
+	user     string
 }
 
 var _ Interface = (*local)(nil)
@@ -54,18 +59,40 @@ func CreateLocalLake(ctx context.Context, logger *zap.Logger, lakePath string) (
 }
 
 func FromRoot(root *lake.Root) Interface {
-	return &local{root: root, compiler: compiler.NewLakeCompiler(root)}
+	var name string
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	return &local{root: root, compiler: compiler.NewLakeCompiler(root), user: name}
+}
+
+// audit records op on the branch of the pool with ID poolID in the audit log
+// of the lake with the outcome given by err.  If ctx carries the record of a
+// request that includes op, targets are added to that record instead.
+func (l *local) audit(ctx context.Context, op string, poolID ksuid.KSUID, branch string, err error, targets ...string) {
+	if rec := audit.RecordFromContext(ctx); rec != nil {
+		rec.Targets = append(rec.Targets, targets...)
+		return
+	}
+	rec := audit.NewRecord(op)
+	rec.UserID = l.user
+	rec.Pool = poolID
+	rec.Branch = branch
+	rec.Targets = targets
+	rec.SetError(err)
+	l.root.Audit(ctx, rec)
 }
 
 func (l *local) Root() *lake.Root {
 	return l.root
 }
 
-func (l *local) CreatePool(ctx context.Context, name string, sortKey order.SortKey, seekStride int, thresh int64) (ksuid.KSUID, error) {
+func (l *local) CreatePool(ctx context.Context, name string, sortKey order.SortKey, seekStride int, thresh int64, compression string, schema *pools.Schema) (id ksuid.KSUID, err error) {
+	defer func() { l.audit(ctx, audit.OpCreatePool, id, "", err, name) }()
 	if name == "" {
 		return ksuid.Nil, errors.New("no pool name provided")
 	}
-	pool, err := l.root.CreatePool(ctx, name, sortKey, seekStride, thresh)
+	pool, err := l.root.CreatePool(ctx, name, sortKey, seekStride, thresh, compression, schema)
 	if err != nil {
 		return ksuid.Nil, err
 	}
@@ -73,11 +100,13 @@ func (l *local) CreatePool(ctx context.Context, name string, sortKey order.SortK
 }
 
 func (l *local) RemovePool(ctx context.Context, id ksuid.KSUID) error {
-	return l.root.RemovePool(ctx, id)
-
+	err := l.root.RemovePool(ctx, id)
+	l.audit(ctx, audit.OpDeletePool, id, "", err)
+	return err
 }
 
-func (l *local) RenamePool(ctx context.Context, id ksuid.KSUID, name string) error {
+func (l *local) RenamePool(ctx context.Context, id ksuid.KSUID, name string) (err error) {
+	defer func() { l.audit(ctx, audit.OpRenamePool, id, "", err, name) }()
 	if name == "" {
 		return errors.New("no pool name provided")
 	}
@@ -86,18 +115,52 @@ func (l *local) RenamePool(ctx context.Context, id ksuid.KSUID, name string) err
 
 func (l *local) CreateBranch(ctx context.Context, poolID ksuid.KSUID, name string, parent ksuid.KSUID) error {
 	_, err := l.root.CreateBranch(ctx, poolID, name, parent)
+	l.audit(ctx, audit.OpCreateBranch, poolID, name, err, audit.IDs(parent)...)
 	return err
 }
 
 func (l *local) RemoveBranch(ctx context.Context, poolID ksuid.KSUID, branchName string) error {
-	return l.root.RemoveBranch(ctx, poolID, branchName)
+	err := l.root.RemoveBranch(ctx, poolID, branchName)
+	l.audit(ctx, audit.OpDeleteBranch, poolID, branchName, err)
+	return err
+}
+
+func (l *local) CreateTag(ctx context.Context, poolID ksuid.KSUID, name string, commit ksuid.KSUID) error {
+	_, err := l.root.CreateTag(ctx, poolID, name, commit)
+	l.audit(ctx, audit.OpCreateTag, poolID, "", err, append([]string{name}, audit.IDs(commit)...)...)
+	return err
+}
+
+func (l *local) ListGrants(ctx context.Context) ([]grants.Grant, error) {
+	return l.root.ListGrants(ctx)
+}
+
+func (l *local) Grant(ctx context.Context, subject string, poolID ksuid.KSUID, branch string, role grants.Role) error {
+	_, err := l.root.Grant(ctx, subject, poolID, branch, role)
+	l.audit(ctx, audit.OpGrant, poolID, branch, err, subject, string(role))
+	return err
+}
+
+func (l *local) Revoke(ctx context.Context, subject string, poolID ksuid.KSUID, branch string) error {
+	err := l.root.Revoke(ctx, subject, poolID, branch)
+	l.audit(ctx, audit.OpRevoke, poolID, branch, err, subject)
+	return err
+}
+
+func (l *local) RemoveTag(ctx context.Context, poolID ksuid.KSUID, tagName string) error {
+	err := l.root.RemoveTag(ctx, poolID, tagName)
+	l.audit(ctx, audit.OpDeleteTag, poolID, "", err, tagName)
+	return err
 }
 
 func (l *local) MergeBranch(ctx context.Context, poolID ksuid.KSUID, childBranch, parentBranch string, message api.CommitMessage) (ksuid.KSUID, error) {
-	return l.root.MergeBranch(ctx, poolID, childBranch, parentBranch, message.Author, message.Body)
+	commit, err := l.root.MergeBranch(ctx, poolID, childBranch, parentBranch, message.Author, message.Body)
+	l.audit(ctx, audit.OpMerge, poolID, parentBranch, err, append([]string{childBranch}, audit.IDs(commit)...)...)
+	return commit, err
 }
 
-func (l *local) Compact(ctx context.Context, poolID ksuid.KSUID, branchName string, objects []ksuid.KSUID, writeVectors bool, commit api.CommitMessage) (ksuid.KSUID, error) {
+func (l *local) Compact(ctx context.Context, poolID ksuid.KSUID, branchName string, objects []ksuid.KSUID, writeVectors bool, commit api.CommitMessage) (id ksuid.KSUID, err error) {
+	defer func() { l.audit(ctx, audit.OpCompact, poolID, branchName, err, audit.IDs(id)...) }()
 	pool, err := l.root.OpenPool(ctx, poolID)
 	if err != nil {
 		return ksuid.Nil, err
@@ -106,11 +169,19 @@ func (l *local) Compact(ctx context.Context, poolID ksuid.KSUID, branchName stri
 }
 
 func (l *local) AddIndexRules(ctx context.Context, rules []index.Rule) error {
-	return l.root.AddIndexRules(ctx, rules)
+	err := l.root.AddIndexRules(ctx, rules)
+	var ids []ksuid.KSUID
+	for _, rule := range rules {
+		ids = append(ids, rule.RuleID())
+	}
+	l.audit(ctx, audit.OpAddIndexRules, ksuid.Nil, "", err, audit.IDs(ids...)...)
+	return err
 }
 
 func (l *local) DeleteIndexRules(ctx context.Context, ids []ksuid.KSUID) ([]index.Rule, error) {
-	return l.root.DeleteIndexRules(ctx, ids)
+	rules, err := l.root.DeleteIndexRules(ctx, ids)
+	l.audit(ctx, audit.OpDeleteIndexRules, ksuid.Nil, "", err, audit.IDs(ids...)...)
+	return rules, err
 }
 
 func (l *local) Query(ctx context.Context, head *lakeparse.Commitish, src string, srcfiles ...string) (zio.ReadCloser, error) {
@@ -126,6 +197,8 @@ func (l *local) QueryWithControl(ctx context.Context, head *lakeparse.Commitish,
 	if err != nil {
 		return nil, err
 	}
+	// A load operator in the query records itself in the audit log.
+	ctx = audit.ContextWithIdentity(ctx, audit.Identity{UserID: l.user})
 	q, err := runtime.CompileLakeQuery(ctx, zed.NewContext(), l.compiler, flowgraph, head, nil)
 	if err != nil {
 		return nil, err
@@ -156,15 +229,19 @@ func (l *local) lookupBranch(ctx context.Context, poolID ksuid.KSUID, branchName
 	return pool, branch, nil
 }
 
-func (l *local) Load(ctx context.Context, ztcx *zed.Context, poolID ksuid.KSUID, branchName string, r zio.Reader, message api.CommitMessage) (ksuid.KSUID, error) {
+func (l *local) Load(ctx context.Context, ztcx *zed.Context, poolID ksuid.KSUID, branchName string, r zio.Reader, message api.CommitMessage) (id ksuid.KSUID, err error) {
+	defer func() { l.audit(ctx, audit.OpLoad, poolID, branchName, err, audit.IDs(id)...) }()
 	_, branch, err := l.lookupBranch(ctx, poolID, branchName)
 	if err != nil {
 		return ksuid.Nil, err
 	}
-	return branch.Load(ctx, ztcx, r, message.Author, message.Body, message.Meta)
+	return branch.Load(ctx, l.compiler, ztcx, r, message.Author, message.Body, message.Meta)
 }
 
-func (l *local) Delete(ctx context.Context, poolID ksuid.KSUID, branchName string, ids []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error) {
+func (l *local) Delete(ctx context.Context, poolID ksuid.KSUID, branchName string, ids []ksuid.KSUID, message api.CommitMessage) (id ksuid.KSUID, err error) {
+	defer func() {
+		l.audit(ctx, audit.OpDelete, poolID, branchName, err, audit.IDs(append([]ksuid.KSUID{id}, ids...)...)...)
+	}()
 	_, branch, err := l.lookupBranch(ctx, poolID, branchName)
 	if err != nil {
 		return ksuid.Nil, err
@@ -176,7 +253,8 @@ func (l *local) Delete(ctx context.Context, poolID ksuid.KSUID, branchName strin
 	return commitID, nil
 }
 
-func (l *local) DeleteWhere(ctx context.Context, poolID ksuid.KSUID, branchName, src string, commit api.CommitMessage) (ksuid.KSUID, error) {
+func (l *local) DeleteWhere(ctx context.Context, poolID ksuid.KSUID, branchName, src string, commit api.CommitMessage) (id ksuid.KSUID, err error) {
+	defer func() { l.audit(ctx, audit.OpDelete, poolID, branchName, err, audit.IDs(id)...) }()
 	op, err := l.compiler.Parse(src)
 	if err != nil {
 		return ksuid.Nil, err
@@ -189,10 +267,15 @@ func (l *local) DeleteWhere(ctx context.Context, poolID ksuid.KSUID, branchName,
 }
 
 func (l *local) Revert(ctx context.Context, poolID ksuid.KSUID, branchName string, commitID ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error) {
-	return l.root.Revert(ctx, poolID, branchName, commitID, message.Author, message.Body)
+	commit, err := l.root.Revert(ctx, poolID, branchName, commitID, message.Author, message.Body)
+	l.audit(ctx, audit.OpRevert, poolID, branchName, err, audit.IDs(commitID, commit)...)
+	return commit, err
 }
 
-func (l *local) ApplyIndexRules(ctx context.Context, ruleRefs []string, poolID ksuid.KSUID, branchName string, inTags []ksuid.KSUID) (ksuid.KSUID, error) {
+func (l *local) ApplyIndexRules(ctx context.Context, ruleRefs []string, poolID ksuid.KSUID, branchName string, inTags []ksuid.KSUID) (id ksuid.KSUID, err error) {
+	defer func() {
+		l.audit(ctx, audit.OpApplyIndexRules, poolID, branchName, err, append(audit.IDs(id), ruleRefs...)...)
+	}()
 	_, branch, err := l.lookupBranch(ctx, poolID, branchName)
 	if err != nil {
 		return ksuid.Nil, err
@@ -212,7 +295,10 @@ func (l *local) ApplyIndexRules(ctx context.Context, ruleRefs []string, poolID k
 	return commit, nil
 }
 
-func (l *local) UpdateIndex(ctx context.Context, ruleRefs []string, poolID ksuid.KSUID, branchName string) (ksuid.KSUID, error) {
+func (l *local) UpdateIndex(ctx context.Context, ruleRefs []string, poolID ksuid.KSUID, branchName string) (id ksuid.KSUID, err error) {
+	defer func() {
+		l.audit(ctx, audit.OpUpdateIndex, poolID, branchName, err, append(audit.IDs(id), ruleRefs...)...)
+	}()
 	_, branch, err := l.lookupBranch(ctx, poolID, branchName)
 	if err != nil {
 		return ksuid.Nil, err
@@ -229,8 +315,12 @@ func (l *local) UpdateIndex(ctx context.Context, ruleRefs []string, poolID ksuid
 	return branch.UpdateIndex(ctx, l.compiler, rules)
 }
 
-func (l *local) AddVectors(ctx context.Context, pool, revision string, ids []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error) {
-	poolID, err := l.PoolID(ctx, pool)
+func (l *local) AddVectors(ctx context.Context, pool, revision string, ids []ksuid.KSUID, message api.CommitMessage) (id ksuid.KSUID, err error) {
+	var poolID ksuid.KSUID
+	defer func() {
+		l.audit(ctx, audit.OpAddVectors, poolID, revision, err, audit.IDs(append([]ksuid.KSUID{id}, ids...)...)...)
+	}()
+	poolID, err = l.PoolID(ctx, pool)
 	if err != nil {
 		return ksuid.Nil, err
 	}
@@ -241,8 +331,12 @@ func (l *local) AddVectors(ctx context.Context, pool, revision string, ids []ksu
 	return branch.AddVectors(ctx, ids, message.Author, message.Body)
 }
 
-func (l *local) DeleteVectors(ctx context.Context, pool, revision string, ids []ksuid.KSUID, message api.CommitMessage) (ksuid.KSUID, error) {
-	poolID, err := l.PoolID(ctx, pool)
+func (l *local) DeleteVectors(ctx context.Context, pool, revision string, ids []ksuid.KSUID, message api.CommitMessage) (id ksuid.KSUID, err error) {
+	var poolID ksuid.KSUID
+	defer func() {
+		l.audit(ctx, audit.OpDeleteVectors, poolID, revision, err, audit.IDs(append([]ksuid.KSUID{id}, ids...)...)...)
+	}()
+	poolID, err = l.PoolID(ctx, pool)
 	if err != nil {
 		return ksuid.Nil, err
 	}
@@ -253,8 +347,12 @@ func (l *local) DeleteVectors(ctx context.Context, pool, revision string, ids []
 	return branch.DeleteVectors(ctx, ids, message.Author, message.Body)
 }
 
-func (l *local) Vacuum(ctx context.Context, pool, revision string, dryrun bool) ([]ksuid.KSUID, error) {
-	poolID, err := l.PoolID(ctx, pool)
+func (l *local) Vacuum(ctx context.Context, pool, revision string, dryrun bool) (ids []ksuid.KSUID, err error) {
+	var poolID ksuid.KSUID
+	if !dryrun {
+		defer func() { l.audit(ctx, audit.OpVacuum, poolID, revision, err, audit.IDs(ids...)...) }()
+	}
+	poolID, err = l.PoolID(ctx, pool)
 	if err != nil {
 		return nil, err
 	}
@@ -268,3 +366,32 @@ func (l *local) Vacuum(ctx context.Context, pool, revision string, dryrun bool)
 	}
 	return p.Vacuum(ctx, commit, dryrun)
 }
+
+func (l *local) Checkpoint(ctx context.Context, pool, revision string, interval int, verify bool) (ids []ksuid.KSUID, err error) {
+	var poolID ksuid.KSUID
+	if !verify {
+		defer func() { l.audit(ctx, audit.OpCheckpoint, poolID, revision, err, audit.IDs(ids...)...) }()
+	}
+	poolID, err = l.PoolID(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+	p, err := l.root.OpenPool(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := p.ResolveRevision(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+	if verify {
+		if err := l.root.VerifyCheckpoints(ctx); err != nil {
+			return nil, err
+		}
+		return p.VerifyCheckpoints(ctx, commit)
+	}
+	if err := l.root.Checkpoint(ctx); err != nil {
+		return nil, err
+	}
+	return p.Checkpoint(ctx, commit, interval)
+}
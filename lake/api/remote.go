@@ -10,7 +10,9 @@ import (
 	"github.com/brimdata/zed/api/client"
 	"github.com/brimdata/zed/api/queryio"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/grants"
 	"github.com/brimdata/zed/lake/index"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/zbuf"
@@ -43,15 +45,23 @@ func (r *remote) PoolID(ctx context.Context, poolName string) (ksuid.KSUID, erro
 
 func (r *remote) CommitObject(ctx context.Context, poolID ksuid.KSUID, branchName string) (ksuid.KSUID, error) {
 	res, err := r.conn.BranchGet(ctx, poolID, branchName)
+	if errors.Is(err, client.ErrBranchNotFound) {
+		// The name may be a tag.
+		if tag, tagErr := r.conn.TagGet(ctx, poolID, branchName); tagErr == nil {
+			return tag.Commit, nil
+		}
+	}
 	return res.Commit, err
 }
 
-func (r *remote) CreatePool(ctx context.Context, name string, sortKey order.SortKey, seekStride int, thresh int64) (ksuid.KSUID, error) {
+func (r *remote) CreatePool(ctx context.Context, name string, sortKey order.SortKey, seekStride int, thresh int64, compression string, schema *pools.Schema) (ksuid.KSUID, error) {
 	res, err := r.conn.CreatePool(ctx, api.PoolPostRequest{
-		Name:       name,
-		SortKey:    sortKey,
-		SeekStride: seekStride,
-		Thresh:     thresh,
+		Name:        name,
+		SortKey:     sortKey,
+		SeekStride:  seekStride,
+		Thresh:      thresh,
+		Compression: compression,
+		Schema:      schema,
 	})
 	if err != nil {
 		return ksuid.Nil, err
@@ -71,6 +81,18 @@ func (r *remote) RemoveBranch(ctx context.Context, poolID ksuid.KSUID, branchNam
 	return errors.New("TBD remote.RemoveBranch")
 }
 
+func (r *remote) CreateTag(ctx context.Context, poolID ksuid.KSUID, name string, commit ksuid.KSUID) error {
+	_, err := r.conn.CreateTag(ctx, poolID, api.TagPostRequest{
+		Name:   name,
+		Commit: commit.String(),
+	})
+	return err
+}
+
+func (r *remote) RemoveTag(ctx context.Context, poolID ksuid.KSUID, tagName string) error {
+	return r.conn.RemoveTag(ctx, poolID, tagName)
+}
+
 func (r *remote) MergeBranch(ctx context.Context, poolID ksuid.KSUID, childBranch, parentBranch string, message api.CommitMessage) (ksuid.KSUID, error) {
 	res, err := r.conn.MergeBranch(ctx, poolID, childBranch, parentBranch, message)
 	return res.Commit, err
@@ -174,3 +196,26 @@ func (r *remote) Vacuum(ctx context.Context, pool, revision string, dryrun bool)
 	res, err := r.conn.Vacuum(ctx, pool, revision, dryrun)
 	return res.ObjectIDs, err
 }
+
+func (r *remote) ListGrants(ctx context.Context) ([]grants.Grant, error) {
+	res, err := r.conn.ListGrants(ctx)
+	return res.Grants, err
+}
+
+func (r *remote) Grant(ctx context.Context, subject string, poolID ksuid.KSUID, branch string, role grants.Role) error {
+	return r.conn.Grant(ctx, api.GrantRequest{
+		Subject: subject,
+		Pool:    poolID,
+		Branch:  branch,
+		Role:    string(role),
+	})
+}
+
+func (r *remote) Revoke(ctx context.Context, subject string, poolID ksuid.KSUID, branch string) error {
+	return r.conn.Revoke(ctx, subject, poolID, branch)
+}
+
+func (r *remote) Checkpoint(ctx context.Context, pool, revision string, interval int, verify bool) ([]ksuid.KSUID, error) {
+	res, err := r.conn.Checkpoint(ctx, pool, revision, interval, verify)
+	return res.Commits, err
+}
@@ -0,0 +1,94 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+)
+
+// Prefix begins every API key so that a key is distinguished from the JWT
+// access tokens presented in the same Authorization header.
+const Prefix = "zed_"
+
+// A Key is the stored form of an API key.  The secret of the key is not
+// stored, only its SHA-256 hash.  A key authenticates as the tenant and
+// user of the identity that created it but not with the roles of that
+// identity, which are claims of its access token, so a key holds only the
+// roles granted to its user when it is used.  If Pools is not empty, the key
+// may only access those pools, and if Expires is not zero, the key is not
+// valid after that time.
+type Key struct {
+	ID       ksuid.KSUID   `zed:"id"`
+	Name     string        `zed:"name"`
+	Hash     string        `zed:"hash"`
+	TenantID string        `zed:"tenant_id"`
+	UserID   string        `zed:"user_id"`
+	Pools    []ksuid.KSUID `zed:"pools"`
+	Created  nano.Ts       `zed:"created"`
+	Expires  nano.Ts       `zed:"expires"`
+}
+
+// New returns a new key and its secret, which is the API key presented by
+// clients.  The secret is not recoverable from the key.
+func New(name, tenantID, userID string, pools []ksuid.KSUID, expires nano.Ts) (*Key, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	id := ksuid.New()
+	secret := Prefix + id.String() + "_" + hex.EncodeToString(b)
+	return &Key{
+		ID:       id,
+		Name:     name,
+		Hash:     hash(secret),
+		TenantID: tenantID,
+		UserID:   userID,
+		Pools:    pools,
+		Created:  nano.Now(),
+		Expires:  expires,
+	}, secret, nil
+}
+
+// Key returns the journal key of k.
+func (k *Key) Key() string {
+	return k.ID.String()
+}
+
+// Verify returns true if secret is the secret of k.
+func (k *Key) Verify(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash(secret)), []byte(k.Hash)) == 1
+}
+
+// Expired returns true if k is not valid at now.
+func (k *Key) Expired(now nano.Ts) bool {
+	return k.Expires != 0 && now >= k.Expires
+}
+
+// IsKey returns true if s has the form of an API key.
+func IsKey(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
+
+// ParseID returns the ID of the key whose secret is s.
+func ParseID(s string) (ksuid.KSUID, bool) {
+	s, ok := strings.CutPrefix(s, Prefix)
+	if !ok {
+		return ksuid.Nil, false
+	}
+	s, _, ok = strings.Cut(s, "_")
+	if !ok {
+		return ksuid.Nil, false
+	}
+	id, err := ksuid.Parse(s)
+	return id, err == nil
+}
+
+func hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
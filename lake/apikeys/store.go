@@ -0,0 +1,153 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrExpired  = errors.New("API key expired")
+	ErrInvalid  = errors.New("invalid API key")
+	ErrNotFound = errors.New("API key not found")
+)
+
+type Store struct {
+	engine storage.Engine
+	logger *zap.Logger
+	path   *storage.URI
+
+	mu    sync.Mutex
+	store *journal.Store
+}
+
+// OpenStore returns the store of the API key journal at path, which is
+// opened on first use.  There is no API key journal until the first key is
+// created so it is created then.
+func OpenStore(engine storage.Engine, logger *zap.Logger, path *storage.URI) *Store {
+	return &Store{engine: engine, logger: logger, path: path}
+}
+
+// journal returns the API key journal, which is nil if it does not exist and
+// create is false.
+func (s *Store) journal(ctx context.Context, create bool) (*journal.Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != nil {
+		return s.store, nil
+	}
+	ok, err := s.engine.Exists(ctx, s.path.JoinPath("HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok:
+		s.store, err = journal.OpenStore(ctx, s.engine, s.logger, s.path, Key{})
+	case create:
+		s.store, err = journal.CreateStore(ctx, s.engine, s.logger, s.path, Key{})
+	}
+	return s.store, err
+}
+
+// All returns the keys ordered by ID and thus by creation time.
+func (s *Store) All(ctx context.Context) ([]Key, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil || store == nil {
+		return nil, err
+	}
+	entries, err := store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Key, 0, len(entries))
+	for _, entry := range entries {
+		key, ok := entry.(*Key)
+		if !ok {
+			return nil, errors.New("corrupt API key journal")
+		}
+		list = append(list, *key)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return ksuid.Compare(list[i].ID, list[j].ID) < 0
+	})
+	return list, nil
+}
+
+func (s *Store) Add(ctx context.Context, key *Key) error {
+	store, err := s.journal(ctx, true)
+	if err != nil {
+		return err
+	}
+	return store.Insert(ctx, key)
+}
+
+func (s *Store) Lookup(ctx context.Context, id ksuid.KSUID) (*Key, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	entry, err := store.Lookup(ctx, id.String())
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, fmt.Errorf("%s: %w", id, ErrNotFound)
+		}
+		return nil, err
+	}
+	key, ok := entry.(*Key)
+	if !ok {
+		return nil, errors.New("corrupt API key journal")
+	}
+	return key, nil
+}
+
+func (s *Store) Remove(ctx context.Context, id ksuid.KSUID) error {
+	store, err := s.journal(ctx, false)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if err := store.Delete(ctx, id.String(), nil); err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return fmt.Errorf("%s: %w", id, ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+// Validate returns the key whose secret is secret.  It returns ErrInvalid
+// if there is no such key, as when the key has been revoked, and
+// ErrExpired if the key has expired.
+func (s *Store) Validate(ctx context.Context, secret string) (*Key, error) {
+	id, ok := ParseID(secret)
+	if !ok {
+		return nil, ErrInvalid
+	}
+	key, err := s.Lookup(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrInvalid
+		}
+		return nil, err
+	}
+	if !key.Verify(secret) {
+		return nil, ErrInvalid
+	}
+	if key.Expired(nano.Now()) {
+		return nil, ErrExpired
+	}
+	return key, nil
+}
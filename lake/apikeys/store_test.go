@@ -0,0 +1,52 @@
+package apikeys
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStoreValidate(t *testing.T) {
+	ctx := context.Background()
+	path := storage.MustParseURI(t.TempDir())
+	store := OpenStore(storage.NewLocalEngine(), zap.NewNop(), path)
+
+	_, err := store.Validate(ctx, Prefix+ksuid.New().String()+"_00")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	key, secret, err := New("ci", "tenant1", "user1", nil, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, key))
+	assert.NotContains(t, key.Hash, secret)
+
+	got, err := store.Validate(ctx, secret)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", got.UserID)
+	_, err = store.Validate(ctx, secret+"0")
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = store.Validate(ctx, "bogus")
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	expired, expiredSecret, err := New("old", "tenant1", "user1", nil, nano.Now().Add(-nano.Duration(time.Hour)))
+	require.NoError(t, err)
+	require.NoError(t, store.Add(ctx, expired))
+	_, err = store.Validate(ctx, expiredSecret)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	list, err := store.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.ElementsMatch(t, []ksuid.KSUID{key.ID, expired.ID}, []ksuid.KSUID{list[0].ID, list[1].ID})
+
+	require.NoError(t, store.Remove(ctx, key.ID))
+	_, err = store.Validate(ctx, secret)
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.ErrorIs(t, store.Remove(ctx, key.ID), ErrNotFound)
+}
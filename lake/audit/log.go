@@ -0,0 +1,251 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zngbytes"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+)
+
+// Operations recorded in the audit log.
+const (
+	OpAddIndexRules    = "add_index_rules"
+	OpAddVectors       = "add_vectors"
+	OpApplyIndexRules  = "apply_index_rules"
+	OpCheckpoint       = "checkpoint"
+	OpCompact          = "compact"
+	OpCreateAPIKey     = "create_apikey"
+	OpCreateBranch     = "create_branch"
+	OpCreatePool       = "create_pool"
+	OpCreateTag        = "create_tag"
+	OpDelete           = "delete"
+	OpDeleteBranch     = "delete_branch"
+	OpDeleteIndexRules = "delete_index_rules"
+	OpDeletePool       = "delete_pool"
+	OpDeleteTag        = "delete_tag"
+	OpDeleteVectors    = "delete_vectors"
+	OpGrant            = "grant"
+	OpLoad             = "load"
+	OpMerge            = "merge"
+	OpRenamePool       = "rename_pool"
+	OpRevert           = "revert"
+	OpRevoke           = "revoke"
+	OpRevokeAPIKey     = "revoke_apikey"
+	OpUpdateIndex      = "update_index"
+	OpVacuum           = "vacuum"
+)
+
+// Outcomes of recorded operations.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+const maxRetries = 100
+
+var ErrRetriesExceeded = errors.New("audit journal unavailable after too many attempts")
+
+// A Record describes an operation that modified or attempted to modify a
+// lake.  Pool and Branch identify the pool and branch operated on, if any,
+// and Targets lists the other objects of the operation such as the IDs of
+// the commits it created, the data objects it deleted, or the index rules it
+// added, the name of a tag, or the subject of a grant.
+type Record struct {
+	Ts        nano.Ts     `zed:"ts"`
+	TenantID  string      `zed:"tenant_id"`
+	UserID    string      `zed:"user_id"`
+	RequestID string      `zed:"request_id"`
+	Op        string      `zed:"op"`
+	Pool      ksuid.KSUID `zed:"pool"`
+	Branch    string      `zed:"branch"`
+	Targets   []string    `zed:"targets"`
+	Outcome   string      `zed:"outcome"`
+	Error     string      `zed:"error"`
+}
+
+func NewRecord(op string) *Record {
+	return &Record{
+		Ts: nano.Now(),
+		Op: op,
+	}
+}
+
+// SetError sets the outcome of the operation recorded by r to Success
+// if err is nil and otherwise to Failure with err as the reason.
+func (r *Record) SetError(err error) {
+	if err == nil {
+		r.Outcome = Success
+		r.Error = ""
+		return
+	}
+	r.Outcome = Failure
+	r.Error = err.Error()
+}
+
+type recordKey struct{}
+
+// ContextWithRecord returns a context carrying rec.  An operation performed
+// with the context adds its targets to rec, which is recorded by the caller,
+// rather than appending its own record to the log.
+func ContextWithRecord(ctx context.Context, rec *Record) context.Context {
+	return context.WithValue(ctx, recordKey{}, rec)
+}
+
+func RecordFromContext(ctx context.Context) *Record {
+	rec, _ := ctx.Value(recordKey{}).(*Record)
+	return rec
+}
+
+// Identity identifies the user and request on whose behalf an operation is
+// performed.
+type Identity struct {
+	TenantID  string
+	UserID    string
+	RequestID string
+}
+
+type identityKey struct{}
+
+// ContextWithIdentity returns a context carrying ident.  Operations that
+// record themselves, like the load operator of a query, take the identity
+// of their records from the context.
+func ContextWithIdentity(ctx context.Context, ident Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, ident)
+}
+
+// NewRecordFromContext is like NewRecord but sets the identity of the record
+// to that carried by ctx, if any.
+func NewRecordFromContext(ctx context.Context, op string) *Record {
+	rec := NewRecord(op)
+	if ident, ok := ctx.Value(identityKey{}).(Identity); ok {
+		rec.TenantID = ident.TenantID
+		rec.UserID = ident.UserID
+		rec.RequestID = ident.RequestID
+	}
+	return rec
+}
+
+// IDs returns the targets for the non-nil IDs of ids.
+func IDs(ids ...ksuid.KSUID) []string {
+	var targets []string
+	for _, id := range ids {
+		if id != ksuid.Nil {
+			targets = append(targets, id.String())
+		}
+	}
+	return targets
+}
+
+// Log is an append-only journal of Records.  A lake has no audit journal
+// until the first record is appended so it is created then.
+type Log struct {
+	engine storage.Engine
+	path   *storage.URI
+
+	mu    sync.Mutex
+	queue *journal.Queue
+}
+
+func OpenLog(engine storage.Engine, path *storage.URI) *Log {
+	return &Log{engine: engine, path: path}
+}
+
+// journal returns the audit journal, which is nil if it does not exist and
+// create is false.
+func (l *Log) journal(ctx context.Context, create bool) (*journal.Queue, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.queue != nil {
+		return l.queue, nil
+	}
+	ok, err := l.engine.Exists(ctx, l.path.JoinPath("HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok:
+		l.queue, err = journal.Open(ctx, l.engine, l.path)
+	case create:
+		l.queue, err = journal.Create(ctx, l.engine, l.path, journal.Nil)
+	}
+	return l.queue, err
+}
+
+// Append adds rec to the end of the log.
+func (l *Log) Append(ctx context.Context, rec *Record) error {
+	serializer := zngbytes.NewSerializer()
+	serializer.Decorate(zson.StylePackage)
+	if err := serializer.Write(rec); err != nil {
+		return err
+	}
+	if err := serializer.Close(); err != nil {
+		return err
+	}
+	q, err := l.journal(ctx, true)
+	if err != nil {
+		return err
+	}
+	at, err := q.ReadHead(ctx)
+	if err != nil {
+		return err
+	}
+	// HEAD may lag the journal when records are appended concurrently
+	// so a conflicting write is retried at the next position.
+	for attempts := 0; attempts < maxRetries; attempts++ {
+		err := q.CommitAt(ctx, at, serializer.Bytes())
+		if !os.IsExist(err) {
+			return err
+		}
+		at++
+	}
+	return ErrRetriesExceeded
+}
+
+// All returns the records of the log as values in zctx in the order they
+// were appended.
+func (l *Log) All(ctx context.Context, zctx *zed.Context) ([]zed.Value, error) {
+	q, err := l.journal(ctx, false)
+	if err != nil || q == nil {
+		return nil, err
+	}
+	head, err := q.ReadHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// HEAD may lag the journal after concurrent appends so the records
+	// past it are included.
+	for {
+		if _, err := q.Load(ctx, head+1); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+		head++
+	}
+	if head == journal.Nil {
+		return nil, nil
+	}
+	r, err := q.OpenAsZNG(ctx, zctx, head, journal.Nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	var vals []zed.Value
+	for {
+		val, err := r.Read()
+		if val == nil || err != nil {
+			return vals, err
+		}
+		vals = append(vals, *val.Copy())
+	}
+}
@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogAppendConcurrent(t *testing.T) {
+	ctx := context.Background()
+	log := OpenLog(storage.NewLocalEngine(), storage.MustParseURI(t.TempDir()))
+	vals, err := log.All(ctx, zed.NewContext())
+	require.NoError(t, err)
+	require.Len(t, vals, 0)
+
+	const N = 20
+	ch := make(chan error)
+	for i := 0; i < N; i++ {
+		go func(i int) {
+			rec := NewRecord(OpLoad)
+			rec.Targets = []string{fmt.Sprint(i)}
+			rec.SetError(nil)
+			ch <- log.Append(ctx, rec)
+		}(i)
+	}
+	for i := 0; i < N; i++ {
+		require.NoError(t, <-ch)
+	}
+	vals, err = log.All(ctx, zed.NewContext())
+	require.NoError(t, err)
+	require.Len(t, vals, N)
+	seen := make(map[string]bool)
+	for _, val := range vals {
+		var rec Record
+		require.NoError(t, zson.UnmarshalZNG(&val, &rec))
+		require.Equal(t, OpLoad, rec.Op)
+		require.Equal(t, Success, rec.Outcome)
+		seen[rec.Targets[0]] = true
+	}
+	require.Len(t, seen, N)
+}
+
+func TestRecord(t *testing.T) {
+	rec := NewRecord(OpDeletePool)
+	rec.SetError(errors.New("pool not found"))
+	require.Equal(t, Failure, rec.Outcome)
+	require.Equal(t, "pool not found", rec.Error)
+
+	ctx := ContextWithRecord(context.Background(), rec)
+	require.Equal(t, rec, RecordFromContext(ctx))
+	require.Nil(t, RecordFromContext(context.Background()))
+
+	id := ksuid.New()
+	require.Equal(t, []string{id.String()}, IDs(ksuid.Nil, id))
+}
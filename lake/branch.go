@@ -13,6 +13,7 @@ import (
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/lake/pools"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/plural"
 	"github.com/brimdata/zed/pkg/storage"
@@ -49,7 +50,29 @@ func OpenBranch(ctx context.Context, config *branches.Config, engine storage.Eng
 	}, nil
 }
 
-func (b *Branch) Load(ctx context.Context, zctx *zed.Context, r zio.Reader, author, message, meta string) (ksuid.KSUID, error) {
+func (b *Branch) Load(ctx context.Context, c runtime.Compiler, zctx *zed.Context, r zio.Reader, author, message, meta string) (ksuid.KSUID, error) {
+	var schema *schemaReader
+	var qbranch *Branch
+	var qw *Writer
+	if s := b.pool.Schema; s != nil {
+		if s.Mode == pools.SchemaQuarantine {
+			var err error
+			qbranch, err = b.pool.OpenBranchByName(ctx, s.Quarantine)
+			if err != nil {
+				return ksuid.Nil, err
+			}
+			if qw, err = NewWriter(ctx, zctx, b.pool); err != nil {
+				return ksuid.Nil, err
+			}
+		}
+		var err error
+		schema, err = newSchemaReader(ctx, c, zctx, s, r, qw)
+		if err != nil {
+			return ksuid.Nil, err
+		}
+		defer schema.Close()
+		r = schema
+	}
 	w, err := NewWriter(ctx, zctx, b.pool)
 	if err != nil {
 		return ksuid.Nil, err
@@ -58,27 +81,63 @@ func (b *Branch) Load(ctx context.Context, zctx *zed.Context, r zio.Reader, auth
 	if closeErr := w.Close(); err == nil {
 		err = closeErr
 	}
+	if qw != nil {
+		if closeErr := qw.Close(); err == nil {
+			err = closeErr
+		}
+	}
 	if err != nil {
 		return ksuid.Nil, err
 	}
+	var report SchemaReport
+	if schema != nil {
+		report = schema.Report()
+	}
 	objects := w.Objects()
-	if len(objects) == 0 {
+	if len(objects) == 0 && report.IsZero() {
 		return ksuid.Nil, commits.ErrEmptyTransaction
 	}
-	if message == "" {
-		message = loadMessage(objects)
-	}
 	appMeta, err := loadMeta(zctx, meta)
 	if err != nil {
 		return ksuid.Nil, err
 	}
+	if !report.IsZero() {
+		if appMeta, err = schemaMeta(zctx, appMeta, report); err != nil {
+			return ksuid.Nil, err
+		}
+	}
+	qmessage := message
+	if message == "" {
+		message = loadMessage(objects)
+		if !report.IsZero() {
+			message += "\n" + report.String()
+		}
+	}
 	// The load operation has only added new objects so we know its
 	// safe to merge at the tip and there can be no conflicts
 	// with other concurrent writers (except for updating the branch pointer
 	// which is handled by Branch.commit)
-	return b.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
+	commit, err := b.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
 		return commits.NewAddsObject(parent.Commit, retries, author, message, *appMeta, objects), nil
 	})
+	if err != nil {
+		return ksuid.Nil, err
+	}
+	// The quarantined values are committed only after the load so a
+	// failed load leaves the quarantine branch untouched.
+	if qw != nil && len(qw.Objects()) > 0 {
+		qobjects := qw.Objects()
+		if qmessage == "" {
+			qmessage = loadMessage(qobjects) + "\n" + report.String()
+		}
+		_, err := qbranch.commit(ctx, func(parent *branches.Config, retries int) (*commits.Object, error) {
+			return commits.NewAddsObject(parent.Commit, retries, author, qmessage, *appMeta, qobjects), nil
+		})
+		if err != nil {
+			return commit, fmt.Errorf("load committed as %s but quarantine branch %q commit failed: %w", commit, qbranch.Name, err)
+		}
+	}
+	return commit, nil
 }
 
 func loadMessage(objects []data.Object) string {
@@ -521,7 +580,7 @@ func (b *Branch) AddVectors(ctx context.Context, ids []ksuid.KSUID, author, mess
 	// XXX We should add some parallelism here to stream the next file while
 	// the CPU is chugging away on the current file.  See issue #4015.
 	for _, id := range ids {
-		if err := data.CreateVector(ctx, b.pool.engine, b.pool.DataPath, id); err != nil {
+		if err := data.CreateVector(ctx, b.pool.engine, b.pool.DataPath, id, b.pool.CompressionFormat()); err != nil {
 			return ksuid.Nil, err
 		}
 	}
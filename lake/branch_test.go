@@ -0,0 +1,39 @@
+package lake_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLoadQuarantineAfterFailedCommit(t *testing.T) {
+	ctx := context.Background()
+	root, err := lake.Create(ctx, storage.NewLocalEngine(), zap.NewNop(), storage.MustParseURI(t.TempDir()))
+	require.NoError(t, err)
+	schema, err := pools.NewSchema("{x:int64}", pools.SchemaQuarantine, "", "")
+	require.NoError(t, err)
+	pool, err := root.CreatePool(ctx, "test", order.NewSortKey(order.Asc, field.DottedList("x")), 0, 0, "", schema)
+	require.NoError(t, err)
+	branch, err := pool.OpenBranchByName(ctx, "main")
+	require.NoError(t, err)
+	// Removing the branch makes the commit of the load fail.
+	require.NoError(t, root.RemoveBranch(ctx, pool.ID, "main"))
+	zctx := zed.NewContext()
+	r := zsonio.NewReader(zctx, strings.NewReader(`{x:1} {x:"2"}`))
+	_, err = branch.Load(ctx, nil, zctx, r, "", "", "")
+	require.Error(t, err)
+	config, err := pool.LookupBranchByName(ctx, pools.DefaultQuarantineBranch)
+	require.NoError(t, err)
+	require.Equal(t, ksuid.Nil, config.Commit)
+}
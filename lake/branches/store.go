@@ -92,3 +92,11 @@ func (s *Store) Remove(ctx context.Context, config Config) error {
 	}
 	return nil
 }
+
+func (s *Store) Checkpoint(ctx context.Context) (journal.ID, error) {
+	return s.store.Checkpoint(ctx)
+}
+
+func (s *Store) VerifyCheckpoints(ctx context.Context) ([]journal.ID, error) {
+	return s.store.VerifyCheckpoints(ctx)
+}
@@ -0,0 +1,118 @@
+package commits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/ksuid"
+)
+
+// DefaultCheckpointInterval is the number of commits between the checkpoints
+// of a commit history.  A checkpoint is a snapshot stored for a commit so
+// that building the snapshot of a later commit replays at most this many
+// commit objects.
+const DefaultCheckpointInterval = 1000
+
+var ErrCheckpointMismatch = errors.New("checkpoint does not match commit history")
+
+const snapshotSuffix = ".snap.zng"
+
+// Checkpoint stores a checkpoint for every interval-th commit on the path
+// from the root of the commit history to leaf, as well as for leaf itself,
+// and returns the commits for which a checkpoint was written.  Commits that
+// already have a checkpoint are skipped.
+func (s *Store) Checkpoint(ctx context.Context, leaf ksuid.KSUID, interval int) ([]ksuid.KSUID, error) {
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+	var written []ksuid.KSUID
+	err := s.replayPath(ctx, leaf, func(id ksuid.KSUID, depth int, snap *Snapshot, exists bool) error {
+		if exists || (depth%interval != 0 && id != leaf) {
+			return nil
+		}
+		if err := s.putSnapshot(ctx, id, snap); err != nil {
+			return err
+		}
+		written = append(written, id)
+		return nil
+	})
+	return written, err
+}
+
+// VerifyCheckpoints compares each checkpoint on the path from the root of
+// the commit history to leaf with the snapshot obtained by replaying the
+// commit objects and returns the commits whose checkpoints were verified.
+// A checkpoint that does not match may be safely deleted as it is rebuilt
+// when needed.
+func (s *Store) VerifyCheckpoints(ctx context.Context, leaf ksuid.KSUID) ([]ksuid.KSUID, error) {
+	var verified []ksuid.KSUID
+	err := s.replayPath(ctx, leaf, func(id ksuid.KSUID, _ int, snap *Snapshot, exists bool) error {
+		if !exists {
+			return nil
+		}
+		checkpoint, err := s.getSnapshot(ctx, id)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.snapshotPathOf(id), err)
+		}
+		if !checkpoint.equal(snap) {
+			return fmt.Errorf("%s: %w", s.snapshotPathOf(id), ErrCheckpointMismatch)
+		}
+		verified = append(verified, id)
+		return nil
+	})
+	return verified, err
+}
+
+// replayPath plays the commit objects on the path from the root of the commit
+// history to leaf and calls fn after each with the commit's depth in the
+// history, the resulting snapshot, and whether a checkpoint exists for the
+// commit.  fn must not modify the snapshot.
+func (s *Store) replayPath(ctx context.Context, leaf ksuid.KSUID, fn func(ksuid.KSUID, int, *Snapshot, bool) error) error {
+	if leaf == ksuid.Nil {
+		return nil
+	}
+	path, err := s.Path(ctx, leaf)
+	if err != nil {
+		return err
+	}
+	checkpoints, err := s.checkpoints(ctx)
+	if err != nil {
+		return err
+	}
+	snap := NewSnapshot()
+	for k := len(path) - 1; k >= 0; k-- {
+		o, err := s.Get(ctx, path[k])
+		if err != nil {
+			return err
+		}
+		if err := Play(snap, o); err != nil {
+			return err
+		}
+		_, exists := checkpoints[path[k]]
+		if err := fn(path[k], len(path)-k, snap, exists); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkpoints returns the set of commits for which a checkpoint is stored.
+func (s *Store) checkpoints(ctx context.Context) (map[ksuid.KSUID]struct{}, error) {
+	infos, err := s.engine.List(ctx, s.path)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[ksuid.KSUID]struct{})
+	for _, info := range infos {
+		name, ok := strings.CutSuffix(info.Name, snapshotSuffix)
+		if !ok {
+			continue
+		}
+		if id, err := ksuid.Parse(name); err == nil {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids, nil
+}
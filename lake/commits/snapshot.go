@@ -214,6 +214,34 @@ func (s *Snapshot) Copy() *Snapshot {
 	return out
 }
 
+// equal reports whether s and other contain the same data objects, index
+// objects, and vectors.
+func (s *Snapshot) equal(other *Snapshot) bool {
+	if len(s.objects) != len(other.objects) || len(s.vectors) != len(other.vectors) {
+		return false
+	}
+	for id, o := range s.objects {
+		if p, ok := other.objects[id]; !ok || o.Count != p.Count || o.Size != p.Size {
+			return false
+		}
+	}
+	for id := range s.vectors {
+		if _, ok := other.vectors[id]; !ok {
+			return false
+		}
+	}
+	indexes := s.indexes.All()
+	if len(indexes) != len(other.indexes.All()) {
+		return false
+	}
+	for _, o := range indexes {
+		if !other.indexes.Exists(o) {
+			return false
+		}
+	}
+	return true
+}
+
 // serialize serializes a snapshot as a sequence of actions.  Commit IDs are
 // omitted from actions since they are neither available here nor required
 // during deserialization.  Deleted entities are serialized as an add-delete
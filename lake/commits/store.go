@@ -152,6 +152,13 @@ func (s *Store) Snapshot(ctx context.Context, leaf ksuid.KSUID) (*Snapshot, erro
 				return nil, err
 			}
 		}
+		// Checkpoint long replays so the next replay through this
+		// part of the history starts from a nearby snapshot.
+		if k > 0 && (len(objects)-k)%DefaultCheckpointInterval == 0 {
+			if err := s.putSnapshot(ctx, objects[k].Commit, snap); err != nil {
+				s.logger.Error("Storing checkpoint", zap.Error(err))
+			}
+		}
 	}
 	if err := s.putSnapshot(ctx, leaf, snap); err != nil {
 		s.logger.Error("Storing snapshot", zap.Error(err))
@@ -178,7 +185,7 @@ func (s *Store) putSnapshot(ctx context.Context, commit ksuid.KSUID, snap *Snaps
 }
 
 func (s *Store) snapshotPathOf(commit ksuid.KSUID) *storage.URI {
-	return s.path.JoinPath(commit.String() + ".snap.zng")
+	return s.path.JoinPath(commit.String() + snapshotSuffix)
 }
 
 // Path return the entire path from the commit object to the root
@@ -342,12 +349,20 @@ func (s *Store) PatchOfPath(ctx context.Context, base *Snapshot, baseID, commit
 }
 
 // Vacuumable returns the set of data.Objects in the path of leaf that are not referenced
-// by the leaf's snapshot.
-func (s *Store) Vacuumable(ctx context.Context, leaf ksuid.KSUID, out chan<- *data.Object) error {
+// by the leaf's snapshot or by the snapshot of any commit in keep.
+func (s *Store) Vacuumable(ctx context.Context, leaf ksuid.KSUID, keep []ksuid.KSUID, out chan<- *data.Object) error {
 	snap, err := s.Snapshot(ctx, leaf)
 	if err != nil {
 		return err
 	}
+	snaps := []*Snapshot{snap}
+	for _, commit := range keep {
+		snap, err := s.Snapshot(ctx, commit)
+		if err != nil {
+			return err
+		}
+		snaps = append(snaps, snap)
+	}
 	for at := leaf; at != ksuid.Nil; {
 		o, err := s.Get(ctx, at)
 		if err != nil {
@@ -361,7 +376,7 @@ func (s *Store) Vacuumable(ctx context.Context, leaf ksuid.KSUID, out chan<- *da
 		for _, action := range o.Actions {
 			switch a := action.(type) {
 			case *Add:
-				if !snap.Exists(a.Object.ID) {
+				if !anyExists(snaps, a.Object.ID) {
 					select {
 					case out <- &a.Object:
 					case <-ctx.Done():
@@ -375,3 +390,12 @@ func (s *Store) Vacuumable(ctx context.Context, leaf ksuid.KSUID, out chan<- *da
 	}
 	return nil
 }
+
+func anyExists(snaps []*Snapshot, id ksuid.KSUID) bool {
+	for _, snap := range snaps {
+		if snap.Exists(id) {
+			return true
+		}
+	}
+	return false
+}
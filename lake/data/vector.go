@@ -16,8 +16,9 @@ import (
 	"github.com/segmentio/ksuid"
 )
 
-// CreateVector writes the vectorized form of an existing Object in the VNG format.
-func CreateVector(ctx context.Context, engine storage.Engine, path *storage.URI, id ksuid.KSUID) error {
+// CreateVector writes the vectorized form of an existing Object in the VNG
+// format with segments compressed with compression.
+func CreateVector(ctx context.Context, engine storage.Engine, path *storage.URI, id ksuid.KSUID, compression zngio.CompressionFormat) error {
 	get, err := engine.Get(ctx, SequenceURI(path, id))
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -26,7 +27,7 @@ func CreateVector(ctx context.Context, engine storage.Engine, path *storage.URI,
 		}
 		return err
 	}
-	w, err := NewVectorWriter(ctx, engine, path, id)
+	w, err := NewVectorWriter(ctx, engine, path, id, compression)
 	if err != nil {
 		get.Close()
 		return err
@@ -55,11 +56,11 @@ type VectorWriter struct {
 	delete func()
 }
 
-func (o *Object) NewVectorWriter(ctx context.Context, engine storage.Engine, path *storage.URI) (*VectorWriter, error) {
-	return NewVectorWriter(ctx, engine, path, o.ID)
+func (o *Object) NewVectorWriter(ctx context.Context, engine storage.Engine, path *storage.URI, compression zngio.CompressionFormat) (*VectorWriter, error) {
+	return NewVectorWriter(ctx, engine, path, o.ID, compression)
 }
 
-func NewVectorWriter(ctx context.Context, engine storage.Engine, path *storage.URI, id ksuid.KSUID) (*VectorWriter, error) {
+func NewVectorWriter(ctx context.Context, engine storage.Engine, path *storage.URI, id ksuid.KSUID, compression zngio.CompressionFormat) (*VectorWriter, error) {
 	put, err := engine.Put(ctx, VectorURI(path, id))
 	if err != nil {
 		return nil, err
@@ -67,7 +68,11 @@ func NewVectorWriter(ctx context.Context, engine storage.Engine, path *storage.U
 	delete := func() {
 		DeleteVector(context.Background(), engine, path, id)
 	}
-	writer, err := vngio.NewWriter(bufwriter.New(put))
+	writer, err := vngio.NewWriterWithOpts(bufwriter.New(put), vngio.WriterOpts{
+		ColumnThresh: vngio.DefaultColumnThresh,
+		SkewThresh:   vngio.DefaultSkewThresh,
+		Compression:  compression,
+	})
 	if err != nil {
 		delete()
 		return nil, err
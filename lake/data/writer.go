@@ -32,10 +32,11 @@ type Writer struct {
 
 // NewWriter returns a writer for writing the data of a zng-row storage object as
 // well as optionally creating a seek index for the row object when the
-// seekIndexStride is non-zero.  We assume all records are non-volatile until
-// Close as zed.Values from the various record bodies are referenced across
-// calls to Write.
-func (o *Object) NewWriter(ctx context.Context, engine storage.Engine, path *storage.URI, order order.Which, poolKey field.Path, seekIndexStride int) (*Writer, error) {
+// seekIndexStride is non-zero.  The object's frames are compressed with
+// compression.  We assume all records are non-volatile until Close as
+// zed.Values from the various record bodies are referenced across calls to
+// Write.
+func (o *Object) NewWriter(ctx context.Context, engine storage.Engine, path *storage.URI, order order.Which, poolKey field.Path, seekIndexStride int, compression zngio.CompressionFormat) (*Writer, error) {
 	out, err := engine.Put(ctx, o.SequenceURI(path))
 	if err != nil {
 		return nil, err
@@ -44,10 +45,14 @@ func (o *Object) NewWriter(ctx context.Context, engine storage.Engine, path *sto
 	w := &Writer{
 		object:      o,
 		byteCounter: counter,
-		writer:      zngio.NewWriter(counter),
-		order:       order,
-		poolKey:     poolKey,
-		first:       true,
+		writer: zngio.NewWriterWithOpts(counter, zngio.WriterOpts{
+			Compress:          true,
+			CompressionFormat: compression,
+			FrameThresh:       zngio.DefaultFrameThresh,
+		}),
+		order:   order,
+		poolKey: poolKey,
+		first:   true,
 	}
 	if seekIndexStride == 0 {
 		seekIndexStride = DefaultSeekStride
@@ -11,6 +11,7 @@ import (
 	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zio/vngio"
+	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zson"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,14 +22,14 @@ func TestDataReaderWriterVector(t *testing.T) {
 	tmp := storage.MustParseURI(t.TempDir())
 	object := data.NewObject()
 	ctx := context.Background()
-	w, err := object.NewWriter(ctx, engine, tmp, order.Asc, field.Path{"a"}, 1000)
+	w, err := object.NewWriter(ctx, engine, tmp, order.Asc, field.Path{"a"}, 1000, zngio.CompressionFormatZstd)
 	require.NoError(t, err)
 	zctx := zed.NewContext()
 	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:1,b:4}")))
 	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:2,b:5}")))
 	require.NoError(t, w.Write(zson.MustParseValue(zctx, "{a:3,b:6}")))
 	require.NoError(t, w.Close(ctx))
-	require.NoError(t, data.CreateVector(ctx, engine, tmp, object.ID))
+	require.NoError(t, data.CreateVector(ctx, engine, tmp, object.ID, zngio.CompressionFormatZstd))
 	// Read back the VNG file and make sure it's the same.
 	get, err := engine.Get(ctx, object.VectorURI(tmp))
 	require.NoError(t, err)
@@ -0,0 +1,128 @@
+package grants
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+)
+
+// A Role is a set of permissions on a lake, pool, or branch.  Each role
+// includes the permissions of the roles before it: a reader may read data
+// and configuration, a writer may also load, delete, merge, and otherwise
+// commit data, and an admin may also drop, rename, and vacuum pools, delete
+// branches, and grant roles to others.
+type Role string
+
+const (
+	Reader Role = "reader"
+	Writer Role = "writer"
+	Admin  Role = "admin"
+)
+
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case Reader, Writer, Admin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q (must be reader, writer, or admin)", s)
+}
+
+func (r Role) rank() int {
+	switch r {
+	case Reader:
+		return 1
+	case Writer:
+		return 2
+	case Admin:
+		return 3
+	}
+	return 0
+}
+
+// Includes returns true if r confers the permissions of other.
+func (r Role) Includes(other Role) bool {
+	return r.rank() > 0 && r.rank() >= other.rank()
+}
+
+// Subject prefixes.  A subject is either a user ID from the user ID claim
+// of an access token or a group, which is a value of the roles claim.
+const (
+	UserPrefix  = "user:"
+	GroupPrefix = "group:"
+)
+
+func User(id string) string {
+	return UserPrefix + id
+}
+
+func Group(name string) string {
+	return GroupPrefix + name
+}
+
+// ParseSubject checks that s is a user or group subject.
+func ParseSubject(s string) (string, error) {
+	for _, prefix := range []string{UserPrefix, GroupPrefix} {
+		if name, ok := strings.CutPrefix(s, prefix); ok && name != "" {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("invalid subject %q (must be user:<id> or group:<name>)", s)
+}
+
+// A Grant confers Role on Subject.  A grant with a nil Pool applies to the
+// entire lake.  Otherwise, it applies to the pool with ID Pool or, if Branch
+// is not empty, only to that branch of the pool.
+type Grant struct {
+	Ts      nano.Ts     `zed:"ts"`
+	Subject string      `zed:"subject"`
+	Pool    ksuid.KSUID `zed:"pool"`
+	Branch  string      `zed:"branch"`
+	Role    Role        `zed:"role"`
+}
+
+func NewGrant(subject string, pool ksuid.KSUID, branch string, role Role) *Grant {
+	return &Grant{
+		Ts:      nano.Now(),
+		Subject: subject,
+		Pool:    pool,
+		Branch:  branch,
+		Role:    role,
+	}
+}
+
+// Key returns the journal key of the grant.  A subject holds at most one
+// role for each lake, pool, or branch scope.
+func (g *Grant) Key() string {
+	return Key(g.Subject, g.Pool, g.Branch)
+}
+
+func Key(subject string, pool ksuid.KSUID, branch string) string {
+	return fmt.Sprintf("%s %s %s", subject, pool, branch)
+}
+
+// Covers returns true if g applies to the branch of pool.  An empty branch
+// denotes the entire pool and a nil pool the entire lake.
+func (g *Grant) Covers(pool ksuid.KSUID, branch string) bool {
+	if g.Pool == ksuid.Nil {
+		return true
+	}
+	return g.Pool == pool && (g.Branch == "" || g.Branch == branch)
+}
+
+// Authorized returns true if one of the grants confers role on one of the
+// subjects for the branch of pool.
+func Authorized(grants []Grant, subjects []string, pool ksuid.KSUID, branch string, role Role) bool {
+	for _, g := range grants {
+		if !g.Role.Includes(role) || !g.Covers(pool, branch) {
+			continue
+		}
+		for _, s := range subjects {
+			if g.Subject == s {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package grants
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleIncludes(t *testing.T) {
+	assert.True(t, Admin.Includes(Reader))
+	assert.True(t, Writer.Includes(Writer))
+	assert.False(t, Reader.Includes(Writer))
+	assert.False(t, Role("bogus").Includes(Role("bogus")))
+}
+
+func TestAuthorized(t *testing.T) {
+	pool, other := ksuid.New(), ksuid.New()
+	list := []Grant{
+		*NewGrant(User("alice"), ksuid.Nil, "", Reader),
+		*NewGrant(User("bob"), pool, "", Writer),
+		*NewGrant(Group("ops"), pool, "dev", Admin),
+	}
+	alice := []string{User("alice")}
+	assert.True(t, Authorized(list, alice, ksuid.Nil, "", Reader))
+	assert.True(t, Authorized(list, alice, other, "main", Reader))
+	assert.False(t, Authorized(list, alice, pool, "", Writer))
+
+	bob := []string{User("bob")}
+	assert.True(t, Authorized(list, bob, pool, "main", Writer))
+	assert.False(t, Authorized(list, bob, other, "", Reader))
+	assert.False(t, Authorized(list, bob, ksuid.Nil, "", Reader))
+
+	carol := []string{User("carol"), Group("ops")}
+	assert.True(t, Authorized(list, carol, pool, "dev", Admin))
+	assert.False(t, Authorized(list, carol, pool, "main", Reader))
+	assert.False(t, Authorized(list, carol, pool, "", Reader))
+}
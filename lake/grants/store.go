@@ -0,0 +1,115 @@
+package grants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("grant not found")
+
+type Store struct {
+	engine storage.Engine
+	logger *zap.Logger
+	path   *storage.URI
+
+	mu    sync.Mutex
+	store *journal.Store
+}
+
+// OpenStore returns the store of the grant journal at path, which is opened
+// on first use.  A lake has no grant journal until the first role is
+// granted so it is created then.
+func OpenStore(engine storage.Engine, logger *zap.Logger, path *storage.URI) *Store {
+	return &Store{engine: engine, logger: logger, path: path}
+}
+
+// journal returns the grant journal, which is nil if it does not exist and
+// create is false.
+func (s *Store) journal(ctx context.Context, create bool) (*journal.Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != nil {
+		return s.store, nil
+	}
+	ok, err := s.engine.Exists(ctx, s.path.JoinPath("HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok:
+		s.store, err = journal.OpenStore(ctx, s.engine, s.logger, s.path, Grant{})
+	case create:
+		s.store, err = journal.CreateStore(ctx, s.engine, s.logger, s.path, Grant{})
+	}
+	return s.store, err
+}
+
+// All returns the grants ordered by subject and scope.
+func (s *Store) All(ctx context.Context) ([]Grant, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil || store == nil {
+		return nil, err
+	}
+	entries, err := store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Grant, 0, len(entries))
+	for _, entry := range entries {
+		grant, ok := entry.(*Grant)
+		if !ok {
+			return nil, errors.New("corrupt grant journal")
+		}
+		list = append(list, *grant)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key() < list[j].Key()
+	})
+	return list, nil
+}
+
+// Add stores grant, replacing the role previously granted to its subject
+// for the same scope, if any.
+func (s *Store) Add(ctx context.Context, grant *Grant) error {
+	store, err := s.journal(ctx, true)
+	if err != nil {
+		return err
+	}
+	for {
+		err := store.Insert(ctx, grant)
+		if !errors.Is(err, journal.ErrKeyExists) {
+			return err
+		}
+		err = store.Update(ctx, grant, nil)
+		if !errors.Is(err, journal.ErrNoSuchKey) {
+			return err
+		}
+		// The grant was revoked between the insert and update,
+		// so try the insert again.
+	}
+}
+
+func (s *Store) Remove(ctx context.Context, subject string, pool ksuid.KSUID, branch string) error {
+	store, err := s.journal(ctx, false)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("%s: %w", subject, ErrNotFound)
+	}
+	if err := store.Delete(ctx, Key(subject, pool, branch), nil); err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return fmt.Errorf("%s: %w", subject, ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/zngio"
+	"github.com/brimdata/zed/zson"
+	"go.uber.org/zap"
+)
+
+// CheckpointInterval is the number of journal entries between the
+// checkpoints written as a store is loaded.  A checkpoint is an immutable
+// copy of the store's table at a journal position.  Unlike the snapshot,
+// which is rewritten in place, a checkpoint is never modified so a store
+// can always start loading from a nearby position in the journal.
+const CheckpointInterval = 1000
+
+var ErrCheckpointMismatch = errors.New("checkpoint does not match journal")
+
+const checkpointPrefix = "checkpoint."
+
+func (s *Store) checkpointURI(id ID) *storage.URI {
+	return s.journal.path.JoinPath(fmt.Sprintf("%s%d.%s", checkpointPrefix, id, ext))
+}
+
+// Checkpoints returns the journal positions of the store's checkpoints in
+// ascending order.
+func (s *Store) Checkpoints(ctx context.Context) ([]ID, error) {
+	infos, err := s.journal.engine.List(ctx, s.journal.path)
+	if err != nil {
+		return nil, err
+	}
+	var ids []ID
+	for _, info := range infos {
+		name, ok := strings.CutPrefix(info.Name, checkpointPrefix)
+		if !ok {
+			continue
+		}
+		name, ok = strings.CutSuffix(name, "."+ext)
+		if !ok {
+			continue
+		}
+		if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+			ids = append(ids, ID(id))
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// latestCheckpoint returns the table of the latest readable checkpoint at
+// or before head.  If there is none, it returns an empty table at Nil.
+func (s *Store) latestCheckpoint(ctx context.Context, head ID) (ID, map[string]Entry, error) {
+	ids, err := s.Checkpoints(ctx)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Nil, nil, err
+	}
+	for k := len(ids) - 1; k >= 0; k-- {
+		if ids[k] > head {
+			continue
+		}
+		at, table, err := s.readTable(ctx, s.checkpointURI(ids[k]))
+		if err == nil && at == ids[k] {
+			return at, table, nil
+		}
+		s.logger.Error("Skipping checkpoint", zap.Uint64("checkpoint", uint64(ids[k])), zap.Error(err))
+	}
+	return Nil, make(map[string]Entry), nil
+}
+
+func (s *Store) putCheckpoint(ctx context.Context, at ID, table map[string]Entry) error {
+	// The checkpoint is written in a single Put so a partially written
+	// checkpoint is never visible.
+	var buf bytes.Buffer
+	zw := zngio.NewWriter(zio.NopCloser(&buf))
+	if err := writeTable(zw, at, table); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return storage.Put(ctx, s.journal.engine, s.checkpointURI(at), &buf)
+}
+
+// Checkpoint writes a checkpoint at the head of the journal if there isn't
+// one already and returns its position.  It returns Nil for an empty journal.
+func (s *Store) Checkpoint(ctx context.Context) (ID, error) {
+	if err := s.load(ctx); err != nil {
+		return Nil, err
+	}
+	s.mu.RLock()
+	at, table := s.at, s.table
+	s.mu.RUnlock()
+	if at == Nil {
+		return Nil, nil
+	}
+	ids, err := s.Checkpoints(ctx)
+	if err != nil {
+		return Nil, err
+	}
+	for _, id := range ids {
+		if id == at {
+			return at, nil
+		}
+	}
+	return at, s.putCheckpoint(ctx, at, table)
+}
+
+// VerifyCheckpoints compares each checkpoint with the table obtained by
+// replaying the journal up to the checkpoint's position and returns the
+// positions of the verified checkpoints.  A checkpoint that does not match
+// may be safely deleted.
+func (s *Store) VerifyCheckpoints(ctx context.Context) ([]ID, error) {
+	ids, err := s.Checkpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table := make(map[string]Entry)
+	var at ID
+	for _, id := range ids {
+		if err := s.replay(ctx, table, id, at); err != nil {
+			return nil, err
+		}
+		at = id
+		checkpointAt, checkpoint, err := s.readTable(ctx, s.checkpointURI(id))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.checkpointURI(id), err)
+		}
+		if checkpointAt != id || !equalTables(table, checkpoint) {
+			return nil, fmt.Errorf("%s: %w", s.checkpointURI(id), ErrCheckpointMismatch)
+		}
+	}
+	return ids, nil
+}
+
+func equalTables(a, b map[string]Entry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, entry := range a {
+		other, ok := b[key]
+		if !ok {
+			return false
+		}
+		x, err := zson.Marshal(entry)
+		if err != nil {
+			return false
+		}
+		y, err := zson.Marshal(other)
+		if err != nil || x != y {
+			return false
+		}
+	}
+	return true
+}
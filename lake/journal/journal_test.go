@@ -8,6 +8,7 @@ import (
 
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 func newQueue(ctx context.Context, t *testing.T) *Queue {
@@ -46,3 +47,49 @@ func TestJournalConcurrent(t *testing.T) {
 		require.NoError(t, <-ch)
 	}
 }
+
+type testEntry struct {
+	Name  string `zed:"name"`
+	Value int    `zed:"value"`
+}
+
+func (e *testEntry) Key() string {
+	return e.Name
+}
+
+func TestStoreCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	path := storage.MustParseURI(t.TempDir())
+	engine := storage.NewLocalEngine()
+	logger := zap.NewNop()
+	store, err := CreateStore(ctx, engine, logger, path, testEntry{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.Insert(ctx, &testEntry{Name: fmt.Sprint(i), Value: i}))
+	}
+	at, err := store.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ID(3), at)
+	require.NoError(t, store.Update(ctx, &testEntry{Name: "0", Value: 10}, nil))
+	require.NoError(t, store.Delete(ctx, "1", nil))
+	at, err = store.Checkpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, ID(5), at)
+	ids, err := store.VerifyCheckpoints(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []ID{3, 5}, ids)
+
+	at, table, err := store.latestCheckpoint(ctx, 4)
+	require.NoError(t, err)
+	require.Equal(t, ID(3), at)
+	require.Len(t, table, 3)
+
+	// Overwrite the latest checkpoint with a table that doesn't match
+	// the journal.
+	require.NoError(t, store.putCheckpoint(ctx, 5, map[string]Entry{
+		"0": &testEntry{Name: "0", Value: 0},
+		"2": &testEntry{Name: "2", Value: 2},
+	}))
+	_, err = store.VerifyCheckpoints(ctx)
+	require.ErrorIs(t, err, ErrCheckpointMismatch)
+}
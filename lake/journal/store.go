@@ -11,6 +11,7 @@ import (
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zngbytes"
 	"github.com/brimdata/zed/zson"
@@ -99,6 +100,48 @@ func (s *Store) load(ctx context.Context) error {
 	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		s.logger.Error("Loading snapshot", zap.Error(err))
 	}
+	if err != nil || at > head {
+		at, table = Nil, make(map[string]Entry)
+		// The snapshot is missing, corrupt, or from the future of a
+		// rewritten journal, so start from the latest checkpoint if
+		// the journal is long enough to have one.
+		if head >= CheckpointInterval {
+			at, table, err = s.latestCheckpoint(ctx, head)
+			if err != nil {
+				s.logger.Error("Loading checkpoint", zap.Error(err))
+				at, table = Nil, make(map[string]Entry)
+			}
+		}
+	}
+	if err := s.replay(ctx, table, head, at); err != nil {
+		return err
+	}
+	now := time.Now()
+	s.mu.Lock()
+	s.table = table
+	s.at = head
+	s.loadTime = now
+	s.mu.Unlock()
+	// Reduce the amount of times we write snapshots to disk by only writing when there are
+	// more than 10 new entries since the last snapshot.
+	if head-at > 10 {
+		if err := s.putSnapshot(ctx, head, table); err != nil {
+			s.logger.Error("Storing snapshot", zap.Error(err))
+		}
+	}
+	// Write a checkpoint each time the head crosses a multiple of
+	// CheckpointInterval.
+	if head/CheckpointInterval > at/CheckpointInterval {
+		if err := s.putCheckpoint(ctx, head, table); err != nil {
+			s.logger.Error("Storing checkpoint", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// replay plays the journal entries after at up to and including head
+// into table.
+func (s *Store) replay(ctx context.Context, table map[string]Entry, head, at ID) error {
 	r, err := s.journal.OpenAsZNG(ctx, zed.NewContext(), head, at)
 	if err != nil {
 		return err
@@ -106,25 +149,9 @@ func (s *Store) load(ctx context.Context) error {
 	defer r.Close()
 	for {
 		val, err := r.Read()
-		if err != nil {
+		if val == nil || err != nil {
 			return err
 		}
-		if val == nil {
-			now := time.Now()
-			s.mu.Lock()
-			s.table = table
-			s.at = head
-			s.loadTime = now
-			s.mu.Unlock()
-			// Reduce the amount of times we write snapshots to disk by only writing when there are
-			// more than 10 new entries since the last snapshot.
-			if head-at > 10 {
-				if err := s.putSnapshot(ctx, head, table); err != nil {
-					s.logger.Error("Storing snapshot", zap.Error(err))
-				}
-			}
-			return nil
-		}
 		var e Entry
 		if err := s.unmarshaler.Unmarshal(val, &e); err != nil {
 			return err
@@ -147,8 +174,12 @@ func (s *Store) load(ctx context.Context) error {
 }
 
 func (s *Store) getSnapshot(ctx context.Context) (ID, map[string]Entry, error) {
+	return s.readTable(ctx, s.snapshotURI())
+}
+
+func (s *Store) readTable(ctx context.Context, u *storage.URI) (ID, map[string]Entry, error) {
 	table := make(map[string]Entry)
-	r, err := s.journal.engine.Get(ctx, s.snapshotURI())
+	r, err := s.journal.engine.Get(ctx, u)
 	if err != nil {
 		return Nil, table, err
 	}
@@ -184,7 +215,11 @@ func (s *Store) putSnapshot(ctx context.Context, at ID, table map[string]Entry)
 	}
 	zw := zngio.NewWriter(w)
 	defer zw.Close()
-	if err := zw.Write(zed.NewUint64(uint64(at))); err != nil {
+	return writeTable(zw, at, table)
+}
+
+func writeTable(w zio.Writer, at ID, table map[string]Entry) error {
+	if err := w.Write(zed.NewUint64(uint64(at))); err != nil {
 		return err
 	}
 	marshaler := zson.NewZNGMarshaler()
@@ -194,7 +229,7 @@ func (s *Store) putSnapshot(ctx context.Context, at ID, table map[string]Entry)
 		if err != nil {
 			return err
 		}
-		if err := zw.Write(val); err != nil {
+		if err := w.Write(val); err != nil {
 			return err
 		}
 	}
@@ -357,6 +392,7 @@ func (s *Store) commit(ctx context.Context, fn func() error, entries ...Entry) e
 		// Force a reload after a change.
 		s.mu.Lock()
 		s.at = Nil
+		s.loadTime = time.Time{}
 		s.mu.Unlock()
 		return nil
 	}
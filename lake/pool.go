@@ -12,6 +12,7 @@ import (
 	"github.com/brimdata/zed/lake/commits"
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/tags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/expr"
@@ -28,6 +29,7 @@ const (
 	IndexTag    = "index"
 	BranchesTag = "branches"
 	CommitsTag  = "commits"
+	TagsTag     = "tags"
 )
 
 type Pool struct {
@@ -38,6 +40,7 @@ type Pool struct {
 	IndexPath *storage.URI
 	branches  *branches.Store
 	commits   *commits.Store
+	tags      *tags.Store
 }
 
 func CreatePool(ctx context.Context, engine storage.Engine, logger *zap.Logger, root *storage.URI, config *pools.Config) error {
@@ -50,6 +53,11 @@ func CreatePool(ctx context.Context, engine storage.Engine, logger *zap.Logger,
 	if err != nil {
 		return err
 	}
+	// create the tags journal store
+	_, err = tags.CreateStore(ctx, engine, logger, poolPath.JoinPath(TagsTag))
+	if err != nil {
+		return err
+	}
 	// create the main branch in the branches journal store.  The parent
 	// commit object of the initial main branch is ksuid.Nil.
 	_, err = CreateBranch(ctx, engine, logger, root, config, "main", ksuid.Nil)
@@ -66,6 +74,12 @@ func CreateBranch(ctx context.Context, engine storage.Engine, logger *zap.Logger
 	if _, err := store.LookupByName(ctx, name); err == nil {
 		return nil, fmt.Errorf("%s/%s: %w", poolConfig.Name, name, branches.ErrExists)
 	}
+	// Branch and tag names share a namespace so that a revision name
+	// is unambiguous.
+	tagStore := tags.OpenStore(engine, logger, poolPath.JoinPath(TagsTag))
+	if _, err := tagStore.LookupByName(ctx, name); err == nil {
+		return nil, fmt.Errorf("%s/%s: %w", poolConfig.Name, name, tags.ErrExists)
+	}
 	branchConfig := branches.NewConfig(name, parent)
 	if err := store.Add(ctx, branchConfig); err != nil {
 		return nil, err
@@ -93,6 +107,7 @@ func OpenPool(ctx context.Context, engine storage.Engine, logger *zap.Logger, ro
 		IndexPath: IndexPath(path),
 		branches:  branches,
 		commits:   commits,
+		tags:      tags.OpenStore(engine, logger, path.JoinPath(TagsTag)),
 	}, nil
 }
 
@@ -144,18 +159,64 @@ func (p *Pool) OpenBranchByName(ctx context.Context, name string) (*Branch, erro
 	return p.openBranch(ctx, branchRef)
 }
 
+func (p *Pool) ListTags(ctx context.Context) ([]tags.Config, error) {
+	return p.tags.All(ctx)
+}
+
+func (p *Pool) LookupTagByName(ctx context.Context, name string) (*tags.Config, error) {
+	return p.tags.LookupByName(ctx, name)
+}
+
+func (p *Pool) createTag(ctx context.Context, name string, commit ksuid.KSUID) (*tags.Config, error) {
+	if commit == ksuid.Nil {
+		return nil, fmt.Errorf("%s/%s: cannot tag an empty branch", p.Name, name)
+	}
+	if _, err := p.commits.Get(ctx, commit); err != nil {
+		return nil, err
+	}
+	if _, err := p.LookupBranchByName(ctx, name); err == nil {
+		return nil, fmt.Errorf("%s/%s: %w", p.Name, name, branches.ErrExists)
+	}
+	config := tags.NewConfig(name, commit)
+	if err := p.tags.Add(ctx, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (p *Pool) removeTag(ctx context.Context, name string) error {
+	return p.tags.Remove(ctx, name)
+}
+
 // ResolveRevision returns the commit id for revision. revision can be either a
-// commit ID in string form or a branch name.
+// commit ID in string form, a branch name, or a tag name.
 func (p *Pool) ResolveRevision(ctx context.Context, revision string) (ksuid.KSUID, error) {
 	id, err := lakeparse.ParseID(revision)
 	if err != nil {
-		branch, err := p.LookupBranchByName(ctx, revision)
-		if err != nil {
+		return p.resolveName(ctx, revision)
+	}
+	return id, nil
+}
+
+// resolveName returns the commit of the branch or tag called name.
+func (p *Pool) resolveName(ctx context.Context, name string) (ksuid.KSUID, error) {
+	branch, err := p.LookupBranchByName(ctx, name)
+	if err == nil {
+		return branch.Commit, nil
+	}
+	if !errors.Is(err, branches.ErrNotFound) {
+		return ksuid.Nil, err
+	}
+	tag, tagErr := p.LookupTagByName(ctx, name)
+	if tagErr != nil {
+		if errors.Is(tagErr, tags.ErrNotFound) {
+			// Report the missing branch as most revisions are
+			// branches.
 			return ksuid.Nil, err
 		}
-		id = branch.Commit
+		return ksuid.Nil, tagErr
 	}
-	return id, nil
+	return tag.Commit, nil
 }
 
 func (p *Pool) BatchifyBranches(ctx context.Context, zctx *zed.Context, recs []zed.Value, m *zson.MarshalZNGContext, f expr.Evaluator) ([]zed.Value, error) {
@@ -190,6 +251,53 @@ type BranchTip struct {
 	Commit ksuid.KSUID
 }
 
+type TagTip struct {
+	Name   string
+	Commit ksuid.KSUID
+}
+
+func (p *Pool) BatchifyTags(ctx context.Context, zctx *zed.Context, recs []zed.Value, m *zson.MarshalZNGContext, f expr.Evaluator) ([]zed.Value, error) {
+	tags, err := p.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ectx expr.ResetContext
+	for _, tagRef := range tags {
+		meta := TagMeta{p.Config, tagRef}
+		rec, err := m.Marshal(&meta)
+		if err != nil {
+			return nil, err
+		}
+		if filter(zctx, ectx.Reset(), rec, f) {
+			recs = append(recs, *rec)
+		}
+	}
+	return recs, nil
+}
+
+// BatchifyTagTips returns the tags of the pool in the form used to label
+// commits in the pool log.
+func (p *Pool) BatchifyTagTips(ctx context.Context, zctx *zed.Context, f expr.Evaluator) ([]zed.Value, error) {
+	tags, err := p.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := zson.NewZNGMarshalerWithContext(zctx)
+	m.Decorate(zson.StylePackage)
+	recs := make([]zed.Value, 0, len(tags))
+	var ectx expr.ResetContext
+	for _, tagRef := range tags {
+		rec, err := m.Marshal(&TagTip{tagRef.Name, tagRef.Commit})
+		if err != nil {
+			return nil, err
+		}
+		if filter(zctx, ectx.Reset(), rec, f) {
+			recs = append(recs, *rec)
+		}
+	}
+	return recs, nil
+}
+
 func (p *Pool) BatchifyBranchTips(ctx context.Context, zctx *zed.Context, f expr.Evaluator) ([]zed.Value, error) {
 	branches, err := p.ListBranches(ctx)
 	if err != nil {
@@ -216,12 +324,23 @@ func (p *Pool) ObjectExists(ctx context.Context, id ksuid.KSUID) (bool, error) {
 	return p.engine.Exists(ctx, data.SequenceURI(p.DataPath, id))
 }
 
+// Vacuum deletes the data objects in the history of commit that are
+// referenced neither by commit nor by a tag of the pool, since a tag is
+// immutable and must remain queryable.
 func (p *Pool) Vacuum(ctx context.Context, commit ksuid.KSUID, dryrun bool) ([]ksuid.KSUID, error) {
+	tags, err := p.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keep := make([]ksuid.KSUID, 0, len(tags))
+	for _, tag := range tags {
+		keep = append(keep, tag.Commit)
+	}
 	group, ctx := errgroup.WithContext(ctx)
 	ch := make(chan *data.Object)
 	group.Go(func() error {
 		defer close(ch)
-		return p.commits.Vacuumable(ctx, commit, ch)
+		return p.commits.Vacuumable(ctx, commit, keep, ch)
 	})
 	var vacuumed []ksuid.KSUID
 	var mu sync.Mutex
@@ -268,6 +387,32 @@ func (p *Pool) Vacuum(ctx context.Context, commit ksuid.KSUID, dryrun bool) ([]k
 	return vacuumed, nil
 }
 
+// Checkpoint writes checkpoints of the pool's branch and tag journals and
+// of the commit history leading to commit, one every interval commits, and
+// returns the commits for which a checkpoint was written.
+func (p *Pool) Checkpoint(ctx context.Context, commit ksuid.KSUID, interval int) ([]ksuid.KSUID, error) {
+	if _, err := p.branches.Checkpoint(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := p.tags.Checkpoint(ctx); err != nil {
+		return nil, err
+	}
+	return p.commits.Checkpoint(ctx, commit, interval)
+}
+
+// VerifyCheckpoints verifies the checkpoints of the pool's branch and tag
+// journals and of the commit history leading to commit and returns the
+// commits whose checkpoints were verified.
+func (p *Pool) VerifyCheckpoints(ctx context.Context, commit ksuid.KSUID) ([]ksuid.KSUID, error) {
+	if _, err := p.branches.VerifyCheckpoints(ctx); err != nil {
+		return nil, err
+	}
+	if _, err := p.tags.VerifyCheckpoints(ctx); err != nil {
+		return nil, err
+	}
+	return p.commits.VerifyCheckpoints(ctx, commit)
+}
+
 func (p *Pool) Main(ctx context.Context) (BranchMeta, error) {
 	branch, err := p.OpenBranchByName(ctx, "main")
 	if err != nil {
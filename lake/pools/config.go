@@ -7,21 +7,24 @@ import (
 	"github.com/brimdata/zed/pkg/field"
 	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio/zngio"
 	"github.com/segmentio/ksuid"
 )
 
 type Config struct {
-	Ts         nano.Ts       `zed:"ts"`
-	Name       string        `zed:"name"`
-	ID         ksuid.KSUID   `zed:"id"`
-	SortKey    order.SortKey `zed:"layout"`
-	SeekStride int           `zed:"seek_stride"`
-	Threshold  int64         `zed:"threshold"`
+	Ts          nano.Ts       `zed:"ts"`
+	Name        string        `zed:"name"`
+	ID          ksuid.KSUID   `zed:"id"`
+	SortKey     order.SortKey `zed:"layout"`
+	SeekStride  int           `zed:"seek_stride"`
+	Threshold   int64         `zed:"threshold"`
+	Compression string        `zed:"compression"`
+	Schema      *Schema       `zed:"schema"`
 }
 
 var _ journal.Entry = (*Config)(nil)
 
-func NewConfig(name string, sortKey order.SortKey, thresh int64, seekStride int) *Config {
+func NewConfig(name string, sortKey order.SortKey, thresh int64, seekStride int, compression string, schema *Schema) *Config {
 	if sortKey.IsNil() {
 		sortKey = order.NewSortKey(order.Desc, field.DottedList("ts"))
 	}
@@ -32,15 +35,28 @@ func NewConfig(name string, sortKey order.SortKey, thresh int64, seekStride int)
 		seekStride = data.DefaultSeekStride
 	}
 	return &Config{
-		Ts:         nano.Now(),
-		Name:       name,
-		ID:         ksuid.New(),
-		SortKey:    sortKey,
-		SeekStride: seekStride,
-		Threshold:  thresh,
+		Ts:          nano.Now(),
+		Name:        name,
+		ID:          ksuid.New(),
+		SortKey:     sortKey,
+		SeekStride:  seekStride,
+		Threshold:   thresh,
+		Compression: compression,
+		Schema:      schema,
 	}
 }
 
+// CompressionFormat returns the compression format of the pool's data
+// objects.
+func (p *Config) CompressionFormat() zngio.CompressionFormat {
+	var format zngio.CompressionFormat
+	if p.Compression != "" {
+		// Compression was validated when the pool was created.
+		format.Set(p.Compression)
+	}
+	return format
+}
+
 func (p *Config) Key() string {
 	return p.Name
 }
@@ -0,0 +1,80 @@
+package pools
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+)
+
+// Modes of a Schema.
+const (
+	SchemaReject     = "reject"
+	SchemaCoerce     = "coerce"
+	SchemaQuarantine = "quarantine"
+)
+
+const DefaultQuarantineBranch = "quarantine"
+
+// A Schema constrains the values loaded into a pool to those of Type, a
+// ZSON type.  The Mode determines what happens to a value of another type.
+// In reject mode, the value is dropped.  In coerce mode, the value is shaped
+// to Type or, if Shaper is set, every value is passed through the Shaper
+// script, and a value that still does not conform is dropped.  In
+// quarantine mode, the value is loaded into the Quarantine branch instead.
+type Schema struct {
+	Type       string `json:"type" zed:"type"`
+	Mode       string `json:"mode" zed:"mode"`
+	Shaper     string `json:"shaper" zed:"shaper"`
+	Quarantine string `json:"quarantine" zed:"quarantine"`
+}
+
+// NewSchema returns a validated Schema with the defaults filled in.
+func NewSchema(typ, mode, shaper, quarantine string) (*Schema, error) {
+	s := &Schema{
+		Type:       typ,
+		Mode:       mode,
+		Shaper:     shaper,
+		Quarantine: quarantine,
+	}
+	if s.Mode == "" {
+		s.Mode = SchemaReject
+	}
+	if s.Mode == SchemaQuarantine && s.Quarantine == "" {
+		s.Quarantine = DefaultQuarantineBranch
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) Validate() error {
+	if _, err := s.LookupType(zed.NewContext()); err != nil {
+		return err
+	}
+	switch s.Mode {
+	case SchemaReject, SchemaCoerce, SchemaQuarantine:
+	default:
+		return fmt.Errorf("unknown schema mode %q", s.Mode)
+	}
+	if s.Shaper != "" && s.Mode != SchemaCoerce {
+		return fmt.Errorf("schema shaper requires %q mode", SchemaCoerce)
+	}
+	if s.Quarantine != "" && s.Mode != SchemaQuarantine {
+		return fmt.Errorf("schema quarantine branch requires %q mode", SchemaQuarantine)
+	}
+	return nil
+}
+
+// LookupType returns the type of the schema in zctx.
+func (s *Schema) LookupType(zctx *zed.Context) (zed.Type, error) {
+	if s.Type == "" {
+		return nil, fmt.Errorf("schema type required")
+	}
+	typ, err := zson.ParseType(zctx, s.Type)
+	if err != nil {
+		return nil, fmt.Errorf("schema type %q: %w", s.Type, err)
+	}
+	return typ, nil
+}
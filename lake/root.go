@@ -10,13 +10,17 @@ import (
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/lake/audit"
 	"github.com/brimdata/zed/lake/branches"
 	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/lake/grants"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/tags"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/vcache"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio/zngio"
 	"github.com/brimdata/zed/zngbytes"
@@ -29,6 +33,9 @@ import (
 const (
 	Version         = 3
 	PoolsTag        = "pools"
+	AuditTag        = "audit"
+	APIKeysTag      = "apikeys"
+	GrantsTag       = "grants"
 	IndexRulesTag   = "index_rules"
 	LakeMagicFile   = "lake.zng"
 	LakeMagicString = "ZED LAKE"
@@ -46,9 +53,12 @@ type Root struct {
 	logger *zap.Logger
 	path   *storage.URI
 
-	poolCache  *lru.ARCCache[ksuid.KSUID, *Pool]
-	pools      *pools.Store
-	indexRules *index.Store
+	poolCache   *lru.ARCCache[ksuid.KSUID, *Pool]
+	pools       *pools.Store
+	audit       *audit.Log
+	grants      *grants.Store
+	indexRules  *index.Store
+	vectorCache *vcache.Cache
 }
 
 type LakeMagic struct {
@@ -62,10 +72,13 @@ func newRoot(engine storage.Engine, logger *zap.Logger, path *storage.URI) *Root
 		panic(err)
 	}
 	return &Root{
-		engine:    engine,
-		logger:    logger,
-		path:      path,
-		poolCache: poolCache,
+		engine:      engine,
+		logger:      logger,
+		path:        path,
+		poolCache:   poolCache,
+		audit:       audit.OpenLog(engine, path.JoinPath(AuditTag)),
+		grants:      grants.OpenStore(engine, logger, path.JoinPath(GrantsTag)),
+		vectorCache: vcache.NewCache(engine),
 	}
 }
 
@@ -238,6 +251,36 @@ type BranchMeta struct {
 	Branch branches.Config `zed:"branch"`
 }
 
+func (r *Root) BatchifyTags(ctx context.Context, zctx *zed.Context, f expr.Evaluator) ([]zed.Value, error) {
+	m := zson.NewZNGMarshalerWithContext(zctx)
+	m.Decorate(zson.StylePackage)
+	poolRefs, err := r.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var vals []zed.Value
+	for k := range poolRefs {
+		pool, err := r.openPool(ctx, &poolRefs[k])
+		if err != nil {
+			// As in BatchifyBranches, the pool may have been deleted.
+			if errors.Is(err, pools.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		vals, err = pool.BatchifyTags(ctx, zctx, vals, m, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vals, nil
+}
+
+type TagMeta struct {
+	Pool pools.Config `zed:"pool"`
+	Tag  tags.Config  `zed:"tag"`
+}
+
 func (r *Root) ListPools(ctx context.Context) ([]pools.Config, error) {
 	return r.pools.All(ctx)
 }
@@ -258,16 +301,14 @@ func (r *Root) PoolID(ctx context.Context, poolName string) (ksuid.KSUID, error)
 	return poolID, nil
 }
 
-func (r *Root) CommitObject(ctx context.Context, poolID ksuid.KSUID, branchName string) (ksuid.KSUID, error) {
+// CommitObject returns the commit of the branch or tag of the pool called
+// name.
+func (r *Root) CommitObject(ctx context.Context, poolID ksuid.KSUID, name string) (ksuid.KSUID, error) {
 	pool, err := r.OpenPool(ctx, poolID)
 	if err != nil {
 		return ksuid.Nil, err
 	}
-	branchRef, err := pool.LookupBranchByName(ctx, branchName)
-	if err != nil {
-		return ksuid.Nil, err
-	}
-	return branchRef.Commit, nil
+	return pool.resolveName(ctx, name)
 }
 
 func (r *Root) SortKey(ctx context.Context, src dag.Op) order.SortKey {
@@ -294,6 +335,18 @@ func (r *Root) SortKey(ctx context.Context, src dag.Op) order.SortKey {
 	return order.Nil
 }
 
+// VectorCache returns the cache of vector objects shared by all queries of
+// the lake.
+func (r *Root) VectorCache() *vcache.Cache {
+	return r.vectorCache
+}
+
+// SetVectorCache replaces the cache used by vector queries of the lake.  It
+// must be called before any queries are run.
+func (r *Root) SetVectorCache(cache *vcache.Cache) {
+	r.vectorCache = cache
+}
+
 func (r *Root) OpenPool(ctx context.Context, id ksuid.KSUID) (*Pool, error) {
 	config, err := r.pools.LookupByID(ctx, id)
 	if err != nil {
@@ -323,7 +376,7 @@ func (r *Root) RenamePool(ctx context.Context, id ksuid.KSUID, newName string) e
 	return r.pools.Rename(ctx, id, newName)
 }
 
-func (r *Root) CreatePool(ctx context.Context, name string, sortKey order.SortKey, seekStride int, thresh int64) (*Pool, error) {
+func (r *Root) CreatePool(ctx context.Context, name string, sortKey order.SortKey, seekStride int, thresh int64, compression string, schema *pools.Schema) (*Pool, error) {
 	if name == "HEAD" {
 		return nil, fmt.Errorf("pool cannot be named %q", name)
 	}
@@ -336,10 +389,27 @@ func (r *Root) CreatePool(ctx context.Context, name string, sortKey order.SortKe
 	if len(sortKey.Keys) > 1 {
 		return nil, errors.New("multiple pool keys not supported")
 	}
-	config := pools.NewConfig(name, sortKey, thresh, seekStride)
+	var format zngio.CompressionFormat
+	if compression != "" {
+		if err := format.Set(compression); err != nil {
+			return nil, err
+		}
+	}
+	if schema != nil {
+		if err := schema.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	config := pools.NewConfig(name, sortKey, thresh, seekStride, format.String(), schema)
 	if err := CreatePool(ctx, r.engine, r.logger, r.path, config); err != nil {
 		return nil, err
 	}
+	if schema != nil && schema.Mode == pools.SchemaQuarantine {
+		if _, err := CreateBranch(ctx, r.engine, r.logger, r.path, config, schema.Quarantine, ksuid.Nil); err != nil {
+			RemovePool(ctx, r.engine, r.path, config)
+			return nil, err
+		}
+	}
 	pool, err := r.openPool(ctx, config)
 	if err != nil {
 		RemovePool(ctx, r.engine, r.path, config)
@@ -385,6 +455,91 @@ func (r *Root) RemoveBranch(ctx context.Context, poolID ksuid.KSUID, name string
 	return pool.removeBranch(ctx, name)
 }
 
+// Checkpoint writes a checkpoint of the lake's pool journal.
+func (r *Root) Checkpoint(ctx context.Context) error {
+	_, err := r.pools.Checkpoint(ctx)
+	return err
+}
+
+// VerifyCheckpoints verifies the checkpoints of the lake's pool journal.
+func (r *Root) VerifyCheckpoints(ctx context.Context) error {
+	_, err := r.pools.VerifyCheckpoints(ctx)
+	return err
+}
+
+func (r *Root) CreateTag(ctx context.Context, poolID ksuid.KSUID, name string, commit ksuid.KSUID) (*tags.Config, error) {
+	pool, err := r.OpenPool(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	return pool.createTag(ctx, name, commit)
+}
+
+func (r *Root) RemoveTag(ctx context.Context, poolID ksuid.KSUID, name string) error {
+	pool, err := r.OpenPool(ctx, poolID)
+	if err != nil {
+		return err
+	}
+	return pool.removeTag(ctx, name)
+}
+
+// Audit appends rec to the audit log of the lake.  A failure to append is
+// logged rather than returned since the operation recorded by rec has
+// already completed.
+func (r *Root) Audit(ctx context.Context, rec *audit.Record) {
+	// The record is appended even if the operation's context was canceled.
+	if err := r.audit.Append(context.WithoutCancel(ctx), rec); err != nil {
+		r.logger.Error("Audit log append failed", zap.String("op", rec.Op), zap.Error(err))
+	}
+}
+
+func (r *Root) BatchifyAudit(ctx context.Context, zctx *zed.Context, f expr.Evaluator) ([]zed.Value, error) {
+	recs, err := r.audit.All(ctx, zctx)
+	if err != nil {
+		return nil, err
+	}
+	var ectx expr.ResetContext
+	var vals []zed.Value
+	for k := range recs {
+		if filter(zctx, ectx.Reset(), &recs[k], f) {
+			vals = append(vals, recs[k])
+		}
+	}
+	return vals, nil
+}
+
+func (r *Root) ListGrants(ctx context.Context) ([]grants.Grant, error) {
+	return r.grants.All(ctx)
+}
+
+// Grant confers role on subject for the branch of the pool with ID poolID.
+// An empty branch denotes the entire pool and a nil poolID the entire lake.
+func (r *Root) Grant(ctx context.Context, subject string, poolID ksuid.KSUID, branch string, role grants.Role) (*grants.Grant, error) {
+	if _, err := grants.ParseSubject(subject); err != nil {
+		return nil, err
+	}
+	if _, err := grants.ParseRole(string(role)); err != nil {
+		return nil, err
+	}
+	if poolID == ksuid.Nil && branch != "" {
+		return nil, errors.New("branch grant requires a pool")
+	}
+	if poolID != ksuid.Nil {
+		if _, err := r.pools.LookupByID(ctx, poolID); err != nil {
+			return nil, err
+		}
+	}
+	grant := grants.NewGrant(subject, poolID, branch, role)
+	if err := r.grants.Add(ctx, grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+func (r *Root) Revoke(ctx context.Context, subject string, poolID ksuid.KSUID, branch string) error {
+	return r.grants.Remove(ctx, subject, poolID, branch)
+}
+
 // MergeBranch merges the indicated branch into its parent returning the
 // commit tag of the new commit into the parent branch.
 func (r *Root) MergeBranch(ctx context.Context, poolID ksuid.KSUID, childBranch, parentBranch, author, message string) (ksuid.KSUID, error) {
@@ -0,0 +1,172 @@
+package lake
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/pkg/plural"
+	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zson"
+)
+
+// SchemaReport tallies the values of a load that did not conform to the
+// schema of the pool.  It is recorded in the metadata of the load commit.
+type SchemaReport struct {
+	Rejected    uint64 `zed:"rejected"`
+	Coerced     uint64 `zed:"coerced"`
+	Quarantined uint64 `zed:"quarantined"`
+}
+
+func (s SchemaReport) IsZero() bool {
+	return s == SchemaReport{}
+}
+
+func (s SchemaReport) String() string {
+	var b strings.Builder
+	if s.Rejected > 0 {
+		fmt.Fprintf(&b, "rejected %d value%s not conforming to pool schema\n", s.Rejected, plural.Int(int(s.Rejected), "s"))
+	}
+	if s.Coerced > 0 {
+		fmt.Fprintf(&b, "coerced %d value%s to pool schema\n", s.Coerced, plural.Int(int(s.Coerced), "s"))
+	}
+	if s.Quarantined > 0 {
+		fmt.Fprintf(&b, "quarantined %d value%s not conforming to pool schema\n", s.Quarantined, plural.Int(int(s.Quarantined), "s"))
+	}
+	return b.String()
+}
+
+// schemaReader applies a pool schema to the values read from reader.
+// Values not conforming to the schema are dropped, coerced, or written
+// to quarantine according to the schema mode.
+type schemaReader struct {
+	reader     zio.Reader
+	query      *runtime.Query
+	typ        zed.Type
+	mode       string
+	shaper     *expr.ConstShaper
+	ectx       expr.Context
+	quarantine zio.Writer
+	report     SchemaReport
+}
+
+// newSchemaReader returns a reader applying schema to r.  Non-conforming
+// values are written to quarantine in quarantine mode.
+func newSchemaReader(ctx context.Context, c runtime.Compiler, zctx *zed.Context, schema *pools.Schema, r zio.Reader, quarantine zio.Writer) (*schemaReader, error) {
+	typ, err := schema.LookupType(zctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &schemaReader{
+		reader:     r,
+		typ:        typ,
+		mode:       schema.Mode,
+		ectx:       expr.NewContext(),
+		quarantine: quarantine,
+	}
+	if schema.Mode == pools.SchemaCoerce {
+		if schema.Shaper == "" {
+			s.shaper = expr.NewConstShaper(zctx, &expr.This{}, typ, expr.Cast|expr.Crop|expr.Fill|expr.Order)
+		} else {
+			program, err := c.Parse(schema.Shaper)
+			if err != nil {
+				return nil, fmt.Errorf("schema shaper: %w", err)
+			}
+			q, err := runtime.CompileQuery(ctx, zctx, c, program, []zio.Reader{r})
+			if err != nil {
+				return nil, fmt.Errorf("schema shaper: %w", err)
+			}
+			s.query = q
+			s.reader = q.AsReader()
+		}
+	}
+	return s, nil
+}
+
+func (s *schemaReader) Read() (*zed.Value, error) {
+	for {
+		val, err := s.reader.Read()
+		if val == nil || err != nil {
+			return val, err
+		}
+		if val.Type == s.typ {
+			return val, nil
+		}
+		switch s.mode {
+		case pools.SchemaCoerce:
+			if s.shaper != nil {
+				if val = s.shaper.Eval(s.ectx, val); val.Type == s.typ {
+					s.report.Coerced++
+					return val, nil
+				}
+			}
+			s.report.Rejected++
+		case pools.SchemaQuarantine:
+			if err := s.quarantine.Write(val); err != nil {
+				return nil, err
+			}
+			s.report.Quarantined++
+		default:
+			s.report.Rejected++
+		}
+	}
+}
+
+func (s *schemaReader) Close() error {
+	if s.query != nil {
+		return s.query.Close()
+	}
+	return nil
+}
+
+// Report returns the tally of non-conforming values, which is complete once
+// Read has returned the last value.  Values coerced by a shaper script are
+// not counted as the script's output can't be matched to its input.
+func (s *schemaReader) Report() SchemaReport {
+	return s.report
+}
+
+// schemaMeta adds report to the commit metadata meta as a field named
+// "schema" of a record.  A null meta becomes a record with only that field
+// and a meta that is not a record becomes field "meta" of the record.
+func schemaMeta(zctx *zed.Context, meta *zed.Value, report SchemaReport) (*zed.Value, error) {
+	rep, err := zson.MarshalZNG(report)
+	if err != nil {
+		return nil, err
+	}
+	// The metadata and report are translated into zctx as they were
+	// created in other contexts.
+	metaType, err := zctx.TranslateType(meta.Type)
+	if err != nil {
+		return nil, err
+	}
+	repType, err := zctx.TranslateType(rep.Type)
+	if err != nil {
+		return nil, err
+	}
+	var fields []zed.Field
+	var b zcode.Builder
+	switch {
+	case meta.IsNull():
+	case zed.TypeRecordOf(metaType) != nil:
+		fields = append(fields, zed.TypeRecordOf(metaType).Fields...)
+		for it := meta.Bytes().Iter(); !it.Done(); {
+			b.Append(it.Next())
+		}
+	default:
+		fields = append(fields, zed.NewField("meta", metaType))
+		b.Append(meta.Bytes())
+	}
+	fields = append(fields, zed.NewField("schema", repType))
+	b.Append(rep.Bytes())
+	typ, err := zctx.LookupTypeRecord(fields)
+	if err != nil {
+		return nil, err
+	}
+	return zed.NewValue(typ, b.Bytes()), nil
+}
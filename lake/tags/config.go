@@ -0,0 +1,26 @@
+package tags
+
+import (
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/segmentio/ksuid"
+)
+
+// A Config names a commit of a pool.  Unlike a branch, a tag never moves
+// so queries of the tag always see the same data.
+type Config struct {
+	Ts     nano.Ts     `zed:"ts"`
+	Name   string      `zed:"name"`
+	Commit ksuid.KSUID `zed:"commit"`
+}
+
+func NewConfig(name string, commit ksuid.KSUID) *Config {
+	return &Config{
+		Ts:     nano.Now(),
+		Name:   name,
+		Commit: commit,
+	}
+}
+
+func (c *Config) Key() string {
+	return c.Name
+}
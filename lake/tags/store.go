@@ -0,0 +1,153 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/brimdata/zed/lake/journal"
+	"github.com/brimdata/zed/pkg/storage"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrExists   = errors.New("tag already exists")
+	ErrNotFound = errors.New("tag not found")
+)
+
+type Store struct {
+	engine storage.Engine
+	logger *zap.Logger
+	path   *storage.URI
+
+	mu    sync.Mutex
+	store *journal.Store
+}
+
+func CreateStore(ctx context.Context, engine storage.Engine, logger *zap.Logger, path *storage.URI) (*Store, error) {
+	store, err := journal.CreateStore(ctx, engine, logger, path, Config{})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{engine: engine, logger: logger, path: path, store: store}, nil
+}
+
+// OpenStore returns the store of the tag journal at path, which is opened
+// on first use.  Pools created before tags were introduced have no tag
+// journal so it is created when the first tag is added.
+func OpenStore(engine storage.Engine, logger *zap.Logger, path *storage.URI) *Store {
+	return &Store{engine: engine, logger: logger, path: path}
+}
+
+// journal returns the tag journal, which is nil if it does not exist and
+// create is false.
+func (s *Store) journal(ctx context.Context, create bool) (*journal.Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store != nil {
+		return s.store, nil
+	}
+	ok, err := s.engine.Exists(ctx, s.path.JoinPath("HEAD"))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok:
+		s.store, err = journal.OpenStore(ctx, s.engine, s.logger, s.path, Config{})
+	case create:
+		s.store, err = journal.CreateStore(ctx, s.engine, s.logger, s.path, Config{})
+	}
+	return s.store, err
+}
+
+func (s *Store) All(ctx context.Context) ([]Config, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil || store == nil {
+		return nil, err
+	}
+	entries, err := store.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Config, 0, len(entries))
+	for _, entry := range entries {
+		tag, ok := entry.(*Config)
+		if !ok {
+			return nil, errors.New("corrupt tag config journal")
+		}
+		list = append(list, *tag)
+	}
+	return list, nil
+}
+
+func (s *Store) LookupByName(ctx context.Context, name string) (*Config, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	if store == nil {
+		return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	entry, err := store.Lookup(ctx, name)
+	if err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+		}
+		return nil, err
+	}
+	tag, ok := entry.(*Config)
+	if !ok {
+		return nil, errors.New("corrupt tag config journal")
+	}
+	return tag, nil
+}
+
+func (s *Store) Add(ctx context.Context, config *Config) error {
+	store, err := s.journal(ctx, true)
+	if err != nil {
+		return err
+	}
+	if err := store.Insert(ctx, config); err != nil {
+		if errors.Is(err, journal.ErrKeyExists) {
+			return fmt.Errorf("%q: %w", config.Name, ErrExists)
+		}
+		return err
+	}
+	return nil
+}
+
+// Remove deletes a tag from the configuration journal.  Tags are immutable
+// so this is the only way to point a tag name at another commit.
+func (s *Store) Remove(ctx context.Context, name string) error {
+	store, err := s.journal(ctx, false)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return fmt.Errorf("%q: %w", name, ErrNotFound)
+	}
+	if err := store.Delete(ctx, name, nil); err != nil {
+		if errors.Is(err, journal.ErrNoSuchKey) {
+			return fmt.Errorf("%q: %w", name, ErrNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Store) Checkpoint(ctx context.Context) (journal.ID, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil || store == nil {
+		return journal.Nil, err
+	}
+	return store.Checkpoint(ctx)
+}
+
+func (s *Store) VerifyCheckpoints(ctx context.Context) ([]journal.ID, error) {
+	store, err := s.journal(ctx, false)
+	if err != nil || store == nil {
+		return nil, err
+	}
+	return store.VerifyCheckpoints(ctx)
+}
@@ -6,3 +6,10 @@ func Slice[S ~[]E, E any](s S, suffix string) string {
 	}
 	return suffix
 }
+
+func Int(n int, suffix string) string {
+	if n == 1 {
+		return ""
+	}
+	return suffix
+}
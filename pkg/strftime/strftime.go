@@ -0,0 +1,499 @@
+// Package strftime formats and parses times using the conversion
+// specifications of C's strftime(3) and strptime(3).
+//
+// The supported conversions are:
+//
+//	%a  abbreviated weekday name (Mon)
+//	%A  full weekday name (Monday)
+//	%b  abbreviated month name (Jan), also %h
+//	%B  full month name (January)
+//	%d  day of the month (01-31)
+//	%D  equivalent to %m/%d/%y
+//	%e  day of the month, space padded ( 1-31)
+//	%f  microseconds (000000-999999)
+//	%F  equivalent to %Y-%m-%d
+//	%H  hour of a 24-hour clock (00-23)
+//	%I  hour of a 12-hour clock (01-12)
+//	%j  day of the year (001-366)
+//	%m  month (01-12)
+//	%M  minute (00-59)
+//	%n  newline
+//	%N  nanoseconds (000000000-999999999)
+//	%p  AM or PM
+//	%R  equivalent to %H:%M
+//	%s  seconds since the Unix epoch
+//	%S  second (00-60)
+//	%t  tab
+//	%T  equivalent to %H:%M:%S
+//	%u  weekday with Monday as 1 (1-7)
+//	%w  weekday with Sunday as 0 (0-6)
+//	%y  year without century (00-99)
+//	%Y  year with century (2006)
+//	%z  time zone offset from UTC (-0700)
+//	%Z  time zone abbreviation (MST)
+//	%%  a literal %
+//
+// When parsing, %f and %N accept from one to nine digits of fractional
+// seconds, %z accepts Z and offsets of the form -07, -0700, and -07:00,
+// %Z accepts only UTC, GMT, and Z, %n and %t match any amount of white space,
+// and white space in the layout matches zero or more white space characters
+// in the input.  The names of weekdays are matched but otherwise ignored.
+package strftime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format returns a textual representation of t formatted according
+// to layout.
+func Format(layout string, t time.Time) (string, error) {
+	var b []byte
+	for k := 0; k < len(layout); k++ {
+		c := layout[k]
+		if c != '%' {
+			b = append(b, c)
+			continue
+		}
+		k++
+		if k == len(layout) {
+			return "", errors.New("layout ends with %")
+		}
+		var err error
+		if b, err = appendConversion(b, layout[k], t); err != nil {
+			return "", err
+		}
+	}
+	return string(b), nil
+}
+
+func appendConversion(b []byte, c byte, t time.Time) ([]byte, error) {
+	switch c {
+	case 'a':
+		return append(b, t.Weekday().String()[:3]...), nil
+	case 'A':
+		return append(b, t.Weekday().String()...), nil
+	case 'b', 'h':
+		return append(b, t.Month().String()[:3]...), nil
+	case 'B':
+		return append(b, t.Month().String()...), nil
+	case 'd':
+		return appendInt(b, t.Day(), 2, '0'), nil
+	case 'D':
+		return appendConversions(b, "mdy", '/', t)
+	case 'e':
+		return appendInt(b, t.Day(), 2, ' '), nil
+	case 'f':
+		return appendInt(b, t.Nanosecond()/1000, 6, '0'), nil
+	case 'F':
+		return appendConversions(b, "Ymd", '-', t)
+	case 'H':
+		return appendInt(b, t.Hour(), 2, '0'), nil
+	case 'I':
+		hour := t.Hour() % 12
+		if hour == 0 {
+			hour = 12
+		}
+		return appendInt(b, hour, 2, '0'), nil
+	case 'j':
+		return appendInt(b, t.YearDay(), 3, '0'), nil
+	case 'm':
+		return appendInt(b, int(t.Month()), 2, '0'), nil
+	case 'M':
+		return appendInt(b, t.Minute(), 2, '0'), nil
+	case 'n':
+		return append(b, '\n'), nil
+	case 'N':
+		return appendInt(b, t.Nanosecond(), 9, '0'), nil
+	case 'p':
+		if t.Hour() < 12 {
+			return append(b, "AM"...), nil
+		}
+		return append(b, "PM"...), nil
+	case 'R':
+		return appendConversions(b, "HM", ':', t)
+	case 's':
+		return strconv.AppendInt(b, t.Unix(), 10), nil
+	case 'S':
+		return appendInt(b, t.Second(), 2, '0'), nil
+	case 't':
+		return append(b, '\t'), nil
+	case 'T':
+		return appendConversions(b, "HMS", ':', t)
+	case 'u':
+		day := int(t.Weekday())
+		if day == 0 {
+			day = 7
+		}
+		return appendInt(b, day, 1, '0'), nil
+	case 'w':
+		return appendInt(b, int(t.Weekday()), 1, '0'), nil
+	case 'y':
+		return appendInt(b, t.Year()%100, 2, '0'), nil
+	case 'Y':
+		return appendInt(b, t.Year(), 4, '0'), nil
+	case 'z':
+		return t.AppendFormat(b, "-0700"), nil
+	case 'Z':
+		return t.AppendFormat(b, "MST"), nil
+	case '%':
+		return append(b, '%'), nil
+	}
+	return nil, fmt.Errorf("unknown conversion %%%c", c)
+}
+
+func appendConversions(b []byte, conversions string, sep byte, t time.Time) ([]byte, error) {
+	for k := range conversions {
+		if k > 0 {
+			b = append(b, sep)
+		}
+		var err error
+		if b, err = appendConversion(b, conversions[k], t); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func appendInt(b []byte, n, width int, pad byte) []byte {
+	if n < 0 {
+		b = append(b, '-')
+		n = -n
+	}
+	s := strconv.Itoa(n)
+	for k := len(s); k < width; k++ {
+		b = append(b, pad)
+	}
+	return append(b, s...)
+}
+
+// Parse parses s according to layout and returns the time it represents.
+// If s does not include a time zone offset, the time is interpreted
+// in loc.  Fields absent from layout default to their values at the
+// start of the Unix epoch.
+func Parse(layout, s string, loc *time.Location) (time.Time, error) {
+	p := parser{
+		in:    s,
+		year:  1970,
+		month: 1,
+		day:   1,
+		loc:   loc,
+	}
+	if err := p.parse(layout); err != nil {
+		return time.Time{}, err
+	}
+	if p.in != "" {
+		return time.Time{}, fmt.Errorf("extra text at end of input: %q", p.in)
+	}
+	return p.time()
+}
+
+type parser struct {
+	in string
+
+	year    int
+	month   int
+	day     int
+	yday    int
+	hour    int
+	minute  int
+	second  int
+	nsec    int
+	pm      bool
+	hasPM   bool
+	hasDate bool
+	unix    int64
+	hasUnix bool
+	loc     *time.Location
+}
+
+func (p *parser) parse(layout string) error {
+	for k := 0; k < len(layout); k++ {
+		c := layout[k]
+		if isSpace(c) {
+			p.skipSpace()
+			continue
+		}
+		if c != '%' {
+			if p.in == "" || p.in[0] != c {
+				return p.errorf("expected %q", c)
+			}
+			p.in = p.in[1:]
+			continue
+		}
+		k++
+		if k == len(layout) {
+			return errors.New("layout ends with %")
+		}
+		if err := p.conversion(layout[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) conversion(c byte) error {
+	var err error
+	switch c {
+	case 'a', 'A':
+		_, err = p.name(weekdays, "weekday")
+	case 'b', 'B', 'h':
+		p.month, err = p.name(months, "month")
+		p.month++
+		p.hasDate = true
+	case 'd', 'e':
+		p.skipSpace()
+		p.day, err = p.int(2, "day of month")
+		p.hasDate = true
+	case 'D':
+		err = p.parse("%m/%d/%y")
+	case 'f', 'N':
+		err = p.fraction()
+	case 'F':
+		err = p.parse("%Y-%m-%d")
+	case 'H':
+		p.hour, err = p.int(2, "hour")
+	case 'I':
+		p.hour, err = p.int(2, "hour")
+		if err == nil && (p.hour < 1 || p.hour > 12) {
+			err = p.errorf("hour out of range")
+		}
+	case 'j':
+		p.yday, err = p.int(3, "day of year")
+	case 'm':
+		p.month, err = p.int(2, "month")
+		p.hasDate = true
+	case 'M':
+		p.minute, err = p.int(2, "minute")
+	case 'n', 't':
+		p.skipSpace()
+	case 'p':
+		switch {
+		case hasPrefixFold(p.in, "AM"):
+			p.pm = false
+		case hasPrefixFold(p.in, "PM"):
+			p.pm = true
+		default:
+			return p.errorf("expected AM or PM")
+		}
+		p.hasPM = true
+		p.in = p.in[2:]
+	case 'R':
+		err = p.parse("%H:%M")
+	case 's':
+		err = p.epoch()
+	case 'S':
+		p.second, err = p.int(2, "second")
+	case 'T':
+		err = p.parse("%H:%M:%S")
+	case 'u', 'w':
+		_, err = p.int(1, "weekday")
+	case 'y':
+		var year int
+		year, err = p.int(2, "year")
+		// POSIX maps 69-99 to the twentieth century and 00-68 to
+		// the twenty-first.
+		if year < 69 {
+			year += 2000
+		} else {
+			year += 1900
+		}
+		p.year = year
+	case 'Y':
+		p.year, err = p.int(4, "year")
+	case 'z':
+		err = p.offset()
+	case 'Z':
+		err = p.zone()
+	case '%':
+		if p.in == "" || p.in[0] != '%' {
+			return p.errorf("expected %%")
+		}
+		p.in = p.in[1:]
+	default:
+		return fmt.Errorf("unknown conversion %%%c", c)
+	}
+	return err
+}
+
+func (p *parser) time() (time.Time, error) {
+	if p.hasUnix {
+		return time.Unix(p.unix, int64(p.nsec)).In(p.loc), nil
+	}
+	hour := p.hour
+	if p.hasPM {
+		hour %= 12
+		if p.pm {
+			hour += 12
+		}
+	}
+	if hour > 23 || p.minute > 59 || p.second > 60 {
+		return time.Time{}, errors.New("time of day out of range")
+	}
+	if p.yday != 0 && !p.hasDate {
+		if p.yday > 366 {
+			return time.Time{}, errors.New("day of year out of range")
+		}
+		t := time.Date(p.year, 1, p.yday, hour, p.minute, p.second, p.nsec, p.loc)
+		if t.Year() != p.year {
+			return time.Time{}, errors.New("day of year out of range")
+		}
+		return t, nil
+	}
+	if p.month < 1 || p.month > 12 {
+		return time.Time{}, errors.New("month out of range")
+	}
+	t := time.Date(p.year, time.Month(p.month), p.day, hour, p.minute, p.second, p.nsec, p.loc)
+	if t.Day() != p.day {
+		return time.Time{}, errors.New("day of month out of range")
+	}
+	return t, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if p.in == "" {
+		return fmt.Errorf("%s at end of input", msg)
+	}
+	return fmt.Errorf("%s at %q", msg, p.in)
+}
+
+func (p *parser) skipSpace() {
+	p.in = strings.TrimLeftFunc(p.in, func(r rune) bool {
+		return r < 0x80 && isSpace(byte(r))
+	})
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// int parses an unsigned decimal number of at most width digits.
+func (p *parser) int(width int, what string) (int, error) {
+	var n, k int
+	for ; k < width && k < len(p.in) && isDigit(p.in[k]); k++ {
+		n = n*10 + int(p.in[k]-'0')
+	}
+	if k == 0 {
+		return 0, p.errorf("expected %s", what)
+	}
+	p.in = p.in[k:]
+	return n, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func (p *parser) fraction() error {
+	var k int
+	nsec := 0
+	for ; k < len(p.in) && isDigit(p.in[k]); k++ {
+		if k < 9 {
+			nsec = nsec*10 + int(p.in[k]-'0')
+		}
+	}
+	if k == 0 {
+		return p.errorf("expected fractional seconds")
+	}
+	for n := k; n < 9; n++ {
+		nsec *= 10
+	}
+	p.nsec = nsec
+	p.in = p.in[k:]
+	return nil
+}
+
+func (p *parser) epoch() error {
+	var neg bool
+	if p.in != "" && p.in[0] == '-' {
+		neg = true
+		p.in = p.in[1:]
+	}
+	var k int
+	for k < len(p.in) && isDigit(p.in[k]) {
+		k++
+	}
+	n, err := strconv.ParseInt(p.in[:k], 10, 64)
+	if err != nil {
+		return p.errorf("expected seconds since the epoch")
+	}
+	if neg {
+		n = -n
+	}
+	p.unix = n
+	p.hasUnix = true
+	p.in = p.in[k:]
+	return nil
+}
+
+func (p *parser) offset() error {
+	if p.in != "" && p.in[0] == 'Z' {
+		p.in = p.in[1:]
+		p.loc = time.UTC
+		return nil
+	}
+	if p.in == "" || (p.in[0] != '+' && p.in[0] != '-') {
+		return p.errorf("expected time zone offset")
+	}
+	sign := 1
+	if p.in[0] == '-' {
+		sign = -1
+	}
+	p.in = p.in[1:]
+	if len(p.in) < 2 || !isDigit(p.in[0]) || !isDigit(p.in[1]) {
+		return p.errorf("expected time zone offset")
+	}
+	hours := int(p.in[0]-'0')*10 + int(p.in[1]-'0')
+	p.in = p.in[2:]
+	var minutes int
+	if p.in != "" && p.in[0] == ':' {
+		p.in = p.in[1:]
+	}
+	if len(p.in) >= 2 && isDigit(p.in[0]) && isDigit(p.in[1]) {
+		minutes = int(p.in[0]-'0')*10 + int(p.in[1]-'0')
+		p.in = p.in[2:]
+	}
+	p.loc = time.FixedZone("", sign*(hours*3600+minutes*60))
+	return nil
+}
+
+func (p *parser) zone() error {
+	for _, name := range []string{"UTC", "GMT", "Z"} {
+		if strings.HasPrefix(p.in, name) {
+			p.in = p.in[len(name):]
+			p.loc = time.UTC
+			return nil
+		}
+	}
+	return p.errorf("expected time zone name")
+}
+
+var weekdays = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+var months = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+
+// name matches a full or three-letter abbreviated name from names without
+// regard to case and returns its index.
+func (p *parser) name(names []string, what string) (int, error) {
+	for k, name := range names {
+		if hasPrefixFold(p.in, name) {
+			p.in = p.in[len(name):]
+			return k, nil
+		}
+	}
+	for k, name := range names {
+		if hasPrefixFold(p.in, name[:3]) {
+			p.in = p.in[3:]
+			return k, nil
+		}
+	}
+	return 0, p.errorf("expected %s name", what)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
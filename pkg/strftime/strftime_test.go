@@ -0,0 +1,79 @@
+package strftime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	ts := time.Date(2023, time.March, 5, 14, 7, 9, 123456789, time.UTC)
+	cases := []struct {
+		layout   string
+		expected string
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2023-03-05 14:07:09"},
+		{"%F %T.%f", "2023-03-05 14:07:09.123456"},
+		{"%a %A %b %B %e", "Sun Sunday Mar March  5"},
+		{"%D %R %I%p", "03/05/23 14:07 02PM"},
+		{"%j %u %w %N", "064 7 0 123456789"},
+		{"%s %z %Z 100%%", "1678025229 +0000 UTC 100%"},
+	}
+	for _, c := range cases {
+		s, err := Format(c.layout, ts)
+		require.NoError(t, err, c.layout)
+		require.Equal(t, c.expected, s, c.layout)
+	}
+	_, err := Format("%Q", ts)
+	require.EqualError(t, err, "unknown conversion %Q")
+	_, err = Format("%", ts)
+	require.EqualError(t, err, "layout ends with %")
+}
+
+func TestParse(t *testing.T) {
+	est := time.FixedZone("", -5*3600)
+	cases := []struct {
+		layout   string
+		input    string
+		loc      *time.Location
+		expected time.Time
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2023-03-05 14:07:09", time.UTC, time.Date(2023, 3, 5, 14, 7, 9, 0, time.UTC)},
+		{"%Y-%m-%d %H:%M:%S", "2023-03-05 14:07:09", est, time.Date(2023, 3, 5, 19, 7, 9, 0, time.UTC)},
+		{"%d/%b/%Y:%H:%M:%S %z", "05/Mar/2023:14:07:09 -0500", time.UTC, time.Date(2023, 3, 5, 19, 7, 9, 0, time.UTC)},
+		{"%a, %d %B %Y %I:%M %p", "Sun, 5 march 2023 2:07 PM", time.UTC, time.Date(2023, 3, 5, 14, 7, 0, 0, time.UTC)},
+		{"%I %p", "12 AM", time.UTC, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"%T.%f", "14:07:09.5", time.UTC, time.Date(1970, 1, 1, 14, 7, 9, 500000000, time.UTC)},
+		{"%Y %j", "2024 060", time.UTC, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"%s", "1678025229", est, time.Date(2023, 3, 5, 14, 7, 9, 0, time.UTC)},
+		{"%D", "03/05/69", time.UTC, time.Date(1969, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"%FT%T%z", "2023-03-05T14:07:09+05:30", time.UTC, time.Date(2023, 3, 5, 8, 37, 9, 0, time.UTC)},
+		{"%FT%TZ", "2023-03-05T14:07:09Z", est, time.Date(2023, 3, 5, 19, 7, 9, 0, time.UTC)},
+		{"%F %T %Z", "2023-03-05 14:07:09 GMT", est, time.Date(2023, 3, 5, 14, 7, 9, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		ts, err := Parse(c.layout, c.input, c.loc)
+		require.NoError(t, err, c.input)
+		require.True(t, c.expected.Equal(ts), "%s: expected %s, got %s", c.input, c.expected, ts)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		layout   string
+		input    string
+		expected string
+	}{
+		{"%Y-%m-%d", "2023-03", "expected '-' at end of input"},
+		{"%Y-%m-%d", "2023-03-05x", `extra text at end of input: "x"`},
+		{"%Y-%m-%d", "2023-02-30", "day of month out of range"},
+		{"%H:%M", "25:00", "time of day out of range"},
+		{"%b", "Foo", `expected month name at "Foo"`},
+		{"%Q", "", "unknown conversion %Q"},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.layout, c.input, time.UTC)
+		require.EqualError(t, err, c.expected, c.input)
+	}
+}
@@ -1,6 +1,8 @@
 package expr
 
 import (
+	"fmt"
+
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/runtime/expr/agg"
 )
@@ -8,14 +10,19 @@ import (
 type Aggregator struct {
 	pattern agg.Pattern
 	expr    Evaluator
+	args    []Evaluator
 	where   Evaluator
+	vals    []*zed.Value
 }
 
-func NewAggregator(op string, expr Evaluator, where Evaluator) (*Aggregator, error) {
-	pattern, err := agg.NewPattern(op, expr != nil)
+func NewAggregator(op string, expr Evaluator, args []Evaluator, where Evaluator) (*Aggregator, error) {
+	pattern, nargs, err := agg.NewPattern(op, expr != nil)
 	if err != nil {
 		return nil, err
 	}
+	if expr != nil && len(args)+1 != nargs {
+		return nil, fmt.Errorf("%s: wrong number of arguments", op)
+	}
 	if expr == nil {
 		// Count is the only that has no argument so we just return
 		// true so it counts each value encountered.
@@ -24,6 +31,7 @@ func NewAggregator(op string, expr Evaluator, where Evaluator) (*Aggregator, err
 	return &Aggregator{
 		pattern: pattern,
 		expr:    expr,
+		args:    args,
 		where:   where,
 	}, nil
 }
@@ -40,9 +48,18 @@ func (a *Aggregator) Apply(zctx *zed.Context, ectx Context, f agg.Function, this
 		}
 	}
 	v := a.expr.Eval(ectx, this)
-	if !v.IsMissing() {
+	if v.IsMissing() {
+		return
+	}
+	if len(a.args) == 0 {
 		f.Consume(v)
+		return
+	}
+	a.vals = append(a.vals[:0], v)
+	for _, arg := range a.args {
+		a.vals = append(a.vals, arg.Eval(ectx, this))
 	}
+	f.(agg.MultiFunction).ConsumeArgs(a.vals)
 }
 
 // NewAggregatorExpr returns an Evaluator from agg. The returned Evaluator
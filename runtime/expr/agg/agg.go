@@ -23,8 +23,35 @@ type Function interface {
 	ResultAsPartial(*zed.Context) *zed.Value
 }
 
-func NewPattern(op string, hasarg bool) (Pattern, error) {
+// A Sizer is a Function whose state grows with its input, such as one that
+// builds a container.  Size returns the approximate number of bytes used by
+// that state.
+type Sizer interface {
+	Function
+	Size() int
+}
+
+// A MultiFunction is a Function that takes more than one argument.
+// ConsumeArgs is called in place of Consume with the value of each
+// argument in order.
+type MultiFunction interface {
+	Function
+	ConsumeArgs([]*zed.Value)
+}
+
+// A ConstArgsFunction is a MultiFunction whose arguments after the first
+// must be constant, such as the q of quantile(x, q).  The compiler rejects
+// other arguments.
+type ConstArgsFunction interface {
+	MultiFunction
+	ConstArgs()
+}
+
+// NewPattern returns the pattern for the aggregate function op along with
+// the number of arguments the function takes.
+func NewPattern(op string, hasarg bool) (Pattern, int, error) {
 	needarg := true
+	nargs := 1
 	var pattern Pattern
 	switch op {
 	case "count":
@@ -57,6 +84,20 @@ func NewPattern(op string, hasarg bool) (Pattern, error) {
 		pattern = func() Function {
 			return newCollectMap()
 		}
+	case "median":
+		pattern = func() Function {
+			return newQuantile(0.5)
+		}
+	case "quantile":
+		nargs = 2
+		pattern = func() Function {
+			return newQuantileArg(1)
+		}
+	case "percentile":
+		nargs = 2
+		pattern = func() Function {
+			return newQuantileArg(100)
+		}
 	case "min":
 		pattern = func() Function {
 			return newMathReducer(anymath.Min)
@@ -73,6 +114,32 @@ func NewPattern(op string, hasarg bool) (Pattern, error) {
 		pattern = func() Function {
 			return &Collect{}
 		}
+	case "var":
+		pattern = func() Function {
+			return newVariance(op, false, false)
+		}
+	case "var_pop":
+		pattern = func() Function {
+			return newVariance(op, true, false)
+		}
+	case "stddev":
+		pattern = func() Function {
+			return newVariance(op, false, true)
+		}
+	case "stddev_pop":
+		pattern = func() Function {
+			return newVariance(op, true, true)
+		}
+	case "covar":
+		nargs = 2
+		pattern = func() Function {
+			return newCovariance(op, false)
+		}
+	case "corr":
+		nargs = 2
+		pattern = func() Function {
+			return newCovariance(op, true)
+		}
 	case "and":
 		pattern = func() Function {
 			return &And{}
@@ -82,10 +149,10 @@ func NewPattern(op string, hasarg bool) (Pattern, error) {
 			return &Or{}
 		}
 	default:
-		return nil, fmt.Errorf("unknown aggregation function: %s", op)
+		return nil, 0, fmt.Errorf("unknown aggregation function: %s", op)
 	}
 	if needarg && !hasarg {
-		return nil, fmt.Errorf("%s: argument required", op)
+		return nil, 0, fmt.Errorf("%s: argument required", op)
 	}
-	return pattern, nil
+	return pattern, nargs, nil
 }
@@ -13,7 +13,7 @@ type Collect struct {
 	size   int
 }
 
-var _ Function = (*Collect)(nil)
+var _ Sizer = (*Collect)(nil)
 
 func (c *Collect) Consume(val *zed.Value) {
 	if !val.IsNull() {
@@ -33,6 +33,10 @@ func (c *Collect) update(val *zed.Value) {
 	}
 }
 
+func (c *Collect) Size() int {
+	return c.size
+}
+
 func (c *Collect) Result(zctx *zed.Context) *zed.Value {
 	if len(c.values) == 0 {
 		// no values found
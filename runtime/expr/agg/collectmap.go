@@ -10,13 +10,14 @@ import (
 type CollectMap struct {
 	entries map[string]mapEntry
 	scratch []byte
+	size    int
 }
 
 func newCollectMap() *CollectMap {
 	return &CollectMap{entries: make(map[string]mapEntry)}
 }
 
-var _ Function = (*Collect)(nil)
+var _ Sizer = (*CollectMap)(nil)
 
 type mapEntry struct {
 	key *zed.Value
@@ -39,6 +40,12 @@ func (c *CollectMap) Consume(val *zed.Value) {
 		val := valueUnder(mtyp.ValType, it.Next())
 		c.scratch = zed.AppendTypeValue(c.scratch[:0], key.Type)
 		c.scratch = append(c.scratch, keyTagAndBody...)
+		if old, ok := c.entries[string(c.scratch)]; ok {
+			c.size -= len(old.val.Bytes())
+		} else {
+			c.size += len(c.scratch)
+		}
+		c.size += len(val.Bytes())
 		// This will squash existing values which is what we want.
 		c.entries[string(c.scratch)] = mapEntry{key, val}
 	}
@@ -48,6 +55,10 @@ func (c *CollectMap) ConsumeAsPartial(val *zed.Value) {
 	c.Consume(val)
 }
 
+func (c *CollectMap) Size() int {
+	return c.size
+}
+
 func (c *CollectMap) Result(zctx *zed.Context) *zed.Value {
 	if len(c.entries) == 0 {
 		return zed.Null
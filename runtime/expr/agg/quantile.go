@@ -0,0 +1,155 @@
+package agg
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr/coerce"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+// Quantile estimates a quantile of its input using a t-digest.  The
+// quantile is either fixed (as for median) or given by the constant second
+// argument (as for quantile and percentile), in which case it is divided by
+// scale.
+type Quantile struct {
+	name   string
+	q      float64
+	hasq   bool
+	scale  float64
+	digest tdigest
+}
+
+var _ ConstArgsFunction = (*Quantile)(nil)
+
+func newQuantile(q float64) *Quantile {
+	return &Quantile{name: "median", q: q, hasq: true}
+}
+
+func newQuantileArg(scale float64) *Quantile {
+	name := "quantile"
+	if scale != 1 {
+		name = "percentile"
+	}
+	return &Quantile{name: name, scale: scale}
+}
+
+func (q *Quantile) Consume(val *zed.Value) {
+	if val.IsNull() {
+		return
+	}
+	if f, ok := coerce.ToFloat(val); ok {
+		q.digest.add(f, 1)
+	}
+}
+
+func (q *Quantile) ConstArgs() {}
+
+func (q *Quantile) ConsumeArgs(args []*zed.Value) {
+	if !q.hasq {
+		// The quantile is constant so it need only be read once.
+		if f, ok := coerce.ToFloat(args[1]); ok {
+			q.q = f / q.scale
+			q.hasq = true
+		}
+	}
+	q.Consume(args[0])
+}
+
+func (q *Quantile) Result(zctx *zed.Context) *zed.Value {
+	if !q.hasq || q.digest.empty() {
+		return zed.NullFloat64
+	}
+	if q.q < 0 || q.q > 1 {
+		return zctx.NewErrorf("%s: argument out of range: %g", q.name, q.q*q.scale)
+	}
+	return zed.NewFloat64(q.digest.quantile(q.q))
+}
+
+const (
+	qName       = "q"
+	minName     = "min"
+	maxName     = "max"
+	meansName   = "means"
+	weightsName = "weights"
+)
+
+func (q *Quantile) ConsumeAsPartial(partial *zed.Value) {
+	if partial.IsNull() {
+		return
+	}
+	if _, ok := partial.Type.(*zed.TypeRecord); !ok {
+		panic(fmt.Errorf("%s: partial has bad type: %s", q.name, zson.FormatValue(partial)))
+	}
+	if qval := partial.Deref(qName); qval != nil && !qval.IsNull() && !q.hasq {
+		q.q = qval.Float()
+		q.hasq = true
+	}
+	means := q.partialFloats(partial, meansName)
+	weights := q.partialFloats(partial, weightsName)
+	if len(means) != len(weights) {
+		panic(fmt.Errorf("%s: partial has mismatched centroids: %s", q.name, zson.FormatValue(partial)))
+	}
+	if len(means) == 0 {
+		return
+	}
+	centroids := make([]centroid, 0, len(means))
+	for k := range means {
+		centroids = append(centroids, centroid{means[k], weights[k]})
+	}
+	min := q.partialFloats(partial, minName)
+	max := q.partialFloats(partial, maxName)
+	q.digest.merge(min[0], max[0], centroids)
+}
+
+func (q *Quantile) partialFloats(partial *zed.Value, name string) []float64 {
+	val := partial.Deref(name)
+	if val == nil {
+		panic(fmt.Errorf("%s: partial %s is missing", q.name, name))
+	}
+	switch typ := val.Type.(type) {
+	case *zed.TypeOfFloat64:
+		return []float64{val.Float()}
+	case *zed.TypeArray:
+		if typ.Type == zed.TypeFloat64 {
+			var out []float64
+			for it := val.Iter(); !it.Done(); {
+				out = append(out, zed.DecodeFloat64(it.Next()))
+			}
+			return out
+		}
+	}
+	panic(fmt.Errorf("%s: partial %s has bad type: %s", q.name, name, zson.FormatValue(val)))
+}
+
+func (q *Quantile) ResultAsPartial(zctx *zed.Context) *zed.Value {
+	q.digest.compress()
+	var b zcode.Builder
+	if q.hasq {
+		b.Append(zed.EncodeFloat64(q.q))
+	} else {
+		b.Append(nil)
+	}
+	b.Append(zed.EncodeFloat64(q.digest.min))
+	b.Append(zed.EncodeFloat64(q.digest.max))
+	b.BeginContainer()
+	for _, c := range q.digest.centroids {
+		b.Append(zed.EncodeFloat64(c.mean))
+	}
+	b.EndContainer()
+	b.BeginContainer()
+	for _, c := range q.digest.centroids {
+		b.Append(zed.EncodeFloat64(c.weight))
+	}
+	b.EndContainer()
+	arrayType := zctx.LookupTypeArray(zed.TypeFloat64)
+	typ := zctx.MustLookupTypeRecord([]zed.Field{
+		zed.NewField(qName, zed.TypeFloat64),
+		zed.NewField(minName, zed.TypeFloat64),
+		zed.NewField(maxName, zed.TypeFloat64),
+		zed.NewField(meansName, arrayType),
+		zed.NewField(weightsName, arrayType),
+	})
+	return zed.NewValue(typ, b.Bytes())
+}
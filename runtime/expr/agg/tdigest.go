@@ -0,0 +1,139 @@
+package agg
+
+import (
+	"math"
+	"sort"
+)
+
+// tdigestCompression controls the accuracy and size of a tdigest.  A digest
+// holds on the order of tdigestCompression centroids.
+const tdigestCompression = 100
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// tdigest is a merging t-digest (see Dunning and Ertl, "Computing Extremely
+// Accurate Quantiles Using t-Digests") that summarizes a distribution of
+// float64 values in a small number of centroids.  Centroids near the tails
+// of the distribution are kept small so extreme quantiles remain accurate.
+// Two digests can be merged, which allows a quantile to be computed from
+// partial results.
+type tdigest struct {
+	centroids []centroid
+	buf       []centroid
+	weight    float64
+	min       float64
+	max       float64
+}
+
+func (t *tdigest) add(x, weight float64) {
+	if math.IsNaN(x) || weight <= 0 {
+		return
+	}
+	if t.weight == 0 && len(t.buf) == 0 {
+		t.min, t.max = x, x
+	} else {
+		t.min = math.Min(t.min, x)
+		t.max = math.Max(t.max, x)
+	}
+	t.buf = append(t.buf, centroid{x, weight})
+	if len(t.buf) >= 5*tdigestCompression {
+		t.compress()
+	}
+}
+
+// merge adds the centroids of another digest with the given bounds to t.
+func (t *tdigest) merge(min, max float64, centroids []centroid) {
+	if len(centroids) == 0 {
+		return
+	}
+	if t.weight == 0 && len(t.buf) == 0 {
+		t.min, t.max = min, max
+	} else {
+		t.min = math.Min(t.min, min)
+		t.max = math.Max(t.max, max)
+	}
+	t.buf = append(t.buf, centroids...)
+	t.compress()
+}
+
+func (t *tdigest) empty() bool {
+	return t.weight == 0 && len(t.buf) == 0
+}
+
+// compress merges buffered values into the centroids, combining adjacent
+// centroids as long as the result stays within the size bound implied by
+// the compression at its position in the distribution.
+func (t *tdigest) compress() {
+	if len(t.buf) == 0 {
+		return
+	}
+	all := append(t.buf, t.centroids...)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].mean < all[j].mean
+	})
+	var total float64
+	for _, c := range all {
+		total += c.weight
+	}
+	out := t.centroids[:0]
+	if cap(out) < len(all) {
+		out = make([]centroid, 0, len(all))
+	}
+	cur := all[0]
+	var sofar float64
+	for _, c := range all[1:] {
+		w := cur.weight + c.weight
+		q0 := sofar / total
+		q1 := (sofar + w) / total
+		limit := 4 * total * math.Min(q0*(1-q0), q1*(1-q1)) / tdigestCompression
+		if w <= limit {
+			cur.mean += (c.mean - cur.mean) * c.weight / w
+			cur.weight = w
+			continue
+		}
+		sofar += cur.weight
+		out = append(out, cur)
+		cur = c
+	}
+	t.centroids = append(out, cur)
+	t.buf = t.buf[:0]
+	t.weight = total
+}
+
+// quantile returns an estimate of the q-th quantile of the values added
+// to t.  It interpolates between the centers of neighboring centroids,
+// treating the minimum and maximum values as centroids of zero weight.
+func (t *tdigest) quantile(q float64) float64 {
+	t.compress()
+	if len(t.centroids) == 0 {
+		return math.NaN()
+	}
+	switch {
+	case q <= 0:
+		return t.min
+	case q >= 1:
+		return t.max
+	}
+	index := q * t.weight
+	prevMean, prevCenter := t.min, 0.0
+	var sofar float64
+	for _, c := range t.centroids {
+		center := sofar + c.weight/2
+		if index < center {
+			return interpolate(index, prevCenter, center, prevMean, c.mean)
+		}
+		sofar += c.weight
+		prevMean, prevCenter = c.mean, center
+	}
+	return interpolate(index, prevCenter, t.weight, prevMean, t.max)
+}
+
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 <= x0 {
+		return y1
+	}
+	return y0 + (x-x0)/(x1-x0)*(y1-y0)
+}
@@ -10,7 +10,7 @@ type Union struct {
 	size  int
 }
 
-var _ Function = (*Union)(nil)
+var _ Sizer = (*Union)(nil)
 
 func newUnion() *Union {
 	return &Union{
@@ -56,6 +56,10 @@ func (u *Union) deleteOne() {
 	}
 }
 
+func (u *Union) Size() int {
+	return u.size
+}
+
 func (u *Union) Result(zctx *zed.Context) *zed.Value {
 	if len(u.types) == 0 {
 		return zed.Null
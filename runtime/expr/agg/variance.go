@@ -0,0 +1,212 @@
+package agg
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr/coerce"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+// Variance computes the variance or standard deviation of its input using
+// Welford's algorithm, which avoids the catastrophic cancellation of the
+// naive sum-of-squares method.  Partial results are combined with the
+// parallel update of Chan et al.
+type Variance struct {
+	name  string
+	pop   bool
+	sqrt  bool
+	count float64
+	mean  float64
+	m2    float64
+}
+
+var _ Function = (*Variance)(nil)
+
+func newVariance(name string, pop, sqrt bool) *Variance {
+	return &Variance{name: name, pop: pop, sqrt: sqrt}
+}
+
+func (v *Variance) Consume(val *zed.Value) {
+	if val.IsNull() {
+		return
+	}
+	if x, ok := coerce.ToFloat(val); ok {
+		v.count++
+		delta := x - v.mean
+		v.mean += delta / v.count
+		v.m2 += delta * (x - v.mean)
+	}
+}
+
+func (v *Variance) Result(*zed.Context) *zed.Value {
+	n := v.count
+	if !v.pop {
+		n--
+	}
+	if n <= 0 {
+		return zed.NullFloat64
+	}
+	result := v.m2 / n
+	if v.sqrt {
+		result = math.Sqrt(result)
+	}
+	return zed.NewFloat64(result)
+}
+
+const (
+	meanName = "mean"
+	m2Name   = "m2"
+)
+
+func (v *Variance) ConsumeAsPartial(partial *zed.Value) {
+	if partial.IsNull() {
+		return
+	}
+	count := partialFloat(v.name, partial, countName)
+	mean := partialFloat(v.name, partial, meanName)
+	m2 := partialFloat(v.name, partial, m2Name)
+	if count == 0 {
+		return
+	}
+	n := v.count + count
+	delta := mean - v.mean
+	v.m2 += m2 + delta*delta*v.count*count/n
+	v.mean += delta * count / n
+	v.count = n
+}
+
+func (v *Variance) ResultAsPartial(zctx *zed.Context) *zed.Value {
+	var zv zcode.Bytes
+	zv = zed.NewFloat64(v.count).Encode(zv)
+	zv = zed.NewFloat64(v.mean).Encode(zv)
+	zv = zed.NewFloat64(v.m2).Encode(zv)
+	typ := zctx.MustLookupTypeRecord([]zed.Field{
+		zed.NewField(countName, zed.TypeFloat64),
+		zed.NewField(meanName, zed.TypeFloat64),
+		zed.NewField(m2Name, zed.TypeFloat64),
+	})
+	return zed.NewValue(typ, zv)
+}
+
+// Covariance computes the sample covariance or the Pearson correlation
+// coefficient of its two arguments using an online co-moment update.
+// Values for which either argument is null or non-numeric are ignored.
+type Covariance struct {
+	name  string
+	corr  bool
+	count float64
+	meanX float64
+	meanY float64
+	m2X   float64
+	m2Y   float64
+	c     float64
+}
+
+var _ MultiFunction = (*Covariance)(nil)
+
+func newCovariance(name string, corr bool) *Covariance {
+	return &Covariance{name: name, corr: corr}
+}
+
+func (c *Covariance) Consume(*zed.Value) {
+	panic(fmt.Errorf("%s: called with one argument", c.name))
+}
+
+func (c *Covariance) ConsumeArgs(args []*zed.Value) {
+	if args[0].IsNull() || args[1].IsNull() {
+		return
+	}
+	x, ok := coerce.ToFloat(args[0])
+	if !ok {
+		return
+	}
+	y, ok := coerce.ToFloat(args[1])
+	if !ok {
+		return
+	}
+	c.count++
+	dx := x - c.meanX
+	dy := y - c.meanY
+	c.meanX += dx / c.count
+	c.meanY += dy / c.count
+	c.m2X += dx * (x - c.meanX)
+	c.m2Y += dy * (y - c.meanY)
+	c.c += dx * (y - c.meanY)
+}
+
+func (c *Covariance) Result(*zed.Context) *zed.Value {
+	if c.count < 2 {
+		return zed.NullFloat64
+	}
+	if !c.corr {
+		return zed.NewFloat64(c.c / (c.count - 1))
+	}
+	d := math.Sqrt(c.m2X * c.m2Y)
+	if d == 0 {
+		return zed.NullFloat64
+	}
+	return zed.NewFloat64(c.c / d)
+}
+
+const (
+	meanXName = "mean_x"
+	meanYName = "mean_y"
+	m2XName   = "m2_x"
+	m2YName   = "m2_y"
+	cName     = "c"
+)
+
+func (c *Covariance) ConsumeAsPartial(partial *zed.Value) {
+	if partial.IsNull() {
+		return
+	}
+	count := partialFloat(c.name, partial, countName)
+	if count == 0 {
+		return
+	}
+	meanX := partialFloat(c.name, partial, meanXName)
+	meanY := partialFloat(c.name, partial, meanYName)
+	n := c.count + count
+	dx := meanX - c.meanX
+	dy := meanY - c.meanY
+	f := c.count * count / n
+	c.m2X += partialFloat(c.name, partial, m2XName) + dx*dx*f
+	c.m2Y += partialFloat(c.name, partial, m2YName) + dy*dy*f
+	c.c += partialFloat(c.name, partial, cName) + dx*dy*f
+	c.meanX += dx * count / n
+	c.meanY += dy * count / n
+	c.count = n
+}
+
+func (c *Covariance) ResultAsPartial(zctx *zed.Context) *zed.Value {
+	var zv zcode.Bytes
+	zv = zed.NewFloat64(c.count).Encode(zv)
+	zv = zed.NewFloat64(c.meanX).Encode(zv)
+	zv = zed.NewFloat64(c.meanY).Encode(zv)
+	zv = zed.NewFloat64(c.m2X).Encode(zv)
+	zv = zed.NewFloat64(c.m2Y).Encode(zv)
+	zv = zed.NewFloat64(c.c).Encode(zv)
+	typ := zctx.MustLookupTypeRecord([]zed.Field{
+		zed.NewField(countName, zed.TypeFloat64),
+		zed.NewField(meanXName, zed.TypeFloat64),
+		zed.NewField(meanYName, zed.TypeFloat64),
+		zed.NewField(m2XName, zed.TypeFloat64),
+		zed.NewField(m2YName, zed.TypeFloat64),
+		zed.NewField(cName, zed.TypeFloat64),
+	})
+	return zed.NewValue(typ, zv)
+}
+
+func partialFloat(name string, partial *zed.Value, field string) float64 {
+	val := partial.Deref(field)
+	if val == nil {
+		panic(fmt.Errorf("%s: partial %s is missing", name, field))
+	}
+	if val.Type != zed.TypeFloat64 {
+		panic(fmt.Errorf("%s: partial %s has bad type: %s", name, field, zson.FormatValue(val)))
+	}
+	return val.Float()
+}
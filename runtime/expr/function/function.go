@@ -100,6 +100,18 @@ func New(zctx *zed.Context, name string, narg int) (expr.Function, field.Path, e
 		argmin = 2
 		argmax = 2
 		f = &Bucket{zctx: zctx}
+	case "date_part":
+		argmin, argmax = 2, 3
+		f = &DatePart{zctx: zctx}
+	case "date_trunc":
+		argmin, argmax = 2, 3
+		f = &DateTrunc{zctx: zctx}
+	case "strftime":
+		argmin, argmax = 2, 3
+		f = &Strftime{zctx: zctx}
+	case "strptime":
+		argmin, argmax = 2, 3
+		f = &Strptime{zctx: zctx}
 	case "typename":
 		argmax = 2
 		f = &typeName{zctx: zctx}
@@ -1,8 +1,12 @@
 package function
 
 import (
+	"errors"
+	"time"
+
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/pkg/strftime"
 	"github.com/brimdata/zed/runtime/expr/coerce"
 )
 
@@ -45,3 +49,199 @@ func (b *Bucket) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
 	}
 	return ctx.CopyValue(*zed.NewTime(nano.Ts(v).Trunc(bin)))
 }
+
+// location returns the time zone named by the optional string argument at
+// position k of args, or UTC if the argument is absent.  The most recently
+// loaded zone is cached since the argument is usually a constant.
+type location struct {
+	name string
+	loc  *time.Location
+}
+
+func (l *location) lookup(args []zed.Value, k int) (*time.Location, error) {
+	if len(args) <= k {
+		return time.UTC, nil
+	}
+	arg := &args[k]
+	if !arg.IsString() || arg.IsNull() {
+		return nil, errors.New("time zone argument must be a string")
+	}
+	name := zed.DecodeString(arg.Bytes())
+	if l.loc == nil || l.name != name {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, err
+		}
+		l.name = name
+		l.loc = loc
+	}
+	return l.loc, nil
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#strftime
+type Strftime struct {
+	zctx *zed.Context
+	location
+}
+
+func (s *Strftime) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	formatArg, tsArg := &args[0], &args[1]
+	if !formatArg.IsString() {
+		return wrapError(s.zctx, ctx, "strftime: format argument must be a string", formatArg)
+	}
+	if zed.TypeUnder(tsArg.Type) != zed.TypeTime {
+		return wrapError(s.zctx, ctx, "strftime: time argument required", tsArg)
+	}
+	loc, err := s.lookup(args, 2)
+	if err != nil {
+		return wrapError(s.zctx, ctx, "strftime: "+err.Error(), &args[2])
+	}
+	if formatArg.IsNull() || tsArg.IsNull() {
+		return zed.NullString
+	}
+	format := zed.DecodeString(formatArg.Bytes())
+	out, err := strftime.Format(format, tsArg.AsTime().Time().In(loc))
+	if err != nil {
+		return wrapError(s.zctx, ctx, "strftime: "+err.Error(), formatArg)
+	}
+	return newString(ctx, out)
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#strptime
+type Strptime struct {
+	zctx *zed.Context
+	location
+}
+
+func (s *Strptime) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	formatArg, sArg := &args[0], &args[1]
+	if !formatArg.IsString() {
+		return wrapError(s.zctx, ctx, "strptime: format argument must be a string", formatArg)
+	}
+	if !sArg.IsString() {
+		return wrapError(s.zctx, ctx, "strptime: string argument required", sArg)
+	}
+	loc, err := s.lookup(args, 2)
+	if err != nil {
+		return wrapError(s.zctx, ctx, "strptime: "+err.Error(), &args[2])
+	}
+	if formatArg.IsNull() || sArg.IsNull() {
+		return zed.NullTime
+	}
+	format := zed.DecodeString(formatArg.Bytes())
+	ts, err := strftime.Parse(format, zed.DecodeString(sArg.Bytes()), loc)
+	if err != nil {
+		return wrapError(s.zctx, ctx, "strptime: "+err.Error(), sArg)
+	}
+	return ctx.CopyValue(*zed.NewTime(nano.TimeToTs(ts)))
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#date_trunc
+type DateTrunc struct {
+	zctx *zed.Context
+	location
+}
+
+func (d *DateTrunc) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	unitArg, tsArg := &args[0], &args[1]
+	if !unitArg.IsString() {
+		return wrapError(d.zctx, ctx, "date_trunc: unit argument must be a string", unitArg)
+	}
+	if zed.TypeUnder(tsArg.Type) != zed.TypeTime {
+		return wrapError(d.zctx, ctx, "date_trunc: time argument required", tsArg)
+	}
+	loc, err := d.lookup(args, 2)
+	if err != nil {
+		return wrapError(d.zctx, ctx, "date_trunc: "+err.Error(), &args[2])
+	}
+	if unitArg.IsNull() || tsArg.IsNull() {
+		return zed.NullTime
+	}
+	t := tsArg.AsTime().Time().In(loc)
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+	switch zed.DecodeString(unitArg.Bytes()) {
+	case "year":
+		t = time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	case "quarter":
+		t = time.Date(year, (month-1)/3*3+1, 1, 0, 0, 0, 0, loc)
+	case "month":
+		t = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	case "week":
+		// Weeks start on Monday as in ISO 8601.
+		t = time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case "day":
+		t = time.Date(year, month, day, 0, 0, 0, 0, loc)
+	case "hour":
+		t = time.Date(year, month, day, hour, 0, 0, 0, loc)
+	case "minute":
+		t = time.Date(year, month, day, hour, min, 0, 0, loc)
+	case "second":
+		t = time.Date(year, month, day, hour, min, sec, 0, loc)
+	case "millisecond":
+		t = t.Truncate(time.Millisecond)
+	case "microsecond":
+		t = t.Truncate(time.Microsecond)
+	default:
+		return wrapError(d.zctx, ctx, "date_trunc: unknown unit", unitArg)
+	}
+	return ctx.CopyValue(*zed.NewTime(nano.TimeToTs(t)))
+}
+
+// https://github.com/brimdata/zed/blob/main/docs/language/functions.md#date_part
+type DatePart struct {
+	zctx *zed.Context
+	location
+}
+
+func (d *DatePart) Call(ctx zed.Allocator, args []zed.Value) *zed.Value {
+	unitArg, tsArg := &args[0], &args[1]
+	if !unitArg.IsString() {
+		return wrapError(d.zctx, ctx, "date_part: unit argument must be a string", unitArg)
+	}
+	if zed.TypeUnder(tsArg.Type) != zed.TypeTime {
+		return wrapError(d.zctx, ctx, "date_part: time argument required", tsArg)
+	}
+	loc, err := d.lookup(args, 2)
+	if err != nil {
+		return wrapError(d.zctx, ctx, "date_part: "+err.Error(), &args[2])
+	}
+	if unitArg.IsNull() || tsArg.IsNull() {
+		return zed.NullInt64
+	}
+	t := tsArg.AsTime().Time().In(loc)
+	var part int
+	switch zed.DecodeString(unitArg.Bytes()) {
+	case "year":
+		part = t.Year()
+	case "quarter":
+		part = (int(t.Month())-1)/3 + 1
+	case "month":
+		part = int(t.Month())
+	case "week":
+		_, part = t.ISOWeek()
+	case "day":
+		part = t.Day()
+	case "dow":
+		part = int(t.Weekday())
+	case "doy":
+		part = t.YearDay()
+	case "hour":
+		part = t.Hour()
+	case "minute":
+		part = t.Minute()
+	case "second":
+		part = t.Second()
+	case "millisecond":
+		part = t.Nanosecond() / 1_000_000
+	case "microsecond":
+		part = t.Nanosecond() / 1_000
+	case "nanosecond":
+		part = t.Nanosecond()
+	case "epoch":
+		return ctx.CopyValue(*zed.NewInt64(t.Unix()))
+	default:
+		return wrapError(d.zctx, ctx, "date_part: unknown unit", unitArg)
+	}
+	return ctx.CopyValue(*zed.NewInt64(int64(part)))
+}
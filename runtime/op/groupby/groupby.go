@@ -1,7 +1,6 @@
 package groupby
 
 import (
-	"context"
 	"encoding/binary"
 	"errors"
 	"sync"
@@ -18,6 +17,12 @@ import (
 
 var DefaultLimit = 1000000
 
+// MemMaxBytes specifies the approximate maximum amount of memory that the
+// table of each groupby operator will consume before it is spilled to disk.
+// Memory is counted for the keys of each row and for the state of aggregate
+// functions that build containers, such as collect and union.
+var MemMaxBytes = 128 * 1024 * 1024
+
 // Proc computes aggregations using an Aggregator.
 type Op struct {
 	octx     *op.Context
@@ -34,7 +39,7 @@ type Op struct {
 // ("every") group-by operations.  Records are generated in a
 // deterministic but undefined total order.
 type Aggregator struct {
-	ctx  context.Context
+	octx *op.Context
 	zctx *zed.Context
 	// The keyTypes and outTypes tables map a vector of types resulting
 	// from evaluating the key and reducer expressions to a small int,
@@ -53,6 +58,8 @@ type Aggregator struct {
 	recordTypes    map[int]*zed.TypeRecord
 	table          map[string]*Row
 	limit          int
+	memMax         int
+	nbytes         int              // approximate memory used by table
 	valueCompare   expr.CompareFn   // to compare primary group keys for early key output
 	keyCompare     expr.CompareFn   // compare the first key (used when input sorted)
 	keysComparator *expr.Comparator // compare all keys
@@ -69,12 +76,16 @@ type Row struct {
 	keyType  int
 	groupval *zed.Value // for sorting when input sorted
 	reducers valRow
+	size     int // size of reducers when last counted in Aggregator.nbytes
 }
 
-func NewAggregator(ctx context.Context, zctx *zed.Context, keyRefs, keyExprs, aggRefs []expr.Evaluator, aggs []*expr.Aggregator, builder *zed.RecordBuilder, limit int, inputDir order.Direction, partialsIn, partialsOut bool) (*Aggregator, error) {
+func NewAggregator(octx *op.Context, keyRefs, keyExprs, aggRefs []expr.Evaluator, aggs []*expr.Aggregator, builder *zed.RecordBuilder, limit, memMax int, inputDir order.Direction, partialsIn, partialsOut bool) (*Aggregator, error) {
 	if limit == 0 {
 		limit = DefaultLimit
 	}
+	if memMax == 0 {
+		memMax = MemMaxBytes
+	}
 	var keyCompare, valueCompare expr.CompareFn
 	nkeys := len(keyExprs)
 	if nkeys > 0 && inputDir != 0 {
@@ -87,10 +98,11 @@ func NewAggregator(ctx context.Context, zctx *zed.Context, keyRefs, keyExprs, ag
 		}
 	}
 	return &Aggregator{
-		ctx:            ctx,
-		zctx:           zctx,
+		octx:           octx,
+		zctx:           octx.Zctx,
 		inputDir:       inputDir,
 		limit:          limit,
+		memMax:         memMax,
 		keyTypes:       zed.NewTypeVectorTable(),
 		outTypes:       zed.NewTypeVectorTable(),
 		keyRefs:        keyRefs,
@@ -134,7 +146,7 @@ func New(octx *op.Context, parent zbuf.Puller, keys []expr.Assignment, aggNames
 		keyRefs = append(keyRefs, expr.NewDottedExpr(octx.Zctx, names[i]))
 		keyExprs = append(keyExprs, keys[i].RHS)
 	}
-	agg, err := NewAggregator(octx.Context, octx.Zctx, keyRefs, keyExprs, valRefs, aggs, builder, limit, inputSortDir, partialsIn, partialsOut)
+	agg, err := NewAggregator(octx, keyRefs, keyExprs, valRefs, aggs, builder, limit, octx.GroupByMemMaxBytes, inputSortDir, partialsIn, partialsOut)
 	if err != nil {
 		return nil, err
 	}
@@ -173,6 +185,7 @@ func (o *Op) run() {
 		if o.agg.spiller != nil {
 			o.agg.spiller.Cleanup()
 		}
+		o.agg.grow(-o.agg.nbytes)
 		// Tell p.ctx's cancel function that we've finished our cleanup.
 		o.octx.WaitGroup.Done()
 	}()
@@ -284,6 +297,7 @@ func (o *Op) reset() {
 		o.agg.spiller = nil
 	}
 	o.agg.table = make(map[string]*Row)
+	o.agg.grow(-o.agg.nbytes)
 	if o.batch != nil {
 		o.batch.Unref()
 		o.batch = nil
@@ -344,6 +358,9 @@ func (a *Aggregator) Consume(batch zbuf.Batch, this *zed.Value) error {
 				return err
 			}
 		}
+		if err := a.grow(len(keyBytes)); err != nil {
+			return err
+		}
 		row = &Row{
 			keyType:  keyType,
 			groupval: prim,
@@ -357,6 +374,15 @@ func (a *Aggregator) Consume(batch zbuf.Batch, this *zed.Value) error {
 	} else {
 		row.reducers.apply(a.zctx, a.ectx.Reset(), a.aggs, this)
 	}
+	if size := row.reducers.size(); size != row.size {
+		if err := a.grow(size - row.size); err != nil {
+			return err
+		}
+		row.size = size
+	}
+	if a.nbytes > a.memMax {
+		return a.spillTable(false, batch)
+	}
 	return nil
 }
 
@@ -373,7 +399,7 @@ func (a *Aggregator) spillTable(eof bool, ref zbuf.Batch) error {
 	}
 	recs := batch.Values()
 	// Note that this will sort recs according to g.keysComparator.
-	if err := a.spiller.Spill(a.ctx, recs); err != nil {
+	if err := a.spiller.Spill(a.octx.Context, recs); err != nil {
 		return err
 	}
 	a.ectx.SetVars(ref.Vars())
@@ -388,6 +414,16 @@ func (a *Aggregator) spillTable(eof bool, ref zbuf.Batch) error {
 	return nil
 }
 
+// grow reserves n bytes against the query's memory limit and adds them to
+// the memory used by the table.
+func (a *Aggregator) grow(n int) error {
+	if err := a.octx.ReserveMem(n); err != nil {
+		return err
+	}
+	a.nbytes += n
+	return nil
+}
+
 // updateMaxTableKey is called with a volatile zed.Value to update the
 // max value seen in the table for the streaming logic when the input is sorted.
 func (a *Aggregator) updateMaxTableKey(val *zed.Value) *zed.Value {
@@ -568,6 +604,7 @@ func (a *Aggregator) readTable(flush, partialsOut bool, batch zbuf.Batch) (zbuf.
 		// unnecessarily by holding back the table entries from GC
 		// until this loop finished.
 		delete(a.table, key)
+		a.grow(-(len(key) + row.size))
 	}
 	if len(recs) == 0 {
 		return nil, nil
@@ -25,6 +25,18 @@ func (v valRow) apply(zctx *zed.Context, ectx expr.Context, aggs []*expr.Aggrega
 	}
 }
 
+// size returns the approximate number of bytes used by the state of the
+// functions in v that implement agg.Sizer.
+func (v valRow) size() int {
+	var n int
+	for _, f := range v {
+		if s, ok := f.(agg.Sizer); ok {
+			n += s.Size()
+		}
+	}
+	return n
+}
+
 func (v valRow) consumeAsPartial(rec *zed.Value, exprs []expr.Evaluator, ectx expr.Context) {
 	for k, r := range v {
 		val := exprs[k].Eval(ectx, rec)
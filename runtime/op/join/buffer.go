@@ -0,0 +1,274 @@
+package join
+
+import (
+	"sync"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/op/spill"
+	"github.com/brimdata/zed/zbuf"
+)
+
+// leftBuffer pulls batches from its parent in a goroutine and queues them
+// for its Pull method, so an upstream operator feeding both inputs of a
+// HashOp, such as fork, is never blocked while the righthand input is read.
+// Queued batches are held in memory up to memMax bytes, beyond which they
+// are spilled to disk.
+type leftBuffer struct {
+	octx   *op.Context
+	parent zbuf.Puller
+	memMax int
+	// done is closed when run returns.
+	done chan struct{}
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	chunks []*chunk
+	// nbytes is the size of the batches in chunks.
+	nbytes int
+	closed bool
+	eos    bool
+	err    error
+
+	// spilled is the chunk being read by Pull.
+	spilled *chunk
+	puller  zbuf.Puller
+}
+
+// A chunk is a run of queued batches held either in memory as a single
+// batch or on disk in a spill file.  A chunk with neither is being spilled.
+type chunk struct {
+	batch  zbuf.Batch
+	nbytes int
+	file   *spill.File
+	// vars are the variables of the batches in file.  Variables are
+	// constant within a stream.
+	vars []zed.Value
+}
+
+func newLeftBuffer(octx *op.Context, parent zbuf.Puller, memMax int) *leftBuffer {
+	b := &leftBuffer{
+		octx:   octx,
+		parent: parent,
+		memMax: memMax,
+		done:   make(chan struct{}),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+func (b *leftBuffer) run() {
+	defer close(b.done)
+	defer func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.err != nil {
+			// Pull returns the error instead of the queued
+			// batches, so they are released here.
+			b.releaseLocked()
+		}
+	}()
+	for {
+		batch, err := b.parent.Pull(false)
+		var nbytes int
+		if batch != nil {
+			nbytes = batchSize(batch)
+			if err = b.octx.ReserveMem(nbytes); err != nil {
+				batch.Unref()
+				batch = nil
+			}
+		}
+		b.mu.Lock()
+		if b.closed {
+			b.eos = batch == nil && err == nil
+			b.mu.Unlock()
+			if batch != nil {
+				batch.Unref()
+				b.octx.ReserveMem(-nbytes)
+			}
+			return
+		}
+		if batch == nil {
+			b.eos = err == nil
+			b.err = err
+			b.cond.Broadcast()
+			b.mu.Unlock()
+			return
+		}
+		b.chunks = append(b.chunks, &chunk{batch: batch, nbytes: nbytes})
+		b.nbytes += nbytes
+		var spilling []*chunk
+		var placeholder *chunk
+		if b.nbytes > b.memMax {
+			// Batches in memory always follow any spilled chunks, so
+			// the trailing run of them is replaced by a single chunk
+			// that is filled in once they are spilled.
+			i := len(b.chunks)
+			for i > 0 && b.chunks[i-1].batch != nil {
+				i--
+			}
+			spilling = append(spilling, b.chunks[i:]...)
+			placeholder = &chunk{}
+			b.chunks = append(b.chunks[:i], placeholder)
+		}
+		b.cond.Broadcast()
+		b.mu.Unlock()
+		if placeholder != nil {
+			if err := b.spill(placeholder, spilling); err != nil {
+				b.mu.Lock()
+				b.err = err
+				b.cond.Broadcast()
+				b.mu.Unlock()
+				return
+			}
+		}
+	}
+}
+
+// spill writes the batches of chunks to a spill file and stores the file in
+// placeholder.
+func (b *leftBuffer) spill(placeholder *chunk, chunks []*chunk) error {
+	var nbytes int
+	defer func() {
+		b.mu.Lock()
+		b.nbytes -= nbytes
+		b.mu.Unlock()
+		b.octx.ReserveMem(-nbytes)
+	}()
+	file, err := spill.NewTempFile()
+	if err != nil {
+		for _, c := range chunks {
+			c.batch.Unref()
+			nbytes += c.nbytes
+		}
+		return err
+	}
+	placeholder.vars = zbuf.CopyVars(chunks[0].batch)
+	for _, c := range chunks {
+		if err == nil {
+			err = zbuf.WriteBatch(file, c.batch)
+		}
+		c.batch.Unref()
+		nbytes += c.nbytes
+	}
+	if err == nil {
+		err = file.Rewind(b.octx.Zctx)
+	}
+	if err != nil {
+		file.CloseAndRemove()
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		file.CloseAndRemove()
+		return nil
+	}
+	placeholder.file = file
+	b.cond.Broadcast()
+	return nil
+}
+
+// Pull returns the queued batches in order followed by end of stream.  If
+// done is true, Pull discards the queued batches and, if the parent has not
+// reached end of stream, passes done to the parent.
+func (b *leftBuffer) Pull(done bool) (zbuf.Batch, error) {
+	if done {
+		b.abandon()
+		<-b.done
+		if b.eos || b.err != nil {
+			return nil, nil
+		}
+		return b.parent.Pull(true)
+	}
+	for {
+		if b.puller != nil {
+			batch, err := b.puller.Pull(false)
+			if err != nil {
+				return nil, err
+			}
+			if batch != nil {
+				return &varsBatch{batch, b.spilled.vars}, nil
+			}
+			b.spilled.file.CloseAndRemove()
+			b.spilled = nil
+			b.puller = nil
+		}
+		b.mu.Lock()
+		for {
+			if b.err != nil {
+				err := b.err
+				b.mu.Unlock()
+				return nil, err
+			}
+			if len(b.chunks) > 0 && (b.chunks[0].batch != nil || b.chunks[0].file != nil) {
+				break
+			}
+			if len(b.chunks) == 0 && b.eos {
+				b.mu.Unlock()
+				return nil, nil
+			}
+			b.cond.Wait()
+		}
+		c := b.chunks[0]
+		b.chunks = b.chunks[1:]
+		if c.batch != nil {
+			b.nbytes -= c.nbytes
+			b.mu.Unlock()
+			b.octx.ReserveMem(-c.nbytes)
+			return c.batch, nil
+		}
+		b.mu.Unlock()
+		b.spilled = c
+		b.puller = zbuf.NewPuller(c.file)
+	}
+}
+
+// abandon stops buffering and releases the queued batches and spill files
+// without waiting for a pull of the parent in progress to complete.  The
+// receiver's goroutine exits once that pull returns.
+func (b *leftBuffer) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.releaseLocked()
+	if b.spilled != nil {
+		b.spilled.file.CloseAndRemove()
+		b.spilled = nil
+		b.puller = nil
+	}
+}
+
+// releaseLocked releases the queued batches and spill files.  It must be
+// called with b.mu held.
+func (b *leftBuffer) releaseLocked() {
+	for _, c := range b.chunks {
+		if c.batch != nil {
+			c.batch.Unref()
+			b.octx.ReserveMem(-c.nbytes)
+		}
+		if c.file != nil {
+			c.file.CloseAndRemove()
+		}
+	}
+	b.chunks = nil
+	b.nbytes = 0
+}
+
+func batchSize(batch zbuf.Batch) int {
+	var n int
+	for _, val := range batch.Values() {
+		n += len(val.Bytes())
+	}
+	return n
+}
+
+// varsBatch is a batch read from a spill file along with the variables of
+// the batches written to it.
+type varsBatch struct {
+	zbuf.Batch
+	vars []zed.Value
+}
+
+func (b *varsBatch) Vars() []zed.Value { return b.vars }
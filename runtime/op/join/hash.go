@@ -0,0 +1,383 @@
+package join
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/expr/coerce"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/op/spill"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+)
+
+// HashMemMaxBytes specifies the maximum amount of memory that the hash table
+// of each hash join will consume.  A hash join whose righthand input does
+// not fit falls back to a sort-merge join.  If HashMemMaxBytes is zero, joins
+// of unsorted inputs use a sort-merge join.
+var HashMemMaxBytes = 128 * 1024 * 1024
+
+// HashOp is a join that builds a hash table from its righthand input then
+// streams its lefthand input through the table, so neither input need be
+// sorted.  Lefthand values are output in input order.  For a full join,
+// righthand values without a match are output after the lefthand input is
+// exhausted.
+//
+// While the table is built, the lefthand input is buffered so that an
+// upstream operator feeding both inputs, such as fork, is not blocked.
+type HashOp struct {
+	octx         *op.Context
+	anti         bool
+	inner        bool
+	full         bool
+	left         zbuf.Puller
+	right        zbuf.Puller
+	buffer       *leftBuffer
+	getLeftKeys  []expr.Evaluator
+	getRightKeys []expr.Evaluator
+	leftDir      order.Direction
+	memMax       int
+	lhs          []*expr.Lval
+	rhs          []expr.Evaluator
+	keyLvals     []*expr.Lval
+	ectx         expr.ResetContext
+	keys         []*zed.Value
+	keyBytes     []byte
+	nbytes       int
+	table        map[string]*hashEntry
+	// entries holds the values of table in insertion order.
+	entries []*hashEntry
+	built   bool
+	eos     bool
+	// merge is the sort-merge join to which the receiver falls back when
+	// its hash table exceeds memMax.
+	merge *Op
+	*splicer
+}
+
+type hashEntry struct {
+	vals    []*zed.Value
+	matched bool
+}
+
+// NewHash returns a hash join with the same semantics as the sort-merge
+// join returned by New.  The hash table and the lefthand values buffered
+// while it is built are each limited to memMax bytes.  leftDir is used only
+// when falling back to a sort-merge join.
+func NewHash(octx *op.Context, anti, inner, full bool, left, right zbuf.Puller, leftKeys, rightKeys []expr.Evaluator,
+	leftDir order.Direction, lhs []*expr.Lval, rhs []expr.Evaluator, keyLvals []*expr.Lval, memMax int) (*HashOp, error) {
+	if len(leftKeys) == 0 || len(leftKeys) != len(rightKeys) {
+		return nil, errors.New("join: left and right keys must be paired")
+	}
+	return &HashOp{
+		octx:         octx,
+		anti:         anti,
+		inner:        inner,
+		full:         full,
+		left:         left,
+		right:        right,
+		getLeftKeys:  leftKeys,
+		getRightKeys: rightKeys,
+		leftDir:      leftDir,
+		memMax:       memMax,
+		lhs:          lhs,
+		rhs:          rhs,
+		keyLvals:     keyLvals,
+		splicer:      newSplicer(octx.Zctx, lhs, rhs, keyLvals, rightKeys),
+	}, nil
+}
+
+func (o *HashOp) Pull(done bool) (zbuf.Batch, error) {
+	if !o.built {
+		o.built = true
+		if err := o.build(); err != nil {
+			return nil, err
+		}
+	}
+	if o.merge != nil {
+		batch, err := o.merge.Pull(done)
+		if batch == nil && err == nil {
+			o.reset()
+		}
+		return batch, err
+	}
+	for {
+		batch, err := o.buffer.Pull(done)
+		if err != nil {
+			return nil, err
+		}
+		if batch == nil {
+			if o.full && !o.eos && !done {
+				o.eos = true
+				if out := o.unmatched(); len(out) > 0 {
+					return zbuf.NewArray(out), nil
+				}
+			}
+			o.reset()
+			return nil, nil
+		}
+		// Safe because batch.Unref is never called.
+		out, err := o.probe(batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > 0 {
+			return zbuf.NewBatch(batch, out), nil
+		}
+	}
+}
+
+func (o *HashOp) reset() {
+	o.built = false
+	o.eos = false
+	o.release()
+	o.table = nil
+	o.entries = nil
+	o.merge = nil
+	o.buffer = nil
+}
+
+// release releases the memory reserved for the hash table.
+func (o *HashOp) release() {
+	o.octx.ReserveMem(-o.nbytes)
+	o.nbytes = 0
+}
+
+// build reads the righthand input into the hash table.  If the table
+// exceeds memMax, build spills it and arranges for the remainder of the
+// join to be handled by a sort-merge join.
+func (o *HashOp) build() (err error) {
+	o.table = make(map[string]*hashEntry)
+	o.buffer = newLeftBuffer(o.octx, o.left, o.memMax)
+	defer func() {
+		if err != nil {
+			o.buffer.abandon()
+			o.release()
+		}
+	}()
+	for {
+		batch, err := o.right.Pull(false)
+		if err != nil {
+			return err
+		}
+		if batch == nil {
+			return nil
+		}
+		o.ectx.SetVars(batch.Vars())
+		nbytes := o.nbytes
+		vals := batch.Values()
+		for i := range vals {
+			var ok bool
+			o.keys, ok = evalKeys(o.ectx.Reset(), o.getRightKeys, &vals[i], o.keys)
+			if !ok {
+				// As in the sort-merge join, righthand values
+				// without keys are dropped.
+				continue
+			}
+			key := o.hashKey(o.keys)
+			entry, ok := o.table[string(key)]
+			if !ok {
+				entry = &hashEntry{}
+				o.table[string(key)] = entry
+				o.entries = append(o.entries, entry)
+				o.nbytes += len(key)
+			}
+			val := vals[i].Copy()
+			entry.vals = append(entry.vals, val)
+			o.nbytes += len(val.Bytes())
+		}
+		batch.Unref()
+		if err := o.octx.ReserveMem(o.nbytes - nbytes); err != nil {
+			o.nbytes = nbytes
+			return err
+		}
+		if o.nbytes > o.memMax {
+			return o.fallback()
+		}
+	}
+}
+
+// fallback writes the hash table to a spill file and creates a sort-merge
+// join whose righthand input is the spill file followed by the rest of the
+// righthand input.
+func (o *HashOp) fallback() error {
+	file, err := spill.NewTempFile()
+	if err != nil {
+		return err
+	}
+	for _, entry := range o.entries {
+		for _, val := range entry.vals {
+			if err := file.Write(val); err != nil {
+				file.CloseAndRemove()
+				return err
+			}
+		}
+	}
+	o.table = nil
+	o.entries = nil
+	o.release()
+	if err := file.Rewind(o.octx.Zctx); err != nil {
+		file.CloseAndRemove()
+		return err
+	}
+	spilled := newSpillReader(o.octx, file)
+	right := zbuf.NewPuller(zio.ConcatReader(spilled, zbuf.PullerReader(o.right)))
+	o.merge, err = New(o.octx, o.anti, o.inner, o.full, o.buffer, right, o.getLeftKeys, o.getRightKeys, o.leftDir, order.Unknown, o.lhs, o.rhs, o.keyLvals)
+	if err != nil {
+		spilled.close()
+	}
+	return err
+}
+
+func (o *HashOp) probe(batch zbuf.Batch) ([]zed.Value, error) {
+	o.ectx.SetVars(batch.Vars())
+	var out []zed.Value
+	vals := batch.Values()
+	for i := range vals {
+		left := &vals[i]
+		var ok bool
+		o.keys, ok = evalKeys(o.ectx.Reset(), o.getLeftKeys, left, o.keys)
+		if !ok {
+			// As in the sort-merge join, lefthand values without
+			// keys are dropped.
+			continue
+		}
+		entry := o.table[string(o.hashKey(o.keys))]
+		if entry == nil {
+			if !o.inner {
+				out = append(out, *left)
+			}
+			continue
+		}
+		entry.matched = true
+		if o.anti {
+			continue
+		}
+		for _, right := range entry.vals {
+			cut := o.cutter.Eval(o.ectx.Reset(), right)
+			val, err := o.splice(&o.ectx, left, cut)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, *val)
+		}
+	}
+	return out, nil
+}
+
+// unmatched returns the righthand values of a full join that did not match
+// any lefthand value.
+func (o *HashOp) unmatched() []zed.Value {
+	var out []zed.Value
+	for _, entry := range o.entries {
+		if entry.matched {
+			continue
+		}
+		for _, val := range entry.vals {
+			out = append(out, *o.unmatchedRight(val))
+		}
+	}
+	return out
+}
+
+// hashKey returns the hash table key for keys.
+func (o *HashOp) hashKey(keys []*zed.Value) []byte {
+	b := o.keyBytes[:0]
+	for _, key := range keys {
+		b = appendHashKey(b, key)
+	}
+	o.keyBytes = b
+	return b
+}
+
+// appendHashKey appends an encoding of val to b.  Numbers are normalized so
+// values that the sort-merge join considers equal after coercion, such as
+// 1(int32), 1(uint8), and 1., have the same encoding, and nulls of any type
+// have the same encoding.
+func appendHashKey(b []byte, val *zed.Value) []byte {
+	if val.IsNull() {
+		b = binary.AppendUvarint(b, zed.IDNull)
+		return zcode.Append(b, nil)
+	}
+	id := val.Type.ID()
+	bytes := val.Bytes()
+	switch {
+	case zed.IsFloat(id):
+		f, _ := coerce.ToFloat(val)
+		if math.Abs(f) < math.MaxInt64 && f == math.Trunc(f) {
+			id, bytes = zed.IDInt64, zed.EncodeInt(int64(f))
+		} else {
+			id, bytes = zed.IDFloat64, zed.EncodeFloat64(f)
+		}
+	case zed.IsSigned(id):
+		id = zed.IDInt64
+	case zed.IsUnsigned(id):
+		if u := zed.DecodeUint(bytes); u <= math.MaxInt64 {
+			id, bytes = zed.IDInt64, zed.EncodeInt(int64(u))
+		} else {
+			id = zed.IDUint64
+		}
+	}
+	b = binary.AppendUvarint(b, uint64(id))
+	return zcode.Append(b, bytes)
+}
+
+// spillReader reads the hash table spilled by HashOp.fallback and removes
+// the spill file at end of stream or when the query is canceled.
+type spillReader struct {
+	mu     sync.Mutex
+	file   *spill.File
+	closed bool
+	done   chan struct{}
+}
+
+func newSpillReader(octx *op.Context, file *spill.File) *spillReader {
+	r := &spillReader{
+		file: file,
+		done: make(chan struct{}),
+	}
+	// Block octx's cancel function until the spill file is removed.
+	octx.WaitGroup.Add(1)
+	go func() {
+		defer octx.WaitGroup.Done()
+		select {
+		case <-r.done:
+		case <-octx.Done():
+			r.close()
+		}
+	}()
+	return r
+}
+
+func (r *spillReader) Read() (*zed.Value, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, nil
+	}
+	val, err := r.file.Read()
+	if val == nil || err != nil {
+		r.closeLocked()
+	}
+	return val, err
+}
+
+func (r *spillReader) close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeLocked()
+}
+
+func (r *spillReader) closeLocked() {
+	if !r.closed {
+		r.closed = true
+		r.file.CloseAndRemove()
+		close(r.done)
+	}
+}
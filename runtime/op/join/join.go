@@ -2,7 +2,7 @@ package join
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"sync"
 
 	"github.com/brimdata/zed"
@@ -15,27 +15,50 @@ import (
 )
 
 type Op struct {
-	octx        *op.Context
-	anti        bool
-	inner       bool
-	ctx         context.Context
-	cancel      context.CancelFunc
-	once        sync.Once
-	left        *puller
-	right       *zio.Peeker
-	getLeftKey  expr.Evaluator
-	getRightKey expr.Evaluator
-	compare     expr.CompareFn
-	cutter      *expr.Cutter
-	joinKey     *zed.Value
-	joinSet     []*zed.Value
-	tmpLeft     zed.Value
-	types       map[int]map[int]*zed.TypeRecord
+	octx         *op.Context
+	anti         bool
+	inner        bool
+	full         bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	once         sync.Once
+	left         *puller
+	right        *zio.Peeker
+	getLeftKeys  []expr.Evaluator
+	getRightKeys []expr.Evaluator
+	leftKeys     []*zed.Value
+	rightKeys    []*zed.Value
+	compare      expr.CompareFn
+	joinKey      *zed.Value
+	joinSet      []*entry
+	joinBytes    int
+	matches      []*zed.Value
+	unmatched    []zed.Value
+	*splicer
 }
 
-func New(octx *op.Context, anti, inner bool, left, right zbuf.Puller, leftKey, rightKey expr.Evaluator,
+// An entry is a righthand value in the join set along with its keys.
+type entry struct {
+	val     *zed.Value
+	keys    []*zed.Value
+	matched bool
+}
+
+// New returns a merge join of left and right on the equality of each
+// expression in leftKeys with its counterpart in rightKeys.  Inputs not
+// sorted by their keys as indicated by leftDir and rightDir are sorted.
+// The direction of an input need only describe the order of its first
+// key since values are merged on the first key and matched on the rest.
+// For a full join, right values without a match are output along with
+// the left values without a match and, if there are assignments, keep each
+// right key in the field given by its counterpart in keyLvals unless that
+// is nil.
+func New(octx *op.Context, anti, inner, full bool, left, right zbuf.Puller, leftKeys, rightKeys []expr.Evaluator,
 	leftDir, rightDir order.Direction, lhs []*expr.Lval,
-	rhs []expr.Evaluator) (*Op, error) {
+	rhs []expr.Evaluator, keyLvals []*expr.Lval) (*Op, error) {
+	if len(leftKeys) == 0 || len(leftKeys) != len(rightKeys) {
+		return nil, errors.New("join: left and right keys must be paired")
+	}
 	var o order.Which
 	switch {
 	case leftDir != order.Unknown:
@@ -46,31 +69,31 @@ func New(octx *op.Context, anti, inner bool, left, right zbuf.Puller, leftKey, r
 	var err error
 	// Add sorts if needed.
 	if !leftDir.HasOrder(o) {
-		left, err = sort.New(octx, left, []expr.Evaluator{leftKey}, o, false)
+		left, err = sort.New(octx, left, leftKeys, o, false)
 		if err != nil {
 			return nil, err
 		}
 	}
 	if !rightDir.HasOrder(o) {
-		right, err = sort.New(octx, right, []expr.Evaluator{rightKey}, o, false)
+		right, err = sort.New(octx, right, rightKeys, o, false)
 		if err != nil {
 			return nil, err
 		}
 	}
 	ctx, cancel := context.WithCancel(octx.Context)
 	return &Op{
-		octx:        octx,
-		anti:        anti,
-		inner:       inner,
-		ctx:         ctx,
-		cancel:      cancel,
-		getLeftKey:  leftKey,
-		getRightKey: rightKey,
-		left:        newPuller(left, ctx),
-		right:       zio.NewPeeker(newPuller(right, ctx)),
-		compare:     expr.NewValueCompareFn(o, true),
-		cutter:      expr.NewCutter(octx.Zctx, lhs, rhs),
-		types:       make(map[int]map[int]*zed.TypeRecord),
+		octx:         octx,
+		anti:         anti,
+		inner:        inner,
+		full:         full,
+		ctx:          ctx,
+		cancel:       cancel,
+		getLeftKeys:  leftKeys,
+		getRightKeys: rightKeys,
+		left:         newPuller(left, ctx),
+		right:        zio.NewPeeker(newPuller(right, ctx)),
+		compare:      expr.NewValueCompareFn(o, true),
+		splicer:      newSplicer(octx.Zctx, lhs, rhs, keyLvals, rightKeys),
 	}, nil
 }
 
@@ -90,23 +113,40 @@ func (o *Op) Pull(done bool) (zbuf.Batch, error) {
 			return nil, err
 		}
 		if leftRec == nil {
+			o.retireJoinSet()
+			if o.full {
+				// Output the righthand values that follow the
+				// last lefthand key.
+				if err := o.readUnmatched(); err != nil {
+					return nil, err
+				}
+				out = append(out, o.unmatched...)
+				o.unmatched = o.unmatched[:0]
+			}
 			if len(out) == 0 {
 				return nil, nil
 			}
 			//XXX See issue #3427.
 			return zbuf.NewArray(out), nil
 		}
-		key := o.getLeftKey.Eval(ectx.Reset(), leftRec)
-		if key.IsMissing() {
-			// If the left key isn't present (which is not a thing
+		var ok bool
+		o.leftKeys, ok = evalKeys(ectx.Reset(), o.getLeftKeys, leftRec, o.leftKeys)
+		if !ok {
+			// If a left key isn't present (which is not a thing
 			// in a sql join), then drop the record and return only
-			// left records that can eval the key expression.
+			// left records that can eval the key expressions.
 			continue
 		}
-		rightRecs, err := o.getJoinSet(key)
+		rightRecs, err := o.getJoinSet(o.leftKeys)
 		if err != nil {
 			return nil, err
 		}
+		if len(o.unmatched) != 0 {
+			// Righthand values skipped by getJoinSet precede
+			// this lefthand value in the join order.
+			out = append(out, o.unmatched...)
+			o.unmatched = o.unmatched[:0]
+		}
 		if rightRecs == nil {
 			// Nothing to add to the left join.
 			// Accumulate this record for an outer join.
@@ -138,10 +178,15 @@ func (o *Op) Pull(done bool) (zbuf.Batch, error) {
 	}
 }
 
-func (o *Op) getJoinSet(leftKey *zed.Value) ([]*zed.Value, error) {
-	if o.joinKey != nil && o.compare(leftKey, o.joinKey) == 0 {
-		return o.joinSet, nil
+// getJoinSet returns the righthand values whose keys equal leftKeys.
+// The righthand stream is merged with the lefthand stream on the first key
+// alone, so the join set holds all righthand values whose first key equals
+// that of leftKeys and is searched for values that match the rest.
+func (o *Op) getJoinSet(leftKeys []*zed.Value) ([]*zed.Value, error) {
+	if o.joinKey != nil && o.compare(leftKeys[0], o.joinKey) == 0 {
+		return o.match(leftKeys), nil
 	}
+	o.retireJoinSet()
 	// See #3366
 	var ectx expr.ResetContext
 	for {
@@ -149,21 +194,22 @@ func (o *Op) getJoinSet(leftKey *zed.Value) ([]*zed.Value, error) {
 		if err != nil || rec == nil {
 			return nil, err
 		}
-		rightKey := o.getRightKey.Eval(ectx.Reset(), rec)
-		if rightKey.IsMissing() {
+		var ok bool
+		o.rightKeys, ok = evalKeys(ectx.Reset(), o.getRightKeys, rec, o.rightKeys)
+		if !ok {
 			o.right.Read()
 			continue
 		}
-		cmp := o.compare(leftKey, rightKey)
+		cmp := o.compare(leftKeys[0], o.rightKeys[0])
 		if cmp == 0 {
-			// Copy leftKey.Bytes since it might get reused.
-			if o.joinKey == nil {
-				o.joinKey = leftKey.Copy()
-			} else {
-				o.joinKey.CopyFrom(leftKey)
-			}
+			// Copy the key since its bytes might get reused.
+			o.joinKey = leftKeys[0].Copy()
 			o.joinSet, err = o.readJoinSet(o.joinKey)
-			return o.joinSet, err
+			if err != nil {
+				o.retireJoinSet()
+				return nil, err
+			}
+			return o.match(leftKeys), nil
 		}
 		if cmp < 0 {
 			// If the left key is smaller than the next eligible
@@ -174,100 +220,109 @@ func (o *Op) getJoinSet(leftKey *zed.Value) ([]*zed.Value, error) {
 		// Discard the peeked-at record and keep looking for
 		// a righthand key that either matches or exceeds the
 		// lefthand key.
+		if o.full {
+			o.unmatched = append(o.unmatched, *o.unmatchedRight(rec))
+		}
 		o.right.Read()
 	}
 }
 
-// fillJoinSet is called when a join key has been found that matches
-// the current lefthand key.  It returns the all the subsequent records
-// from the righthand stream that match this key.
-func (o *Op) readJoinSet(joinKey *zed.Value) ([]*zed.Value, error) {
-	var recs []*zed.Value
-	// See #3366
-	var ectx expr.ResetContext
-	for {
-		rec, err := o.right.Peek()
-		if err != nil {
-			return nil, err
-		}
-		if rec == nil {
-			return recs, nil
-		}
-		key := o.getRightKey.Eval(ectx.Reset(), rec)
-		if key.IsMissing() {
-			o.right.Read()
-			continue
+// match returns the values in the join set whose keys equal leftKeys or
+// nil if there are none.
+func (o *Op) match(leftKeys []*zed.Value) []*zed.Value {
+	o.matches = o.matches[:0]
+	for _, e := range o.joinSet {
+		if o.compareKeys(e.keys, leftKeys) == 0 {
+			e.matched = true
+			o.matches = append(o.matches, e.val)
 		}
-		if o.compare(key, joinKey) != 0 {
-			return recs, nil
-		}
-		recs = append(recs, rec.Copy())
-		o.right.Read()
 	}
-}
-
-func (o *Op) lookupType(left, right *zed.TypeRecord) *zed.TypeRecord {
-	if table, ok := o.types[left.ID()]; ok {
-		return table[right.ID()]
+	if len(o.matches) == 0 {
+		return nil
 	}
-	return nil
+	return o.matches
 }
 
-func (o *Op) enterType(combined, left, right *zed.TypeRecord) {
-	id := left.ID()
-	table := o.types[id]
-	if table == nil {
-		table = make(map[int]*zed.TypeRecord)
-		o.types[id] = table
+// retireJoinSet discards the join set.  For a full join, the values in the
+// set that matched no lefthand value are moved to o.unmatched.
+func (o *Op) retireJoinSet() {
+	if o.full {
+		for _, e := range o.joinSet {
+			if !e.matched {
+				o.unmatched = append(o.unmatched, *o.unmatchedRight(e.val))
+			}
+		}
 	}
-	table[right.ID()] = combined
+	o.joinKey = nil
+	o.joinSet = nil
+	o.octx.ReserveMem(-o.joinBytes)
+	o.joinBytes = 0
 }
 
-func (o *Op) buildType(left, right *zed.TypeRecord) (*zed.TypeRecord, error) {
-	fields := make([]zed.Field, 0, len(left.Fields)+len(right.Fields))
-	fields = append(fields, left.Fields...)
-	for _, f := range right.Fields {
-		name := f.Name
-		for k := 2; left.HasField(name); k++ {
-			name = fmt.Sprintf("%s_%d", f.Name, k)
+// readUnmatched reads the remainder of the righthand stream into
+// o.unmatched.  It is called for a full join once the lefthand stream
+// is exhausted.
+func (o *Op) readUnmatched() error {
+	// See #3366
+	var ectx expr.ResetContext
+	for {
+		rec, err := o.right.Read()
+		if err != nil || rec == nil {
+			return err
 		}
-		fields = append(fields, zed.NewField(name, f.Type))
+		var ok bool
+		o.rightKeys, ok = evalKeys(ectx.Reset(), o.getRightKeys, rec, o.rightKeys)
+		if !ok {
+			continue
+		}
+		o.unmatched = append(o.unmatched, *o.unmatchedRight(rec))
 	}
-	return o.octx.Zctx.LookupTypeRecord(fields)
 }
 
-func (o *Op) combinedType(left, right *zed.TypeRecord) (*zed.TypeRecord, error) {
-	if typ := o.lookupType(left, right); typ != nil {
-		return typ, nil
-	}
-	typ, err := o.buildType(left, right)
-	if err != nil {
-		return nil, err
+func (o *Op) compareKeys(a, b []*zed.Value) int {
+	for k := range a {
+		if cmp := o.compare(a[k], b[k]); cmp != 0 {
+			return cmp
+		}
 	}
-	o.enterType(typ, left, right)
-	return typ, nil
+	return 0
 }
 
-func (o *Op) splice(ectx *expr.ResetContext, left, right *zed.Value) (*zed.Value, error) {
-	if right == nil {
-		// This happens on a simple join, i.e., "join key",
-		// where there are no cut expressions.  For left joins,
-		// this does nothing, but for inner joins, it will
-		// filter the lefthand stream by what's in the righthand
-		// stream.
-		return left, nil
-	}
-	// tmpLeft lives in Op because the 1.20.6 compiler thinks it escapes.
-	left = left.Under(&o.tmpLeft)
-	var tmpRight zed.Value
-	right = right.Under(&tmpRight)
-	typ, err := o.combinedType(zed.TypeRecordOf(left.Type), zed.TypeRecordOf(right.Type))
-	if err != nil {
-		return nil, err
+// readJoinSet is called when a join key has been found that matches
+// the first key of the current lefthand value.  It returns all the
+// subsequent records from the righthand stream whose first key matches
+// and reserves their memory in o.joinBytes.
+func (o *Op) readJoinSet(joinKey *zed.Value) ([]*entry, error) {
+	var entries []*entry
+	// See #3366
+	var ectx expr.ResetContext
+	for {
+		rec, err := o.right.Peek()
+		if err != nil {
+			return nil, err
+		}
+		if rec == nil {
+			return entries, nil
+		}
+		var ok bool
+		o.rightKeys, ok = evalKeys(ectx.Reset(), o.getRightKeys, rec, o.rightKeys)
+		if !ok {
+			o.right.Read()
+			continue
+		}
+		if o.compare(o.rightKeys[0], joinKey) != 0 {
+			return entries, nil
+		}
+		keys := make([]*zed.Value, 0, len(o.rightKeys))
+		for _, key := range o.rightKeys {
+			keys = append(keys, key.Copy())
+		}
+		val := rec.Copy()
+		if err := o.octx.ReserveMem(len(val.Bytes())); err != nil {
+			return nil, err
+		}
+		o.joinBytes += len(val.Bytes())
+		entries = append(entries, &entry{val: val, keys: keys})
+		o.right.Read()
 	}
-	n := len(left.Bytes())
-	bytes := make([]byte, n+len(right.Bytes()))
-	copy(bytes, left.Bytes())
-	copy(bytes[n:], right.Bytes())
-	return ectx.NewValue(typ, bytes), nil
 }
@@ -0,0 +1,79 @@
+package join
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/stretchr/testify/require"
+)
+
+func newPullerOf(zctx *zed.Context, zson string) zbuf.Puller {
+	return zbuf.NewPuller(zsonio.NewReader(zctx, strings.NewReader(zson)))
+}
+
+func pullAll(p zbuf.Puller) error {
+	for {
+		batch, err := p.Pull(false)
+		if batch == nil || err != nil {
+			return err
+		}
+		batch.Unref()
+	}
+}
+
+// errPuller returns a batch of {a:1} followed by an error.
+type errPuller struct {
+	zctx   *zed.Context
+	pulled bool
+}
+
+func (p *errPuller) Pull(bool) (zbuf.Batch, error) {
+	if p.pulled {
+		return nil, errors.New("pull error")
+	}
+	p.pulled = true
+	return newPullerOf(p.zctx, "{a:1}").Pull(false)
+}
+
+func TestJoinSetMemLimit(t *testing.T) {
+	var right strings.Builder
+	for k := 0; k < 10; k++ {
+		fmt.Fprintf(&right, "{b:1,s:%q}\n", strings.Repeat("x", 100))
+	}
+	run := func(limit int64) (*op.Context, error) {
+		octx := op.DefaultContext()
+		octx.SetLimits(op.Limits{MemMaxBytes: limit})
+		zctx := octx.Zctx
+		leftKeys := []expr.Evaluator{expr.NewDottedExpr(zctx, field.Path{"a"})}
+		rightKeys := []expr.Evaluator{expr.NewDottedExpr(zctx, field.Path{"b"})}
+		j, err := New(octx, false, true, false, newPullerOf(zctx, "{a:1}"), newPullerOf(zctx, right.String()),
+			leftKeys, rightKeys, order.Up, order.Up, nil, nil, nil)
+		require.NoError(t, err)
+		return octx, pullAll(j)
+	}
+	var limitErr *op.LimitError
+	_, err := run(500)
+	require.ErrorAs(t, err, &limitErr)
+	// The join set is released at the end of the join.
+	octx, err := run(5000)
+	require.NoError(t, err)
+	require.NoError(t, octx.ReserveMem(5000))
+}
+
+func TestLeftBufferReleasesOnError(t *testing.T) {
+	octx := op.DefaultContext()
+	octx.SetLimits(op.Limits{MemMaxBytes: 1000})
+	b := newLeftBuffer(octx, &errPuller{zctx: octx.Zctx}, 1000)
+	<-b.done
+	require.EqualError(t, pullAll(b), "pull error")
+	require.NoError(t, octx.ReserveMem(1000))
+}
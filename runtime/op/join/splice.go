@@ -0,0 +1,135 @@
+package join
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+)
+
+// splicer combines a lefthand value with the fields assigned from a
+// matching righthand value.  It is shared by the merge and hash joins.
+type splicer struct {
+	zctx      *zed.Context
+	cutter    *expr.Cutter
+	unmatched *expr.Cutter
+	hasCut    bool
+	tmpLeft   zed.Value
+	types     map[int]map[int]*zed.TypeRecord
+}
+
+// newSplicer returns a splicer for the assignments of lhs from rhs.  A
+// righthand value with no match keeps each of rightKeys in the field given
+// by its counterpart in keyLvals unless that is nil.
+func newSplicer(zctx *zed.Context, lhs []*expr.Lval, rhs []expr.Evaluator, keyLvals []*expr.Lval, rightKeys []expr.Evaluator) *splicer {
+	var unmatchedLhs []*expr.Lval
+	var unmatchedRhs []expr.Evaluator
+	for k, lval := range keyLvals {
+		if lval != nil {
+			unmatchedLhs = append(unmatchedLhs, lval)
+			unmatchedRhs = append(unmatchedRhs, rightKeys[k])
+		}
+	}
+	unmatchedLhs = append(unmatchedLhs, lhs...)
+	unmatchedRhs = append(unmatchedRhs, rhs...)
+	return &splicer{
+		zctx:      zctx,
+		cutter:    expr.NewCutter(zctx, lhs, rhs),
+		unmatched: expr.NewCutter(zctx, unmatchedLhs, unmatchedRhs),
+		hasCut:    len(lhs) != 0,
+		types:     make(map[int]map[int]*zed.TypeRecord),
+	}
+}
+
+// unmatchedRight returns a copy of the value output for a righthand value
+// with no matching lefthand value, which comprises its keys and the fields
+// assigned from it or, if there are no assignments, the righthand value
+// itself.
+func (s *splicer) unmatchedRight(rec *zed.Value) *zed.Value {
+	if !s.hasCut {
+		return rec.Copy()
+	}
+	// See #3366
+	var ectx expr.ResetContext
+	return s.unmatched.Eval(&ectx, rec).Copy()
+}
+
+// evalKeys evaluates exprs on val, storing the results in keys.  It returns
+// false if any of the results is missing.
+func evalKeys(ectx expr.Context, exprs []expr.Evaluator, val *zed.Value, keys []*zed.Value) ([]*zed.Value, bool) {
+	keys = keys[:0]
+	for _, e := range exprs {
+		key := e.Eval(ectx, val)
+		if key.IsMissing() {
+			return keys, false
+		}
+		keys = append(keys, key)
+	}
+	return keys, true
+}
+
+func (s *splicer) lookupType(left, right *zed.TypeRecord) *zed.TypeRecord {
+	if table, ok := s.types[left.ID()]; ok {
+		return table[right.ID()]
+	}
+	return nil
+}
+
+func (s *splicer) enterType(combined, left, right *zed.TypeRecord) {
+	id := left.ID()
+	table := s.types[id]
+	if table == nil {
+		table = make(map[int]*zed.TypeRecord)
+		s.types[id] = table
+	}
+	table[right.ID()] = combined
+}
+
+func (s *splicer) buildType(left, right *zed.TypeRecord) (*zed.TypeRecord, error) {
+	fields := make([]zed.Field, 0, len(left.Fields)+len(right.Fields))
+	fields = append(fields, left.Fields...)
+	for _, f := range right.Fields {
+		name := f.Name
+		for k := 2; left.HasField(name); k++ {
+			name = fmt.Sprintf("%s_%d", f.Name, k)
+		}
+		fields = append(fields, zed.NewField(name, f.Type))
+	}
+	return s.zctx.LookupTypeRecord(fields)
+}
+
+func (s *splicer) combinedType(left, right *zed.TypeRecord) (*zed.TypeRecord, error) {
+	if typ := s.lookupType(left, right); typ != nil {
+		return typ, nil
+	}
+	typ, err := s.buildType(left, right)
+	if err != nil {
+		return nil, err
+	}
+	s.enterType(typ, left, right)
+	return typ, nil
+}
+
+func (s *splicer) splice(ectx *expr.ResetContext, left, right *zed.Value) (*zed.Value, error) {
+	if right == nil {
+		// This happens on a simple join, i.e., "join key",
+		// where there are no cut expressions.  For left joins,
+		// this does nothing, but for inner joins, it will
+		// filter the lefthand stream by what's in the righthand
+		// stream.
+		return left, nil
+	}
+	// tmpLeft lives in splicer because the 1.20.6 compiler thinks it escapes.
+	left = left.Under(&s.tmpLeft)
+	var tmpRight zed.Value
+	right = right.Under(&tmpRight)
+	typ, err := s.combinedType(zed.TypeRecordOf(left.Type), zed.TypeRecordOf(right.Type))
+	if err != nil {
+		return nil, err
+	}
+	n := len(left.Bytes())
+	bytes := make([]byte, n+len(right.Bytes()))
+	copy(bytes, left.Bytes())
+	copy(bytes[n:], right.Bytes())
+	return ectx.NewValue(typ, bytes), nil
+}
@@ -0,0 +1,45 @@
+package op
+
+import (
+	"fmt"
+	"time"
+)
+
+// Limits bounds the resources that a query may consume.  A zero value for
+// any field means no limit.
+type Limits struct {
+	// MemMaxBytes limits the memory held at once by the sort, groupby,
+	// and join operators of a query.
+	MemMaxBytes int64
+	// RowsMax limits the number of values output by a query.
+	RowsMax int64
+	// Timeout limits the wall-clock duration of a query.
+	Timeout time.Duration
+	// ScanMaxBytes limits the number of bytes read from storage by the
+	// scanners of a query.
+	ScanMaxBytes int64
+}
+
+// Names of limits as they appear in LimitError.
+const (
+	LimitMemory   = "memory"
+	LimitRows     = "rows"
+	LimitDuration = "duration"
+	LimitScan     = "scan"
+)
+
+// LimitError is the error returned when a query exceeds one of its Limits.
+type LimitError struct {
+	Limit string
+	Max   int64
+}
+
+func (l *LimitError) Error() string {
+	switch l.Limit {
+	case LimitRows:
+		return fmt.Sprintf("query exceeded limit of %d rows", l.Max)
+	case LimitDuration:
+		return fmt.Sprintf("query exceeded duration limit of %s", time.Duration(l.Max))
+	}
+	return fmt.Sprintf("query exceeded %s limit of %d bytes", l.Limit, l.Max)
+}
@@ -3,6 +3,8 @@ package load
 import (
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/audit"
+	"github.com/brimdata/zed/runtime"
 	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/segmentio/ksuid"
@@ -11,6 +13,7 @@ import (
 type Op struct {
 	octx    *op.Context
 	lk      *lake.Root
+	c       runtime.Compiler
 	parent  zbuf.Puller
 	pool    ksuid.KSUID
 	branch  string
@@ -20,10 +23,11 @@ type Op struct {
 	done    bool
 }
 
-func New(octx *op.Context, lk *lake.Root, parent zbuf.Puller, pool ksuid.KSUID, branch, author, message, meta string) *Op {
+func New(octx *op.Context, lk *lake.Root, c runtime.Compiler, parent zbuf.Puller, pool ksuid.KSUID, branch, author, message, meta string) *Op {
 	return &Op{
 		octx:    octx,
 		lk:      lk,
+		c:       c,
 		parent:  parent,
 		pool:    pool,
 		branch:  branch,
@@ -53,20 +57,36 @@ func (o *Op) Pull(done bool) (zbuf.Batch, error) {
 		o.branch = "main"
 	}
 	o.done = true
-	reader := zbuf.PullerReader(o.parent)
-	pool, err := o.lk.OpenPool(o.octx.Context, o.pool)
+	commitID, err := o.load()
+	o.audit(commitID, err)
 	if err != nil {
 		return nil, err
 	}
-	branch, err := pool.OpenBranchByName(o.octx.Context, o.branch)
+	commitByte := zed.NewBytes(commitID[:])
+	valueID := []zed.Value{*commitByte}
+	return zbuf.NewArray(valueID), nil
+}
+
+func (o *Op) load() (ksuid.KSUID, error) {
+	reader := zbuf.PullerReader(o.parent)
+	pool, err := o.lk.OpenPool(o.octx.Context, o.pool)
 	if err != nil {
-		return nil, err
+		return ksuid.Nil, err
 	}
-	commitID, err := branch.Load(o.octx.Context, o.octx.Zctx, reader, o.author, o.message, o.meta)
+	branch, err := pool.OpenBranchByName(o.octx.Context, o.branch)
 	if err != nil {
-		return nil, err
+		return ksuid.Nil, err
 	}
-	commitByte := zed.NewBytes(commitID[:])
-	valueID := []zed.Value{*commitByte}
-	return zbuf.NewArray(valueID), nil
+	return branch.Load(o.octx.Context, o.c, o.octx.Zctx, reader, o.author, o.message, o.meta)
+}
+
+// audit records the load in the audit log of the lake as the user of the
+// query, which is carried by the query's context.
+func (o *Op) audit(commitID ksuid.KSUID, err error) {
+	rec := audit.NewRecordFromContext(o.octx.Context, audit.OpLoad)
+	rec.Pool = o.pool
+	rec.Branch = o.branch
+	rec.Targets = audit.IDs(commitID)
+	rec.SetError(err)
+	o.lk.Audit(o.octx.Context, rec)
 }
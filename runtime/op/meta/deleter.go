@@ -94,7 +94,7 @@ func (d *Deleter) nextDeletion() (zbuf.Puller, error) {
 		}
 		// Use a no-op progress so stats are not inflated.
 		var progress zbuf.Progress
-		scanner, object, err := newScanner(d.octx.Context, d.octx.Zctx, d.pool, d.unmarshaler, d.pruner, d.filter, &progress, &vals[0])
+		scanner, object, err := newScanner(d.octx, d.pool, d.unmarshaler, d.pruner, d.filter, nil, &progress, &vals[0])
 		if err != nil {
 			return nil, err
 		}
@@ -104,7 +104,7 @@ func (d *Deleter) nextDeletion() (zbuf.Puller, error) {
 }
 
 func (d *Deleter) hasDeletes(val *zed.Value) (bool, error) {
-	scanner, object, err := newScanner(d.octx.Context, d.octx.Zctx, d.pool, d.unmarshaler, d.pruner, d.filter, d.progress, val)
+	scanner, object, err := newScanner(d.octx, d.pool, d.unmarshaler, d.pruner, d.filter, nil, d.progress, val)
 	if err != nil {
 		return false, err
 	}
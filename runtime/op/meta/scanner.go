@@ -19,12 +19,16 @@ func NewLakeMetaScanner(ctx context.Context, zctx *zed.Context, r *lake.Root, me
 	var vals []zed.Value
 	var err error
 	switch meta {
+	case "audit":
+		vals, err = r.BatchifyAudit(ctx, zctx, nil)
 	case "pools":
 		vals, err = r.BatchifyPools(ctx, zctx, nil)
 	case "branches":
 		vals, err = r.BatchifyBranches(ctx, zctx, nil)
 	case "index_rules":
 		vals, err = r.BatchifyIndexRules(ctx, zctx, nil)
+	case "tags":
+		vals, err = r.BatchifyTags(ctx, zctx, nil)
 	default:
 		return nil, fmt.Errorf("unknown lake metadata type: %q", meta)
 	}
@@ -48,6 +52,13 @@ func NewPoolMetaScanner(ctx context.Context, zctx *zed.Context, r *lake.Root, po
 		if err != nil {
 			return nil, err
 		}
+	case "tags":
+		m := zson.NewZNGMarshalerWithContext(zctx)
+		m.Decorate(zson.StylePackage)
+		vals, err = p.BatchifyTags(ctx, zctx, nil, m, nil)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("unknown pool metadata type: %q", meta)
 	}
@@ -91,6 +102,11 @@ func NewCommitMetaScanner(ctx context.Context, zctx *zed.Context, r *lake.Root,
 		if err != nil {
 			return nil, err
 		}
+		tagTips, err := p.BatchifyTagTips(ctx, zctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		tips = append(tips, tagTips...)
 		tipsScanner, err := zbuf.NewScanner(ctx, zbuf.NewArray(tips), nil)
 		if err != nil {
 			return nil, err
@@ -1,7 +1,6 @@
 package meta
 
 import (
-	"context"
 	"errors"
 	"io"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/brimdata/zed/lake/data"
 	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/op/combine"
 	"github.com/brimdata/zed/runtime/op/merge"
 	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zio/zngio"
@@ -27,6 +27,7 @@ type SequenceScanner struct {
 	pool        *lake.Pool
 	progress    *zbuf.Progress
 	unmarshaler *zson.UnmarshalZNGContext
+	vectors     *VectorScan
 	done        bool
 	err         error
 }
@@ -73,7 +74,7 @@ func (s *SequenceScanner) Pull(done bool) (zbuf.Batch, error) {
 				s.close(err)
 				return nil, err
 			}
-			s.scanner, _, err = newScanner(s.octx.Context, s.octx.Zctx, s.pool, s.unmarshaler, s.pruner, s.filter, s.progress, &vals[0])
+			s.scanner, _, err = newScanner(s.octx, s.pool, s.unmarshaler, s.pruner, s.filter, s.vectors, s.progress, &vals[0])
 			if err != nil {
 				s.close(err)
 				return nil, err
@@ -96,7 +97,7 @@ func (s *SequenceScanner) close(err error) {
 	s.done = true
 }
 
-func newScanner(ctx context.Context, zctx *zed.Context, pool *lake.Pool, u *zson.UnmarshalZNGContext, pruner expr.Evaluator, filter zbuf.Filter, progress *zbuf.Progress, val *zed.Value) (zbuf.Puller, *data.Object, error) {
+func newScanner(octx *op.Context, pool *lake.Pool, u *zson.UnmarshalZNGContext, pruner expr.Evaluator, filter zbuf.Filter, vectors *VectorScan, progress *zbuf.Progress, val *zed.Value) (zbuf.Puller, *data.Object, error) {
 	named, ok := val.Type.(*zed.TypeNamed)
 	if !ok {
 		return nil, nil, errors.New("system error: SequenceScanner encountered unnamed object")
@@ -115,11 +116,11 @@ func newScanner(ctx context.Context, zctx *zed.Context, pool *lake.Pool, u *zson
 		}
 		objects = part.Objects
 	}
-	scanner, err := newObjectsScanner(ctx, zctx, pool, objects, pruner, filter, progress)
+	scanner, err := newObjectsScanner(octx, pool, objects, pruner, filter, vectors, progress)
 	return scanner, objects[0], err
 }
 
-func newObjectsScanner(ctx context.Context, zctx *zed.Context, pool *lake.Pool, objects []*data.Object, pruner expr.Evaluator, filter zbuf.Filter, progress *zbuf.Progress) (zbuf.Puller, error) {
+func newObjectsScanner(octx *op.Context, pool *lake.Pool, objects []*data.Object, pruner expr.Evaluator, filter zbuf.Filter, vectors *VectorScan, progress *zbuf.Progress) (zbuf.Puller, error) {
 	pullers := make([]zbuf.Puller, 0, len(objects))
 	pullersDone := func() {
 		for _, puller := range pullers {
@@ -127,7 +128,7 @@ func newObjectsScanner(ctx context.Context, zctx *zed.Context, pool *lake.Pool,
 		}
 	}
 	for _, object := range objects {
-		s, err := newObjectScanner(ctx, zctx, pool, object, filter, pruner, progress)
+		s, err := newObjectScanner(octx, pool, object, filter, pruner, vectors, progress)
 		if err != nil {
 			pullersDone()
 			return nil, err
@@ -137,35 +138,49 @@ func newObjectsScanner(ctx context.Context, zctx *zed.Context, pool *lake.Pool,
 	if len(pullers) == 1 {
 		return pullers[0], nil
 	}
-	return merge.New(ctx, pullers, lake.ImportComparator(zctx, pool).Compare), nil
+	if vectors != nil && vectors.Summarize != nil {
+		// Partial aggregations have no order to preserve.
+		return combine.New(octx, pullers), nil
+	}
+	return merge.New(octx, pullers, lake.ImportComparator(octx.Zctx, pool).Compare), nil
 }
 
-func newObjectScanner(ctx context.Context, zctx *zed.Context, pool *lake.Pool, object *data.Object, filter zbuf.Filter, pruner expr.Evaluator, progress *zbuf.Progress) (zbuf.Puller, error) {
-	ranges, err := data.LookupSeekRange(ctx, pool.Storage(), pool.DataPath, object, pruner)
+func newObjectScanner(octx *op.Context, pool *lake.Pool, object *data.Object, filter zbuf.Filter, pruner expr.Evaluator, vectors *VectorScan, progress *zbuf.Progress) (zbuf.Puller, error) {
+	if vectors != nil && vectors.Snapshot.HasVector(object.ID) {
+		return newVectorScanner(octx, pool, object, vectors, progress)
+	}
+	ranges, err := data.LookupSeekRange(octx, pool.Storage(), pool.DataPath, object, pruner)
 	if err != nil {
 		return nil, err
 	}
-	rc, err := object.NewReader(ctx, pool.Storage(), pool.DataPath, ranges)
+	rc, err := object.NewReader(octx, pool.Storage(), pool.DataPath, ranges)
 	if err != nil {
 		return nil, err
 	}
-	scanner, err := zngio.NewReader(zctx, rc).NewScanner(ctx, filter)
+	scanner, err := zngio.NewReader(octx.Zctx, rc).NewScanner(octx, filter)
 	if err != nil {
 		rc.Close()
 		return nil, err
 	}
-	return &statScanner{
+	var puller zbuf.Puller = &statScanner{
+		octx:     octx,
 		scanner:  scanner,
 		closer:   rc,
 		progress: progress,
-	}, nil
+	}
+	if vectors != nil && vectors.Summarize != nil {
+		return vectors.GroupBy(puller)
+	}
+	return puller, nil
 }
 
 type statScanner struct {
+	octx     *op.Context
 	scanner  zbuf.Scanner
 	closer   io.Closer
 	err      error
 	progress *zbuf.Progress
+	nbytes   int64
 }
 
 func (s *statScanner) Pull(done bool) (zbuf.Batch, error) {
@@ -173,6 +188,17 @@ func (s *statScanner) Pull(done bool) (zbuf.Batch, error) {
 		return nil, s.err
 	}
 	batch, err := s.scanner.Pull(done)
+	if err == nil {
+		// Charge the bytes read since the last Pull against the
+		// query's scan limit.
+		nbytes := s.scanner.Progress().BytesRead
+		err = s.octx.AddScanBytes(nbytes - s.nbytes)
+		s.nbytes = nbytes
+		if err != nil && batch != nil {
+			batch.Unref()
+			batch = nil
+		}
+	}
 	if batch == nil || err != nil {
 		s.progress.Add(s.scanner.Progress())
 		if err2 := s.closer.Close(); err == nil {
@@ -0,0 +1,91 @@
+package meta
+
+import (
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/data"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/vcache"
+	"github.com/brimdata/zed/zbuf"
+)
+
+// VectorScan configures a SequenceScanner to read each object that has a
+// vector through the vector cache rather than reading the object's rows.
+type VectorScan struct {
+	Cache    *vcache.Cache
+	Snapshot commits.View
+	// Fields lists the top-level fields used downstream.  Each record is
+	// cut to these fields.
+	Fields []string
+	Filter *vcache.Filter
+	// If Summarize is non-nil, the scanner returns partial aggregations
+	// for each object instead of the object's values.  GroupBy computes
+	// the same partials from a puller of values and is used for objects
+	// that Summarize cannot handle.
+	Summarize *vcache.Summarize
+	GroupBy   func(zbuf.Puller) (zbuf.Puller, error)
+}
+
+func NewVectorSequenceScanner(octx *op.Context, parent zbuf.Puller, pool *lake.Pool, filter zbuf.Filter, pruner expr.Evaluator, progress *zbuf.Progress, vectors *VectorScan) *SequenceScanner {
+	s := NewSequenceScanner(octx, parent, pool, filter, pruner, progress)
+	s.vectors = vectors
+	return s
+}
+
+func newVectorScanner(octx *op.Context, pool *lake.Pool, object *data.Object, vectors *VectorScan, progress *zbuf.Progress) (zbuf.Puller, error) {
+	uri := data.VectorURI(pool.DataPath, object.ID)
+	o, err := vectors.Cache.Fetch(octx.Context, uri, object.ID)
+	if err != nil {
+		return nil, err
+	}
+	if vectors.Summarize != nil && o.CanSummarize(vectors.Filter) {
+		vals, p, err := o.Summarize(octx.Zctx, vectors.Filter, vectors.Summarize)
+		if err != nil {
+			return nil, err
+		}
+		progress.Add(p)
+		if err := octx.AddScanBytes(p.BytesRead); err != nil {
+			return nil, err
+		}
+		return zbuf.NewPuller(zbuf.NewArray(vals)), nil
+	}
+	scan, err := o.NewScan(octx.Zctx, vectors.Fields, vectors.Filter)
+	if err != nil {
+		return nil, err
+	}
+	// NewScan has loaded all of the columns, so we charge them against
+	// the query's scan limit up front.
+	if err := octx.AddScanBytes(scan.Progress().BytesRead); err != nil {
+		return nil, err
+	}
+	var puller zbuf.Puller = &vectorScanner{
+		puller:   zbuf.NewPuller(scan),
+		scan:     scan,
+		progress: progress,
+	}
+	if vectors.Summarize != nil {
+		return vectors.GroupBy(puller)
+	}
+	return puller, nil
+}
+
+// vectorScanner adds the progress of a vcache.Scan to the scanner's progress
+// when the scan is done.
+type vectorScanner struct {
+	puller   zbuf.Puller
+	scan     *vcache.Scan
+	progress *zbuf.Progress
+}
+
+func (v *vectorScanner) Pull(done bool) (zbuf.Batch, error) {
+	if v.puller == nil {
+		return nil, nil
+	}
+	batch, err := v.puller.Pull(done)
+	if batch == nil || err != nil || done {
+		v.progress.Add(v.scan.Progress())
+		v.puller = nil
+	}
+	return batch, err
+}
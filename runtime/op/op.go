@@ -3,6 +3,7 @@ package op
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/zbuf"
@@ -27,7 +28,13 @@ type Context struct {
 	// (e.g., removing temporary files) before Cancel returns.
 	WaitGroup sync.WaitGroup
 	Zctx      *zed.Context
-	cancel    context.CancelFunc
+	// GroupByMemMaxBytes, if nonzero, overrides groupby.MemMaxBytes for
+	// the groupby operators of this context.
+	GroupByMemMaxBytes int
+	limits             Limits
+	memBytes           atomic.Int64
+	scanBytes          atomic.Int64
+	cancel             context.CancelFunc
 }
 
 func NewContext(ctx context.Context, zctx *zed.Context, logger *zap.Logger) *Context {
@@ -47,6 +54,51 @@ func DefaultContext() *Context {
 	return NewContext(context.Background(), zed.NewContext(), nil)
 }
 
+// SetLimits sets the resource limits of the query run by c.  It must be
+// called before the query's operators are built.
+func (c *Context) SetLimits(limits Limits) {
+	c.limits = limits
+	if limits.Timeout > 0 {
+		err := &LimitError{Limit: LimitDuration, Max: int64(limits.Timeout)}
+		ctx, cancel := context.WithTimeoutCause(c.Context, limits.Timeout, err)
+		parentCancel := c.cancel
+		c.Context = ctx
+		c.cancel = func() {
+			cancel()
+			parentCancel()
+		}
+	}
+}
+
+// Limits returns the resource limits of the query run by c.
+func (c *Context) Limits() Limits {
+	return c.limits
+}
+
+// ReserveMem records that an operator holds n more bytes of memory, or -n
+// fewer bytes if n is negative.  If n is positive and the memory held by all
+// operators would exceed the memory limit, ReserveMem reserves nothing and
+// returns a *LimitError.
+func (c *Context) ReserveMem(n int) error {
+	total := c.memBytes.Add(int64(n))
+	if max := c.limits.MemMaxBytes; max > 0 && n > 0 && total > max {
+		c.memBytes.Add(-int64(n))
+		return &LimitError{Limit: LimitMemory, Max: max}
+	}
+	return nil
+}
+
+// AddScanBytes records that a scanner has read n bytes from storage.  It
+// returns a *LimitError if the bytes read by all scanners exceed the scan
+// limit.
+func (c *Context) AddScanBytes(n int64) error {
+	total := c.scanBytes.Add(n)
+	if max := c.limits.ScanMaxBytes; max > 0 && total > max {
+		return &LimitError{Limit: LimitScan, Max: max}
+	}
+	return nil
+}
+
 // Cancel cancels the context.  Cancel must be called to ensure that operators
 // complete cleanup work (e.g., removing temporary files).
 func (c *Context) Cancel() {
@@ -0,0 +1,19 @@
+package op
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveMem(t *testing.T) {
+	octx := DefaultContext()
+	octx.SetLimits(Limits{MemMaxBytes: 10})
+	require.NoError(t, octx.ReserveMem(8))
+	var limitErr *LimitError
+	require.ErrorAs(t, octx.ReserveMem(5), &limitErr)
+	// The failed reservation holds nothing.
+	require.NoError(t, octx.ReserveMem(2))
+	require.NoError(t, octx.ReserveMem(-10))
+	require.Zero(t, octx.memBytes.Load())
+}
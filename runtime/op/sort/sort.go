@@ -62,14 +62,20 @@ func (o *Op) Pull(done bool) (zbuf.Batch, error) {
 func (o *Op) run() {
 	defer close(o.resultCh)
 	var spiller *spill.MergeSort
+	var nbytes int
+	// release releases the memory reserved for out.
+	release := func() {
+		o.octx.ReserveMem(-nbytes)
+		nbytes = 0
+	}
 	defer func() {
 		if spiller != nil {
 			spiller.Cleanup()
 		}
+		release()
 		// Tell p.ctx's cancel function that we've finished our cleanup.
 		o.octx.WaitGroup.Done()
 	}()
-	var nbytes int
 	var out []zed.Value
 	for {
 		batch, err := o.parent.Pull(false)
@@ -89,7 +95,7 @@ func (o *Op) run() {
 				if ok := o.sendResult(nil, nil); !ok {
 					return
 				}
-				nbytes = 0
+				release()
 				out = nil
 				continue
 			}
@@ -99,7 +105,7 @@ func (o *Op) run() {
 						return
 					}
 					spiller = nil
-					nbytes = 0
+					release()
 					out = nil
 					continue
 				}
@@ -109,7 +115,7 @@ func (o *Op) run() {
 			}
 			spiller.Cleanup()
 			spiller = nil
-			nbytes = 0
+			release()
 			out = nil
 			continue
 		}
@@ -120,6 +126,10 @@ func (o *Op) run() {
 		if o.comparator == nil && len(out) > 0 {
 			o.setComparator(&out[0])
 		}
+		if err := o.octx.ReserveMem(delta); err != nil {
+			o.sendResult(nil, err)
+			return
+		}
 		nbytes += delta
 		if nbytes < MemMaxBytes {
 			continue
@@ -131,7 +141,7 @@ func (o *Op) run() {
 					return
 				}
 				out = nil
-				nbytes = 0
+				release()
 				continue
 			}
 		}
@@ -141,7 +151,7 @@ func (o *Op) run() {
 			}
 		}
 		out = nil
-		nbytes = 0
+		release()
 	}
 }
 
@@ -0,0 +1,160 @@
+package window
+
+import (
+	"fmt"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/expr/agg"
+)
+
+// A Func is a window function.  Each partition has its own state for
+// each function.
+type Func interface {
+	newState() state
+}
+
+type state interface {
+	// result is called for each value of a partition in order and returns
+	// the result of the function for that value.  peer is true if the value
+	// has the same order key as the value that preceded it in the partition.
+	result(zctx *zed.Context, ectx expr.Context, this *zed.Value, peer bool) *zed.Value
+}
+
+// NewFunc returns the window function name with arguments args.  The offset
+// argument of lag and lead must be a constant, so it is passed separately
+// as offset and is zero when absent.
+func NewFunc(name string, args []expr.Evaluator, offset int) (Func, error) {
+	switch name {
+	case "row_number", "rank", "dense_rank":
+		if len(args) != 0 {
+			return nil, fmt.Errorf("%s: wrong number of arguments", name)
+		}
+		return &rankFunc{name: name}, nil
+	case "lag", "lead":
+		if len(args) < 1 || len(args) > 2 {
+			return nil, fmt.Errorf("%s: wrong number of arguments", name)
+		}
+		if offset == 0 {
+			offset = 1
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("%s: offset must be a positive integer", name)
+		}
+		var def expr.Evaluator
+		if len(args) == 2 {
+			def = args[1]
+		}
+		if name == "lead" {
+			return &leadFunc{expr: args[0], offset: offset, def: def}, nil
+		}
+		return &lagFunc{expr: args[0], offset: offset, def: def}, nil
+	}
+	return nil, fmt.Errorf("unknown window function: %s", name)
+}
+
+// NewAggFunc returns a window function that computes the running value of an
+// aggregate function from the first value of a partition through the
+// current value.
+func NewAggFunc(agg *expr.Aggregator) Func {
+	return &aggFunc{agg}
+}
+
+type rankFunc struct {
+	name string
+}
+
+func (r *rankFunc) newState() state {
+	return &rankState{name: r.name}
+}
+
+type rankState struct {
+	name  string
+	count uint64
+	rank  uint64
+	dense uint64
+}
+
+func (r *rankState) result(_ *zed.Context, _ expr.Context, _ *zed.Value, peer bool) *zed.Value {
+	r.count++
+	if !peer {
+		r.rank = r.count
+		r.dense++
+	}
+	switch r.name {
+	case "rank":
+		return zed.NewUint64(r.rank)
+	case "dense_rank":
+		return zed.NewUint64(r.dense)
+	}
+	return zed.NewUint64(r.count)
+}
+
+type lagFunc struct {
+	expr   expr.Evaluator
+	offset int
+	def    expr.Evaluator
+}
+
+func (l *lagFunc) newState() state {
+	return &lagState{lagFunc: l}
+}
+
+type lagState struct {
+	*lagFunc
+	// vals is a ring buffer of the last offset values.
+	vals []zed.Value
+	next int
+}
+
+func (l *lagState) result(_ *zed.Context, ectx expr.Context, this *zed.Value, _ bool) *zed.Value {
+	val := *l.expr.Eval(ectx, this).Copy()
+	if len(l.vals) < l.offset {
+		l.vals = append(l.vals, val)
+		if l.def != nil {
+			return l.def.Eval(ectx, this)
+		}
+		return zed.Null
+	}
+	out := l.vals[l.next]
+	l.vals[l.next] = val
+	l.next = (l.next + 1) % l.offset
+	return &out
+}
+
+// leadFunc is handled by Op since its result depends on values that follow
+// the current value.
+type leadFunc struct {
+	expr   expr.Evaluator
+	offset int
+	def    expr.Evaluator
+}
+
+func (*leadFunc) newState() state {
+	return nil
+}
+
+func (l *leadFunc) defaultValue(ectx expr.Context, this *zed.Value) *zed.Value {
+	if l.def != nil {
+		return l.def.Eval(ectx, this)
+	}
+	return zed.Null
+}
+
+type aggFunc struct {
+	agg *expr.Aggregator
+}
+
+func (a *aggFunc) newState() state {
+	return &aggState{a.agg, a.agg.NewFunction()}
+}
+
+type aggState struct {
+	agg *expr.Aggregator
+	fn  agg.Function
+}
+
+func (a *aggState) result(zctx *zed.Context, ectx expr.Context, this *zed.Value, _ bool) *zed.Value {
+	a.agg.Apply(zctx, ectx, a.fn, this)
+	return a.fn.Result(zctx)
+}
@@ -0,0 +1,265 @@
+package window
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Op computes window functions over partitions of its input, where each
+// partition is the sequence of values for which the key expressions are equal.
+// The functions are computed in the order that values arrive, so the compiler
+// arranges for the input to be sorted by the window's order expressions.
+// When the input is already in that order (e.g., a pool scanned in pool-key
+// order), no sort is needed and the operator streams its input.
+//
+// Each output value is its input value with the result of each function
+// assigned to a field as with put.  Output order matches input order within
+// each partition, so values whose lead() results depend on values not yet
+// seen are held back until those values arrive or the input ends.  Values
+// of other partitions are not held back with them.
+type Op struct {
+	octx       *op.Context
+	parent     zbuf.Puller
+	keys       []expr.Evaluator
+	order      []expr.Evaluator
+	funcs      []Func
+	putter     *expr.Putter
+	ectx       expr.ResetContext
+	partitions map[string]*partition
+	current    *row
+	seq        uint64
+	keyBytes   zcode.Bytes
+	orderBytes zcode.Bytes
+	eos        bool
+}
+
+type partition struct {
+	states  []state
+	count   uint64
+	order   zcode.Bytes
+	waiting []*row
+	// pending holds the values of the partition that are held back, in
+	// input order.
+	pending []*row
+}
+
+type row struct {
+	val     *zed.Value
+	seq     uint64
+	index   uint64
+	results []*zed.Value
+	nlead   int
+}
+
+func New(octx *op.Context, parent zbuf.Puller, keys, order []expr.Evaluator, lvals []*expr.Lval, funcs []Func) *Op {
+	o := &Op{
+		octx:       octx,
+		parent:     parent,
+		keys:       keys,
+		order:      order,
+		funcs:      funcs,
+		partitions: make(map[string]*partition),
+	}
+	clauses := make([]expr.Assignment, 0, len(funcs))
+	for k, lval := range lvals {
+		clauses = append(clauses, expr.Assignment{LHS: lval, RHS: &result{o, k}})
+	}
+	o.putter = expr.NewPutter(octx.Zctx, clauses)
+	return o
+}
+
+func (o *Op) Pull(done bool) (zbuf.Batch, error) {
+	if done {
+		o.reset()
+		return o.parent.Pull(true)
+	}
+	for {
+		if o.eos {
+			o.reset()
+			return nil, nil
+		}
+		batch, err := o.parent.Pull(false)
+		if err != nil {
+			return nil, err
+		}
+		if batch == nil {
+			o.eos = true
+			if out := o.flush(); len(out) > 0 {
+				return zbuf.NewArray(out), nil
+			}
+			continue
+		}
+		o.ectx.SetVars(batch.Vars())
+		vals := batch.Values()
+		var out []zed.Value
+		for i := range vals {
+			out = o.step(out, &vals[i])
+		}
+		if len(out) > 0 {
+			defer batch.Unref()
+			return zbuf.NewBatch(batch, out), nil
+		}
+		batch.Unref()
+	}
+}
+
+func (o *Op) reset() {
+	o.partitions = make(map[string]*partition)
+	o.seq = 0
+	o.eos = false
+}
+
+func (o *Op) step(out []zed.Value, this *zed.Value) []zed.Value {
+	ectx := o.ectx.Reset()
+	p := o.lookup(ectx, this)
+	r := &row{
+		val:     this,
+		seq:     o.seq,
+		index:   p.count,
+		results: make([]*zed.Value, len(o.funcs)),
+	}
+	o.seq++
+	p.count++
+	peer := o.isPeer(ectx, p, this)
+	for k, f := range o.funcs {
+		if _, ok := f.(*leadFunc); ok {
+			r.nlead++
+			continue
+		}
+		r.results[k] = p.states[k].result(o.octx.Zctx, ectx, this, peer).Copy()
+	}
+	o.resolveLeads(ectx, p, this, r.index)
+	if r.nlead > 0 {
+		p.waiting = append(p.waiting, r)
+	}
+	if r.nlead > 0 || len(p.pending) > 0 {
+		// Values from this batch may be emitted after it's released.
+		r.val = this.Copy()
+		p.pending = append(p.pending, r)
+		return o.drain(out, p)
+	}
+	return o.emit(out, r)
+}
+
+func (o *Op) lookup(ectx expr.Context, this *zed.Value) *partition {
+	o.keyBytes = o.keyBytes[:0]
+	for _, key := range o.keys {
+		o.keyBytes = appendKey(o.keyBytes, key.Eval(ectx, this))
+	}
+	p, ok := o.partitions[string(o.keyBytes)]
+	if !ok {
+		p = &partition{states: make([]state, len(o.funcs))}
+		for k, f := range o.funcs {
+			p.states[k] = f.newState()
+		}
+		o.partitions[string(o.keyBytes)] = p
+	}
+	return p
+}
+
+// isPeer returns true if this has the same order key as the previous value
+// of the partition.
+func (o *Op) isPeer(ectx expr.Context, p *partition, this *zed.Value) bool {
+	if len(o.order) == 0 {
+		return false
+	}
+	o.orderBytes = o.orderBytes[:0]
+	for _, e := range o.order {
+		o.orderBytes = appendKey(o.orderBytes, e.Eval(ectx, this))
+	}
+	peer := p.count > 1 && string(p.order) == string(o.orderBytes)
+	p.order = append(p.order[:0], o.orderBytes...)
+	return peer
+}
+
+func appendKey(b zcode.Bytes, val *zed.Value) zcode.Bytes {
+	b = zcode.Append(b, zed.EncodeInt(int64(val.Type.ID())))
+	return zcode.Append(b, val.Bytes())
+}
+
+// resolveLeads supplies this, the value at position index in partition p, to
+// the lead functions of the values of p that are waiting on it.
+func (o *Op) resolveLeads(ectx expr.Context, p *partition, this *zed.Value, index uint64) {
+	for _, r := range p.waiting {
+		for k, f := range o.funcs {
+			if lead, ok := f.(*leadFunc); ok && r.index+uint64(lead.offset) == index {
+				r.results[k] = lead.expr.Eval(ectx, this).Copy()
+				r.nlead--
+			}
+		}
+	}
+	n := 0
+	for _, r := range p.waiting {
+		if r.nlead > 0 {
+			p.waiting[n] = r
+			n++
+		}
+	}
+	p.waiting = p.waiting[:n]
+}
+
+// drain emits the values at the front of the pending queue of partition p
+// that are no longer waiting on lead values.
+func (o *Op) drain(out []zed.Value, p *partition) []zed.Value {
+	n := 0
+	for _, r := range p.pending {
+		if r.nlead > 0 {
+			break
+		}
+		out = o.emit(out, r)
+		n++
+	}
+	p.pending = p.pending[n:]
+	return out
+}
+
+// flush emits the pending values of all partitions in input order at the
+// end of input with any unresolved lead functions set to their default
+// values.
+func (o *Op) flush() []zed.Value {
+	var pending []*row
+	for _, p := range o.partitions {
+		pending = append(pending, p.pending...)
+		p.pending = nil
+	}
+	slices.SortFunc(pending, func(a, b *row) int {
+		return cmp.Compare(a.seq, b.seq)
+	})
+	var out []zed.Value
+	for _, r := range pending {
+		ectx := o.ectx.Reset()
+		for k, f := range o.funcs {
+			if lead, ok := f.(*leadFunc); ok && r.results[k] == nil {
+				r.results[k] = lead.defaultValue(ectx, r.val).Copy()
+			}
+		}
+		out = o.emit(out, r)
+	}
+	return out
+}
+
+func (o *Op) emit(out []zed.Value, r *row) []zed.Value {
+	o.current = r
+	val := o.putter.Eval(o.ectx.Reset(), r.val)
+	if val.IsError() && (val.IsQuiet() || val.IsMissing()) {
+		return out
+	}
+	return append(out, *val.Copy())
+}
+
+// result is an Evaluator that returns the result of a window function for
+// the value being emitted.
+type result struct {
+	op *Op
+	k  int
+}
+
+func (r *result) Eval(expr.Context, *zed.Value) *zed.Value {
+	return r.op.current.results[r.k]
+}
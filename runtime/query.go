@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/brimdata/zed"
@@ -18,8 +19,10 @@ import (
 // methods provide a convenient means to run a flowgraph as zio.Reader.
 type Query struct {
 	zbuf.Puller
-	octx  *op.Context
-	meter zbuf.Meter
+	octx     *op.Context
+	meter    zbuf.Meter
+	rows     int64
+	limitErr error
 }
 
 var _ zbuf.Puller = (*Query)(nil)
@@ -88,5 +91,35 @@ func (q *Query) Pull(done bool) (zbuf.Batch, error) {
 	if done {
 		q.octx.Cancel()
 	}
-	return q.Puller.Pull(done)
+	if q.limitErr != nil {
+		q.octx.Cancel()
+		return nil, q.limitErr
+	}
+	batch, err := q.Puller.Pull(done)
+	if err != nil {
+		// Report an elapsed timeout as such rather than as the
+		// cancellation it caused.
+		var limitErr *op.LimitError
+		if errors.As(context.Cause(q.octx), &limitErr) {
+			err = limitErr
+		}
+		return nil, err
+	}
+	if max := q.octx.Limits().RowsMax; batch != nil && max > 0 {
+		vals := batch.Values()
+		if q.rows+int64(len(vals)) > max {
+			if q.rows >= max {
+				batch.Unref()
+				q.octx.Cancel()
+				return nil, &op.LimitError{Limit: op.LimitRows, Max: max}
+			}
+			// Output values up to the limit.  The next call to Pull
+			// returns an error.
+			q.limitErr = &op.LimitError{Limit: op.LimitRows, Max: max}
+			vals = vals[:max-q.rows]
+			batch = zbuf.NewBatch(batch, vals)
+		}
+		q.rows += int64(len(vals))
+	}
+	return batch, nil
 }
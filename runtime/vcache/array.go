@@ -25,23 +25,27 @@ func NewArray(array *vector.Array, r io.ReaderAt) (*Array, error) {
 	}, nil
 }
 
-func (a *Array) NewIter(reader io.ReaderAt) (iterator, error) {
+func (a *Array) NewIter(o *Object) (iterator, error) {
 	// The lengths vector is typically large and is loaded on demand.
 	if a.lengths == nil {
-		lengths, err := vng.ReadIntVector(a.segmap, reader)
+		lengths, err := vng.ReadIntVector(a.segmap, o.readerAt())
 		if err != nil {
 			return nil, err
 		}
 		a.lengths = lengths
+		o.loaded(a, 4*len(lengths))
+	} else {
+		o.touch(a)
 	}
-	values, err := a.values.NewIter(reader)
+	lengths := a.lengths
+	values, err := a.values.NewIter(o)
 	if err != nil {
 		return nil, err
 	}
 	off := 0
 	return func(b *zcode.Builder) error {
 		b.BeginContainer()
-		len := a.lengths[off]
+		len := lengths[off]
 		off++
 		for ; len > 0; len-- {
 			if err := values(b); err != nil {
@@ -52,3 +56,7 @@ func (a *Array) NewIter(reader io.ReaderAt) (iterator, error) {
 		return nil
 	}, nil
 }
+
+func (a *Array) unload() {
+	a.lengths = nil
+}
@@ -1,39 +1,214 @@
 package vcache
 
 import (
+	"container/list"
 	"context"
+	"sync"
+	"time"
 
 	"github.com/brimdata/zed/pkg/storage"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/segmentio/ksuid"
+	"golang.org/x/sync/singleflight"
 )
 
+// CacheOpts configures the memory budget of a Cache and the handling of the
+// storage readers of its objects.
+type CacheOpts struct {
+	// MaxBytes is the budget in bytes for the vector data and object
+	// metadata held by the cache (0 for no limit).  When the budget is
+	// exceeded, the least recently used vectors and objects are evicted.
+	MaxBytes int64
+	// IdleTimeout is the duration after which the storage reader of an
+	// object that is not being loaded from is closed (0 to never close).
+	// The reader is reopened when the object's vectors must be loaded.
+	IdleTimeout time.Duration
+}
+
+// Cache holds the objects and vectors loaded for vector queries in a
+// single LRU list.  An entry of the list is either an object, which charges
+// the cache for the object's metadata, or a loaded vector of an object,
+// which charges the cache for the vector's data.  Evicting a vector unloads
+// its data, which is reloaded when next needed, while evicting an object
+// removes it from the cache along with all of its vectors.  Queries holding
+// an evicted object may continue to use it.
 type Cache struct {
-	engine storage.Engine
-	// objects is currently a simple map but we will turn this into an
-	// LRU cache sometime soon.  First step is object-level granularity, though
-	// we might want LRU inside of objects based on vectors.  We can do that
-	// later if measurements warrant it.  XXX note that we keep the storage
-	// reader open for every object and never close it.  We should timeout
-	// files and close them and then reopen them when needed to access
-	// vectors that haven't yet been loaded.
+	engine  storage.Engine
+	opts    CacheOpts
+	metrics metrics
+	// loads ensures each object missing from the cache is loaded from
+	// storage at most once at a time and without holding mu.
+	loads singleflight.Group
+
+	mu      sync.Mutex
 	objects map[ksuid.KSUID]*Object
+	lru     *list.List
+	size    int64
+}
+
+// entry is an element of the LRU list of a Cache.  vector is nil for the
+// entry of an object.
+type entry struct {
+	object *Object
+	vector loadable
+	size   int64
 }
 
+// NewCache returns a cache with no memory budget whose objects keep their
+// storage readers open.
 func NewCache(engine storage.Engine) *Cache {
+	return NewCacheWithOpts(engine, CacheOpts{}, nil)
+}
+
+func NewCacheWithOpts(engine storage.Engine, opts CacheOpts, registerer prometheus.Registerer) *Cache {
+	if registerer == nil {
+		registerer = prometheus.NewRegistry()
+	}
 	return &Cache{
 		engine:  engine,
+		opts:    opts,
+		metrics: newMetrics(registerer),
 		objects: make(map[ksuid.KSUID]*Object),
+		lru:     list.New(),
 	}
 }
 
 func (c *Cache) Fetch(ctx context.Context, uri *storage.URI, id ksuid.KSUID) (*Object, error) {
+	object, err := c.fetch(ctx, uri, id)
+	if err != nil {
+		return nil, err
+	}
+	c.shrink()
+	return object, nil
+}
+
+func (c *Cache) fetch(ctx context.Context, uri *storage.URI, id ksuid.KSUID) (*Object, error) {
+	c.mu.Lock()
 	if object, ok := c.objects[id]; ok {
+		c.lru.MoveToFront(object.elem)
+		c.metrics.hits.Inc()
+		c.mu.Unlock()
 		return object, nil
 	}
+	c.metrics.misses.Inc()
+	c.mu.Unlock()
+	ch := c.loads.DoChan(id.String(), func() (interface{}, error) {
+		// The load is shared by every query fetching the object, so
+		// it isn't canceled with the query that started it.
+		return c.load(context.WithoutCancel(ctx), uri, id)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*Object), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load loads the object with ID id from storage and adds it to the cache.
+func (c *Cache) load(ctx context.Context, uri *storage.URI, id ksuid.KSUID) (*Object, error) {
 	object, err := NewObject(ctx, c.engine, uri, id)
 	if err != nil {
 		return nil, err
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.objects[id]; ok {
+		// A previous load finished after our caller missed the
+		// object in the cache.
+		object.Close()
+		c.lru.MoveToFront(cached.elem)
+		return cached, nil
+	}
+	object.cache = c
+	object.entries = make(map[loadable]*list.Element)
+	if c.opts.IdleTimeout > 0 {
+		object.readerMu.Lock()
+		object.idleTimeout = c.opts.IdleTimeout
+		object.timer = time.AfterFunc(c.opts.IdleTimeout, object.closeIdle)
+		object.readerMu.Unlock()
+	}
+	object.elem = c.push(&entry{object: object, size: object.size()})
 	c.objects[id] = object
+	c.metrics.objects.Set(float64(len(c.objects)))
 	return object, nil
 }
+
+// loaded adds an entry for vector v of object o, whose data of size bytes
+// was just loaded.
+func (c *Cache) loaded(o *Object, v loadable, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if o.elem == nil {
+		// The object was evicted.
+		return
+	}
+	if elem, ok := o.entries[v]; ok {
+		c.remove(elem)
+	}
+	o.entries[v] = c.push(&entry{object: o, vector: v, size: size})
+	c.lru.MoveToFront(o.elem)
+}
+
+// touch marks vector v of object o and the object itself as most recently
+// used.
+func (c *Cache) touch(o *Object, v loadable) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := o.entries[v]; ok {
+		c.lru.MoveToFront(elem)
+	}
+	if o.elem != nil {
+		c.lru.MoveToFront(o.elem)
+	}
+}
+
+// shrink evicts the least recently used entries until the cache fits its
+// budget.  It must be called without holding the lock of any object.
+func (c *Cache) shrink() {
+	for {
+		c.mu.Lock()
+		if c.opts.MaxBytes <= 0 || c.size <= c.opts.MaxBytes || c.lru.Len() == 0 {
+			c.mu.Unlock()
+			return
+		}
+		e := c.lru.Back().Value.(*entry)
+		o := e.object
+		var vectors []loadable
+		if e.vector != nil {
+			c.remove(o.entries[e.vector])
+			delete(o.entries, e.vector)
+			vectors = append(vectors, e.vector)
+			c.metrics.evictions.WithLabelValues("vector").Inc()
+		} else {
+			for v, elem := range o.entries {
+				c.remove(elem)
+				vectors = append(vectors, v)
+			}
+			c.remove(o.elem)
+			o.elem, o.entries = nil, nil
+			delete(c.objects, o.id)
+			c.metrics.objects.Set(float64(len(c.objects)))
+			c.metrics.evictions.WithLabelValues("object").Inc()
+		}
+		c.mu.Unlock()
+		// The object lock is taken only after releasing c.mu since
+		// vectors are loaded while holding the object lock.
+		o.unload(vectors, e.vector == nil)
+	}
+}
+
+// push and remove must be called with c.mu held.
+func (c *Cache) push(e *entry) *list.Element {
+	c.size += e.size
+	c.metrics.bytes.Set(float64(c.size))
+	return c.lru.PushFront(e)
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	c.size -= c.lru.Remove(elem).(*entry).size
+	c.metrics.bytes.Set(float64(c.size))
+}
@@ -0,0 +1,134 @@
+package vcache
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/vngio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+func writeObject(t *testing.T, engine storage.Engine, name string, n int) *storage.URI {
+	var sb strings.Builder
+	for k := 0; k < n; k++ {
+		fmt.Fprintf(&sb, "\"%s%d\"\n", strings.Repeat("a", 100), k)
+	}
+	uri := storage.MustParseURI(filepath.Join(t.TempDir(), name))
+	put, err := engine.Put(context.Background(), uri)
+	require.NoError(t, err)
+	w, err := vngio.NewWriter(put)
+	require.NoError(t, err)
+	require.NoError(t, zio.Copy(w, zsonio.NewReader(zed.NewContext(), strings.NewReader(sb.String()))))
+	require.NoError(t, w.Close())
+	return uri
+}
+
+func readAll(t *testing.T, o *Object) []string {
+	var vals []string
+	r := o.NewReader()
+	for {
+		val, err := r.Read()
+		require.NoError(t, err)
+		if val == nil {
+			return vals
+		}
+		vals = append(vals, zson.FormatValue(val))
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	engine := storage.NewLocalEngine()
+	uri1 := writeObject(t, engine, "1.vng", 300)
+	uri2 := writeObject(t, engine, "2.vng", 300)
+	id1, id2 := ksuid.New(), ksuid.New()
+	// The budget holds the metadata of both objects but the vector of
+	// only one.
+	cache := NewCacheWithOpts(engine, CacheOpts{MaxBytes: 45000}, nil)
+
+	o1, err := cache.Fetch(ctx, uri1, id1)
+	require.NoError(t, err)
+	expected := readAll(t, o1)
+	require.Len(t, expected, 300)
+	require.Len(t, o1.entries, 1)
+	require.LessOrEqual(t, cache.size, int64(45000))
+
+	o2, err := cache.Fetch(ctx, uri2, id2)
+	require.NoError(t, err)
+	require.Len(t, readAll(t, o2), 300)
+	require.Len(t, o2.entries, 1)
+	require.Len(t, o1.entries, 0)
+	require.Nil(t, o1.vectors[0].(*Primitive).bytes)
+	require.LessOrEqual(t, cache.size, int64(45000))
+
+	// The unloaded vector of the first object is reloaded.
+	again, err := cache.Fetch(ctx, uri1, id1)
+	require.NoError(t, err)
+	require.Same(t, o1, again)
+	require.Equal(t, expected, readAll(t, o1))
+	require.Len(t, o1.entries, 1)
+	require.Len(t, o2.entries, 0)
+
+	// A budget smaller than any object evicts whole objects, which
+	// remain usable.
+	cache = NewCacheWithOpts(engine, CacheOpts{MaxBytes: 1}, nil)
+	o1, err = cache.Fetch(ctx, uri1, id1)
+	require.NoError(t, err)
+	require.Empty(t, cache.objects)
+	require.Zero(t, cache.size)
+	require.Equal(t, expected, readAll(t, o1))
+	require.Nil(t, o1.reader)
+}
+
+func TestCacheIdleTimeout(t *testing.T) {
+	ctx := context.Background()
+	engine := storage.NewLocalEngine()
+	uri := writeObject(t, engine, "1.vng", 10)
+	cache := NewCacheWithOpts(engine, CacheOpts{IdleTimeout: time.Millisecond}, nil)
+	o, err := cache.Fetch(ctx, uri, ksuid.New())
+	require.NoError(t, err)
+	isClosed := func() bool {
+		o.readerMu.Lock()
+		defer o.readerMu.Unlock()
+		return o.reader == nil
+	}
+	require.Eventually(t, isClosed, time.Second, time.Millisecond)
+	// The reader is reopened to load vectors.
+	require.Len(t, readAll(t, o), 10)
+	require.Eventually(t, isClosed, time.Second, time.Millisecond)
+}
+
+func TestCacheConcurrentFetch(t *testing.T) {
+	ctx := context.Background()
+	engine := storage.NewLocalEngine()
+	uri := writeObject(t, engine, "1.vng", 10)
+	id := ksuid.New()
+	cache := NewCache(engine)
+	objects := make([]*Object, 8)
+	var group errgroup.Group
+	for k := range objects {
+		k := k
+		group.Go(func() error {
+			var err error
+			objects[k], err = cache.Fetch(ctx, uri, id)
+			return err
+		})
+	}
+	require.NoError(t, group.Wait())
+	for _, o := range objects {
+		require.Same(t, objects[0], o)
+	}
+	require.Len(t, cache.objects, 1)
+	require.Equal(t, 1, cache.lru.Len())
+}
@@ -0,0 +1,149 @@
+package vcache
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+)
+
+// A column holds the values of a vector materialized as a slice with one
+// element per value and a nil element for each null.
+type column struct {
+	typ  zed.Type
+	vals []zcode.Bytes
+	size int
+}
+
+// count returns the number of values of the type with ID id.  It must be
+// called with o.mu held.
+func (o *Object) count(id int) int {
+	if o.counts == nil {
+		o.counts = make([]int, len(o.types))
+		for _, id := range o.typeIDs {
+			o.counts[id]++
+		}
+	}
+	return o.counts[id]
+}
+
+// load materializes the n values of vector v.  It must be called with o.mu
+// held.
+func (o *Object) load(v Vector, typ zed.Type, n int) (*column, error) {
+	it, err := v.NewIter(o)
+	if err != nil {
+		return nil, err
+	}
+	var b zcode.Builder
+	for k := 0; k < n; k++ {
+		if err := it(&b); err != nil {
+			return nil, err
+		}
+	}
+	vals := make([]zcode.Bytes, 0, n)
+	for it := b.Bytes().Iter(); !it.Done(); {
+		vals = append(vals, it.Next())
+	}
+	return &column{typ: typ, vals: vals, size: len(b.Bytes())}, nil
+}
+
+// loadSelected is like load but, if v is a primitive vector whose data is
+// not loaded and some of whose segments hold no value selected by sel, it
+// reads only the segments holding selected values and materializes a null
+// for each of the other values.  The data so read is not kept in the cache.
+// sel may be nil, in which case every value is selected.  It must be called
+// with o.mu held.
+func (o *Object) loadSelected(v Vector, typ zed.Type, n int, sel []bool) (*column, error) {
+	if p, ok := v.(*Primitive); ok && sel != nil && p.bytes == nil && p.count() == n && p.skips(sel) {
+		return p.loadSegments(o, typ, sel)
+	}
+	return o.load(v, typ, n)
+}
+
+// values returns a column of the values of the type with ID id.  Values not
+// selected by sel may be null.
+func (o *Object) values(id int, sel []bool) (*column, error) {
+	o.mu.Lock()
+	defer o.unlock()
+	return o.loadSelected(o.vectors[id], o.types[id], o.count(id), sel)
+}
+
+// field returns a column of the values of the field name in the values of
+// the type with ID id.  It returns nil if the type is not a record type or
+// has no such field.  A null record has a null value for each field.  The
+// values of records not selected by sel may be null.
+func (o *Object) field(id int, name string, sel []bool) (*column, error) {
+	typ := zed.TypeRecordOf(o.types[id])
+	if typ == nil {
+		return nil, nil
+	}
+	i, ok := typ.IndexOfField(name)
+	if !ok {
+		return nil, nil
+	}
+	o.mu.Lock()
+	defer o.unlock()
+	n := o.count(id)
+	if record, ok := o.vectors[id].(Record); ok {
+		// The common case: no record is null, so the field's vector
+		// holds a value for each record.
+		return o.loadSelected(record[i], typ.Fields[i].Type, n, sel)
+	}
+	// Some records are null, so we materialize the records and pick out
+	// the field from each.
+	records, err := o.load(o.vectors[id], typ, n)
+	if err != nil {
+		return nil, err
+	}
+	vals := records.vals
+	for k, b := range vals {
+		if b != nil {
+			it := b.Iter()
+			for j := 0; j < i; j++ {
+				it.Next()
+			}
+			vals[k] = it.Next()
+		}
+	}
+	return &column{typ: typ.Fields[i].Type, vals: vals, size: records.size}, nil
+}
+
+// countOf returns the number of values of the type with ID id.
+func (o *Object) countOf(id int) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.count(id)
+}
+
+// fieldCache loads each field column of the type with ID id at most once
+// and tallies the size of the loaded columns.  If sel is not nil, the
+// columns hold only the values it selects and may be null elsewhere.
+type fieldCache struct {
+	object *Object
+	id     int
+	sel    []bool
+	cols   map[string]*column
+	size   int
+}
+
+func newFieldCache(o *Object, id int, sel []bool) *fieldCache {
+	return &fieldCache{
+		object: o,
+		id:     id,
+		sel:    sel,
+		cols:   make(map[string]*column),
+	}
+}
+
+func (f *fieldCache) get(name string) (*column, error) {
+	if col, ok := f.cols[name]; ok {
+		return col, nil
+	}
+	col, err := f.object.field(f.id, name, f.sel)
+	if err != nil {
+		return nil, err
+	}
+	if col != nil {
+		f.size += col.size
+	}
+	f.cols[name] = col
+	return col, nil
+}
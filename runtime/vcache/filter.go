@@ -0,0 +1,133 @@
+package vcache
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/vng"
+)
+
+// Filter is a predicate over the values of an object arranged for
+// evaluation a column at a time.  A value passes the filter if each of its
+// terms evaluates to true.
+type Filter struct {
+	columns  []ColumnFilter
+	residual expr.Evaluator
+	full     expr.Evaluator
+	pruner   expr.Evaluator
+}
+
+// ColumnFilter is a filter term that depends on a single top-level field.
+// Expr is evaluated with the field's value as "this".
+type ColumnFilter struct {
+	Field string
+	Expr  expr.Evaluator
+}
+
+// NewFilter returns a Filter comprising the column terms in columns and the
+// remaining terms in residual, which is evaluated for each value that passes
+// the column terms and may be nil.  full is the entire filter and is
+// evaluated for values that are not records.  pruner is evaluated for the
+// column statistics of each type (as returned by vng.Object.Stats) and
+// skips the values of the type when true.  It may be nil.
+func NewFilter(columns []ColumnFilter, residual, full, pruner expr.Evaluator) *Filter {
+	return &Filter{
+		columns:  columns,
+		residual: residual,
+		full:     full,
+		pruner:   pruner,
+	}
+}
+
+// prune returns true if the column statistics in stats rule out every
+// value of their type.
+func (f *Filter) prune(zctx *zed.Context, ectx *expr.ResetContext, stats *zed.Value) (bool, error) {
+	if f == nil || f.pruner == nil || stats == nil {
+		return false, nil
+	}
+	typ, err := zctx.TranslateType(stats.Type)
+	if err != nil {
+		return false, err
+	}
+	return isTrue(f.pruner.Eval(ectx.Reset(), zed.NewValue(typ, stats.Bytes()))), nil
+}
+
+// selectSegments returns the selection vector for the n values of a type
+// in which the values of each span in spans whose statistics rule out f
+// are not selected.  A nil selection vector means no value is selected.
+func (f *Filter) selectSegments(zctx *zed.Context, ectx *expr.ResetContext, spans []vng.SpanStats, n int) ([]bool, error) {
+	sel := make([]bool, n)
+	for k := range sel {
+		sel[k] = true
+	}
+	if f == nil || f.pruner == nil {
+		return sel, nil
+	}
+	selected := n
+	for _, s := range spans {
+		pruned, err := f.prune(zctx, ectx, s.Stats)
+		if err != nil {
+			return nil, err
+		}
+		if pruned {
+			end := min(s.Off+s.Len, n)
+			for k := s.Off; k < end; k++ {
+				sel[k] = false
+			}
+			selected -= end - s.Off
+		}
+	}
+	if selected <= 0 {
+		return nil, nil
+	}
+	return sel, nil
+}
+
+// selectColumns refines the selection vector sel of the values of a record
+// type, whose field columns are in cols, by the column terms of f and
+// returns it.  A nil selection vector means no value is selected.
+func (f *Filter) selectColumns(zctx *zed.Context, ectx *expr.ResetContext, cols *fieldCache, sel []bool) ([]bool, error) {
+	if f == nil {
+		return sel, nil
+	}
+	for _, c := range f.columns {
+		col, err := cols.get(c.Field)
+		if err != nil {
+			return nil, err
+		}
+		if col == nil {
+			// The field is missing from every value of this type
+			// so the term has the same result for each of them.
+			if !isTrue(c.Expr.Eval(ectx.Reset(), zctx.Missing())) {
+				return nil, nil
+			}
+			continue
+		}
+		typ, err := zctx.TranslateType(col.typ)
+		if err != nil {
+			return nil, err
+		}
+		var selected bool
+		for k, b := range col.vals {
+			if sel[k] {
+				sel[k] = isTrue(c.Expr.Eval(ectx.Reset(), ectx.NewValue(typ, b)))
+				selected = selected || sel[k]
+			}
+		}
+		if !selected {
+			return nil, nil
+		}
+	}
+	return sel, nil
+}
+
+func (f *Filter) evalResidual(ectx *expr.ResetContext, val *zed.Value) bool {
+	return f == nil || f.residual == nil || isTrue(f.residual.Eval(ectx.Reset(), val))
+}
+
+func (f *Filter) evalFull(ectx *expr.ResetContext, val *zed.Value) bool {
+	return f == nil || f.full == nil || isTrue(f.full.Eval(ectx.Reset(), val))
+}
+
+func isTrue(val *zed.Value) bool {
+	return val.Type == zed.TypeBool && val.Bool()
+}
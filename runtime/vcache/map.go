@@ -31,26 +31,30 @@ func NewMap(m *vector.Map, r io.ReaderAt) (*Map, error) {
 	}, nil
 }
 
-func (m *Map) NewIter(reader io.ReaderAt) (iterator, error) {
+func (m *Map) NewIter(o *Object) (iterator, error) {
 	// The lengths vector is typically large and is loaded on demand.
 	if m.lengths == nil {
-		lengths, err := vng.ReadIntVector(m.segmap, reader)
+		lengths, err := vng.ReadIntVector(m.segmap, o.readerAt())
 		if err != nil {
 			return nil, err
 		}
 		m.lengths = lengths
+		o.loaded(m, 4*len(lengths))
+	} else {
+		o.touch(m)
 	}
-	keys, err := m.keys.NewIter(reader)
+	lengths := m.lengths
+	keys, err := m.keys.NewIter(o)
 	if err != nil {
 		return nil, err
 	}
-	values, err := m.values.NewIter(reader)
+	values, err := m.values.NewIter(o)
 	if err != nil {
 		return nil, err
 	}
 	off := 0
 	return func(b *zcode.Builder) error {
-		len := m.lengths[off]
+		len := lengths[off]
 		off++
 		b.BeginContainer()
 		for ; len > 0; len-- {
@@ -65,3 +69,7 @@ func (m *Map) NewIter(reader io.ReaderAt) (iterator, error) {
 		return nil
 	}, nil
 }
+
+func (m *Map) unload() {
+	m.lengths = nil
+}
@@ -0,0 +1,58 @@
+package vcache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	hits       prometheus.Counter
+	misses     prometheus.Counter
+	evictions  *prometheus.CounterVec
+	idleCloses prometheus.Counter
+	bytes      prometheus.Gauge
+	objects    prometheus.Gauge
+}
+
+func newMetrics(reg prometheus.Registerer) metrics {
+	factory := promauto.With(reg)
+	return metrics{
+		hits: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "vcache_hits_total",
+				Help: "Number of object fetches found in the vector cache.",
+			},
+		),
+		misses: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "vcache_misses_total",
+				Help: "Number of object fetches not found in the vector cache.",
+			},
+		),
+		evictions: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "vcache_evictions_total",
+				Help: "Number of objects and vectors evicted from the vector cache.",
+			},
+			[]string{"kind"},
+		),
+		idleCloses: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "vcache_idle_closes_total",
+				Help: "Number of object readers closed after the idle timeout.",
+			},
+		),
+		bytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "vcache_bytes",
+				Help: "Bytes of vector data and object metadata held by the vector cache.",
+			},
+		),
+		objects: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "vcache_objects",
+				Help: "Number of objects held by the vector cache.",
+			},
+		),
+	}
+}
@@ -40,10 +40,10 @@ func NewNulls(nulls *vector.Nulls, values Vector, r io.ReaderAt) (*Nulls, error)
 	}, nil
 }
 
-func (n *Nulls) NewIter(reader io.ReaderAt) (iterator, error) {
+func (n *Nulls) NewIter(o *Object) (iterator, error) {
 	null := true
 	var run, off int
-	values, err := n.values.NewIter(reader)
+	values, err := n.values.NewIter(o)
 	if err != nil {
 		return nil, err
 	}
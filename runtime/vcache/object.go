@@ -1,8 +1,12 @@
 package vcache
 
 import (
+	"container/list"
 	"context"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/pkg/storage"
@@ -22,7 +26,13 @@ type Object struct {
 	id     ksuid.KSUID
 	uri    *storage.URI
 	engine storage.Engine
-	reader storage.Reader
+	// cache is the cache holding the object or nil if the object was
+	// created outside of a cache.  elem is the object's entry in the
+	// cache's LRU list and entries holds the entries of its loaded vectors.
+	// Both are guarded by cache.mu and are nil once the object is evicted.
+	cache   *Cache
+	elem    *list.Element
+	entries map[loadable]*list.Element
 	// We keep a local context for each object since a new type context is created
 	// for each query and we need to map the VNG object context to the query
 	// context.  Of course, with Zed, this is very cheap.
@@ -34,6 +44,25 @@ type Object struct {
 	vectors []Vector
 	types   []zed.Type
 	typeIDs []int32
+	// stats holds the column statistics of each type, which are nil for a
+	// type without statistics, and segStats holds the statistics of the
+	// spans of values of each type delimited by its column segments.
+	stats    []*zed.Value
+	segStats [][]vng.SpanStats
+	// mu serializes the loading and unloading of vectors by the kernels,
+	// which may run concurrently for different queries, and by the cache.
+	// counts holds the number of values of each type and is computed on
+	// first use.  evicted is true once the object is evicted from its cache.
+	mu      sync.Mutex
+	counts  []int
+	evicted bool
+	// readerMu guards the storage reader, which is opened on demand when
+	// vectors are loaded and is closed after idleTimeout without use.
+	readerMu    sync.Mutex
+	reader      storage.Reader
+	idleTimeout time.Duration
+	lastUsed    time.Time
+	timer       *time.Timer
 }
 
 // NewObject creates a new in-memory Object corresponding to a VNG object
@@ -42,15 +71,19 @@ type Object struct {
 // the metadata needed to load vector chunks on demand only as they are
 // referenced.
 func NewObject(ctx context.Context, engine storage.Engine, uri *storage.URI, id ksuid.KSUID) (*Object, error) {
-	// XXX currently we open a storage.Reader for every object and never close it.
-	// We should either close after a timeout and reopen when needed or change the
-	// storage API to have a more reasonable semantics around the Put/Get not leaving
-	// a file descriptor open for every long Get.  Perhaps there should be another
-	// method for intermitted random access.
 	reader, err := engine.Get(ctx, uri)
 	if err != nil {
 		return nil, err
 	}
+	o, err := newObject(engine, uri, id, reader)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return o, nil
+}
+
+func newObject(engine storage.Engine, uri *storage.URI, id ksuid.KSUID, reader storage.Reader) (*Object, error) {
 	size, err := storage.Size(reader)
 	if err != nil {
 		return nil, err
@@ -71,8 +104,12 @@ func NewObject(ctx context.Context, engine storage.Engine, uri *storage.URI, id
 		return nil, fmt.Errorf("too many types in VNG object: %s", uri)
 	}
 	types := make([]zed.Type, 0, len(metas))
-	for _, meta := range metas {
+	stats := make([]*zed.Value, 0, len(metas))
+	segStats := make([][]vng.SpanStats, 0, len(metas))
+	for k, meta := range metas {
 		types = append(types, meta.Type(zctx))
+		stats = append(stats, z.Stats(k))
+		segStats = append(segStats, z.SegmentStats(k))
 	}
 	var group errgroup.Group
 	vectors := make([]Vector, len(metas))
@@ -92,22 +129,150 @@ func NewObject(ctx context.Context, engine storage.Engine, uri *storage.URI, id
 		return nil, err
 	}
 	return &Object{
-		id:      id,
-		uri:     uri,
-		engine:  engine,
-		reader:  reader,
-		local:   zctx,
-		vectors: vectors,
-		types:   types,
-		typeIDs: typeIDs,
+		id:       id,
+		uri:      uri,
+		engine:   engine,
+		reader:   reader,
+		local:    zctx,
+		vectors:  vectors,
+		types:    types,
+		typeIDs:  typeIDs,
+		stats:    stats,
+		segStats: segStats,
+		lastUsed: time.Now(),
 	}, nil
 }
 
 func (o *Object) Close() error {
-	if o.reader != nil {
-		return o.reader.Close()
+	o.readerMu.Lock()
+	defer o.readerMu.Unlock()
+	return o.closeReader()
+}
+
+// closeReader closes the storage reader if it is open.  It must be called
+// with o.readerMu held.
+func (o *Object) closeReader() error {
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	if o.reader == nil {
+		return nil
+	}
+	err := o.reader.Close()
+	o.reader = nil
+	return err
+}
+
+// readerAt returns the reader through which vectors of the object load
+// their data.  It must be called with o.mu held.
+func (o *Object) readerAt() io.ReaderAt {
+	return (*objectReader)(o)
+}
+
+// objectReader is an io.ReaderAt that reopens the object's storage reader
+// if it was closed because it was idle.  Vectors that are already loaded
+// are thus iterated without an open reader.
+type objectReader Object
+
+func (r *objectReader) ReadAt(b []byte, off int64) (int, error) {
+	o := (*Object)(r)
+	o.readerMu.Lock()
+	if o.reader == nil {
+		// The vector loads that get here have no context of their
+		// own as they run on behalf of any query using the object.
+		reader, err := o.engine.Get(context.Background(), o.uri)
+		if err != nil {
+			o.readerMu.Unlock()
+			return 0, err
+		}
+		o.reader = reader
+	}
+	reader := o.reader
+	o.lastUsed = time.Now()
+	if o.idleTimeout > 0 {
+		if o.timer == nil {
+			o.timer = time.AfterFunc(o.idleTimeout, o.closeIdle)
+		} else {
+			o.timer.Reset(o.idleTimeout)
+		}
+	}
+	o.readerMu.Unlock()
+	return reader.ReadAt(b, off)
+}
+
+// closeIdle closes the storage reader if it has not been used for the idle
+// timeout.  Holding o.mu ensures no vector is loading.
+func (o *Object) closeIdle() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.readerMu.Lock()
+	defer o.readerMu.Unlock()
+	if o.reader == nil || time.Since(o.lastUsed) < o.idleTimeout {
+		return
 	}
-	return nil
+	o.closeReader()
+	if o.cache != nil {
+		o.cache.metrics.idleCloses.Inc()
+	}
+}
+
+// unlock releases o.mu after vectors have been loaded.  It closes the
+// storage reader of an evicted object, which no longer counts against the
+// budget of the cache, and then shrinks the cache to fit its budget.
+func (o *Object) unlock() {
+	if o.evicted {
+		o.readerMu.Lock()
+		o.closeReader()
+		o.readerMu.Unlock()
+	}
+	o.mu.Unlock()
+	if o.cache != nil {
+		o.cache.shrink()
+	}
+}
+
+// loaded notes that the data of vector v, which is size bytes, has been
+// loaded.  touch notes that the loaded data of v has been used.
+func (o *Object) loaded(v loadable, size int) {
+	if o.cache != nil {
+		o.cache.loaded(o, v, int64(size))
+	}
+}
+
+func (o *Object) touch(v loadable) {
+	if o.cache != nil {
+		o.cache.touch(o, v)
+	}
+}
+
+// unload unloads vectors as they are evicted from the cache and closes the
+// storage reader if the object itself is evicted.
+func (o *Object) unload(vectors []loadable, evicted bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, v := range vectors {
+		v.unload()
+	}
+	if evicted {
+		o.evicted = true
+		o.readerMu.Lock()
+		o.closeReader()
+		o.readerMu.Unlock()
+	}
+}
+
+// size returns the number of bytes of the object's metadata charged to the
+// cache, which is dominated by the type ID of each value.
+func (o *Object) size() int64 {
+	return int64(4 * len(o.typeIDs))
+}
+
+// newIter returns an iterator over the values of vector v, loading its data
+// as needed.
+func (o *Object) newIter(v Vector) (iterator, error) {
+	o.mu.Lock()
+	defer o.unlock()
+	return v.NewIter(o)
 }
 
 func (o *Object) NewReader() *Reader {
@@ -1,8 +1,9 @@
 package vcache
 
 import (
-	"io"
+	"slices"
 
+	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/vng/vector"
 	"github.com/brimdata/zed/zcode"
 )
@@ -16,7 +17,7 @@ func NewPrimitive(meta *vector.Primitive) (*Primitive, error) {
 	return &Primitive{meta: meta}, nil
 }
 
-func (p *Primitive) NewIter(r io.ReaderAt) (iterator, error) {
+func (p *Primitive) NewIter(o *Object) (iterator, error) {
 	if p.bytes == nil {
 		// The VNG primitive columns are stored as one big
 		// list of Zed values.  So we can just read the data in
@@ -29,12 +30,15 @@ func (p *Primitive) NewIter(r io.ReaderAt) (iterator, error) {
 		data := make([]byte, n)
 		var off int
 		for _, segment := range p.meta.Segmap {
-			if err := segment.Read(r, data[off:]); err != nil {
+			if err := segment.Read(o.readerAt(), data[off:]); err != nil {
 				return nil, err
 			}
 			off += int(segment.MemLength)
 		}
 		p.bytes = data
+		o.loaded(p, n)
+	} else {
+		o.touch(p)
 	}
 	if dict := p.meta.Dict; dict != nil {
 		bytes := p.bytes
@@ -52,6 +56,64 @@ func (p *Primitive) NewIter(r io.ReaderAt) (iterator, error) {
 	}, nil
 }
 
+// count returns the number of values in p.
+func (p *Primitive) count() int {
+	var n int
+	for _, segment := range p.meta.Segmap {
+		n += int(segment.Count)
+	}
+	return n
+}
+
+// skips returns true if some segment of p holds no value selected by sel.
+func (p *Primitive) skips(sel []bool) bool {
+	var off int
+	for _, segment := range p.meta.Segmap {
+		end := off + int(segment.Count)
+		if !slices.Contains(sel[off:end], true) {
+			return true
+		}
+		off = end
+	}
+	return false
+}
+
+// loadSegments materializes the values of p, reading only the segments that
+// hold a value selected by sel and leaving the values of the other segments
+// null.
+func (p *Primitive) loadSegments(o *Object, typ zed.Type, sel []bool) (*column, error) {
+	var b zcode.Builder
+	var off int
+	for _, segment := range p.meta.Segmap {
+		end := off + int(segment.Count)
+		if !slices.Contains(sel[off:end], true) {
+			for ; off < end; off++ {
+				b.Append(nil)
+			}
+			continue
+		}
+		data := make([]byte, segment.MemLength)
+		if err := segment.Read(o.readerAt(), data); err != nil {
+			return nil, err
+		}
+		if dict := p.meta.Dict; dict != nil {
+			for _, pos := range data {
+				b.Append(dict[pos].Value.Bytes())
+			}
+		} else {
+			for it := zcode.Iter(data); !it.Done(); {
+				b.Append(it.Next())
+			}
+		}
+		off = end
+	}
+	vals := make([]zcode.Bytes, 0, off)
+	for it := b.Bytes().Iter(); !it.Done(); {
+		vals = append(vals, it.Next())
+	}
+	return &column{typ: typ, vals: vals, size: len(b.Bytes())}, nil
+}
+
 type Const struct {
 	bytes zcode.Bytes
 }
@@ -60,7 +122,11 @@ func NewConst(meta *vector.Const) *Const {
 	return &Const{bytes: meta.Value.Bytes()}
 }
 
-func (c *Const) NewIter(r io.ReaderAt) (iterator, error) {
+func (p *Primitive) unload() {
+	p.bytes = nil
+}
+
+func (c *Const) NewIter(*Object) (iterator, error) {
 	return func(b *zcode.Builder) error {
 		b.Append(c.bytes)
 		return nil
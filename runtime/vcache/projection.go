@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/brimdata/zed"
-	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/zcode"
 	"github.com/brimdata/zed/zio"
 	"golang.org/x/sync/errgroup"
@@ -57,6 +56,8 @@ func (p *Projection) Read() (*zed.Value, error) {
 }
 
 func findCuts(o *Object, names []string) ([]*cut, error) {
+	o.mu.Lock()
+	defer o.unlock()
 	var dirty bool
 	cuts := make([]*cut, len(o.types))
 	var group errgroup.Group
@@ -82,7 +83,7 @@ func findCuts(o *Object, names []string) ([]*cut, error) {
 		dirty = true
 		whichCut := k
 		group.Go(func() error {
-			c, err := newCut(o.local, recType, fields, actuals, o.reader)
+			c, err := newCut(o, recType, fields, actuals)
 			cuts[whichCut] = c
 			return err
 		})
@@ -96,7 +97,7 @@ func findCuts(o *Object, names []string) ([]*cut, error) {
 	return cuts, nil
 }
 
-func newCut(zctx *zed.Context, typ *zed.TypeRecord, fields []Vector, actuals []string, reader storage.Reader) (*cut, error) {
+func newCut(o *Object, typ *zed.TypeRecord, fields []Vector, actuals []string) (*cut, error) {
 	var group errgroup.Group
 	iters := make([]iterator, len(actuals))
 	outFields := make([]zed.Field, len(actuals))
@@ -105,7 +106,7 @@ func newCut(zctx *zed.Context, typ *zed.TypeRecord, fields []Vector, actuals []s
 		outFields[k] = typ.Fields[i]
 		which := k
 		group.Go(func() error {
-			it, err := fields[i].NewIter(reader)
+			it, err := fields[i].NewIter(o)
 			if err != nil {
 				return err
 			}
@@ -116,7 +117,7 @@ func newCut(zctx *zed.Context, typ *zed.TypeRecord, fields []Vector, actuals []s
 	if err := group.Wait(); err != nil {
 		return nil, err
 	}
-	outType, err := zctx.LookupTypeRecord(outFields)
+	outType, err := o.local.LookupTypeRecord(outFields)
 	if err != nil {
 		return nil, err
 	}
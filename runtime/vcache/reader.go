@@ -26,7 +26,7 @@ func (r *Reader) Read() (*zed.Value, error) {
 	it := r.iters[id]
 	if it == nil {
 		var err error
-		it, err = o.vectors[id].NewIter(o.reader)
+		it, err = o.newIter(o.vectors[id])
 		if err != nil {
 			return nil, err
 		}
@@ -22,14 +22,14 @@ func NewRecord(fields []vector.Field, r io.ReaderAt) (Record, error) {
 	return record, nil
 }
 
-func (r Record) NewIter(reader io.ReaderAt) (iterator, error) {
+func (r Record) NewIter(o *Object) (iterator, error) {
 	fields := make([]iterator, len(r))
 	var group errgroup.Group
 	for k, f := range r {
 		which := k
 		field := f
 		group.Go(func() error {
-			it, err := field.NewIter(reader)
+			it, err := field.NewIter(o)
 			if err != nil {
 				return err
 			}
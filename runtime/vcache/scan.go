@@ -0,0 +1,169 @@
+package vcache
+
+import (
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+)
+
+// Scan reads the values of an object that pass a Filter with each record
+// cut to a set of top-level fields.  Unlike a Projection, a Scan returns a
+// value for every record, even one with none of the fields, and returns
+// values that are not records in their entirety, so a query that uses only
+// those fields gets the same result from the Scan as from the whole
+// object.  Values are returned in the query's type context.
+type Scan struct {
+	object   *Object
+	filter   *Filter
+	types    []*scanType
+	off      int
+	ectx     expr.ResetContext
+	builder  zcode.Builder
+	val      zed.Value
+	progress zbuf.Progress
+}
+
+type scanType struct {
+	typ zed.Type
+	// pruned is true if the column statistics of the type or of its
+	// segments rule out the filter, in which case no values of the type
+	// are loaded.
+	pruned bool
+	record bool
+	// sel is the selection vector of the values not ruled out by the
+	// statistics of their segments.  For a record type, sel is further
+	// refined by the column terms of the filter (nil if no value is
+	// selected) and cols holds the fields of the cut.  For any other type,
+	// cols holds the values.
+	sel  []bool
+	cols []*column
+	off  int
+}
+
+var _ zio.Reader = (*Scan)(nil)
+
+func (o *Object) NewScan(zctx *zed.Context, fields []string, filter *Filter) (*Scan, error) {
+	s := &Scan{
+		object: o,
+		filter: filter,
+		types:  make([]*scanType, len(o.types)),
+	}
+	for id, typ := range o.types {
+		t, err := s.newScanType(zctx, id, typ, fields)
+		if err != nil {
+			return nil, err
+		}
+		s.types[id] = t
+	}
+	return s, nil
+}
+
+func (s *Scan) newScanType(zctx *zed.Context, id int, typ zed.Type, fields []string) (*scanType, error) {
+	o := s.object
+	pruned, err := s.filter.prune(zctx, &s.ectx, o.stats[id])
+	if err != nil || pruned {
+		return &scanType{pruned: true}, err
+	}
+	sel, err := s.filter.selectSegments(zctx, &s.ectx, o.segStats[id], o.countOf(id))
+	if err != nil || sel == nil {
+		return &scanType{pruned: true}, err
+	}
+	recType := zed.TypeRecordOf(typ)
+	if recType == nil {
+		vals, err := o.values(id, sel)
+		if err != nil {
+			return nil, err
+		}
+		s.progress.BytesRead += int64(vals.size)
+		typ, err := zctx.TranslateType(typ)
+		if err != nil {
+			return nil, err
+		}
+		return &scanType{typ: typ, sel: sel, cols: []*column{vals}}, nil
+	}
+	fc := newFieldCache(o, id, sel)
+	defer func() { s.progress.BytesRead += int64(fc.size) }()
+	sel, err = s.filter.selectColumns(zctx, &s.ectx, fc, sel)
+	if err != nil {
+		return nil, err
+	}
+	var cols []*column
+	outFields := []zed.Field{}
+	if sel != nil {
+		for _, f := range recType.Fields {
+			if !contains(fields, f.Name) {
+				continue
+			}
+			col, err := fc.get(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			typ, err := zctx.TranslateType(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, col)
+			outFields = append(outFields, zed.NewField(f.Name, typ))
+		}
+	}
+	outType, err := zctx.LookupTypeRecord(outFields)
+	if err != nil {
+		return nil, err
+	}
+	return &scanType{typ: outType, record: true, sel: sel, cols: cols}, nil
+}
+
+func (s *Scan) Read() (*zed.Value, error) {
+	o := s.object
+	for s.off < len(o.typeIDs) {
+		t := s.types[o.typeIDs[s.off]]
+		s.off++
+		k := t.off
+		t.off++
+		s.progress.RecordsRead++
+		if t.pruned || t.sel == nil || !t.sel[k] {
+			continue
+		}
+		if t.record {
+			s.builder.Truncate()
+			for _, col := range t.cols {
+				s.builder.Append(col.vals[k])
+			}
+			b := s.builder.Bytes()
+			if b == nil {
+				// A record with no fields is empty, not null.
+				b = zcode.Bytes{}
+			}
+			s.val = *zed.NewValue(t.typ, b)
+			if !s.filter.evalResidual(&s.ectx, &s.val) {
+				continue
+			}
+		} else {
+			s.val = *zed.NewValue(t.typ, t.cols[0].vals[k])
+			if !s.filter.evalFull(&s.ectx, &s.val) {
+				continue
+			}
+		}
+		s.progress.RecordsMatched++
+		s.progress.BytesMatched += int64(len(s.val.Bytes()))
+		return &s.val, nil
+	}
+	return nil, nil
+}
+
+// Progress returns the number of bytes materialized from the object's
+// vectors and the number of values read and matched so far.
+func (s *Scan) Progress() zbuf.Progress {
+	return s.progress
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package vcache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/vng"
+	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
+	"github.com/brimdata/zed/zio/vngio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/brimdata/zed/zson"
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/require"
+)
+
+type bytesReader struct {
+	*bytes.Reader
+}
+
+func (bytesReader) Close() error { return nil }
+
+// segmentedObject returns an object holding the n records {x:k} for k from
+// 0 to n-1 with the x column flushed into a segment every thresh values.
+// (The VNG writer does not yet split columns into segments.)
+func segmentedObject(t *testing.T, n, thresh int) *Object {
+	zctx := zed.NewContext()
+	typ, err := zctx.LookupTypeRecord([]zed.Field{zed.NewField("x", zed.TypeInt64)})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	spiller := vector.NewSpiller(&buf, vng.MaxSegmentThresh)
+	w := vector.NewWriter(typ, spiller)
+	for k := 0; k < n; k++ {
+		var b zcode.Builder
+		b.Append(zed.EncodeInt(int64(k)))
+		require.NoError(t, w.Write(b.Bytes()))
+		if (k+1)%thresh == 0 {
+			require.NoError(t, w.Flush(false))
+		}
+	}
+	require.NoError(t, w.Flush(true))
+	reader := bytesReader{bytes.NewReader(buf.Bytes())}
+	z := &vng.Object{
+		ReaderAt: reader,
+		Zctx:     zctx,
+		Maps:     []vector.Metadata{w.Metadata()},
+	}
+	v, err := NewVector(z.Maps[0], reader)
+	require.NoError(t, err)
+	return &Object{
+		reader:   reader,
+		local:    zctx,
+		vectors:  []Vector{v},
+		types:    []zed.Type{typ},
+		typeIDs:  make([]int32, n),
+		stats:    []*zed.Value{z.Stats(0)},
+		segStats: [][]vng.SpanStats{z.SegmentStats(0)},
+	}
+}
+
+// pruneBelow is a pruner that rules out the values whose x column has a
+// maximum below it.
+type pruneBelow int64
+
+func (p pruneBelow) Eval(_ expr.Context, stats *zed.Value) *zed.Value {
+	if max := stats.Deref("x").Deref("max"); max != nil && max.Int() < int64(p) {
+		return zed.True
+	}
+	return zed.False
+}
+
+func TestScanSegmentPruning(t *testing.T) {
+	o := segmentedObject(t, 900, 300)
+	s, err := o.NewScan(zed.NewContext(), []string{"x"}, NewFilter(nil, nil, nil, pruneBelow(600)))
+	require.NoError(t, err)
+	var vals []string
+	for {
+		val, err := s.Read()
+		require.NoError(t, err)
+		if val == nil {
+			break
+		}
+		vals = append(vals, zson.FormatValue(val))
+	}
+	require.Len(t, vals, 300)
+	require.Equal(t, "{x:600}", vals[0])
+	require.Equal(t, "{x:899}", vals[299])
+	// Only the last of the three segments is read, and it is not cached.
+	require.Nil(t, o.vectors[0].(Record)[0].(*Primitive).bytes)
+	all, err := o.NewScan(zed.NewContext(), []string{"x"}, nil)
+	require.NoError(t, err)
+	require.Less(t, s.Progress().BytesRead, all.Progress().BytesRead)
+
+	// A pruner ruling out every segment prunes the type.
+	s, err = o.NewScan(zed.NewContext(), []string{"x"}, NewFilter(nil, nil, nil, pruneBelow(900)))
+	require.NoError(t, err)
+	require.True(t, s.types[0].pruned)
+}
+
+func scanAll(t *testing.T, o *Object, f *Filter) []string {
+	s, err := o.NewScan(zed.NewContext(), []string{"x"}, f)
+	require.NoError(t, err)
+	var vals []string
+	for {
+		val, err := s.Read()
+		require.NoError(t, err)
+		if val == nil {
+			return vals
+		}
+		vals = append(vals, zson.FormatValue(val))
+	}
+}
+
+func TestScanCached(t *testing.T) {
+	ctx := context.Background()
+	engine := storage.NewLocalEngine()
+	var sb strings.Builder
+	for k := 0; k < 300; k++ {
+		fmt.Fprintf(&sb, "{x:%d}\n", k)
+	}
+	uri := storage.MustParseURI(filepath.Join(t.TempDir(), "1.vng"))
+	put, err := engine.Put(ctx, uri)
+	require.NoError(t, err)
+	w, err := vngio.NewWriter(put)
+	require.NoError(t, err)
+	require.NoError(t, zio.Copy(w, zsonio.NewReader(zed.NewContext(), strings.NewReader(sb.String()))))
+	require.NoError(t, w.Close())
+
+	cache := NewCache(engine)
+	o, err := cache.Fetch(ctx, uri, ksuid.New())
+	require.NoError(t, err)
+	// A scan whose filter prunes no segment loads its columns into the
+	// cache.
+	f := NewFilter(nil, nil, nil, pruneBelow(0))
+	expected := scanAll(t, o, f)
+	require.Len(t, expected, 300)
+	require.Len(t, o.entries, 1)
+
+	// The same scan again is served from the cache without reading the
+	// object, which is no longer in storage.
+	require.NoError(t, engine.Delete(ctx, uri))
+	o.readerMu.Lock()
+	require.NoError(t, o.closeReader())
+	o.readerMu.Unlock()
+	again, err := cache.Fetch(ctx, uri, o.id)
+	require.NoError(t, err)
+	require.Same(t, o, again)
+	require.Equal(t, expected, scanAll(t, o, f))
+	require.Equal(t, expected, scanAll(t, o, nil))
+}
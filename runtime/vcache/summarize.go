@@ -0,0 +1,196 @@
+package vcache
+
+import (
+	"encoding/binary"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/field"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/runtime/expr/agg"
+	"github.com/brimdata/zed/zbuf"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Summarize is an aggregation over top-level fields that Object.Summarize
+// computes a column at a time.
+type Summarize struct {
+	Keys []SummarizeKey
+	Aggs []SummarizeAgg
+}
+
+// SummarizeKey is a grouping key whose value is that of a top-level field.
+type SummarizeKey struct {
+	Name  field.Path
+	Field string
+}
+
+// SummarizeAgg is an aggregation of the values of a top-level field.  Field
+// is empty for an aggregation with no argument, i.e., count().
+type SummarizeAgg struct {
+	Name  field.Path
+	Agg   *expr.Aggregator
+	Field string
+}
+
+type group struct {
+	keyType int
+	funcs   []agg.Function
+}
+
+// CanSummarize returns true if o.Summarize can compute an aggregation over
+// the values of o that pass filter, i.e., if every value is a record and
+// each term of filter is a column term.
+func (o *Object) CanSummarize(filter *Filter) bool {
+	if filter != nil && filter.residual != nil {
+		return false
+	}
+	for _, typ := range o.types {
+		if zed.TypeRecordOf(typ) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Summarize computes s over the values of o that pass filter and returns
+// the partial result for each group as a record laid out like the output of
+// a summarize operator that produces partials.  The caller must check
+// CanSummarize first.
+func (o *Object) Summarize(zctx *zed.Context, filter *Filter, s *Summarize) ([]zed.Value, zbuf.Progress, error) {
+	var progress zbuf.Progress
+	names := make(field.List, 0, len(s.Keys)+len(s.Aggs))
+	for _, key := range s.Keys {
+		names = append(names, key.Name)
+	}
+	for _, a := range s.Aggs {
+		names = append(names, a.Name)
+	}
+	builder, err := zed.NewRecordBuilder(zctx, names)
+	if err != nil {
+		return nil, progress, err
+	}
+	keyTypes := zed.NewTypeVectorTable()
+	table := make(map[string]*group)
+	missing := zctx.Missing()
+	var ectx expr.ResetContext
+	var keyBytes zcode.Bytes
+	var types []zed.Type
+	keyCols := make([]*column, len(s.Keys))
+	keyColTypes := make([]zed.Type, len(s.Keys))
+	aggCols := make([]*column, len(s.Aggs))
+	aggColTypes := make([]zed.Type, len(s.Aggs))
+	for id := range o.types {
+		n := o.countOf(id)
+		progress.RecordsRead += int64(n)
+		pruned, err := filter.prune(zctx, &ectx, o.stats[id])
+		if err != nil {
+			return nil, progress, err
+		}
+		if pruned {
+			continue
+		}
+		sel, err := filter.selectSegments(zctx, &ectx, o.segStats[id], n)
+		if err != nil {
+			return nil, progress, err
+		}
+		if sel == nil {
+			continue
+		}
+		cols := newFieldCache(o, id, sel)
+		sel, err = filter.selectColumns(zctx, &ectx, cols, sel)
+		if err != nil {
+			return nil, progress, err
+		}
+		if sel == nil {
+			progress.BytesRead += int64(cols.size)
+			continue
+		}
+		for k, key := range s.Keys {
+			if keyCols[k], keyColTypes[k], err = lookupColumn(zctx, cols, key.Field); err != nil {
+				return nil, progress, err
+			}
+		}
+		for k, a := range s.Aggs {
+			if a.Field == "" {
+				continue
+			}
+			if aggCols[k], aggColTypes[k], err = lookupColumn(zctx, cols, a.Field); err != nil {
+				return nil, progress, err
+			}
+		}
+		progress.BytesRead += int64(cols.size)
+	Values:
+		for j := 0; j < n; j++ {
+			if !sel[j] {
+				continue
+			}
+			progress.RecordsMatched++
+			ectx.Reset()
+			keyBytes = keyBytes[:0]
+			types = types[:0]
+			for k, col := range keyCols {
+				key := missing
+				if col != nil {
+					key = ectx.NewValue(keyColTypes[k], col.vals[j])
+					if key.IsQuiet() {
+						continue Values
+					}
+				}
+				types = append(types, key.Type)
+				keyBytes = zcode.Append(keyBytes, key.Bytes())
+			}
+			keyType := keyTypes.Lookup(types)
+			keyBytes = binary.AppendUvarint(keyBytes, uint64(keyType))
+			g, ok := table[string(keyBytes)]
+			if !ok {
+				g = &group{
+					keyType: keyType,
+					funcs:   make([]agg.Function, 0, len(s.Aggs)),
+				}
+				for _, a := range s.Aggs {
+					g.funcs = append(g.funcs, a.Agg.NewFunction())
+				}
+				table[string(keyBytes)] = g
+			}
+			for k, f := range g.funcs {
+				if s.Aggs[k].Field == "" {
+					f.Consume(zed.True)
+				} else if col := aggCols[k]; col != nil {
+					f.Consume(ectx.NewValue(aggColTypes[k], col.vals[j]))
+				}
+			}
+		}
+	}
+	vals := make([]zed.Value, 0, len(table))
+	for key, g := range table {
+		builder.Reset()
+		types = types[:0]
+		it := zcode.Bytes(key).Iter()
+		for _, typ := range keyTypes.Types(g.keyType) {
+			builder.Append(it.Next())
+			types = append(types, typ)
+		}
+		for _, f := range g.funcs {
+			val := f.ResultAsPartial(zctx)
+			builder.Append(val.Bytes())
+			types = append(types, val.Type)
+		}
+		b, err := builder.Encode()
+		if err != nil {
+			return nil, progress, err
+		}
+		vals = append(vals, *zed.NewValue(builder.Type(types), b))
+	}
+	return vals, progress, nil
+}
+
+// lookupColumn returns the column for field from cols along with its type in
+// zctx.  It returns a nil column if the field is missing.
+func lookupColumn(zctx *zed.Context, cols *fieldCache, field string) (*column, zed.Type, error) {
+	col, err := cols.get(field)
+	if col == nil || err != nil {
+		return nil, nil, err
+	}
+	typ, err := zctx.TranslateType(col.typ)
+	return col, typ, err
+}
@@ -32,21 +32,25 @@ func NewUnion(union *vector.Union, r io.ReaderAt) (*Union, error) {
 	}, nil
 }
 
-func (u *Union) NewIter(reader io.ReaderAt) (iterator, error) {
+func (u *Union) NewIter(o *Object) (iterator, error) {
 	if u.tags == nil {
-		tags, err := vng.ReadIntVector(u.segmap, reader)
+		tags, err := vng.ReadIntVector(u.segmap, o.readerAt())
 		if err != nil {
 			return nil, err
 		}
 		u.tags = tags
+		o.loaded(u, 4*len(tags))
+	} else {
+		o.touch(u)
 	}
+	tags := u.tags
 	var group errgroup.Group
 	iters := make([]iterator, len(u.values))
 	for k, v := range u.values {
 		which := k
 		vals := v
 		group.Go(func() error {
-			it, err := vals.NewIter(reader)
+			it, err := vals.NewIter(o)
 			if err != nil {
 				return err
 			}
@@ -59,7 +63,7 @@ func (u *Union) NewIter(reader io.ReaderAt) (iterator, error) {
 	}
 	off := 0
 	return func(b *zcode.Builder) error {
-		tag := u.tags[off]
+		tag := tags[off]
 		off++
 		if tag < 0 || int(tag) >= len(iters) {
 			return fmt.Errorf("VNG cache: bad union tag encountered %d of %d", tag, len(iters))
@@ -73,3 +77,7 @@ func (u *Union) NewIter(reader io.ReaderAt) (iterator, error) {
 		return nil
 	}, nil
 }
+
+func (u *Union) unload() {
+	u.tags = nil
+}
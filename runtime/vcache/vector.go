@@ -15,7 +15,14 @@ type iterator func(*zcode.Builder) error
 // and various forms of pushdown, we will enhance this interface with
 // corresponding methods.
 type Vector interface {
-	NewIter(io.ReaderAt) (iterator, error)
+	NewIter(*Object) (iterator, error)
+}
+
+// A loadable is a vector whose data is loaded from storage on demand and
+// which may be unloaded by the cache to free memory.
+type loadable interface {
+	Vector
+	unload()
 }
 
 // NewVector converts a VNG metadata reader to its equivalent vector cache
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/brimdata/zed/lake/audit"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/service/auth"
+	"github.com/gorilla/mux"
+	"github.com/segmentio/ksuid"
+)
+
+// auditMiddleware records each request handled by next in the audit log of
+// the lake as op.  The pool and branch of the record are taken from the
+// route's "pool" and "branch" or "revision" parameters and its targets from
+// the "child", "commit", and "tag" parameters.  Handlers add the commits and
+// other objects they create or delete with Request.auditTargets, as do the
+// operations of lake/api, to which the record is passed in the request's
+// context.
+func auditMiddleware(op string, next func(*Core, *ResponseWriter, *Request)) func(*Core, *ResponseWriter, *Request) {
+	return func(c *Core, w *ResponseWriter, r *Request) {
+		ident := auth.IdentityFromContext(r.Context())
+		rec := audit.NewRecord(op)
+		rec.TenantID = string(ident.TenantID)
+		rec.UserID = string(ident.UserID)
+		rec.RequestID = r.ID()
+		vars := mux.Vars(r.Request)
+		if s, err := url.QueryUnescape(vars["pool"]); err == nil && s != "" {
+			// The pool is looked up before next runs as next may
+			// delete or rename it.
+			if id, err := lakeparse.ParseID(s); err == nil {
+				rec.Pool = id
+			} else if id, err := c.root.PoolID(r.Context(), s); err == nil {
+				rec.Pool = id
+			}
+		}
+		for _, param := range []string{"branch", "revision"} {
+			if s, err := url.QueryUnescape(vars[param]); err == nil && s != "" {
+				rec.Branch = s
+			}
+		}
+		for _, param := range []string{"child", "commit", "tag"} {
+			if s, err := url.QueryUnescape(vars[param]); err == nil && s != "" {
+				rec.Targets = append(rec.Targets, s)
+			}
+		}
+		r.audit = rec
+		r.Request = r.Request.WithContext(audit.ContextWithRecord(r.Context(), rec))
+		next(c, w, r)
+		if r.audit == nil {
+			return
+		}
+		err := w.err
+		if err == nil && w.status >= 400 {
+			err = errors.New(http.StatusText(w.status))
+		}
+		rec.SetError(err)
+		c.root.Audit(r.Context(), rec)
+	}
+}
+
+// auditPool sets the pool of the request's audit record to id.
+func (r *Request) auditPool(id ksuid.KSUID) {
+	if r.audit != nil {
+		r.audit.Pool = id
+	}
+}
+
+// auditBranch sets the branch of the request's audit record to name.
+func (r *Request) auditBranch(name string) {
+	if r.audit != nil {
+		r.audit.Branch = name
+	}
+}
+
+// auditTargets adds targets to the request's audit record.
+func (r *Request) auditTargets(targets ...string) {
+	if r.audit != nil {
+		r.audit.Targets = append(r.audit.Targets, targets...)
+	}
+}
+
+// skipAudit prevents the request from being recorded in the audit log,
+// as for a dry run of an operation that would otherwise modify the lake.
+func (r *Request) skipAudit() {
+	r.audit = nil
+}
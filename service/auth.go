@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"time"
 
 	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/lake/apikeys"
 	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/golang-jwt/jwt/v4/request"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
@@ -15,6 +19,23 @@ import (
 type AuthConfig struct {
 	Enabled  bool
 	JWKSPath string
+	// RBAC enables authorization of requests by the roles granted in the
+	// lake and those named by RolesClaim.
+	RBAC       bool
+	RolesClaim string
+	// Tenants enables isolation of tenants, each of which is served from
+	// its own lake under the service's root.
+	Tenants bool
+	// TenantIDClaim and UserIDClaim name the access token claims holding
+	// the tenant ID and user ID of an identity.
+	TenantIDClaim string
+	UserIDClaim   string
+
+	// Issuer is the URL of an OIDC issuer, which is used in place of
+	// Domain and JWKSPath.  The issuer's keys are fetched using its
+	// discovery document and are fetched again every JWKSRefresh.
+	Issuer      string
+	JWKSRefresh time.Duration
 
 	// Audience, ClientID, and Domain are sent in the /auth/method response so API
 	// clients can interact with the right Auth0 tenant (production, testing, etc.)
@@ -29,7 +50,14 @@ func (c *AuthConfig) SetFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.Audience, "auth.audience", "", "Auth0 audience for API clients (will be publicly accessible)")
 	fs.StringVar(&c.ClientID, "auth.clientid", "", "Auth0 client ID for API clients (will be publicly accessible)")
 	fs.StringVar(&c.Domain, "auth.domain", "", "Auth0 domain (as a URL) for API clients (will be publicly accessible)")
+	fs.StringVar(&c.Issuer, "auth.issuer", "", "OIDC issuer URL for API clients, in place of -auth.domain and -auth.jwkspath (will be publicly accessible)")
+	fs.DurationVar(&c.JWKSRefresh, "auth.jwksrefresh", auth.DefaultJWKSRefresh, "interval at which the OIDC issuer's keys are fetched again")
 	fs.StringVar(&c.JWKSPath, "auth.jwkspath", "", "path to JSON Web Key Set file")
+	fs.BoolVar(&c.RBAC, "auth.rbac", false, "enable authorization by granted roles (requires -auth.enabled)")
+	fs.StringVar(&c.RolesClaim, "auth.rolesclaim", auth.RolesClaim, "access token claim naming roles and groups of the user")
+	fs.BoolVar(&c.Tenants, "auth.tenants", false, "serve each tenant from its own lake (requires -auth.enabled)")
+	fs.StringVar(&c.TenantIDClaim, "auth.tenantclaim", auth.TenantIDClaim, "access token claim holding the tenant ID of the user")
+	fs.StringVar(&c.UserIDClaim, "auth.userclaim", auth.UserIDClaim, "access token claim holding the user ID of the user")
 }
 
 type Auth0Authenticator struct {
@@ -41,37 +69,70 @@ type Auth0Authenticator struct {
 
 // NewAuthenticator returns an Auth0Authenticator that checks for a JWT signed
 // by a key referenced in the JWKS file, has the required audience and issuer
-// claims, and contains claims for a brim tenant and user id.
+// claims, and contains claims for a brim tenant and user id.  In place of
+// a JWT, the authenticator also accepts an API key issued by the service.
+// If config.Issuer is set, the JWT must instead be signed by a key of that
+// OIDC issuer and have it as its issuer claim.
 func NewAuthenticator(ctx context.Context, logger *zap.Logger, registerer prometheus.Registerer, config AuthConfig) (*Auth0Authenticator, error) {
-	if config.Audience == "" || config.ClientID == "" || config.Domain == "" || config.JWKSPath == "" {
-		return nil, errors.New("auth.audience, auth.clientid, auth.domain, and auth.jwkspath must be set when auth enabled")
+	var validator *auth.TokenValidator
+	var methodResponse api.AuthMethodResponse
+	if config.Issuer != "" {
+		if config.Audience == "" || config.ClientID == "" {
+			return nil, errors.New("auth.audience and auth.clientid must be set when auth.issuer is set")
+		}
+		if config.Domain != "" || config.JWKSPath != "" {
+			return nil, errors.New("auth.domain and auth.jwkspath cannot be set when auth.issuer is set")
+		}
+		var err error
+		validator, err = auth.NewOIDCTokenValidator(ctx, config.Audience, config.Issuer, config.JWKSRefresh)
+		if err != nil {
+			return nil, err
+		}
+		methodResponse = api.AuthMethodResponse{
+			Kind: api.AuthMethodOIDC,
+			OIDC: &api.AuthMethodOIDCDetails{
+				Audience: config.Audience,
+				ClientID: config.ClientID,
+				Issuer:   config.Issuer,
+			},
+		}
+	} else {
+		if config.Audience == "" || config.ClientID == "" || config.Domain == "" || config.JWKSPath == "" {
+			return nil, errors.New("auth.audience, auth.clientid, auth.domain, and auth.jwkspath must be set when auth enabled")
+		}
+		var err error
+		validator, err = auth.NewTokenValidator(config.Audience, config.Domain, config.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+		methodResponse = api.AuthMethodResponse{
+			Kind: api.AuthMethodAuth0,
+			Auth0: &api.AuthMethodAuth0Details{
+				Audience: config.Audience,
+				Domain:   config.Domain,
+				ClientID: config.ClientID,
+			},
+		}
 	}
-	validator, err := auth.NewTokenValidator(config.Audience, config.Domain, config.JWKSPath)
-	if err != nil {
-		return nil, err
+	if config.RolesClaim != "" {
+		validator.SetRolesClaim(config.RolesClaim)
 	}
+	validator.SetIdentityClaims(config.TenantIDClaim, config.UserIDClaim)
 	unauthorized := promauto.With(registerer).NewCounter(prometheus.CounterOpts{
 		Name: "request_errors_unauthorized_total",
 		Help: "Number of request errors due to bad or missing authorization.",
 	})
 	return &Auth0Authenticator{
-		logger: logger.Named("auth"),
-		methodResponse: api.AuthMethodResponse{
-			Kind: api.AuthMethodAuth0,
-			Auth0: &api.AuthMethodAuth0Details{
-				Audience: config.Audience,
-				Domain:   config.Domain,
-				ClientID: config.ClientID,
-			},
-		},
-		unauthorized: unauthorized,
-		validator:    validator,
+		logger:         logger.Named("auth"),
+		methodResponse: methodResponse,
+		unauthorized:   unauthorized,
+		validator:      validator,
 	}, nil
 }
 
 func (a *Auth0Authenticator) Middleware(next func(*Core, *ResponseWriter, *Request)) func(*Core, *ResponseWriter, *Request) {
 	return func(c *Core, w *ResponseWriter, r *Request) {
-		token, ident, err := a.validator.ValidateRequest(r.Request)
+		token, ident, err := a.authenticate(c, r)
 		if err != nil {
 			a.unauthorized.Inc()
 			a.logger.Info("Unauthorized request",
@@ -87,6 +148,35 @@ func (a *Auth0Authenticator) Middleware(next func(*Core, *ResponseWriter, *Reque
 	}
 }
 
+// authenticate returns the bearer token of r and the identity it
+// authenticates.  A token with the API key prefix is validated against the
+// API keys of c and any other token as a JWT.
+func (a *Auth0Authenticator) authenticate(c *Core, r *Request) (string, auth.Identity, error) {
+	token, err := request.AuthorizationHeaderExtractor.ExtractToken(r.Request)
+	if err != nil {
+		return "", auth.Identity{}, srverr.ErrNoCredentials(err)
+	}
+	if !apikeys.IsKey(token) {
+		ident, err := a.validator.Validate(token)
+		return token, ident, err
+	}
+	key, err := c.apikeys.Validate(r.Context(), token)
+	if err != nil {
+		if errors.Is(err, apikeys.ErrInvalid) || errors.Is(err, apikeys.ErrExpired) {
+			err = srverr.ErrNoCredentials(err)
+		}
+		return "", auth.Identity{}, err
+	}
+	// The identity of a key has no roles so the roles of its user are
+	// those granted in the lake, which are looked up for each request.
+	return token, auth.Identity{
+		TenantID: auth.TenantID(key.TenantID),
+		UserID:   auth.UserID(key.UserID),
+		APIKey:   key.ID,
+		Pools:    key.Pools,
+	}, nil
+}
+
 func (a *Auth0Authenticator) MethodResponse() api.AuthMethodResponse {
 	return a.methodResponse
 }
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+
+	"github.com/segmentio/ksuid"
 )
 
 type TenantID string
@@ -15,6 +17,30 @@ const (
 type Identity struct {
 	TenantID TenantID
 	UserID   UserID
+	// Roles are the values of the roles claim, which name either a role
+	// conferred on the entire lake or a group granted roles in the lake.
+	Roles []string
+	// APIKey is the ID of the API key that authenticated the identity or
+	// nil if it was authenticated by an access token.
+	APIKey ksuid.KSUID
+	// Pools, if not empty, are the IDs of the only pools the identity
+	// may access, as for an API key scoped to pools.
+	Pools []ksuid.KSUID
+}
+
+// Covers returns true if the identity may access the pool with ID pool.
+// A nil pool denotes the entire lake, which only an identity not scoped to
+// pools may access.
+func (i Identity) Covers(pool ksuid.KSUID) bool {
+	if len(i.Pools) == 0 {
+		return true
+	}
+	for _, id := range i.Pools {
+		if id == pool && pool != ksuid.Nil {
+			return true
+		}
+	}
+	return false
 }
 
 type identityKey struct{}
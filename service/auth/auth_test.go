@@ -0,0 +1,19 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/segmentio/ksuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityCovers(t *testing.T) {
+	pool, other := ksuid.New(), ksuid.New()
+	unscoped := Identity{}
+	assert.True(t, unscoped.Covers(ksuid.Nil))
+	assert.True(t, unscoped.Covers(pool))
+	scoped := Identity{Pools: []ksuid.KSUID{pool}}
+	assert.True(t, scoped.Covers(pool))
+	assert.False(t, scoped.Covers(other))
+	assert.False(t, scoped.Covers(ksuid.Nil))
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefresh is the default interval at which the keys published
+// by an OIDC issuer are fetched again.
+const DefaultJWKSRefresh = time.Hour
+
+// minJWKSRefresh limits how often the keys of an OIDC issuer are fetched
+// when tokens name unknown keys, so that such tokens cannot be used to
+// flood the issuer with requests.
+const minJWKSRefresh = 10 * time.Second
+
+var errUnknownKey = errors.New("unknown token key id")
+
+// A keySet supplies the public key with which a token was signed.
+type keySet interface {
+	key(id string) (*rsa.PublicKey, error)
+}
+
+type staticKeys map[string]*rsa.PublicKey
+
+func (s staticKeys) key(id string) (*rsa.PublicKey, error) {
+	if key, ok := s[id]; ok {
+		return key, nil
+	}
+	return nil, errUnknownKey
+}
+
+// remoteKeys is the key set published at the JWKS URL of an OIDC issuer.
+// The keys are fetched again when they are older than the refresh interval
+// or, as when the issuer has rotated its keys, when a token names an unknown
+// key.  Keys are fetched in the background, one fetch at a time, and only a
+// token naming an unknown key waits for the fetch to complete.  If the keys
+// cannot be fetched, those fetched last remain in use.
+type remoteKeys struct {
+	client  *http.Client
+	url     string
+	refresh time.Duration
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+	// fetching is closed when the fetch in progress completes and is nil
+	// if there is none.  err is the error of the last fetch.
+	fetching chan struct{}
+	err      error
+}
+
+func (r *remoteKeys) key(id string) (*rsa.PublicKey, error) {
+	r.mu.Lock()
+	key, ok := r.keys[id]
+	age := time.Since(r.fetched)
+	if r.fetching == nil && (age > r.refresh || (!ok && age > minJWKSRefresh)) {
+		// The attempt counts as a fetch even if it fails so that an
+		// unavailable issuer is not asked again on every request.
+		r.fetched = time.Now()
+		r.fetching = make(chan struct{})
+		go r.update(r.fetching)
+	}
+	fetching := r.fetching
+	r.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if fetching == nil {
+		return nil, errUnknownKey
+	}
+	<-fetching
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key, ok := r.keys[id]; ok {
+		return key, nil
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return nil, errUnknownKey
+}
+
+// update fetches the keys and closes done when they are updated.
+func (r *remoteKeys) update(done chan struct{}) {
+	keys, err := r.fetch(context.Background())
+	r.mu.Lock()
+	if err == nil {
+		r.keys = keys
+	}
+	r.err = err
+	r.fetching = nil
+	r.mu.Unlock()
+	close(done)
+}
+
+// fetch fetches the keys without holding r.mu.
+func (r *remoteKeys) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var set jwks
+	if err := getJSON(ctx, r.client, r.url, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	return keys, nil
+}
+
+// oidcConfiguration holds the fields of an OIDC discovery document used by
+// the validator.
+// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderMetadata
+type oidcConfiguration struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// NewOIDCTokenValidator returns a TokenValidator for the access tokens
+// issued by the OIDC issuer at the URL issuer.  The URL of the issuer's
+// signing keys is taken from its discovery document and the keys are
+// fetched again every refresh interval.
+func NewOIDCTokenValidator(ctx context.Context, audience, issuer string, refresh time.Duration) (*TokenValidator, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	var config oidcConfiguration
+	if err := getJSON(ctx, client, DiscoveryURL(issuer), &config); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	if strings.TrimRight(config.Issuer, "/") != strings.TrimRight(issuer, "/") {
+		return nil, fmt.Errorf("OIDC discovery document has issuer %q instead of %q", config.Issuer, issuer)
+	}
+	if config.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document has no jwks_uri")
+	}
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+	keys := &remoteKeys{
+		client:  client,
+		url:     config.JWKSURI,
+		refresh: refresh,
+		fetched: time.Now(),
+	}
+	var err error
+	if keys.keys, err = keys.fetch(ctx); err != nil {
+		return nil, err
+	}
+	// The issuer claim must match the discovery document exactly.
+	return newTokenValidator(audience, config.Issuer, keys), nil
+}
+
+// DiscoveryURL returns the URL of the OIDC discovery document of issuer.
+func DiscoveryURL(issuer string) string {
+	return strings.TrimRight(issuer, "/") + "/.well-known/openid-configuration"
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
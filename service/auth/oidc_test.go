@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+// testIssuer is a stub OIDC issuer serving a discovery document and JWKS.
+type testIssuer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	jwks []byte
+}
+
+func newTestIssuer(t *testing.T, jwks []byte) *testIssuer {
+	issuer := &testIssuer{jwks: jwks}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.URL,
+			"jwks_uri": issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		w.Write(issuer.jwks)
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *testIssuer) setJWKS(jwks []byte) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.jwks = jwks
+}
+
+func (i *testIssuer) token(t *testing.T, keyID string, claims jwt.MapClaims) string {
+	claims["aud"] = testAudience
+	claims["exp"] = time.Now().Add(time.Hour).Unix()
+	claims["iss"] = i.URL
+	token, err := makeToken(keyID, testKeyFile, claims)
+	require.NoError(t, err)
+	return token
+}
+
+func TestOIDCValidator(t *testing.T) {
+	jwks, err := os.ReadFile(testJWKSFile)
+	require.NoError(t, err)
+	issuer := newTestIssuer(t, jwks)
+	v, err := NewOIDCTokenValidator(context.Background(), testAudience, issuer.URL, time.Hour)
+	require.NoError(t, err)
+	v.SetIdentityClaims("", "sub")
+
+	ident, err := v.Validate(issuer.token(t, testKeyID, jwt.MapClaims{"sub": "user1"}))
+	require.NoError(t, err)
+	require.Equal(t, Identity{TenantID: AnonymousTenantID, UserID: "user1"}, ident)
+
+	token, err := GenerateAccessToken(testKeyID, testKeyFile, time.Hour, testAudience, "https://testdomain", "tenant1", "user1")
+	require.NoError(t, err)
+	_, err = v.Validate(token)
+	require.Error(t, err, "token of another issuer")
+
+	_, err = NewOIDCTokenValidator(context.Background(), testAudience, issuer.URL+"/other", time.Hour)
+	require.Error(t, err)
+}
+
+func TestOIDCValidatorKeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t, []byte(`{"keys":[]}`))
+	v, err := NewOIDCTokenValidator(context.Background(), testAudience, issuer.URL, time.Millisecond)
+	require.NoError(t, err)
+	token := issuer.token(t, "rotated", jwt.MapClaims{UserIDClaim: "user1"})
+	_, err = v.Validate(token)
+	require.Error(t, err)
+
+	// The rotated key is published with its modulus and exponent rather
+	// than a certificate.
+	privateKey, err := loadPrivateKey(testKeyFile)
+	require.NoError(t, err)
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "rotated",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	issuer.setJWKS(jwks)
+	time.Sleep(2 * time.Millisecond)
+	ident, err := v.Validate(token)
+	require.NoError(t, err)
+	require.Equal(t, UserID("user1"), ident.UserID)
+
+	// Keys fetched earlier remain in use while the issuer is unavailable.
+	issuer.Close()
+	time.Sleep(2 * time.Millisecond)
+	_, err = v.Validate(token)
+	require.NoError(t, err)
+}
+
+func TestOIDCValidatorBackgroundRefresh(t *testing.T) {
+	jwks, err := os.ReadFile(testJWKSFile)
+	require.NoError(t, err)
+	issuer := newTestIssuer(t, jwks)
+	v, err := NewOIDCTokenValidator(context.Background(), testAudience, issuer.URL, time.Millisecond)
+	require.NoError(t, err)
+	token := issuer.token(t, testKeyID, jwt.MapClaims{UserIDClaim: "user1"})
+	// Holding the issuer's lock blocks its JWKS responses, so a token
+	// with a known key is validated while the keys are being fetched.
+	issuer.mu.Lock()
+	time.Sleep(2 * time.Millisecond)
+	start := time.Now()
+	_, err = v.Validate(token)
+	elapsed := time.Since(start)
+	issuer.mu.Unlock()
+	require.NoError(t, err)
+	require.Less(t, elapsed, time.Second)
+}
+
+func TestJWKSSkipsOtherKeyTypes(t *testing.T) {
+	var set jwks
+	require.NoError(t, json.Unmarshal([]byte(`{"keys":[
+		{"kty":"EC","kid":"ec","use":"sig","crv":"P-256","x5c":["not an RSA certificate"]},
+		{"kty":"RSA","kid":"rsa","use":"sig","n":"AQAB","e":"AQAB"}
+	]}`), &set))
+	keys, err := set.publicKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Contains(t, keys, "rsa")
+}
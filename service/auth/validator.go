@@ -2,8 +2,9 @@ package auth
 
 import (
 	"crypto/rsa"
-	"errors"
+	"encoding/base64"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/url"
 	"time"
@@ -19,12 +20,18 @@ const (
 	// access token.
 	TenantIDClaim = "https://lake.brimdata.io/tenant_id"
 	UserIDClaim   = "https://lake.brimdata.io/user_id"
+	// RolesClaim is the default claim whose string or array of strings
+	// value supplies the roles of an identity.
+	RolesClaim = "https://lake.brimdata.io/roles"
 )
 
 type TokenValidator struct {
 	expectedAudience string
 	expectedIssuer   string
-	keyGetter        jwt.Keyfunc
+	keys             keySet
+	rolesClaim       string
+	tenantIDClaim    string
+	userIDClaim      string
 }
 
 func NewTokenValidator(audience, domain, jwksPath string) (*TokenValidator, error) {
@@ -32,26 +39,50 @@ func NewTokenValidator(audience, domain, jwksPath string) (*TokenValidator, erro
 	if err != nil {
 		return nil, fmt.Errorf("bad auth.domain URL: %w", err)
 	}
-	keys, err := loadPublicKeys(jwksPath)
+	var set jwks
+	if err := fs.UnmarshalJSONFile(jwksPath, &set); err != nil {
+		return nil, fmt.Errorf("failed to load JWKS file: %w", err)
+	}
+	keys, err := set.publicKeys()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load JWKS file: %w", err)
 	}
 	// Auth0 issuer is always the domain URL with trailing "/".
 	// https://auth0.com/docs/tokens/access-tokens/get-access-tokens#custom-domains-and-the-management-api
-	expectedIssuer := domainURL.String() + "/"
-	keyGetter := func(token *jwt.Token) (interface{}, error) {
-		tokenKeyID, _ := token.Header["kid"].(string)
-		key, ok := keys[tokenKeyID]
-		if !ok {
-			return token, errors.New("unknown token key id")
-		}
-		return key, nil
-	}
+	return newTokenValidator(audience, domainURL.String()+"/", staticKeys(keys)), nil
+}
+
+func newTokenValidator(audience, issuer string, keys keySet) *TokenValidator {
 	return &TokenValidator{
 		expectedAudience: audience,
-		expectedIssuer:   expectedIssuer,
-		keyGetter:        keyGetter,
-	}, nil
+		expectedIssuer:   issuer,
+		keys:             keys,
+		rolesClaim:       RolesClaim,
+		tenantIDClaim:    TenantIDClaim,
+		userIDClaim:      UserIDClaim,
+	}
+}
+
+// SetRolesClaim sets the claim from which the roles of an identity are
+// taken.
+func (v *TokenValidator) SetRolesClaim(claim string) {
+	v.rolesClaim = claim
+}
+
+// SetIdentityClaims sets the claims from which the tenant ID and user ID of
+// an identity are taken.  An empty claim leaves the current claim in place.
+func (v *TokenValidator) SetIdentityClaims(tenantIDClaim, userIDClaim string) {
+	if tenantIDClaim != "" {
+		v.tenantIDClaim = tenantIDClaim
+	}
+	if userIDClaim != "" {
+		v.userIDClaim = userIDClaim
+	}
+}
+
+func (v *TokenValidator) keyFunc(token *jwt.Token) (interface{}, error) {
+	tokenKeyID, _ := token.Header["kid"].(string)
+	return v.keys.key(tokenKeyID)
 }
 
 func (v *TokenValidator) ValidateRequest(r *http.Request) (string, Identity, error) {
@@ -70,7 +101,7 @@ func (v *TokenValidator) Validate(token string) (Identity, error) {
 	if token == "" {
 		return Identity{}, srverr.ErrNoCredentials()
 	}
-	parsed, err := jwt.Parse(token, v.keyGetter)
+	parsed, err := jwt.Parse(token, v.keyFunc)
 	if err != nil || !parsed.Valid {
 		return Identity{}, srverr.ErrNoCredentials("invalid token")
 	}
@@ -90,21 +121,36 @@ func (v *TokenValidator) Validate(token string) (Identity, error) {
 	if !claims.VerifyIssuer(v.expectedIssuer, true) {
 		return Identity{}, srverr.ErrNoCredentials("invalid issuer")
 	}
-	ident := Identity{AnonymousTenantID, AnonymousUserID}
-	if v, ok := claims[TenantIDClaim]; ok {
+	ident := Identity{TenantID: AnonymousTenantID, UserID: AnonymousUserID}
+	if v, ok := claims[v.tenantIDClaim]; ok {
 		s, _ := v.(string)
 		if s == "" || TenantID(s) == AnonymousTenantID {
 			return Identity{}, srverr.ErrNoCredentials("invalid tenant ID")
 		}
 		ident.TenantID = TenantID(s)
 	}
-	if v, ok := claims[UserIDClaim]; ok {
+	if v, ok := claims[v.userIDClaim]; ok {
 		s, _ := v.(string)
 		if s == "" || UserID(s) == AnonymousUserID {
 			return Identity{}, srverr.ErrNoCredentials("invalid tenant ID")
 		}
 		ident.UserID = UserID(s)
 	}
+	switch roles := claims[v.rolesClaim].(type) {
+	case nil:
+	case string:
+		ident.Roles = []string{roles}
+	case []interface{}:
+		for _, elem := range roles {
+			s, ok := elem.(string)
+			if !ok {
+				return Identity{}, srverr.ErrNoCredentials("invalid roles")
+			}
+			ident.Roles = append(ident.Roles, s)
+		}
+	default:
+		return Identity{}, srverr.ErrNoCredentials("invalid roles")
+	}
 	return ident, nil
 }
 
@@ -121,22 +167,41 @@ type jwks struct {
 	} `json:"keys"`
 }
 
-func loadPublicKeys(jwkspath string) (map[string]*rsa.PublicKey, error) {
-	var jwks jwks
-	if err := fs.UnmarshalJSONFile(jwkspath, &jwks); err != nil {
-		return nil, err
-	}
+// publicKeys returns the RSA signing keys of the set by key ID.  A key is
+// taken from its certificate chain if present and otherwise from its modulus
+// and exponent.  Keys of other types, which the validator does not accept,
+// are skipped.  (A key with no type is taken to be an RSA key, as in the JWKS
+// files accepted by earlier versions.)
+func (j *jwks) publicKeys() (map[string]*rsa.PublicKey, error) {
 	keys := make(map[string]*rsa.PublicKey)
-	for _, jwk := range jwks.Keys {
-		if len(jwk.X5c) == 0 {
+	for _, jwk := range j.Keys {
+		if (jwk.Kty != "" && jwk.Kty != "RSA") || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		if len(jwk.X5c) > 0 {
+			cert := "-----BEGIN CERTIFICATE-----\n" + jwk.X5c[0] + "\n-----END CERTIFICATE-----"
+			public, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cert))
+			if err != nil {
+				return nil, err
+			}
+			keys[jwk.Kid] = public
+			continue
+		}
+		if jwk.N == "" || jwk.E == "" {
 			continue
 		}
-		cert := "-----BEGIN CERTIFICATE-----\n" + jwk.X5c[0] + "\n-----END CERTIFICATE-----"
-		public, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cert))
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("key %q: bad modulus: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: bad exponent: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
 		}
-		keys[jwk.Kid] = public
 	}
 	return keys, nil
 }
@@ -65,7 +65,7 @@ func TestValidateNoTenantIDAndUserIDClaims(t *testing.T) {
 	require.NoError(t, err)
 	ident, err := testValidator(t).Validate(token)
 	require.NoError(t, err)
-	require.Equal(t, Identity{AnonymousTenantID, AnonymousUserID}, ident)
+	require.Equal(t, Identity{TenantID: AnonymousTenantID, UserID: AnonymousUserID}, ident)
 }
 
 func TestBadClaims(t *testing.T) {
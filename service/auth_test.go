@@ -2,15 +2,24 @@ package service_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/grants"
+	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/service"
 	"github.com/brimdata/zed/service/auth"
+	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -32,6 +41,14 @@ func genToken(t *testing.T, tenantID auth.TenantID, userID auth.UserID) string {
 	return token
 }
 
+func genTokenWithRoles(t *testing.T, tenantID auth.TenantID, userID auth.UserID, roles []string) string {
+	ac := testAuthConfig()
+	token, err := auth.GenerateAccessTokenWithRoles("testkey", "testdata/auth-private-key",
+		1*time.Hour, ac.Audience, ac.Domain, tenantID, userID, roles)
+	require.NoError(t, err)
+	return token
+}
+
 func TestAuthIdentity(t *testing.T) {
 	authConfig := testAuthConfig()
 	core, conn := newCoreWithConfig(t, service.Config{
@@ -88,3 +105,225 @@ func TestAuthMethodGet(t *testing.T) {
 		}, resp)
 	})
 }
+
+func TestAuthTenants(t *testing.T) {
+	authConfig := testAuthConfig()
+	authConfig.Tenants = true
+	root := t.TempDir()
+	_, conn := newCoreWithConfig(t, service.Config{
+		Auth: authConfig,
+		Root: storage.MustParseURI(root),
+	})
+
+	conn.SetAuthToken(genToken(t, "tenant1", "user1"))
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+	require.Equal(t, "{x:1}\n", conn.TestQuery("from test"))
+
+	conn.SetAuthToken(genToken(t, "tenant2", "user2"))
+	require.Len(t, conn.TestPoolList(), 0)
+	_, err := conn.Query(context.Background(), nil, "from test")
+	require.Error(t, err)
+	conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	require.Equal(t, "", conn.TestQuery("from test"))
+
+	conn.SetAuthToken(genToken(t, "tenant1", "user1"))
+	pools := conn.TestPoolList()
+	require.Len(t, pools, 1)
+	require.Equal(t, poolID, pools[0].ID)
+
+	for _, tenant := range []string{"tenant1", "tenant2"} {
+		_, err := os.Stat(filepath.Join(root, service.TenantsDir, tenant, lake.LakeMagicFile))
+		require.NoError(t, err)
+	}
+
+	conn.SetAuthToken(genToken(t, "../tenant1", "user1"))
+	_, err = conn.Query(context.Background(), nil, "from :pools")
+	var resErr *client.ErrorResponse
+	require.True(t, errors.As(err, &resErr))
+	require.Equal(t, http.StatusForbidden, resErr.StatusCode)
+}
+
+func requireStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var resErr *client.ErrorResponse
+	require.True(t, errors.As(err, &resErr), "expected error response: %v", err)
+	require.Equal(t, status, resErr.StatusCode)
+}
+
+func TestAuthQueryListAndKill(t *testing.T) {
+	_, conn := newCoreWithConfig(t, service.Config{
+		Auth: testAuthConfig(),
+	})
+	ctx := context.Background()
+	userToken := genToken(t, "tenant1", "user1")
+	conn.SetAuthToken(userToken)
+	testLoadBlocking(conn)
+	res, err := conn.Query(ctx, nil, "from test")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	infos, err := conn.ListQueries(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+
+	conn.SetAuthToken(genToken(t, "tenant1", "user2"))
+	others, err := conn.ListQueries(ctx)
+	require.NoError(t, err)
+	require.Len(t, others, 0)
+	require.ErrorIs(t, conn.KillQuery(ctx, infos[0].RequestID), client.ErrQueryNotFound)
+
+	conn.SetAuthToken(userToken)
+	require.NoError(t, conn.KillQuery(ctx, infos[0].RequestID))
+}
+
+func TestAuthQueryKillByAdmin(t *testing.T) {
+	authConfig := testAuthConfig()
+	authConfig.RBAC = true
+	_, conn := newCoreWithConfig(t, service.Config{
+		Auth: authConfig,
+	})
+	ctx := context.Background()
+	conn.SetAuthToken(genTokenWithRoles(t, "tenant1", "user1", []string{"writer"}))
+	testLoadBlocking(conn)
+	res, err := conn.Query(ctx, nil, "from test")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	conn.SetAuthToken(genTokenWithRoles(t, "tenant1", "user2", []string{"writer"}))
+	infos, err := conn.ListQueries(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 0)
+
+	conn.SetAuthToken(genTokenWithRoles(t, "tenant1", "admin1", []string{"admin"}))
+	infos, err = conn.ListQueries(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, "user1", infos[0].UserID)
+	require.NoError(t, conn.KillQuery(ctx, infos[0].RequestID))
+}
+
+func TestAuthAPIKey(t *testing.T) {
+	authConfig := testAuthConfig()
+	authConfig.Tenants = true
+	_, conn := newCoreWithConfig(t, service.Config{
+		Auth: authConfig,
+		Root: storage.MustParseURI(t.TempDir()),
+	})
+	ctx := context.Background()
+	userToken := genToken(t, "tenant1", "user1")
+	conn.SetAuthToken(userToken)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "a"})
+	conn.TestLoad(poolID, "main", strings.NewReader("{x:1}"))
+	conn.TestPoolPost(api.PoolPostRequest{Name: "b"})
+	unscoped, err := conn.CreateAPIKey(ctx, api.APIKeyRequest{Name: "all"})
+	require.NoError(t, err)
+	scoped, err := conn.CreateAPIKey(ctx, api.APIKeyRequest{Name: "a", Pools: []ksuid.KSUID{poolID}})
+	require.NoError(t, err)
+	res, err := conn.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, res.Keys, 2)
+
+	conn.SetAuthToken(unscoped.Secret)
+	require.Equal(t, api.AuthIdentityResponse{TenantID: "tenant1", UserID: "user1"}, conn.TestAuthIdentity())
+	require.Equal(t, "{x:1}\n", conn.TestQuery("from a"))
+	conn.TestPoolPost(api.PoolPostRequest{Name: "c"})
+	_, err = conn.CreateAPIKey(ctx, api.APIKeyRequest{Name: "another"})
+	requireStatus(t, err, http.StatusForbidden)
+
+	conn.SetAuthToken(scoped.Secret)
+	require.Equal(t, "{x:1}\n", conn.TestQuery("from a"))
+	_, err = conn.Query(ctx, nil, "from b")
+	requireStatus(t, err, http.StatusForbidden)
+	_, err = conn.CreatePool(ctx, api.PoolPostRequest{Name: "d"})
+	requireStatus(t, err, http.StatusForbidden)
+
+	conn.SetAuthToken(genToken(t, "tenant1", "user2"))
+	res, err = conn.ListAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, res.Keys, 0)
+	require.ErrorIs(t, conn.RevokeAPIKey(ctx, unscoped.Key.ID), client.ErrAPIKeyNotFound)
+
+	conn.SetAuthToken(userToken)
+	require.NoError(t, conn.RevokeAPIKey(ctx, unscoped.Key.ID))
+	conn.SetAuthToken(unscoped.Secret)
+	_, err = conn.AuthIdentity(ctx)
+	requireStatus(t, err, http.StatusUnauthorized)
+}
+
+func TestAuthAPIKeyRoles(t *testing.T) {
+	authConfig := testAuthConfig()
+	authConfig.RBAC = true
+	_, conn := newCoreWithConfig(t, service.Config{
+		Auth: authConfig,
+		Root: storage.MustParseURI(t.TempDir()),
+	})
+	ctx := context.Background()
+	adminToken := genTokenWithRoles(t, "tenant1", "admin1", []string{"admin"})
+	conn.SetAuthToken(adminToken)
+	key, err := conn.CreateAPIKey(ctx, api.APIKeyRequest{Name: "all"})
+	require.NoError(t, err)
+
+	// The key does not hold the roles claimed by the access token of
+	// the user who created it.
+	conn.SetAuthToken(key.Secret)
+	require.Equal(t, api.AuthIdentityResponse{TenantID: "tenant1", UserID: "admin1"}, conn.TestAuthIdentity())
+	_, err = conn.CreatePool(ctx, api.PoolPostRequest{Name: "a"})
+	requireStatus(t, err, http.StatusForbidden)
+
+	// The key holds the roles granted to its user when it is used.
+	conn.SetAuthToken(adminToken)
+	require.NoError(t, conn.Grant(ctx, api.GrantRequest{Subject: grants.User("admin1"), Role: "writer"}))
+	conn.SetAuthToken(key.Secret)
+	conn.TestPoolPost(api.PoolPostRequest{Name: "a"})
+	conn.SetAuthToken(adminToken)
+	require.NoError(t, conn.Revoke(ctx, grants.User("admin1"), ksuid.Nil, ""))
+	conn.SetAuthToken(key.Secret)
+	_, err = conn.CreatePool(ctx, api.PoolPostRequest{Name: "b"})
+	requireStatus(t, err, http.StatusForbidden)
+}
+
+func TestAuthOIDC(t *testing.T) {
+	jwks, err := os.ReadFile("testdata/auth-public-jwks.json")
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	issuer := httptest.NewServer(mux)
+	defer issuer.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		// auth.GenerateAccessToken appends a slash to the issuer claim.
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.URL + "/",
+			"jwks_uri": issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	})
+	authConfig := service.AuthConfig{
+		Enabled:  true,
+		Audience: "testaudience",
+		ClientID: "testclientid",
+		Issuer:   issuer.URL,
+	}
+	_, conn := newCoreWithConfig(t, service.Config{Auth: authConfig})
+	require.Equal(t, api.AuthMethodResponse{
+		Kind: api.AuthMethodOIDC,
+		OIDC: &api.AuthMethodOIDCDetails{
+			Audience: authConfig.Audience,
+			ClientID: authConfig.ClientID,
+			Issuer:   authConfig.Issuer,
+		},
+	}, conn.TestAuthMethod())
+
+	_, err = conn.AuthIdentity(context.Background())
+	requireStatus(t, err, http.StatusUnauthorized)
+	token, err := auth.GenerateAccessToken("testkey", "testdata/auth-private-key",
+		time.Hour, authConfig.Audience, issuer.URL, "tenant1", "user1")
+	require.NoError(t, err)
+	conn.SetAuthToken(token)
+	require.Equal(t, api.AuthIdentityResponse{TenantID: "tenant1", UserID: "user1"}, conn.TestAuthIdentity())
+
+	// A token of the Auth0 domain used by the other tests is rejected.
+	conn.SetAuthToken(genToken(t, "tenant1", "user1"))
+	_, err = conn.AuthIdentity(context.Background())
+	requireStatus(t, err, http.StatusUnauthorized)
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/brimdata/zed/api"
+	"github.com/brimdata/zed/compiler"
+	"github.com/brimdata/zed/compiler/ast"
+	"github.com/brimdata/zed/compiler/ast/dag"
+	"github.com/brimdata/zed/compiler/data"
+	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/grants"
+	"github.com/brimdata/zed/lakeparse"
+	"github.com/brimdata/zed/pkg/storage"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/service/srverr"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/ksuid"
+	"go.uber.org/zap"
+)
+
+// An access is the role a route requires on the lake, on the pool named by
+// the route's "pool" parameter, or on a branch of that pool named by another
+// route parameter.
+type access struct {
+	role  grants.Role
+	param string
+}
+
+func lakeAccess(role grants.Role) access {
+	return access{role: role}
+}
+
+func poolAccess(role grants.Role) access {
+	return access{role: role, param: "pool"}
+}
+
+func branchAccess(role grants.Role, param string) access {
+	return access{role: role, param: param}
+}
+
+// authorizer checks that a request is within the scope of the pools of
+// its identity, if any, and, when role-based authorization is enabled, that
+// the identity holds the roles required by the request.  An identity holds
+// the roles granted in the lake to its user and to the groups named by its
+// roles claim as well as the roles named by its roles claim, which apply to
+// the entire lake.
+type authorizer struct {
+	logger    *zap.Logger
+	rbac      bool
+	root      *lake.Root
+	forbidden prometheus.Counter
+}
+
+func newAuthorizer(logger *zap.Logger, registerer prometheus.Registerer, rbac bool) *authorizer {
+	forbidden := promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+		Name: "request_errors_forbidden_total",
+		Help: "Number of request errors due to missing roles.",
+	})
+	return &authorizer{
+		logger:    logger.Named("authz"),
+		rbac:      rbac,
+		forbidden: forbidden,
+	}
+}
+
+// withRoot returns an authorizer checking the roles granted in root.
+func (a *authorizer) withRoot(root *lake.Root) *authorizer {
+	return &authorizer{
+		logger:    a.logger,
+		rbac:      a.rbac,
+		root:      root,
+		forbidden: a.forbidden,
+	}
+}
+
+// Middleware authorizes requests with the authorizer of the Core passed to
+// next, which checks the roles granted in the lake of the Core.
+func (a *authorizer) Middleware(next func(*Core, *ResponseWriter, *Request), accesses []access) func(*Core, *ResponseWriter, *Request) {
+	return func(c *Core, w *ResponseWriter, r *Request) {
+		for _, access := range accesses {
+			if err := c.authz.authorizeRoute(r, access); err != nil {
+				c.authz.deny(w, r, err)
+				return
+			}
+		}
+		next(c, w, r)
+	}
+}
+
+func (a *authorizer) deny(w *ResponseWriter, r *Request, err error) {
+	if srverr.IsForbidden(err) {
+		a.forbidden.Inc()
+		a.logger.Info("Forbidden request",
+			zap.String("request_id", api.RequestIDFromContext(r.Context())),
+			zap.Error(err))
+	}
+	w.Error(err)
+}
+
+func (a *authorizer) authorizeRoute(r *Request, access access) error {
+	if access.param == "" {
+		return a.authorize(r.Context(), access.role, ksuid.Nil, "")
+	}
+	vars := mux.Vars(r.Request)
+	// A pool that does not exist is authorized by a grant on the lake
+	// so that the route reports the missing pool only to those who
+	// could otherwise see it.
+	var poolID ksuid.KSUID
+	if s, err := url.QueryUnescape(vars["pool"]); err == nil {
+		poolID = a.lookupPool(r.Context(), s)
+	}
+	var branch string
+	if access.param != "pool" {
+		branch, _ = url.QueryUnescape(vars[access.param])
+	}
+	return a.authorize(r.Context(), access.role, poolID, branch)
+}
+
+func (a *authorizer) lookupPool(ctx context.Context, s string) ksuid.KSUID {
+	if id, err := lakeparse.ParseID(s); err == nil {
+		return id
+	}
+	id, err := a.root.PoolID(ctx, s)
+	if err != nil {
+		return ksuid.Nil
+	}
+	return id
+}
+
+// authorize returns an error unless the identity of ctx may access the pool
+// with ID poolID and, when role-based authorization is enabled, holds role
+// on the branch of the pool.  An empty branch denotes the entire pool and a
+// nil poolID the entire lake.
+func (a *authorizer) authorize(ctx context.Context, role grants.Role, poolID ksuid.KSUID, branch string) error {
+	ident := auth.IdentityFromContext(ctx)
+	if !ident.Covers(poolID) {
+		return srverr.ErrForbidden("%s not in scope of API key", scopeName(poolID, ""))
+	}
+	if !a.rbac {
+		return nil
+	}
+	subjects := []string{grants.User(string(ident.UserID))}
+	for _, name := range ident.Roles {
+		if grants.Role(name).Includes(role) {
+			return nil
+		}
+		subjects = append(subjects, grants.Group(name))
+	}
+	list, err := a.root.ListGrants(ctx)
+	if err != nil {
+		return err
+	}
+	if grants.Authorized(list, subjects, poolID, branch, role) {
+		return nil
+	}
+	return srverr.ErrForbidden("%s role required on %s", role, scopeName(poolID, branch))
+}
+
+func scopeName(poolID ksuid.KSUID, branch string) string {
+	if poolID == ksuid.Nil {
+		return "lake"
+	}
+	scope := "pool " + poolID.String()
+	if branch != "" {
+		scope = fmt.Sprintf("branch %q of %s", branch, scope)
+	}
+	return scope
+}
+
+// authorizeQuery checks that the identity of octx may read the pools scanned
+// by query and write the branches it loads.  A query that does not compile
+// is authorized so that the compilation error is reported.  The lake's
+// meta-queries, which list pools and index rules, require no role except for
+// the audit log, which requires the admin role on the lake.
+func (a *authorizer) authorizeQuery(octx *op.Context, query ast.Seq, head *lakeparse.Commitish) error {
+	if !a.rbac && len(auth.IdentityFromContext(octx).Pools) == 0 {
+		// Nothing to check so the query is not compiled twice.
+		return nil
+	}
+	job, err := compiler.NewJob(octx, query, data.NewSource(storage.NewRemoteEngine(), a.root), head)
+	if err != nil {
+		return nil
+	}
+	return walkOps(job.Entry(), func(o dag.Op) error {
+		switch o := o.(type) {
+		case *dag.Lister:
+			return a.authorize(octx, grants.Reader, o.Pool, "")
+		case *dag.SeqScan:
+			return a.authorize(octx, grants.Reader, o.Pool, "")
+		case *dag.PoolScan:
+			return a.authorize(octx, grants.Reader, o.ID, "")
+		case *dag.DeleteScan:
+			return a.authorize(octx, grants.Reader, o.ID, "")
+		case *dag.Deleter:
+			return a.authorize(octx, grants.Reader, o.Pool, "")
+		case *dag.PoolMetaScan:
+			return a.authorize(octx, grants.Reader, o.ID, "")
+		case *dag.CommitMetaScan:
+			return a.authorize(octx, grants.Reader, o.Pool, "")
+		case *dag.Load:
+			return a.authorize(octx, grants.Writer, o.Pool, o.Branch)
+		case *dag.LakeMetaScan:
+			if o.Meta == "audit" {
+				return a.authorize(octx, grants.Admin, ksuid.Nil, "")
+			}
+		}
+		return nil
+	})
+}
+
+func walkOps(seq dag.Seq, fn func(dag.Op) error) error {
+	for _, o := range seq {
+		if err := fn(o); err != nil {
+			return err
+		}
+		var seqs []dag.Seq
+		switch o := o.(type) {
+		case *dag.Fork:
+			seqs = o.Paths
+		case *dag.Scatter:
+			seqs = o.Paths
+		case *dag.Over:
+			seqs = []dag.Seq{o.Body}
+		case *dag.Scope:
+			seqs = []dag.Seq{o.Body}
+		case *dag.Switch:
+			for _, c := range o.Cases {
+				seqs = append(seqs, c.Path)
+			}
+		}
+		for _, seq := range seqs {
+			if err := walkOps(seq, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -15,8 +15,16 @@ import (
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/compiler"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/apikeys"
+	"github.com/brimdata/zed/lake/audit"
+	"github.com/brimdata/zed/lake/grants"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/runtime/op"
+	"github.com/brimdata/zed/runtime/vcache"
+	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zson"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -45,14 +53,18 @@ type Config struct {
 	Auth                  AuthConfig
 	CORSAllowedOrigins    []string
 	DefaultResponseFormat string
+	QueryLimits           op.Limits
 	Root                  *storage.URI
 	RootContent           io.ReadSeeker
+	VectorCache           vcache.CacheOpts
 	Version               string
 	Logger                *zap.Logger
 }
 
 type Core struct {
+	apikeys          *apikeys.Store
 	auth             *Auth0Authenticator
+	authz            *authorizer
 	compiler         runtime.Compiler
 	conf             Config
 	engine           storage.Engine
@@ -65,6 +77,8 @@ type Core struct {
 	runningQueriesMu sync.Mutex
 	subscriptions    map[chan event]struct{}
 	subscriptionsMu  sync.RWMutex
+	tenants          *tenants
+	vectorCache      *vcache.Cache
 }
 
 func NewCore(ctx context.Context, conf Config) (*Core, error) {
@@ -93,6 +107,10 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 		if authenticator, err = NewAuthenticator(ctx, conf.Logger, registry, conf.Auth); err != nil {
 			return nil, err
 		}
+	} else if conf.Auth.RBAC {
+		return nil, errors.New("auth.rbac requires auth.enabled")
+	} else if conf.Auth.Tenants {
+		return nil, errors.New("auth.tenants requires auth.enabled")
 	}
 	path := conf.Root
 	if path == nil {
@@ -107,9 +125,13 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 	default:
 		return nil, fmt.Errorf("root path cannot have scheme %q", path.Scheme)
 	}
-	root, err := lake.CreateOrOpen(ctx, engine, conf.Logger.Named("lake"), path)
-	if err != nil {
-		return nil, err
+	var authz *authorizer
+	var keys *apikeys.Store
+	if conf.Auth.Enabled {
+		authz = newAuthorizer(conf.Logger, registry, conf.Auth.RBAC)
+		// API keys are stored under the root rather than in the lake of
+		// a tenant so that a key is found before its tenant is known.
+		keys = apikeys.OpenStore(engine, conf.Logger.Named("apikeys"), path.JoinPath(lake.APIKeysTag))
 	}
 
 	routerAux := mux.NewRouter()
@@ -142,17 +164,25 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 	routerAPI.Use(corsMiddleware(conf.CORSAllowedOrigins))
 
 	c := &Core{
+		apikeys:        keys,
 		auth:           authenticator,
-		compiler:       compiler.NewLakeCompiler(root),
+		authz:          authz,
 		conf:           conf,
 		engine:         engine,
 		logger:         conf.Logger.Named("core"),
-		root:           root,
 		registry:       registry,
 		routerAPI:      routerAPI,
 		routerAux:      routerAux,
 		runningQueries: make(map[string]*queryStatus),
 		subscriptions:  make(map[chan event]struct{}),
+		vectorCache:    vcache.NewCacheWithOpts(engine, conf.VectorCache, registry),
+	}
+	if conf.Auth.Tenants {
+		// Each tenant is served from its own lake under the root, which
+		// is opened by the tenant's first request.
+		c.tenants = newTenants(c)
+	} else if err := c.openLake(ctx, path); err != nil {
+		return nil, err
 	}
 
 	c.addAPIServerRoutes()
@@ -161,33 +191,63 @@ func NewCore(ctx context.Context, conf Config) (*Core, error) {
 }
 
 func (c *Core) addAPIServerRoutes() {
+	c.authhandle("/auth/apikey", handleAPIKeyList).Methods("GET")
+	c.audithandle(audit.OpCreateAPIKey, "/auth/apikey", handleAPIKeyPost).Methods("POST")
+	c.audithandle(audit.OpRevokeAPIKey, "/auth/apikey/{id}", handleAPIKeyDelete).Methods("DELETE")
+	c.authhandle("/auth/grant", handleGrantList).Methods("GET")
+	c.audithandle(audit.OpGrant, "/auth/grant", handleGrantPost).Methods("POST")
+	c.audithandle(audit.OpRevoke, "/auth/grant", handleGrantDelete).Methods("DELETE")
 	c.authhandle("/auth/identity", handleAuthIdentityGet).Methods("GET")
 	// /auth/method intentionally requires no authentication
 	c.routerAPI.Handle("/auth/method", c.handler(handleAuthMethodGet)).Methods("GET")
 	c.authhandle("/events", handleEvents).Methods("GET")
-	c.authhandle("/index", handleIndexRulesDelete).Methods("DELETE")
-	c.authhandle("/index", handleIndexRulesPost).Methods("POST")
-	c.authhandle("/pool", handlePoolPost).Methods("POST")
-	c.authhandle("/pool/{pool}", handlePoolDelete).Methods("DELETE")
-	c.authhandle("/pool/{pool}", handleBranchPost).Methods("POST")
-	c.authhandle("/pool/{pool}", handlePoolPut).Methods("PUT")
-	c.authhandle("/pool/{pool}/branch/{branch}", handleBranchGet).Methods("GET")
-	c.authhandle("/pool/{pool}/branch/{branch}", handleBranchDelete).Methods("DELETE")
-	c.authhandle("/pool/{pool}/branch/{branch}", handleBranchLoad).Methods("POST")
-	c.authhandle("/pool/{pool}/branch/{branch}/compact", handleCompact).Methods("POST")
-	c.authhandle("/pool/{pool}/branch/{branch}/delete", handleDelete).Methods("POST")
-	c.authhandle("/pool/{pool}/branch/{branch}/index", branchHandle(handleIndexApply)).Methods("POST")
-	c.authhandle("/pool/{pool}/branch/{branch}/index/update", branchHandle(handleIndexUpdate)).Methods("POST")
-	c.authhandle("/pool/{pool}/branch/{branch}/merge/{child}", handleBranchMerge).Methods("POST")
-	c.authhandle("/pool/{pool}/branch/{branch}/revert/{commit}", handleRevertPost).Methods("POST")
-	c.authhandle("/pool/{pool}/revision/{revision}/vacuum", handleVacuum).Methods("POST")
-	c.authhandle("/pool/{pool}/revision/{revision}/vector", handleVectorPost).Methods("POST")
-	c.authhandle("/pool/{pool}/revision/{revision}/vector", handleVectorDelete).Methods("DELETE")
-	c.authhandle("/pool/{pool}/stats", handlePoolStats).Methods("GET")
+	c.audithandle(audit.OpDeleteIndexRules, "/index", handleIndexRulesDelete, lakeAccess(grants.Admin)).Methods("DELETE")
+	c.audithandle(audit.OpAddIndexRules, "/index", handleIndexRulesPost, lakeAccess(grants.Admin)).Methods("POST")
+	c.audithandle(audit.OpCreatePool, "/pool", handlePoolPost, lakeAccess(grants.Writer)).Methods("POST")
+	c.audithandle(audit.OpDeletePool, "/pool/{pool}", handlePoolDelete, poolAccess(grants.Admin)).Methods("DELETE")
+	c.audithandle(audit.OpCreateBranch, "/pool/{pool}", handleBranchPost, poolAccess(grants.Writer)).Methods("POST")
+	c.audithandle(audit.OpRenamePool, "/pool/{pool}", handlePoolPut, poolAccess(grants.Admin)).Methods("PUT")
+	c.authhandle("/pool/{pool}/branch/{branch}", handleBranchGet, branchAccess(grants.Reader, "branch")).Methods("GET")
+	c.audithandle(audit.OpDeleteBranch, "/pool/{pool}/branch/{branch}", handleBranchDelete, branchAccess(grants.Admin, "branch")).Methods("DELETE")
+	c.audithandle(audit.OpLoad, "/pool/{pool}/branch/{branch}", handleBranchLoad, branchAccess(grants.Writer, "branch")).Methods("POST")
+	c.audithandle(audit.OpCompact, "/pool/{pool}/branch/{branch}/compact", handleCompact, branchAccess(grants.Writer, "branch")).Methods("POST")
+	c.audithandle(audit.OpDelete, "/pool/{pool}/branch/{branch}/delete", handleDelete, branchAccess(grants.Writer, "branch")).Methods("POST")
+	c.audithandle(audit.OpApplyIndexRules, "/pool/{pool}/branch/{branch}/index", branchHandle(handleIndexApply), branchAccess(grants.Writer, "branch")).Methods("POST")
+	c.audithandle(audit.OpUpdateIndex, "/pool/{pool}/branch/{branch}/index/update", branchHandle(handleIndexUpdate), branchAccess(grants.Writer, "branch")).Methods("POST")
+	c.audithandle(audit.OpMerge, "/pool/{pool}/branch/{branch}/merge/{child}", handleBranchMerge, branchAccess(grants.Writer, "branch"), branchAccess(grants.Reader, "child")).Methods("POST")
+	c.audithandle(audit.OpRevert, "/pool/{pool}/branch/{branch}/revert/{commit}", handleRevertPost, branchAccess(grants.Writer, "branch")).Methods("POST")
+	c.audithandle(audit.OpVacuum, "/pool/{pool}/revision/{revision}/vacuum", handleVacuum, poolAccess(grants.Admin)).Methods("POST")
+	c.audithandle(audit.OpCheckpoint, "/pool/{pool}/revision/{revision}/checkpoint", handleCheckpoint, poolAccess(grants.Admin)).Methods("POST")
+	c.audithandle(audit.OpAddVectors, "/pool/{pool}/revision/{revision}/vector", handleVectorPost, poolAccess(grants.Writer)).Methods("POST")
+	c.audithandle(audit.OpDeleteVectors, "/pool/{pool}/revision/{revision}/vector", handleVectorDelete, poolAccess(grants.Writer)).Methods("DELETE")
+	c.authhandle("/pool/{pool}/stats", handlePoolStats, poolAccess(grants.Reader)).Methods("GET")
+	c.audithandle(audit.OpCreateTag, "/pool/{pool}/tag", handleTagPost, poolAccess(grants.Writer)).Methods("POST")
+	c.authhandle("/pool/{pool}/tag/{tag}", handleTagGet, poolAccess(grants.Reader)).Methods("GET")
+	c.audithandle(audit.OpDeleteTag, "/pool/{pool}/tag/{tag}", handleTagDelete, poolAccess(grants.Admin)).Methods("DELETE")
+	// Queries are authorized by handleQuery according to the pools they
+	// read and write.  Running queries may be listed and killed by the
+	// users who ran them and by admins of the lake (see managesQuery).
 	c.authhandle("/query", handleQuery).Methods("OPTIONS", "POST")
+	c.authhandle("/query", handleQueryList).Methods("GET")
+	c.authhandle("/query/{requestID}", handleQueryKill).Methods("DELETE")
 	c.authhandle("/query/status/{requestID}", handleQueryStatus).Methods("GET")
 }
 
+// openLake creates or opens the lake at path and serves requests from it.
+func (c *Core) openLake(ctx context.Context, path *storage.URI) error {
+	root, err := lake.CreateOrOpen(ctx, c.engine, c.conf.Logger.Named("lake"), path)
+	if err != nil {
+		return err
+	}
+	root.SetVectorCache(c.vectorCache)
+	c.root = root
+	c.compiler = compiler.NewLakeCompiler(root)
+	if c.authz != nil {
+		c.authz = c.authz.withRoot(root)
+	}
+	return nil
+}
+
 func (c *Core) handler(f func(*Core, *ResponseWriter, *Request)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if res, req, ok := newRequest(w, r, c); ok {
@@ -196,7 +256,27 @@ func (c *Core) handler(f func(*Core, *ResponseWriter, *Request)) http.Handler {
 	})
 }
 
-func (c *Core) authhandle(path string, f func(*Core, *ResponseWriter, *Request)) *mux.Route {
+// authhandle registers f as the handler of an authenticated route.  The
+// identity of a request must be in the scope of accesses and, when
+// role-based authorization is enabled, must also hold their roles.  When
+// tenant isolation is enabled, f is passed the Core of the tenant of the
+// identity.
+func (c *Core) authhandle(path string, f func(*Core, *ResponseWriter, *Request), accesses ...access) *mux.Route {
+	return c.audithandle("", path, f, accesses...)
+}
+
+// audithandle is like authhandle but also records each request, including
+// one that is denied, in the audit log of the lake as op unless op is empty.
+func (c *Core) audithandle(op, path string, f func(*Core, *ResponseWriter, *Request), accesses ...access) *mux.Route {
+	if c.authz != nil && len(accesses) > 0 {
+		f = c.authz.Middleware(f, accesses)
+	}
+	if op != "" {
+		f = auditMiddleware(op, f)
+	}
+	if c.tenants != nil {
+		f = c.tenants.Middleware(f)
+	}
 	if c.auth != nil {
 		f = c.auth.Middleware(f)
 	}
@@ -262,7 +342,7 @@ func (c *Core) publishEvent(w *ResponseWriter, name string, data interface{}) {
 	}()
 }
 
-func (c *Core) newQueryStatus(r *Request) *queryStatus {
+func (c *Core) newQueryStatus(r *Request, query string, meter zbuf.Meter, cancel context.CancelFunc) *queryStatus {
 	id := r.ID()
 	remove := func() {
 		// Have query status wait around for a few seconds after done is signaled
@@ -272,7 +352,20 @@ func (c *Core) newQueryStatus(r *Request) *queryStatus {
 		delete(c.runningQueries, id)
 		c.runningQueriesMu.Unlock()
 	}
-	q := &queryStatus{remove: remove}
+	ident := auth.IdentityFromContext(r.Context())
+	q := &queryStatus{
+		remove: remove,
+		cancel: cancel,
+		meter:  meter,
+		info: api.QueryInfo{
+			RequestID: id,
+			TenantID:  string(ident.TenantID),
+			UserID:    string(ident.UserID),
+			StartTime: nano.Now(),
+			Query:     query,
+		},
+		running: true,
+	}
 	q.wg.Add(1)
 	c.runningQueriesMu.Lock()
 	c.runningQueries[id] = q
@@ -284,6 +377,41 @@ type queryStatus struct {
 	wg     sync.WaitGroup
 	remove func()
 	error  string
+	cancel context.CancelFunc
+	meter  zbuf.Meter
+	info   api.QueryInfo
+
+	mu      sync.Mutex
+	running bool
+	killed  bool
+}
+
+// Info returns a description of the query and its progress and whether
+// the query is still running.
+func (q *queryStatus) Info() (api.QueryInfo, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	info := q.info
+	info.Progress = q.meter.Progress()
+	return info, q.running
+}
+
+// Kill cancels the query if it is still running and reports whether it was.
+func (q *queryStatus) Kill() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.running {
+		return false
+	}
+	q.killed = true
+	q.cancel()
+	return true
+}
+
+func (q *queryStatus) Killed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.killed
 }
 
 func (q *queryStatus) setError(err error) {
@@ -293,6 +421,9 @@ func (q *queryStatus) setError(err error) {
 }
 
 func (q *queryStatus) Done() {
+	q.mu.Lock()
+	q.running = false
+	q.mu.Unlock()
 	q.wg.Done()
 	go q.remove()
 }
@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/brimdata/zed"
@@ -15,11 +18,14 @@ import (
 	"github.com/brimdata/zed/compiler/optimizer/demand"
 	"github.com/brimdata/zed/lake"
 	lakeapi "github.com/brimdata/zed/lake/api"
+	"github.com/brimdata/zed/lake/apikeys"
+	"github.com/brimdata/zed/lake/audit"
 	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/grants"
 	"github.com/brimdata/zed/lake/index"
 	"github.com/brimdata/zed/lake/journal"
 	"github.com/brimdata/zed/lakeparse"
-	"github.com/brimdata/zed/runtime"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/runtime/exec"
 	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/service/auth"
@@ -32,6 +38,8 @@ import (
 	"go.uber.org/zap"
 )
 
+var errQueryKilled = errors.New("query killed")
+
 func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	const queryStatsInterval = time.Second
 	var req api.QueryRequest
@@ -52,13 +60,40 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	// The client must look at the return code and interpret the result
 	// accordingly and when it sees a ZNG error after underway,
 	// the error should be relay that to the caller/user.
+	if req.SummarizeMemMax < 0 {
+		w.Error(srverr.ErrInvalid("summarize_mem_max must not be negative"))
+		return
+	}
+	limits, err := queryLimits(c.conf.QueryLimits, req.Limits)
+	if err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
 	query, err := c.compiler.Parse(req.Query)
 	if err != nil {
 		w.Error(srverr.ErrInvalid(err))
 		return
 	}
-	flowgraph, err := runtime.CompileLakeQuery(r.Context(), zed.NewContext(), c.compiler, query, &req.Head, r.Logger)
+	// A load operator in the query records itself in the audit log.
+	ident := auth.IdentityFromContext(r.Context())
+	ctx := audit.ContextWithIdentity(r.Context(), audit.Identity{
+		TenantID:  string(ident.TenantID),
+		UserID:    string(ident.UserID),
+		RequestID: r.ID(),
+	})
+	octx := op.NewContext(ctx, zed.NewContext(), r.Logger)
+	octx.GroupByMemMaxBytes = req.SummarizeMemMax
+	octx.SetLimits(limits)
+	if c.authz != nil {
+		if err := c.authz.authorizeQuery(octx, query, &req.Head); err != nil {
+			octx.Cancel()
+			c.authz.deny(w, r, err)
+			return
+		}
+	}
+	flowgraph, err := c.compiler.NewLakeQuery(octx, query, 0, &req.Head)
 	if err != nil {
+		octx.Cancel()
 		w.Error(srverr.ErrInvalid(err))
 		return
 	}
@@ -75,9 +110,12 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	// Launch query status which will report and runtime errors (i.e., system
 	// errors that occur after the OK header has been sent) to the query status
 	// endpoint.
-	status := c.newQueryStatus(r)
+	status := c.newQueryStatus(r, req.Query, flowgraph.Meter(), octx.Cancel)
 	defer status.Done()
 	handleError := func(err error) {
+		if status.Killed() {
+			err = errQueryKilled
+		}
 		writer.WriteError(err)
 		status.setError(err)
 	}
@@ -142,6 +180,86 @@ func handleQuery(c *Core, w *ResponseWriter, r *Request) {
 	}
 }
 
+// queryLimits returns the limits for a query given the service's limits
+// and those of the request, which may lower but not raise the service's.
+func queryLimits(limits op.Limits, req *api.QueryLimits) (op.Limits, error) {
+	if req == nil {
+		return limits, nil
+	}
+	if req.MemMax < 0 || req.RowsMax < 0 || req.Timeout < 0 || req.ScanMax < 0 {
+		return op.Limits{}, errors.New("query limits must not be negative")
+	}
+	limits.MemMaxBytes = lowerLimit(limits.MemMaxBytes, req.MemMax)
+	limits.RowsMax = lowerLimit(limits.RowsMax, req.RowsMax)
+	limits.Timeout = time.Duration(lowerLimit(int64(limits.Timeout), int64(req.Timeout)))
+	limits.ScanMaxBytes = lowerLimit(limits.ScanMaxBytes, req.ScanMax)
+	return limits, nil
+}
+
+func lowerLimit(max, v int64) int64 {
+	if v > 0 && (max == 0 || v < max) {
+		return v
+	}
+	return max
+}
+
+func handleQueryList(c *Core, w *ResponseWriter, r *Request) {
+	c.runningQueriesMu.Lock()
+	queries := make([]*queryStatus, 0, len(c.runningQueries))
+	for _, q := range c.runningQueries {
+		queries = append(queries, q)
+	}
+	c.runningQueriesMu.Unlock()
+	// Authorization may consult the lake so it is done without holding
+	// runningQueriesMu.
+	infos := make([]api.QueryInfo, 0, len(queries))
+	for _, q := range queries {
+		if info, running := q.Info(); running && c.managesQuery(r.Context(), info) {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].StartTime < infos[j].StartTime
+	})
+	w.Respond(http.StatusOK, infos)
+}
+
+func handleQueryKill(c *Core, w *ResponseWriter, r *Request) {
+	id, ok := r.StringFromPath(w, "requestID")
+	if !ok {
+		return
+	}
+	c.runningQueriesMu.Lock()
+	q, ok := c.runningQueries[id]
+	c.runningQueriesMu.Unlock()
+	if ok {
+		// A query that cannot be killed by the caller is reported as
+		// missing so that its existence is not revealed.
+		info, _ := q.Info()
+		ok = c.managesQuery(r.Context(), info)
+	}
+	if !ok || !q.Kill() {
+		w.Error(srverr.ErrNotFound("query not found"))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// managesQuery returns true if the identity of ctx may list and kill the
+// query described by info.  A query is managed by the user who ran it and,
+// when role-based authorization is enabled, by the admins of the lake of its
+// tenant.
+func (c *Core) managesQuery(ctx context.Context, info api.QueryInfo) bool {
+	ident := auth.IdentityFromContext(ctx)
+	if info.TenantID != string(ident.TenantID) {
+		return false
+	}
+	if info.UserID == string(ident.UserID) {
+		return true
+	}
+	return c.authz.rbac && c.authz.authorize(ctx, grants.Admin, ksuid.Nil, "") == nil
+}
+
 func handleQueryStatus(c *Core, w *ResponseWriter, r *Request) {
 	id, ok := r.StringFromPath(w, "requestID")
 	if !ok {
@@ -214,11 +332,13 @@ func handlePoolPost(c *Core, w *ResponseWriter, r *Request) {
 	if !r.Unmarshal(w, &req) {
 		return
 	}
-	pool, err := c.root.CreatePool(r.Context(), req.Name, req.SortKey, req.SeekStride, req.Thresh)
+	r.auditTargets(req.Name)
+	pool, err := c.root.CreatePool(r.Context(), req.Name, req.SortKey, req.SeekStride, req.Thresh, req.Compression, req.Schema)
 	if err != nil {
 		w.Error(err)
 		return
 	}
+	r.auditPool(pool.ID)
 	meta, err := pool.Main(r.Context())
 	if err != nil {
 		w.Error(err)
@@ -237,6 +357,7 @@ func handlePoolPut(c *Core, w *ResponseWriter, r *Request) {
 	if !ok {
 		return
 	}
+	r.auditTargets(req.Name)
 	if err := c.root.RenamePool(r.Context(), id, req.Name); err != nil {
 		w.Error(err)
 		return
@@ -259,6 +380,8 @@ func handleBranchPost(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(srverr.ErrInvalid("invalid commit object: %s", req.Commit))
 		return
 	}
+	r.auditBranch(req.Name)
+	r.auditTargets(req.Commit)
 	branchRef, err := c.root.CreateBranch(r.Context(), poolID, req.Name, commit)
 	if err != nil {
 		w.Error(err)
@@ -268,6 +391,68 @@ func handleBranchPost(c *Core, w *ResponseWriter, r *Request) {
 	c.publishEvent(w, "branch-update", api.EventBranch{PoolID: poolID, Branch: branchRef.Name})
 }
 
+func handleTagPost(c *Core, w *ResponseWriter, r *Request) {
+	var req api.TagPostRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	pool, ok := r.openPool(w, c.root)
+	if !ok {
+		return
+	}
+	commit, err := pool.ResolveRevision(r.Context(), req.Commit)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if commit == ksuid.Nil {
+		w.Error(srverr.ErrInvalid("cannot tag empty branch %q", req.Commit))
+		return
+	}
+	r.auditTargets(req.Name, commit.String())
+	tagRef, err := c.root.CreateTag(r.Context(), pool.ID, req.Name, commit)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, tagRef)
+	c.publishEvent(w, "tag-update", api.EventTag{PoolID: pool.ID, Tag: tagRef.Name})
+}
+
+func handleTagGet(c *Core, w *ResponseWriter, r *Request) {
+	tagName, ok := r.StringFromPath(w, "tag")
+	if !ok {
+		return
+	}
+	pool, ok := r.openPool(w, c.root)
+	if !ok {
+		return
+	}
+	tagRef, err := pool.LookupTagByName(r.Context(), tagName)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, tagRef)
+}
+
+func handleTagDelete(c *Core, w *ResponseWriter, r *Request) {
+	poolID, ok := r.PoolID(w, c.root)
+	if !ok {
+		return
+	}
+	tagName, ok := r.StringFromPath(w, "tag")
+	if !ok {
+		return
+	}
+	if err := c.root.RemoveTag(r.Context(), poolID, tagName); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+	c.publishEvent(w, "tag-delete", api.EventTag{PoolID: poolID, Tag: tagName})
+}
+
 func handleRevertPost(c *Core, w *ResponseWriter, r *Request) {
 	poolID, ok := r.PoolID(w, c.root)
 	if !ok {
@@ -290,6 +475,7 @@ func handleRevertPost(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(err)
 		return
 	}
+	r.auditTargets(commit.String())
 	w.Respond(http.StatusOK, api.CommitResponse{Commit: commit})
 	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
 		CommitID: commit,
@@ -320,6 +506,7 @@ func handleBranchMerge(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(err)
 		return
 	}
+	r.auditTargets(commit.String())
 	w.Respond(http.StatusOK, api.CommitResponse{Commit: commit})
 	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
 		CommitID: commit,
@@ -389,7 +576,7 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(err)
 		return
 	}
-	reader, err := anyio.GzipReader(r.Body)
+	reader, err := anyio.DecompressReader(r.Body)
 	if err != nil {
 		w.Error(err)
 		return
@@ -430,7 +617,7 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 	}
 	defer zrc.Close()
 	wr := &warningsReader{zrc, []string{}}
-	kommit, err := branch.Load(r.Context(), zctx, wr, message.Author, message.Body, message.Meta)
+	kommit, err := branch.Load(r.Context(), c.compiler, zctx, wr, message.Author, message.Body, message.Meta)
 	if err != nil {
 		if errors.Is(err, commits.ErrEmptyTransaction) {
 			err = srverr.ErrInvalid("no records in request")
@@ -441,6 +628,7 @@ func handleBranchLoad(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(err)
 		return
 	}
+	r.auditTargets(kommit.String())
 	w.Respond(http.StatusOK, api.CommitResponse{
 		Warnings: wr.warnings,
 		Commit:   kommit,
@@ -492,6 +680,7 @@ func handleCompact(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(err)
 		return
 	}
+	r.auditTargets(commit.String())
 	w.Respond(http.StatusOK, api.CommitResponse{Commit: commit})
 	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
 		CommitID: commit,
@@ -534,6 +723,7 @@ func handleDelete(c *Core, w *ResponseWriter, r *Request) {
 			w.Error(srverr.ErrInvalid(err))
 			return
 		}
+		r.auditTargets(payload.ObjectIDs...)
 		commit, err = branch.Delete(r.Context(), ids, message.Author, message.Body)
 	} else {
 		if payload.Where == "" {
@@ -555,6 +745,7 @@ func handleDelete(c *Core, w *ResponseWriter, r *Request) {
 		w.Error(err)
 		return
 	}
+	r.auditTargets(commit.String())
 	w.Marshal(api.CommitResponse{Commit: commit})
 	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
 		CommitID: commit,
@@ -568,6 +759,9 @@ func handleIndexRulesPost(c *Core, w *ResponseWriter, r *Request) {
 	if !r.Unmarshal(w, &body, index.RuleTypes...) {
 		return
 	}
+	for _, rule := range body.Rules {
+		r.auditTargets(rule.RuleID().String())
+	}
 	if err := c.root.AddIndexRules(r.Context(), body.Rules); err != nil {
 		w.Error(err)
 		return
@@ -584,6 +778,7 @@ func handleIndexRulesDelete(c *Core, w *ResponseWriter, r *Request) {
 	if err != nil {
 		w.Error(srverr.ErrInvalid(err))
 	}
+	r.auditTargets(req.RuleIDs...)
 	rules, err := c.root.DeleteIndexRules(r.Context(), ruleIDs)
 	if err != nil {
 		w.Error(err)
@@ -617,6 +812,8 @@ func handleIndexApply(c *Core, w *ResponseWriter, r *Request, branch *lake.Branc
 		w.Error(err)
 		return
 	}
+	r.auditTargets(commit.String())
+	r.auditTargets(req.Rules...)
 	w.Respond(http.StatusOK, api.CommitResponse{Commit: commit})
 	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
 		CommitID: commit,
@@ -650,6 +847,8 @@ func handleIndexUpdate(c *Core, w *ResponseWriter, r *Request, branch *lake.Bran
 		w.Error(err)
 		return
 	}
+	r.auditTargets(commit.String())
+	r.auditTargets(req.Rules...)
 	w.Respond(http.StatusOK, api.CommitResponse{Commit: commit})
 	c.publishEvent(w, "branch-commit", api.EventBranchCommit{
 		CommitID: commit,
@@ -671,6 +870,9 @@ func handleVacuum(c *Core, w *ResponseWriter, r *Request) {
 	if !ok {
 		return
 	}
+	if dryrun {
+		r.skipAudit()
+	}
 	lk := lakeapi.FromRoot(c.root)
 	oids, err := lk.Vacuum(r.Context(), pool, revision, dryrun)
 	if err != nil {
@@ -680,6 +882,35 @@ func handleVacuum(c *Core, w *ResponseWriter, r *Request) {
 	w.Respond(http.StatusOK, api.VacuumResponse{ObjectIDs: oids})
 }
 
+func handleCheckpoint(c *Core, w *ResponseWriter, r *Request) {
+	pool, ok := r.StringFromPath(w, "pool")
+	if !ok {
+		return
+	}
+	revision, ok := r.StringFromPath(w, "revision")
+	if !ok {
+		return
+	}
+	interval, ok := r.IntFromQuery(w, "interval")
+	if !ok {
+		return
+	}
+	verify, ok := r.BoolFromQuery(w, "verify")
+	if !ok {
+		return
+	}
+	if verify {
+		r.skipAudit()
+	}
+	lk := lakeapi.FromRoot(c.root)
+	commits, err := lk.Checkpoint(r.Context(), pool, revision, interval, verify)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, api.CheckpointResponse{Commits: commits})
+}
+
 func handleVectorPost(c *Core, w *ResponseWriter, r *Request) {
 	pool, ok := r.StringFromPath(w, "pool")
 	if !ok {
@@ -737,9 +968,210 @@ func handleAuthIdentityGet(c *Core, w *ResponseWriter, r *Request) {
 	w.Respond(http.StatusOK, api.AuthIdentityResponse{
 		TenantID: string(ident.TenantID),
 		UserID:   string(ident.UserID),
+		Roles:    ident.Roles,
 	})
 }
 
+func handleGrantList(c *Core, w *ResponseWriter, r *Request) {
+	list, err := c.root.ListGrants(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if c.authz != nil {
+		// Only the grants the caller could revoke are listed.
+		var visible []grants.Grant
+		for _, g := range list {
+			if c.authz.authorize(r.Context(), grants.Admin, g.Pool, g.Branch) == nil {
+				visible = append(visible, g)
+			}
+		}
+		list = visible
+	}
+	w.Respond(http.StatusOK, api.GrantsResponse{Grants: list})
+}
+
+func handleGrantPost(c *Core, w *ResponseWriter, r *Request) {
+	var req api.GrantRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	r.auditPool(req.Pool)
+	r.auditBranch(req.Branch)
+	r.auditTargets(req.Subject, req.Role)
+	role, err := grants.ParseRole(req.Role)
+	if err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if _, err := grants.ParseSubject(req.Subject); err != nil {
+		w.Error(srverr.ErrInvalid(err))
+		return
+	}
+	if req.Pool == ksuid.Nil && req.Branch != "" {
+		w.Error(srverr.ErrInvalid("branch grant requires a pool"))
+		return
+	}
+	if c.authz != nil {
+		if err := c.authz.authorize(r.Context(), grants.Admin, req.Pool, req.Branch); err != nil {
+			c.authz.deny(w, r, err)
+			return
+		}
+	}
+	grant, err := c.root.Grant(r.Context(), req.Subject, req.Pool, req.Branch, role)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, grant)
+}
+
+func handleGrantDelete(c *Core, w *ResponseWriter, r *Request) {
+	query := r.URL.Query()
+	subject := query.Get("subject")
+	if subject == "" {
+		w.Error(srverr.ErrInvalid("subject query param required"))
+		return
+	}
+	var poolID ksuid.KSUID
+	if s := query.Get("pool"); s != "" {
+		var err error
+		if poolID, err = lakeparse.ParseID(s); err != nil {
+			w.Error(srverr.ErrInvalid("invalid query param %q: %w", "pool", err))
+			return
+		}
+	}
+	branch := query.Get("branch")
+	r.auditPool(poolID)
+	r.auditBranch(branch)
+	r.auditTargets(subject)
+	if c.authz != nil {
+		if err := c.authz.authorize(r.Context(), grants.Admin, poolID, branch); err != nil {
+			c.authz.deny(w, r, err)
+			return
+		}
+	}
+	if err := c.root.Revoke(r.Context(), subject, poolID, branch); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errAPIKeysDisabled = srverr.ErrInvalid("API keys require auth.enabled")
+
+func handleAPIKeyList(c *Core, w *ResponseWriter, r *Request) {
+	if c.apikeys == nil {
+		w.Error(errAPIKeysDisabled)
+		return
+	}
+	list, err := c.apikeys.All(r.Context())
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	keys := []api.APIKey{}
+	for _, key := range list {
+		if c.managesAPIKey(r.Context(), &key) {
+			keys = append(keys, apiKey(&key))
+		}
+	}
+	w.Respond(http.StatusOK, api.APIKeysResponse{Keys: keys})
+}
+
+func handleAPIKeyPost(c *Core, w *ResponseWriter, r *Request) {
+	if c.apikeys == nil {
+		w.Error(errAPIKeysDisabled)
+		return
+	}
+	var req api.APIKeyRequest
+	if !r.Unmarshal(w, &req) {
+		return
+	}
+	ident := auth.IdentityFromContext(r.Context())
+	if ident.APIKey != ksuid.Nil {
+		// Otherwise, a key could be used to replace itself with one that
+		// never expires.
+		w.Error(srverr.ErrForbidden("API keys cannot create API keys"))
+		return
+	}
+	if req.Expires != 0 && req.Expires <= nano.Now() {
+		w.Error(srverr.ErrInvalid("expiration must be in the future"))
+		return
+	}
+	for _, id := range req.Pools {
+		if _, err := c.root.OpenPool(r.Context(), id); err != nil {
+			w.Error(err)
+			return
+		}
+	}
+	key, secret, err := apikeys.New(req.Name, string(ident.TenantID), string(ident.UserID), req.Pools, req.Expires)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	r.auditTargets(key.ID.String())
+	if err := c.apikeys.Add(r.Context(), key); err != nil {
+		w.Error(err)
+		return
+	}
+	w.Respond(http.StatusOK, api.APIKeyResponse{Key: apiKey(key), Secret: secret})
+}
+
+func handleAPIKeyDelete(c *Core, w *ResponseWriter, r *Request) {
+	if c.apikeys == nil {
+		w.Error(errAPIKeysDisabled)
+		return
+	}
+	id, ok := r.TagFromPath(w, "id")
+	if !ok {
+		return
+	}
+	r.auditTargets(id.String())
+	key, err := c.apikeys.Lookup(r.Context(), id)
+	if err != nil {
+		w.Error(err)
+		return
+	}
+	if !c.managesAPIKey(r.Context(), key) {
+		// A key that cannot be revoked by the caller is reported as
+		// missing so that its existence is not revealed.
+		w.Error(fmt.Errorf("%s: %w", id, apikeys.ErrNotFound))
+		return
+	}
+	if err := c.apikeys.Remove(r.Context(), id); err != nil {
+		w.Error(err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// managesAPIKey returns true if the identity of ctx may list and revoke key.
+// A key is managed by the user who created it and, when role-based
+// authorization is enabled, by the admins of the lake of its tenant.
+func (c *Core) managesAPIKey(ctx context.Context, key *apikeys.Key) bool {
+	ident := auth.IdentityFromContext(ctx)
+	if key.TenantID != string(ident.TenantID) {
+		return false
+	}
+	if key.UserID == string(ident.UserID) {
+		return true
+	}
+	return c.authz.rbac && c.authz.authorize(ctx, grants.Admin, ksuid.Nil, "") == nil
+}
+
+func apiKey(key *apikeys.Key) api.APIKey {
+	return api.APIKey{
+		ID:       key.ID,
+		Name:     key.Name,
+		TenantID: key.TenantID,
+		UserID:   key.UserID,
+		Pools:    key.Pools,
+		Created:  key.Created,
+		Expires:  key.Expires,
+	}
+}
+
 func handleAuthMethodGet(c *Core, w *ResponseWriter, r *Request) {
 	if c.auth == nil {
 		w.Respond(http.StatusOK, api.AuthMethodResponse{Kind: api.AuthMethodNone})
@@ -2,18 +2,26 @@ package service_test
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/brimdata/zed/api"
 	"github.com/brimdata/zed/api/client"
+	"github.com/brimdata/zed/api/queryio"
 	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/storage"
 	"github.com/brimdata/zed/runtime/exec"
+	"github.com/brimdata/zed/runtime/op"
 	"github.com/brimdata/zed/service"
+	"github.com/brimdata/zed/zbuf"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/segmentio/ksuid"
 	"github.com/stretchr/testify/assert"
@@ -55,6 +63,134 @@ func TestQueryGroupByReverse(t *testing.T) {
 	require.Equal(t, counts, "\n"+conn.TestQuery("from test | count() by every(1s)"))
 }
 
+func TestQuerySummarizeMemMax(t *testing.T) {
+	src := `
+{k:1,x:1}
+{k:2,x:2}
+{k:1,x:3}
+`
+	_, conn := newCore(t)
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	conn.TestLoad(poolID, "main", strings.NewReader(src))
+	query := "from test | union(x) by k | sort k"
+	req := conn.NewRequest(context.Background(), "POST", "/query", api.QueryRequest{Query: query, SummarizeMemMax: 1})
+	req.Header.Set("Accept", api.MediaTypeZSON)
+	res, err := conn.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "{k:1,union:|[1,3]|}\n{k:2,union:|[2]|}\n", string(b))
+	req = conn.NewRequest(context.Background(), "POST", "/query", api.QueryRequest{Query: query, SummarizeMemMax: -1})
+	_, err = conn.Do(req)
+	require.Error(t, err)
+	require.Equal(t, 400, err.(*client.ErrorResponse).StatusCode)
+}
+
+func TestQueryLimits(t *testing.T) {
+	src := `
+{x:1}
+{x:2}
+{x:3}
+`
+	_, conn := newCoreWithConfig(t, service.Config{
+		QueryLimits: op.Limits{RowsMax: 2},
+	})
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	conn.TestLoad(poolID, "main", strings.NewReader(src))
+	query := func(src string, limits *api.QueryLimits) (int, error) {
+		req := conn.NewRequest(context.Background(), "POST", "/query?ctrl=T", api.QueryRequest{Query: src, Limits: limits})
+		res, err := conn.Do(req)
+		require.NoError(t, err)
+		q := queryio.NewQuery(res.Body)
+		defer q.Close()
+		r := zbuf.NoControl(q)
+		var n int
+		for {
+			val, err := r.Read()
+			if val == nil || err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	var limitErr *op.LimitError
+	n, err := query("from test", nil)
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &op.LimitError{Limit: op.LimitRows, Max: 2}, limitErr)
+	assert.Equal(t, 2, n)
+	n, err = query("from test | head 2", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	// A request may lower the service's limits but not raise them.
+	n, err = query("from test", &api.QueryLimits{RowsMax: 1})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &op.LimitError{Limit: op.LimitRows, Max: 1}, limitErr)
+	assert.Equal(t, 1, n)
+	_, err = query("from test", &api.QueryLimits{RowsMax: 5})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, int64(2), limitErr.Max)
+	_, err = query("from test", &api.QueryLimits{ScanMax: 1})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &op.LimitError{Limit: op.LimitScan, Max: 1}, limitErr)
+	_, err = query("from test | sort x", &api.QueryLimits{MemMax: 1})
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, &op.LimitError{Limit: op.LimitMemory, Max: 1}, limitErr)
+	req := conn.NewRequest(context.Background(), "POST", "/query", api.QueryRequest{Query: "from test", Limits: &api.QueryLimits{RowsMax: -1}})
+	_, err = conn.Do(req)
+	require.Error(t, err)
+	require.Equal(t, 400, err.(*client.ErrorResponse).StatusCode)
+}
+
+// testLoadBlocking loads enough incompressible data into a pool named test
+// that a query reading it blocks writing its response until the response is
+// read.
+func testLoadBlocking(conn *testClient) {
+	var src strings.Builder
+	b := make([]byte, 50000)
+	rng := rand.New(rand.NewSource(0))
+	for i := 0; i < 200; i++ {
+		rng.Read(b)
+		fmt.Fprintf(&src, "{s:%q}\n", hex.EncodeToString(b))
+	}
+	poolID := conn.TestPoolPost(api.PoolPostRequest{Name: "test"})
+	conn.TestLoad(poolID, "main", strings.NewReader(src.String()))
+}
+
+func TestQueryListAndKill(t *testing.T) {
+	_, conn := newCore(t)
+	testLoadBlocking(conn)
+	ctx := context.Background()
+	infos, err := conn.ListQueries(ctx)
+	require.NoError(t, err)
+	assert.Len(t, infos, 0)
+	res, err := conn.Query(ctx, nil, "from test")
+	require.NoError(t, err)
+	infos, err = conn.ListQueries(ctx)
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	assert.Equal(t, res.Header.Get(api.RequestIDHeader), infos[0].RequestID)
+	assert.Equal(t, "from test", infos[0].Query)
+	require.NoError(t, conn.KillQuery(ctx, infos[0].RequestID))
+	q := queryio.NewQuery(res.Body)
+	defer q.Close()
+	r := zbuf.NoControl(q)
+	for {
+		val, err := r.Read()
+		if err != nil {
+			assert.EqualError(t, err, "query killed")
+			break
+		}
+		require.NotNil(t, val, "query was not killed")
+	}
+	require.Eventually(t, func() bool {
+		infos, err := conn.ListQueries(ctx)
+		return err == nil && len(infos) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	err = conn.KillQuery(ctx, infos[0].RequestID)
+	assert.ErrorIs(t, err, client.ErrQueryNotFound)
+}
+
 func TestPoolStats(t *testing.T) {
 	src := `
 {_path:"conn",ts:1970-01-01T00:00:01Z,uid:"CBrzd94qfowOqJwCHa"}
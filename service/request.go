@@ -15,10 +15,14 @@ import (
 	"github.com/brimdata/zed/compiler/optimizer/demand"
 	"github.com/brimdata/zed/compiler/parser"
 	"github.com/brimdata/zed/lake"
+	"github.com/brimdata/zed/lake/apikeys"
+	"github.com/brimdata/zed/lake/audit"
 	"github.com/brimdata/zed/lake/branches"
 	"github.com/brimdata/zed/lake/commits"
+	"github.com/brimdata/zed/lake/grants"
 	"github.com/brimdata/zed/lake/journal"
 	"github.com/brimdata/zed/lake/pools"
+	"github.com/brimdata/zed/lake/tags"
 	"github.com/brimdata/zed/lakeparse"
 	"github.com/brimdata/zed/service/srverr"
 	"github.com/brimdata/zed/zio"
@@ -32,6 +36,8 @@ import (
 type Request struct {
 	*http.Request
 	Logger *zap.Logger
+	// audit is the audit record of a request that modifies the lake.
+	audit *audit.Record
 }
 
 func newRequest(w http.ResponseWriter, r *http.Request, c *Core) (*ResponseWriter, *Request, bool) {
@@ -152,6 +158,19 @@ func (r *Request) JournalIDFromQuery(w *ResponseWriter, param string) (journal.I
 	return journal.ID(id), true
 }
 
+func (r *Request) IntFromQuery(w *ResponseWriter, param string) (int, bool) {
+	s := r.URL.Query().Get(param)
+	if s == "" {
+		return 0, true
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		w.Error(srverr.ErrInvalid("invalid query param %q: %w", param, err))
+		return 0, false
+	}
+	return i, true
+}
+
 func (r *Request) BoolFromQuery(w *ResponseWriter, param string) (bool, bool) {
 	s := r.URL.Query().Get(param)
 	if s == "" {
@@ -216,6 +235,9 @@ type ResponseWriter struct {
 	zw        zio.WriteCloser
 	marshaler *zson.MarshalZNGContext
 	written   int32
+	// status and err are the response status and error, if any.
+	status int
+	err    error
 }
 
 func (w *ResponseWriter) ContentType() string {
@@ -239,6 +261,11 @@ func (w *ResponseWriter) ZioWriter() zio.WriteCloser {
 	return w.zw
 }
 
+func (w *ResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
 func (w *ResponseWriter) Write(b []byte) (int, error) {
 	if atomic.CompareAndSwapInt32(&w.written, 0, 1) {
 		typ, err := api.FormatToMediaType(w.Format)
@@ -256,6 +283,7 @@ func (w *ResponseWriter) Respond(status int, body interface{}) bool {
 }
 
 func (w *ResponseWriter) Error(err error) {
+	w.err = err
 	status, res := errorResponse(err)
 	if status >= 500 {
 		w.Logger.Warn("Error", zap.Int("status", status), zap.Error(err))
@@ -305,10 +333,11 @@ func errorResponse(e error) (status int, ae *api.Error) {
 	}
 
 	switch {
-	case errors.Is(e, branches.ErrExists) || errors.Is(e, pools.ErrExists):
+	case errors.Is(e, branches.ErrExists) || errors.Is(e, pools.ErrExists) || errors.Is(e, tags.ErrExists):
 		ze.Kind = srverr.Conflict
 	case errors.Is(e, branches.ErrNotFound) || errors.Is(e, commits.ErrNotFound) ||
-		errors.Is(e, pools.ErrNotFound) || errors.Is(e, fs.ErrNotExist):
+		errors.Is(e, pools.ErrNotFound) || errors.Is(e, tags.ErrNotFound) || errors.Is(e, grants.ErrNotFound) ||
+		errors.Is(e, apikeys.ErrNotFound) || errors.Is(e, fs.ErrNotExist):
 		ze.Kind = srverr.NotFound
 	}
 
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sync"
+
+	"github.com/brimdata/zed/service/auth"
+	"github.com/brimdata/zed/service/srverr"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// TenantsDir is the directory under the root of a service with tenant
+// isolation that holds the lake of each tenant in a subdirectory named
+// for the tenant ID.
+const TenantsDir = "tenants"
+
+// A tenant ID names a storage directory so it is limited to characters
+// that are safe in a path.
+var tenantIDRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// tenants holds a Core for each tenant, which serves the tenant's requests
+// from the tenant's lake.  Pools, index rules, grants, queries, and events
+// are thus visible only to the tenant.  The Core of a tenant shares the
+// configuration, authenticator, API keys, metrics, and vector cache of its
+// parent.
+type tenants struct {
+	parent *Core
+	// opens ensures the lake of each tenant missing from cores is opened
+	// at most once at a time and without holding mu.
+	opens singleflight.Group
+
+	mu    sync.Mutex
+	cores map[auth.TenantID]*Core
+}
+
+func newTenants(parent *Core) *tenants {
+	return &tenants{
+		parent: parent,
+		cores:  make(map[auth.TenantID]*Core),
+	}
+}
+
+// Middleware passes next the Core of the tenant of the identity of a request.
+func (t *tenants) Middleware(next func(*Core, *ResponseWriter, *Request)) func(*Core, *ResponseWriter, *Request) {
+	return func(_ *Core, w *ResponseWriter, r *Request) {
+		c, err := t.lookup(r.Context(), auth.IdentityFromContext(r.Context()).TenantID)
+		if err != nil {
+			w.Error(err)
+			return
+		}
+		next(c, w, r)
+	}
+}
+
+func (t *tenants) lookup(ctx context.Context, id auth.TenantID) (*Core, error) {
+	if id == "." || id == ".." || !tenantIDRegexp.MatchString(string(id)) {
+		return nil, srverr.ErrForbidden("invalid tenant ID %q", id)
+	}
+	t.mu.Lock()
+	c, ok := t.cores[id]
+	t.mu.Unlock()
+	if ok {
+		return c, nil
+	}
+	// The lake is opened with a context that outlives the request so a
+	// canceled request does not fail the opening shared by other requests.
+	ch := t.opens.DoChan(string(id), func() (interface{}, error) {
+		return t.open(context.WithoutCancel(ctx), id)
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*Core), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// open opens the lake of tenant id and adds its Core to t.
+func (t *tenants) open(ctx context.Context, id auth.TenantID) (*Core, error) {
+	t.mu.Lock()
+	c, ok := t.cores[id]
+	t.mu.Unlock()
+	if ok {
+		// A previous open finished after our caller missed the
+		// tenant in t.cores.
+		return c, nil
+	}
+	parent := t.parent
+	conf := parent.conf
+	conf.Logger = conf.Logger.With(zap.String("tenant_id", string(id)))
+	c = &Core{
+		apikeys:        parent.apikeys,
+		auth:           parent.auth,
+		authz:          parent.authz,
+		conf:           conf,
+		engine:         parent.engine,
+		logger:         parent.logger.With(zap.String("tenant_id", string(id))),
+		registry:       parent.registry,
+		runningQueries: make(map[string]*queryStatus),
+		subscriptions:  make(map[chan event]struct{}),
+		vectorCache:    parent.vectorCache,
+	}
+	if err := c.openLake(ctx, conf.Root.JoinPath(TenantsDir, string(id))); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.cores[id] = c
+	t.mu.Unlock()
+	c.logger.Info("Opened tenant lake")
+	return c, nil
+}
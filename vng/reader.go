@@ -1,27 +1,44 @@
 package vng
 
 import (
+	"context"
 	"fmt"
 	"io"
 
 	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zbuf"
 	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
 )
 
 // Reader implements zio.Reader for a VNG object.
 type Reader struct {
 	Root    *vector.Int64Reader
 	Readers []TypedReader
+	object  *Object
 	builder zcode.Builder
 	val     zed.Value
 }
 
 type TypedReader struct {
-	Type   zed.Type
+	Type zed.Type
+	// Reader is nil for a type whose values are skipped.
 	Reader vector.Reader
+	// skips holds the spans of values of the type that are skipped, and
+	// n is the number of values of the type read or skipped so far.
+	skips []span
+	n     int
 }
 
+type span struct {
+	off int
+	len int
+}
+
+var _ zbuf.ScannerAble = (*Reader)(nil)
+
 // NewReader returns a Reader for o.
 func NewReader(o *Object) (*Reader, error) {
 	root := vector.NewInt64Reader(o.Root, o.ReaderAt)
@@ -36,23 +53,94 @@ func NewReader(o *Object) (*Reader, error) {
 	return &Reader{
 		Root:    root,
 		Readers: readers,
+		object:  o,
 	}, nil
 
 }
 
 func (r *Reader) Read() (*zed.Value, error) {
-	r.builder.Truncate()
-	typeNo, err := r.Root.Read()
-	if err == io.EOF {
-		return nil, nil
+	for {
+		r.builder.Truncate()
+		typeNo, err := r.Root.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if typeNo < 0 || int(typeNo) >= len(r.Readers) {
+			return nil, fmt.Errorf("system error: type number out of range in VNG root metadata: %d out of %d", typeNo, len(r.Readers))
+		}
+		tr := &r.Readers[typeNo]
+		if tr.Reader == nil {
+			continue
+		}
+		k := tr.n
+		tr.n++
+		if len(tr.skips) > 0 && k >= tr.skips[0].off {
+			skip := tr.skips[0]
+			if k == skip.off {
+				if err := vector.Skip(tr.Reader, skip.len); err != nil {
+					return nil, err
+				}
+			}
+			if tr.n == skip.off+skip.len {
+				tr.skips = tr.skips[1:]
+			}
+			continue
+		}
+		if err := tr.Reader.Read(&r.builder); err != nil {
+			return nil, err
+		}
+		r.val = *zed.NewValue(tr.Type, r.builder.Bytes().Body())
+		return &r.val, nil
 	}
-	if typeNo < 0 || int(typeNo) >= len(r.Readers) {
-		return nil, fmt.Errorf("system error: type number out of range in VNG root metadata: %d out of %d", typeNo, len(r.Readers))
+}
+
+func (r *Reader) Close() error {
+	return r.object.Close()
+}
+
+// NewScanner implements zbuf.ScannerAble.  Values whose column statistics,
+// either those of their type or those of the segments holding them, rule
+// out filter are skipped without reading their segments.
+func (r *Reader) NewScanner(ctx context.Context, filter zbuf.Filter) (zbuf.Scanner, error) {
+	if filter != nil {
+		pruner, err := filter.AsPruner()
+		if err != nil {
+			return nil, err
+		}
+		if pruner != nil {
+			r.prune(pruner)
+		}
 	}
-	tr := r.Readers[typeNo]
-	if err := tr.Reader.Read(&r.builder); err != nil {
-		return nil, err
+	// Hide our NewScanner method so zbuf.NewScanner doesn't call it.
+	return zbuf.NewScanner(ctx, struct{ zio.Reader }{r}, filter)
+}
+
+func (r *Reader) prune(pruner expr.Evaluator) {
+	var ectx expr.ResetContext
+	for k := range r.Readers {
+		stats := r.object.Stats(k)
+		if stats == nil {
+			continue
+		}
+		if isTrue(pruner.Eval(ectx.Reset(), stats)) {
+			r.Readers[k].Reader = nil
+			continue
+		}
+		var skips []span
+		for _, s := range r.object.SegmentStats(k) {
+			if !isTrue(pruner.Eval(ectx.Reset(), s.Stats)) {
+				continue
+			}
+			if n := len(skips); n > 0 && skips[n-1].off+skips[n-1].len == s.Off {
+				skips[n-1].len += s.Len
+			} else {
+				skips = append(skips, span{s.Off, s.Len})
+			}
+		}
+		r.Readers[k].skips = skips
 	}
-	r.val = *zed.NewValue(tr.Type, r.builder.Bytes().Body())
-	return &r.val, nil
+}
+
+func isTrue(val *zed.Value) bool {
+	return val.Type == zed.TypeBool && val.Bool()
 }
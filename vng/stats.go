@@ -0,0 +1,240 @@
+package vng
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/order"
+	"github.com/brimdata/zed/runtime/expr"
+	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Stats returns a value describing the statistics of the values of type
+// typeNo.  The value mirrors the structure of the type with each primitive
+// column replaced by a record of type
+//
+//	{min:T,max:T,count:uint32,nulls:uint32,distinct:uint32}
+//
+// where T is the type of the column, holding the statistics of the column's
+// segments combined.  (distinct is the largest per-segment estimate and so
+// is a lower bound on the number of distinct values in the column.)  Columns
+// without statistics, like those inside arrays, maps, and unions or those of
+// objects written before statistics were recorded, are omitted.  Stats
+// returns nil if no column of the type has statistics.
+func (o *Object) Stats(typeNo int) *zed.Value {
+	typ, bytes, ok := columnStats(o.Zctx, o.Maps[typeNo])
+	if !ok {
+		return nil
+	}
+	return zed.NewValue(typ, bytes)
+}
+
+func columnStats(zctx *zed.Context, meta vector.Metadata) (zed.Type, zcode.Bytes, bool) {
+	switch meta := vector.Under(meta).(type) {
+	case *vector.Record:
+		var fields []zed.Field
+		var b zcode.Builder
+		for _, f := range meta.Fields {
+			if typ, bytes, ok := columnStats(zctx, f.Values); ok {
+				fields = append(fields, zed.NewField(f.Name, typ))
+				b.Append(bytes)
+			}
+		}
+		if len(fields) == 0 {
+			return nil, nil, false
+		}
+		typ, err := zctx.LookupTypeRecord(fields)
+		if err != nil {
+			return nil, nil, false
+		}
+		return typ, b.Bytes(), true
+	case *vector.Primitive:
+		if len(meta.Stats) != len(meta.Segmap) {
+			return nil, nil, false
+		}
+		cmp := expr.NewValueCompareFn(order.Asc, false)
+		var min, max *zed.Value
+		var count, nulls, distinct uint32
+		for k, s := range meta.Stats {
+			if s.Min != nil && (min == nil || cmp(s.Min, min) < 0) {
+				min = s.Min
+			}
+			if s.Max != nil && (max == nil || cmp(s.Max, max) > 0) {
+				max = s.Max
+			}
+			count += meta.Segmap[k].Count
+			nulls += s.Nulls
+			if s.Distinct > distinct {
+				distinct = s.Distinct
+			}
+		}
+		return statsRecord(zctx, meta.Typ, min, max, count, nulls, distinct)
+	case *vector.Const:
+		var nulls, distinct uint32
+		if meta.Value.IsNull() {
+			nulls = meta.Count
+		} else {
+			distinct = 1
+		}
+		return statsRecord(zctx, meta.Value.Type, meta.Value, meta.Value, meta.Count, nulls, distinct)
+	}
+	return nil, nil, false
+}
+
+func statsRecord(zctx *zed.Context, typ zed.Type, min, max *zed.Value, count, nulls, distinct uint32) (zed.Type, zcode.Bytes, bool) {
+	recType, err := zctx.LookupTypeRecord([]zed.Field{
+		zed.NewField("min", typ),
+		zed.NewField("max", typ),
+		zed.NewField("count", zed.TypeUint32),
+		zed.NewField("nulls", zed.TypeUint32),
+		zed.NewField("distinct", zed.TypeUint32),
+	})
+	if err != nil {
+		return nil, nil, false
+	}
+	var b zcode.Builder
+	b.Append(bytesOf(min))
+	b.Append(bytesOf(max))
+	b.Append(zed.EncodeUint(uint64(count)))
+	b.Append(zed.EncodeUint(uint64(nulls)))
+	b.Append(zed.EncodeUint(uint64(distinct)))
+	return recType, b.Bytes(), true
+}
+
+// bytesOf returns the bytes of val or nil (i.e., null) if val is nil.
+func bytesOf(val *zed.Value) zcode.Bytes {
+	if val == nil {
+		return nil
+	}
+	return val.Bytes()
+}
+
+// SpanStats holds the statistics of a span of the values of a type.
+type SpanStats struct {
+	// Off and Len give the span as a range of indexes of the values of
+	// the type.
+	Off int
+	Len int
+	// Stats has the structure described for Object.Stats and holds the
+	// statistics of the column segments covering the span.
+	Stats *zed.Value
+}
+
+// SegmentStats partitions the values of type typeNo into spans at the
+// segment boundaries of its primitive columns and returns the statistics of
+// each span in order.  Unlike Stats, SegmentStats omits columns whose
+// segments cannot be mapped to values of the type, like those inside
+// records that may be null, and spans covered by no column statistics.
+func (o *Object) SegmentStats(typeNo int) []SpanStats {
+	meta := o.Maps[typeNo]
+	offs := make(map[*vector.Primitive][]int)
+	segmentOffsets(meta, offs)
+	var bounds []int
+	for _, off := range offs {
+		bounds = append(bounds, off...)
+	}
+	slices.Sort(bounds)
+	bounds = slices.Compact(bounds)
+	var spans []SpanStats
+	for k := 0; k+1 < len(bounds); k++ {
+		off, end := bounds[k], bounds[k+1]
+		if typ, bytes, ok := spanStats(o.Zctx, meta, offs, off, end); ok {
+			spans = append(spans, SpanStats{
+				Off:   off,
+				Len:   end - off,
+				Stats: zed.NewValue(typ, bytes),
+			})
+		}
+	}
+	return spans
+}
+
+// segmentOffsets stores in offs the offsets of the segment boundaries of
+// each primitive column under meta with a value for each value of meta.
+func segmentOffsets(meta vector.Metadata, offs map[*vector.Primitive][]int) {
+	switch meta := underNamed(meta).(type) {
+	case *vector.Record:
+		for _, f := range meta.Fields {
+			segmentOffsets(f.Values, offs)
+		}
+	case *vector.Nulls:
+		// Only the segments of a primitive column can be mapped to
+		// values here since the nulls of the column are counted in
+		// the statistics of its segments.
+		if p, ok := underNamed(meta.Values).(*vector.Primitive); ok {
+			addSegmentOffsets(p, true, offs)
+		}
+	case *vector.Primitive:
+		addSegmentOffsets(meta, false, offs)
+	}
+}
+
+func addSegmentOffsets(p *vector.Primitive, nulls bool, offs map[*vector.Primitive][]int) {
+	if len(p.Stats) != len(p.Segmap) {
+		return
+	}
+	off := 0
+	bounds := []int{off}
+	for k, s := range p.Segmap {
+		off += int(s.Count)
+		if nulls {
+			off += int(p.Stats[k].Nulls)
+		}
+		bounds = append(bounds, off)
+	}
+	offs[p] = bounds
+}
+
+// spanStats is like columnStats but for the values from off to end, which
+// lie within a single segment of each column in offs.
+func spanStats(zctx *zed.Context, meta vector.Metadata, offs map[*vector.Primitive][]int, off, end int) (zed.Type, zcode.Bytes, bool) {
+	switch meta := underNamed(meta).(type) {
+	case *vector.Record:
+		var fields []zed.Field
+		var b zcode.Builder
+		for _, f := range meta.Fields {
+			if typ, bytes, ok := spanStats(zctx, f.Values, offs, off, end); ok {
+				fields = append(fields, zed.NewField(f.Name, typ))
+				b.Append(bytes)
+			}
+		}
+		if len(fields) == 0 {
+			return nil, nil, false
+		}
+		typ, err := zctx.LookupTypeRecord(fields)
+		if err != nil {
+			return nil, nil, false
+		}
+		return typ, b.Bytes(), true
+	case *vector.Nulls:
+		if p, ok := underNamed(meta.Values).(*vector.Primitive); ok {
+			return spanStats(zctx, p, offs, off, end)
+		}
+	case *vector.Primitive:
+		bounds, ok := offs[meta]
+		if !ok {
+			return nil, nil, false
+		}
+		k := sort.SearchInts(bounds, off+1) - 1
+		if k >= len(meta.Segmap) || end > bounds[k+1] {
+			return nil, nil, false
+		}
+		s := meta.Stats[k]
+		return statsRecord(zctx, meta.Typ, s.Min, s.Max, meta.Segmap[k].Count, s.Nulls, s.Distinct)
+	case *vector.Const:
+		return columnStats(zctx, meta)
+	}
+	return nil, nil, false
+}
+
+func underNamed(meta vector.Metadata) vector.Metadata {
+	for {
+		named, ok := meta.(*vector.Named)
+		if !ok {
+			return meta
+		}
+		meta = named.Values
+	}
+}
@@ -0,0 +1,280 @@
+package vector
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Values for [Segment.Encoding].
+const (
+	EncodingNone         uint8 = 0 // Sequence of ZNG-encoded values
+	EncodingRLE          uint8 = 1 // Run-length encoding
+	EncodingDelta        uint8 = 2 // Delta encoding
+	EncodingDeltaOfDelta uint8 = 3 // Delta-of-delta encoding
+	EncodingBitPack      uint8 = 4 // Frame-of-reference bit packing
+)
+
+// EncodingName returns the name of encoding e.
+func EncodingName(e uint8) string {
+	switch e {
+	case EncodingNone:
+		return "none"
+	case EncodingRLE:
+		return "rle"
+	case EncodingDelta:
+		return "delta"
+	case EncodingDeltaOfDelta:
+		return "delta-of-delta"
+	case EncodingBitPack:
+		return "bitpack"
+	}
+	return "unknown"
+}
+
+var errBadEncoding = errors.New("corrupt VNG: bad segment encoding")
+
+// An encoded segment begins with a byte that is one if the values are
+// signed and zero otherwise, which is followed by the values as int64s laid
+// out according to the encoding:
+//
+//	EncodingRLE: a sequence of runs, each a uvarint count and a varint value
+//	EncodingDelta: a varint first value and a varint difference from the
+//	    previous value for each remaining value
+//	EncodingDeltaOfDelta: a varint first value, a varint first difference,
+//	    and a varint difference from the previous difference for each
+//	    remaining value
+//	EncodingBitPack: a varint minimum value, a byte bit width w, and the
+//	    difference of each value from the minimum in w bits, packed from the
+//	    least significant bit of each byte
+
+// canEncode returns true if the values of typ may be stored in an encoded
+// segment.
+func canEncode(typ zed.Type) bool {
+	switch typ.ID() {
+	case zed.IDUint8, zed.IDUint16, zed.IDUint32, zed.IDUint64,
+		zed.IDInt8, zed.IDInt16, zed.IDInt32, zed.IDInt64,
+		zed.IDDuration, zed.IDTime:
+		return true
+	}
+	return false
+}
+
+// encodeSegment returns the most compact encoding of the ZNG-encoded values
+// in b, whose type must satisfy canEncode, along with the encoded bytes.  It
+// returns EncodingNone if no encoding is smaller than b or if b contains a
+// null.
+func encodeSegment(typ zed.Type, b zcode.Bytes) (uint8, []byte) {
+	signed := zed.IsSigned(typ.ID())
+	var vals []int64
+	for it := b.Iter(); !it.Done(); {
+		bytes := it.Next()
+		if bytes == nil {
+			return EncodingNone, nil
+		}
+		if signed {
+			vals = append(vals, zed.DecodeInt(bytes))
+		} else {
+			vals = append(vals, int64(zed.DecodeUint(bytes)))
+		}
+	}
+	if len(vals) == 0 {
+		return EncodingNone, nil
+	}
+	header := byte(0)
+	if signed {
+		header = 1
+	}
+	encoding, out := EncodingNone, []byte(nil)
+	for _, e := range []uint8{EncodingRLE, EncodingDelta, EncodingDeltaOfDelta, EncodingBitPack} {
+		var candidate []byte
+		switch e {
+		case EncodingRLE:
+			candidate = encodeRLE([]byte{header}, vals)
+		case EncodingDelta:
+			candidate = encodeDelta([]byte{header}, vals)
+		case EncodingDeltaOfDelta:
+			candidate = encodeDeltaOfDelta([]byte{header}, vals)
+		case EncodingBitPack:
+			candidate = encodeBitPack([]byte{header}, vals, signed)
+		}
+		if len(candidate) < len(b) && (out == nil || len(candidate) < len(out)) {
+			encoding, out = e, candidate
+		}
+	}
+	return encoding, out
+}
+
+func encodeRLE(out []byte, vals []int64) []byte {
+	for len(vals) > 0 {
+		n := 1
+		for n < len(vals) && vals[n] == vals[0] {
+			n++
+		}
+		out = binary.AppendUvarint(out, uint64(n))
+		out = binary.AppendVarint(out, vals[0])
+		vals = vals[n:]
+	}
+	return out
+}
+
+func encodeDelta(out []byte, vals []int64) []byte {
+	out = binary.AppendVarint(out, vals[0])
+	for k := 1; k < len(vals); k++ {
+		out = binary.AppendVarint(out, vals[k]-vals[k-1])
+	}
+	return out
+}
+
+func encodeDeltaOfDelta(out []byte, vals []int64) []byte {
+	out = binary.AppendVarint(out, vals[0])
+	var prev int64
+	for k := 1; k < len(vals); k++ {
+		delta := vals[k] - vals[k-1]
+		out = binary.AppendVarint(out, delta-prev)
+		prev = delta
+	}
+	return out
+}
+
+func encodeBitPack(out []byte, vals []int64, signed bool) []byte {
+	lo, hi := vals[0], vals[0]
+	for _, v := range vals[1:] {
+		if signed && v < lo || !signed && uint64(v) < uint64(lo) {
+			lo = v
+		}
+		if signed && v > hi || !signed && uint64(v) > uint64(hi) {
+			hi = v
+		}
+	}
+	width := bits.Len64(uint64(hi - lo))
+	out = binary.AppendVarint(out, lo)
+	out = append(out, byte(width))
+	packed := make([]byte, (len(vals)*width+7)/8)
+	var off int
+	for _, v := range vals {
+		u := uint64(v - lo)
+		for k := 0; k < width; {
+			// Put as many bits of u as fit in the current byte.
+			shift := off % 8
+			take := min(width-k, 8-shift)
+			packed[off/8] |= byte((u>>k)&mask(take)) << shift
+			k += take
+			off += take
+		}
+	}
+	return append(out, packed...)
+}
+
+// decodeSegment appends to dst the count ZNG-encoded values in the segment
+// src, which is encoded with encoding, and returns the extended buffer.
+func decodeSegment(dst []byte, encoding uint8, src []byte, count uint32) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, errBadEncoding
+	}
+	signed := src[0] == 1
+	src = src[1:]
+	appendVal := func(dst []byte, v int64) []byte {
+		if signed {
+			return zcode.Append(dst, zed.EncodeInt(v))
+		}
+		return zcode.Append(dst, zed.EncodeUint(uint64(v)))
+	}
+	r := &varintReader{src: src}
+	switch encoding {
+	case EncodingRLE:
+		for n := uint32(0); n < count; {
+			run := r.uvarint()
+			v := r.varint()
+			if r.err != nil || run == 0 || run > uint64(count-n) {
+				return nil, errBadEncoding
+			}
+			for k := uint64(0); k < run; k++ {
+				dst = appendVal(dst, v)
+			}
+			n += uint32(run)
+		}
+	case EncodingDelta:
+		var v int64
+		for n := uint32(0); n < count; n++ {
+			v += r.varint()
+			dst = appendVal(dst, v)
+		}
+	case EncodingDeltaOfDelta:
+		var v, delta int64
+		for n := uint32(0); n < count; n++ {
+			if n == 0 {
+				v = r.varint()
+			} else {
+				delta += r.varint()
+				v += delta
+			}
+			dst = appendVal(dst, v)
+		}
+	case EncodingBitPack:
+		lo := r.varint()
+		if r.err != nil || len(r.src) == 0 || r.src[0] > 64 {
+			return nil, errBadEncoding
+		}
+		width := int(r.src[0])
+		packed := r.src[1:]
+		if uint64(len(packed))*8 < uint64(count)*uint64(width) {
+			return nil, errBadEncoding
+		}
+		var off int
+		for n := uint32(0); n < count; n++ {
+			var u uint64
+			for k := 0; k < width; {
+				// Take as many bits of u as remain in the current byte.
+				shift := off % 8
+				take := min(width-k, 8-shift)
+				u |= (uint64(packed[off/8]>>shift) & mask(take)) << k
+				k += take
+				off += take
+			}
+			dst = appendVal(dst, lo+int64(u))
+		}
+		return dst, nil
+	default:
+		return nil, errBadEncoding
+	}
+	if r.err != nil {
+		return nil, errBadEncoding
+	}
+	return dst, nil
+}
+
+type varintReader struct {
+	src []byte
+	err error
+}
+
+func (v *varintReader) varint() int64 {
+	x, n := binary.Varint(v.src)
+	if n <= 0 {
+		v.err = errBadEncoding
+		return 0
+	}
+	v.src = v.src[n:]
+	return x
+}
+
+func (v *varintReader) uvarint() uint64 {
+	x, n := binary.Uvarint(v.src)
+	if n <= 0 {
+		v.err = errBadEncoding
+		return 0
+	}
+	v.src = v.src[n:]
+	return x
+}
+
+func mask(n int) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<n - 1
+}
@@ -94,6 +94,27 @@ type Reader interface {
 	Read(*zcode.Builder) error
 }
 
+// Skipper is implemented by a Reader that can discard values without
+// reading the segments that hold them.
+type Skipper interface {
+	Skip(n int) error
+}
+
+// Skip discards the next n values of r.
+func Skip(r Reader, n int) error {
+	if s, ok := r.(Skipper); ok {
+		return s.Skip(n)
+	}
+	var b zcode.Builder
+	for ; n > 0; n-- {
+		b.Truncate()
+		if err := r.Read(&b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func NewReader(meta Metadata, r io.ReaderAt) (Reader, error) {
 	switch meta := meta.(type) {
 	case nil:
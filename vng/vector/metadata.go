@@ -131,12 +131,27 @@ type Primitive struct {
 	Max    *zed.Value
 	Count  uint32
 	Nulls  uint32
+	Stats  []SegmentStats
 }
 
 func (p *Primitive) Type(zctx *zed.Context) zed.Type {
 	return p.Typ
 }
 
+// SegmentStats describes the values in a segment of a primitive vector.  Min
+// and Max are the least and greatest non-null values (or nil if there are
+// none), Nulls is the number of nulls, and Distinct is an estimate of the
+// number of distinct non-null values.  Nulls encoded by an enclosing Nulls
+// vector are counted in the segment of the values written around them.
+// Primitive.Stats holds the statistics of each segment of Primitive.Segmap
+// and is empty for objects written before statistics were recorded.
+type SegmentStats struct {
+	Min      *zed.Value
+	Max      *zed.Value
+	Nulls    uint32
+	Distinct uint32
+}
+
 type Nulls struct {
 	Runs   []Segment
 	Values Metadata
@@ -30,6 +30,9 @@ func (n *NullsWriter) Write(body zcode.Bytes) error {
 		return n.values.Write(body)
 	}
 	n.touchNull()
+	if p, ok := n.values.(*PrimitiveWriter); ok {
+		p.AddNull()
+	}
 	return nil
 }
 
@@ -112,3 +115,25 @@ func (n *NullsReader) Read(b *zcode.Builder) error {
 	}
 	return n.Values.Read(b)
 }
+
+// Skip discards the next count values of n.
+func (n *NullsReader) Skip(count int) error {
+	var values int
+	for count > 0 {
+		for n.run == 0 {
+			n.null = !n.null
+			v, err := n.Runs.Read()
+			if err != nil {
+				return err
+			}
+			n.run = int(v)
+		}
+		k := min(count, n.run)
+		if !n.null {
+			values += k
+		}
+		n.run -= k
+		count -= k
+	}
+	return Skip(n.Values, values)
+}
@@ -6,6 +6,7 @@ import (
 	"slices"
 	"sort"
 
+	"github.com/axiomhq/hyperloglog"
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/order"
 	"github.com/brimdata/zed/runtime/expr"
@@ -27,6 +28,13 @@ type PrimitiveWriter struct {
 	countWritten uint32
 	nulls        uint32
 	hasNull      int
+	stats        []SegmentStats
+	// segMin, segMax, segNulls, and segSketch accumulate the statistics of
+	// the values written since the last segment was flushed.
+	segMin    *zed.Value
+	segMax    *zed.Value
+	segNulls  uint32
+	segSketch *hyperloglog.Sketch
 }
 
 func NewPrimitiveWriter(typ zed.Type, spiller *Spiller, useDict bool) *PrimitiveWriter {
@@ -35,10 +43,11 @@ func NewPrimitiveWriter(typ zed.Type, spiller *Spiller, useDict bool) *Primitive
 		dict = make(map[string]uint32)
 	}
 	return &PrimitiveWriter{
-		typ:     typ,
-		spiller: spiller,
-		dict:    dict,
-		cmp:     expr.NewValueCompareFn(order.Asc, false),
+		typ:       typ,
+		spiller:   spiller,
+		dict:      dict,
+		cmp:       expr.NewValueCompareFn(order.Asc, false),
+		segSketch: hyperloglog.New(),
 	}
 }
 
@@ -52,6 +61,7 @@ func (p *PrimitiveWriter) update(body zcode.Bytes) {
 	p.count++
 	if body == nil {
 		p.nulls++
+		p.segNulls++
 		p.hasNull = 1
 		return
 	}
@@ -62,6 +72,13 @@ func (p *PrimitiveWriter) update(body zcode.Bytes) {
 	if p.max == nil || p.cmp(val, p.max) > 0 {
 		p.max = val.Copy()
 	}
+	if p.segMin == nil || p.cmp(val, p.segMin) < 0 {
+		p.segMin = val.Copy()
+	}
+	if p.segMax == nil || p.cmp(val, p.segMax) > 0 {
+		p.segMax = val.Copy()
+	}
+	p.segSketch.Insert(body)
 	if p.dict != nil {
 		p.dict[string(body)]++
 		if len(p.dict)+p.hasNull > MaxDictSize {
@@ -70,15 +87,36 @@ func (p *PrimitiveWriter) update(body zcode.Bytes) {
 	}
 }
 
+// AddNull counts a null in the statistics of the current segment.  It is
+// called by a NullsWriter for each null it encodes in place of a value of
+// the vector.
+func (p *PrimitiveWriter) AddNull() {
+	p.segNulls++
+}
+
 func (p *PrimitiveWriter) Flush(eof bool) error {
 	if p.dict != nil {
 		p.bytes = p.makeDictVector()
 	}
 	var err error
 	if len(p.bytes) > 0 {
-		p.segments, err = p.spiller.Write(p.segments, p.bytes, p.count-p.countWritten)
+		encoding, b := EncodingNone, []byte(p.bytes)
+		if p.dict == nil && canEncode(p.typ) {
+			if e, eb := encodeSegment(p.typ, p.bytes); e != EncodingNone {
+				encoding, b = e, eb
+			}
+		}
+		p.segments, err = p.spiller.WriteEncoded(p.segments, b, len(p.bytes), p.count-p.countWritten, encoding)
 		p.bytes = p.bytes[:0]
 		p.countWritten = p.count
+		p.stats = append(p.stats, SegmentStats{
+			Min:      p.segMin,
+			Max:      p.segMax,
+			Nulls:    p.segNulls,
+			Distinct: uint32(p.segSketch.Estimate()),
+		})
+		p.segMin, p.segMax, p.segNulls = nil, nil, 0
+		p.segSketch = hyperloglog.New()
 	}
 	return err
 }
@@ -146,6 +184,7 @@ func (p *PrimitiveWriter) Metadata() Metadata {
 		Nulls:  p.nulls,
 		Min:    p.min,
 		Max:    p.max,
+		Stats:  p.stats,
 	}
 }
 
@@ -211,6 +250,30 @@ func (p *PrimitiveReader) ReadBytes() (zcode.Bytes, error) {
 	return p.it.Next(), nil
 }
 
+// Skip discards the next n values of p, reading only the segment that holds
+// the first value not discarded.
+func (p *PrimitiveReader) Skip(n int) error {
+	for n > 0 {
+		if p.it != nil && !p.it.Done() {
+			p.it.Next()
+			n--
+			continue
+		}
+		if len(p.segmap) == 0 {
+			return io.EOF
+		}
+		if count := int(p.segmap[0].Count); count <= n {
+			p.segmap = p.segmap[1:]
+			n -= count
+			continue
+		}
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *PrimitiveReader) next() error {
 	segment := p.segmap[0]
 	p.segmap = p.segmap[1:]
@@ -270,6 +333,31 @@ func (d *DictReader) ReadBytes() (zcode.Bytes, error) {
 	return d.dict[sel].Value.Bytes(), nil
 }
 
+// Skip discards the next n values of d, reading only the segment that holds
+// the first value not discarded.
+func (d *DictReader) Skip(n int) error {
+	for n > 0 {
+		if d.off < len(d.selectors) {
+			k := min(n, len(d.selectors)-d.off)
+			d.off += k
+			n -= k
+			continue
+		}
+		if len(d.segmap) == 0 {
+			return io.EOF
+		}
+		if count := int(d.segmap[0].Count); count <= n {
+			d.segmap = d.segmap[1:]
+			n -= count
+			continue
+		}
+		if err := d.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (d *DictReader) next() error {
 	segment := d.segmap[0]
 	d.segmap = d.segmap[1:]
@@ -303,3 +391,12 @@ func (c *ConstReader) Read(b *zcode.Builder) error {
 	b.Append(c.bytes)
 	return nil
 }
+
+func (c *ConstReader) Skip(n int) error {
+	if uint32(n) > c.cnt {
+		c.cnt = 0
+		return io.EOF
+	}
+	c.cnt -= uint32(n)
+	return nil
+}
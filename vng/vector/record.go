@@ -93,3 +93,13 @@ func (r *RecordReader) Read(b *zcode.Builder) error {
 	b.EndContainer()
 	return nil
 }
+
+// Skip discards the next n values of r.
+func (r *RecordReader) Skip(n int) error {
+	for _, f := range r.Values {
+		if err := Skip(f.Values, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
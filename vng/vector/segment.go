@@ -6,6 +6,7 @@ import (
 	"slices"
 	"sync"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
 )
 
@@ -13,6 +14,7 @@ import (
 const (
 	CompressionFormatNone uint8 = 0 // No compression
 	CompressionFormatLZ4  uint8 = 1 // LZ4 compression
+	CompressionFormatZstd uint8 = 2 // Zstandard compression
 )
 
 type Segment struct {
@@ -21,40 +23,84 @@ type Segment struct {
 	MemLength         int32  // Length in memory
 	CompressionFormat uint8  // Compression format in file
 	Count             uint32 // Number of values encoded in segment
+	Encoding          uint8  // Encoding of values in segment
 }
 
 var zbufPool = sync.Pool{
 	New: func() any { return new([]byte) },
 }
 
-// Read reads the segement r, uncompresses it if necessary, and stores it in the
-// first s.MemLength bytes of b. If the length of b is less than s.MemLength,
-// Read returns [io.ErrShortBuffer].
+// zstdDecoder is shared by all segments since DecodeAll is safe for
+// concurrent use.  DecodeAll fails rather than grow its output beyond the
+// capacity of the buffer it is given.
+var zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecodeAllCapLimit(true))
+
+// Read reads the segement r, uncompresses it if necessary, decodes it if
+// necessary, and stores it in the first s.MemLength bytes of b. If the length
+// of b is less than s.MemLength, Read returns [io.ErrShortBuffer].
 func (s *Segment) Read(r io.ReaderAt, b []byte) error {
 	if len(b) < int(s.MemLength) {
 		return io.ErrShortBuffer
 	}
 	b = b[:s.MemLength]
+	if s.Encoding == EncodingNone {
+		n, err := s.read(r, b)
+		if err != nil {
+			return err
+		}
+		if n != int(s.MemLength) {
+			return fmt.Errorf("vng: got %d uncompressed bytes, expected %d", n, s.MemLength)
+		}
+		return nil
+	}
+	// An encoded segment is never larger than its decoding.
+	ebuf := zbufPool.Get().(*[]byte)
+	defer zbufPool.Put(ebuf)
+	*ebuf = slices.Grow((*ebuf)[:0], int(s.MemLength))[:s.MemLength]
+	n, err := s.read(r, *ebuf)
+	if err != nil {
+		return err
+	}
+	// decodeSegment appends to b[:0] in place unless the output is larger
+	// than expected.
+	out, err := decodeSegment(b[:0], s.Encoding, (*ebuf)[:n], s.Count)
+	if err != nil {
+		return err
+	}
+	if len(out) != int(s.MemLength) {
+		return fmt.Errorf("vng: got %d decoded bytes, expected %d", len(out), s.MemLength)
+	}
+	return nil
+}
+
+// read reads the segment r, uncompresses it if necessary, stores it in b,
+// and returns its uncompressed length.
+func (s *Segment) read(r io.ReaderAt, b []byte) (int, error) {
 	switch s.CompressionFormat {
 	case CompressionFormatNone:
-		_, err := r.ReadAt(b, s.Offset)
-		return err
-	case CompressionFormatLZ4:
+		if len(b) < int(s.Length) {
+			return 0, io.ErrShortBuffer
+		}
+		_, err := r.ReadAt(b[:s.Length], s.Offset)
+		return int(s.Length), err
+	case CompressionFormatLZ4, CompressionFormatZstd:
 		zbuf := zbufPool.Get().(*[]byte)
 		defer zbufPool.Put(zbuf)
 		*zbuf = slices.Grow((*zbuf)[:0], int(s.Length))[:s.Length]
 		if _, err := r.ReadAt(*zbuf, s.Offset); err != nil {
-			return err
+			return 0, err
 		}
-		n, err := lz4.UncompressBlock(*zbuf, b)
-		if err != nil {
-			return err
+		if s.CompressionFormat == CompressionFormatLZ4 {
+			return lz4.UncompressBlock(*zbuf, b)
 		}
-		if n != int(s.MemLength) {
-			return fmt.Errorf("vng: got %d uncompressed bytes, expected %d", n, s.MemLength)
+		// DecodeAll appends to b[:0], whose capacity limits the
+		// output to the length of b.
+		out, err := zstdDecoder.DecodeAll(*zbuf, b[:0:len(b)])
+		if err != nil {
+			return 0, err
 		}
-		return nil
+		return len(out), nil
 	default:
-		return fmt.Errorf("vng: unknown compression format 0x%x", s.CompressionFormat)
+		return 0, fmt.Errorf("vng: unknown compression format 0x%x", s.CompressionFormat)
 	}
 }
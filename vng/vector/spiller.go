@@ -1,9 +1,11 @@
 package vector
 
 import (
+	"fmt"
 	"io"
 	"slices"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
 )
 
@@ -12,40 +14,83 @@ type Spiller struct {
 	Thresh int
 
 	buf        []byte
+	format     uint8
 	compressor lz4.Compressor
+	encoder    *zstd.Encoder
 	off        int64
 }
 
+// NewSpiller returns a Spiller that writes segments to w compressed with
+// LZ4.
 func NewSpiller(w io.Writer, thresh int) *Spiller {
 	return &Spiller{
 		writer: w,
 		Thresh: thresh,
+		format: CompressionFormatLZ4,
 	}
 }
 
+// NewSpillerWithCompression returns a Spiller that writes segments to w
+// compressed with format, which must be CompressionFormatLZ4 or
+// CompressionFormatZstd.  For CompressionFormatZstd, level is the zstd
+// compression level, with zero meaning the default level.
+func NewSpillerWithCompression(w io.Writer, thresh int, format uint8, level int) (*Spiller, error) {
+	s := NewSpiller(w, thresh)
+	switch format {
+	case CompressionFormatLZ4:
+	case CompressionFormatZstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		var err error
+		if s.encoder, err = zstd.NewWriter(nil, opts...); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("vng: unknown compression format 0x%x", format)
+	}
+	s.format = format
+	return s, nil
+}
+
 func (s *Spiller) Position() int64 {
 	return s.off
 }
 
 func (s *Spiller) Write(segments []Segment, b []byte, count uint32) ([]Segment, error) {
+	return s.WriteEncoded(segments, b, len(b), count, EncodingNone)
+}
+
+// WriteEncoded is like Write for a segment whose values are encoded with
+// encoding and whose decoding is memLength bytes long.
+func (s *Spiller) WriteEncoded(segments []Segment, b []byte, memLength int, count uint32, encoding uint8) ([]Segment, error) {
 	cf := CompressionFormatNone
 	contentLen := len(b)
-	// Use contentLen-1 so compression will fail if it doesn't result in
-	// fewer bytes.
-	s.buf = slices.Grow(s.buf[:0], contentLen-1)[:contentLen-1]
-	zlen, err := s.compressor.CompressBlock(b, s.buf)
-	if err != nil && err != lz4.ErrInvalidSourceShortBuffer {
-		return nil, err
-	}
-	if zlen > 0 {
-		// Compression succeeded.
-		b = s.buf[:zlen]
-		cf = CompressionFormatLZ4
+	if s.format == CompressionFormatZstd {
+		s.buf = s.encoder.EncodeAll(b, s.buf[:0])
+		if len(s.buf) < contentLen {
+			b = s.buf
+			cf = CompressionFormatZstd
+		}
+	} else {
+		// Use contentLen-1 so compression will fail if it doesn't
+		// result in fewer bytes.
+		s.buf = slices.Grow(s.buf[:0], contentLen-1)[:contentLen-1]
+		zlen, err := s.compressor.CompressBlock(b, s.buf)
+		if err != nil && err != lz4.ErrInvalidSourceShortBuffer {
+			return nil, err
+		}
+		if zlen > 0 {
+			// Compression succeeded.
+			b = s.buf[:zlen]
+			cf = CompressionFormatLZ4
+		}
 	}
 	if _, err := s.writer.Write(b); err != nil {
 		return nil, err
 	}
-	segment := Segment{s.off, int32(len(b)), int32(contentLen), cf, count}
+	segment := Segment{s.off, int32(len(b)), int32(memLength), cf, count, encoding}
 	s.off += int64(len(b))
 	return append(segments, segment), nil
 }
@@ -2,15 +2,28 @@ package vng_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/compiler/optimizer/demand"
 	"github.com/brimdata/zed/fuzz"
+	"github.com/brimdata/zed/pkg/nano"
 	"github.com/brimdata/zed/pkg/units"
+	"github.com/brimdata/zed/runtime/expr"
 	"github.com/brimdata/zed/vng"
+	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/vngio"
+	"github.com/brimdata/zed/zio/zsonio"
+	"github.com/brimdata/zed/zson"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 )
 
@@ -62,3 +75,191 @@ func roundtrip(t *testing.T, valuesIn []zed.Value, writerOpts vngio.WriterOpts)
 	require.NoError(t, err)
 	fuzz.CompareValues(t, valuesIn, valuesOut)
 }
+
+func TestStats(t *testing.T) {
+	const input = `
+{x:3,y:"b",z:[1,2]}
+{x:1,y:null(string),z:[3]}
+{x:2,y:"a",z:null([int64])}
+`
+	var buf bytes.Buffer
+	w, err := vngio.NewWriter(zio.NopCloser(&buf))
+	require.NoError(t, err)
+	require.NoError(t, zio.Copy(w, zsonio.NewReader(zed.NewContext(), strings.NewReader(input))))
+	require.NoError(t, w.Close())
+	o, err := vng.NewObject(zed.NewContext(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	expected := `{x:{min:1,max:3,count:3(uint32),nulls:0(uint32),distinct:3(uint32)},y:{min:"a",max:"b",count:2(uint32),nulls:1(uint32),distinct:2(uint32)}}`
+	require.Equal(t, expected, zson.FormatValue(o.Stats(0)))
+}
+
+// segmentedObject returns an object holding the values of type {x:int64,y:int64}
+// from x=0 to x=n-1 with y null for every tenth value and with each column
+// flushed into a segment every thresh values.  (The VNG writer does not yet
+// split columns into segments.)
+func segmentedObject(t *testing.T, n, thresh int) *vng.Object {
+	zctx := zed.NewContext()
+	typ, err := zctx.LookupTypeRecord([]zed.Field{
+		zed.NewField("x", zed.TypeInt64),
+		zed.NewField("y", zed.TypeInt64),
+	})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	spiller := vector.NewSpiller(&buf, vng.MaxSegmentThresh)
+	root := vector.NewInt64Writer(spiller)
+	w := vector.NewWriter(typ, spiller)
+	for k := 0; k < n; k++ {
+		var b zcode.Builder
+		b.Append(zed.EncodeInt(int64(k)))
+		if k%10 == 0 {
+			b.Append(nil)
+		} else {
+			b.Append(zed.EncodeInt(int64(k)))
+		}
+		require.NoError(t, root.Write(0))
+		require.NoError(t, w.Write(b.Bytes()))
+		if (k+1)%thresh == 0 {
+			require.NoError(t, w.Flush(false))
+		}
+	}
+	require.NoError(t, w.Flush(true))
+	require.NoError(t, root.Flush(true))
+	return &vng.Object{
+		ReaderAt: bytes.NewReader(buf.Bytes()),
+		Zctx:     zctx,
+		Root:     root.Segmap(),
+		Maps:     []vector.Metadata{w.Metadata()},
+	}
+}
+
+func TestSegmentStats(t *testing.T) {
+	o := segmentedObject(t, 900, 300)
+	spans := o.SegmentStats(0)
+	require.Len(t, spans, 3)
+	for k, s := range spans {
+		off := 300 * k
+		require.Equal(t, off, s.Off)
+		require.Equal(t, 300, s.Len)
+		expected := fmt.Sprintf("{x:{min:%d,max:%d,count:300(uint32),nulls:0(uint32),distinct:", off, off+299)
+		require.True(t, strings.HasPrefix(zson.FormatValue(s.Stats), expected), zson.FormatValue(s.Stats))
+		expected = fmt.Sprintf("y:{min:%d,max:%d,count:270(uint32),nulls:30(uint32),distinct:", off+1, off+299)
+		require.Contains(t, zson.FormatValue(s.Stats), expected)
+	}
+}
+
+// pruneBelow is a pruner that rules out the values whose x column has a
+// maximum below it.
+type pruneBelow int64
+
+func (p pruneBelow) Eval(_ expr.Context, stats *zed.Value) *zed.Value {
+	if max := stats.Deref("x").Deref("max"); max != nil && max.Int() < int64(p) {
+		return zed.True
+	}
+	return zed.False
+}
+
+type prunerFilter struct {
+	pruner expr.Evaluator
+}
+
+func (*prunerFilter) AsEvaluator() (expr.Evaluator, error)        { return nil, nil }
+func (*prunerFilter) AsBufferFilter() (*expr.BufferFilter, error) { return nil, nil }
+func (f *prunerFilter) AsPruner() (expr.Evaluator, error)         { return f.pruner, nil }
+
+func TestReaderSegmentPruning(t *testing.T) {
+	r, err := vng.NewReader(segmentedObject(t, 900, 300))
+	require.NoError(t, err)
+	s, err := r.NewScanner(context.Background(), &prunerFilter{pruneBelow(600)})
+	require.NoError(t, err)
+	var vals []string
+	for {
+		batch, err := s.Pull(false)
+		require.NoError(t, err)
+		if batch == nil {
+			break
+		}
+		for _, val := range batch.Values() {
+			vals = append(vals, zson.FormatValue(&val))
+		}
+	}
+	require.Len(t, vals, 300)
+	require.Equal(t, "{x:600,y:null(int64)}", vals[0])
+	require.Equal(t, "{x:899,y:899}", vals[299])
+}
+
+func TestEncodings(t *testing.T) {
+	runs := make([]zed.Value, 0, 3000)
+	for k := 0; k < 3000; k++ {
+		runs = append(runs, *zed.NewValue(zed.TypeInt64, zed.EncodeInt(int64(k/10))))
+	}
+	deltas := make([]zed.Value, 0, 3000)
+	for k := 0; k < 3000; k++ {
+		deltas = append(deltas, *zed.NewValue(zed.TypeUint32, zed.EncodeUint(uint64(7*k))))
+	}
+	times := make([]zed.Value, 0, 3000)
+	for k := 0; k < 3000; k++ {
+		times = append(times, *zed.NewValue(zed.TypeTime, zed.EncodeTime(nano.Ts(1e9*k))))
+	}
+	rng := rand.New(rand.NewSource(1))
+	packed := make([]zed.Value, 0, 3000)
+	for k := 0; k < 3000; k++ {
+		packed = append(packed, *zed.NewValue(zed.TypeInt16, zed.EncodeInt(1000+rng.Int63n(1000))))
+	}
+	extremes := []zed.Value{
+		*zed.NewValue(zed.TypeInt64, zed.EncodeInt(math.MinInt64)),
+		*zed.NewValue(zed.TypeInt64, zed.EncodeInt(math.MaxInt64)),
+	}
+	for k := 0; k < 3000; k++ {
+		extremes = append(extremes, *zed.NewValue(zed.TypeInt64, zed.EncodeInt(int64(rng.Uint64()))))
+	}
+	unsigned := []zed.Value{
+		*zed.NewValue(zed.TypeUint64, zed.EncodeUint(0)),
+		*zed.NewValue(zed.TypeUint64, zed.EncodeUint(math.MaxUint64)),
+	}
+	for k := 0; k < 3000; k++ {
+		unsigned = append(unsigned, *zed.NewValue(zed.TypeUint64, zed.EncodeUint(rng.Uint64())))
+	}
+	cases := []struct {
+		name     string
+		values   []zed.Value
+		encoding uint8
+	}{
+		{"rle", runs, vector.EncodingRLE},
+		{"delta", deltas, vector.EncodingDelta},
+		{"delta-of-delta", times, vector.EncodingDeltaOfDelta},
+		{"bitpack", packed, vector.EncodingBitPack},
+		{"extremes", extremes, vector.EncodingBitPack},
+		{"unsigned", unsigned, vector.EncodingBitPack},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			fuzz.WriteVNG(t, c.values, &buf, vngio.WriterOpts{
+				ColumnThresh: units.Bytes(vngio.DefaultColumnThresh),
+				SkewThresh:   units.Bytes(vngio.DefaultSkewThresh),
+			})
+			o, err := vng.NewObject(zed.NewContext(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			require.NoError(t, err)
+			primitive, ok := vector.Under(o.Maps[0]).(*vector.Primitive)
+			require.True(t, ok)
+			require.Len(t, primitive.Segmap, 1)
+			require.Equal(t, vector.EncodingName(c.encoding), vector.EncodingName(primitive.Segmap[0].Encoding))
+			values, err := fuzz.ReadVNG(buf.Bytes(), demand.All())
+			require.NoError(t, err)
+			fuzz.CompareValues(t, c.values, values)
+		})
+	}
+}
+
+func TestSegmentZstdLimit(t *testing.T) {
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	// A segment that expands to far more than its declared length.
+	zbuf := enc.EncodeAll(bytes.Repeat([]byte{0}, 16*1024*1024), nil)
+	segment := vector.Segment{
+		Length:            int32(len(zbuf)),
+		MemLength:         10,
+		CompressionFormat: vector.CompressionFormatZstd,
+	}
+	require.ErrorIs(t, segment.Read(bytes.NewReader(zbuf), make([]byte, 10)), zstd.ErrDecoderSizeExceeded)
+}
@@ -36,14 +36,20 @@ type Writer struct {
 	root      *vector.Int64Writer
 }
 
-func NewWriter(w io.WriteCloser, skewThresh, segThresh int) (*Writer, error) {
+// NewWriter returns a Writer to w.  Segments are compressed with compression,
+// which is a vector.CompressionFormat value other than
+// vector.CompressionFormatNone, at level compressionLevel.
+func NewWriter(w io.WriteCloser, skewThresh, segThresh int, compression uint8, compressionLevel int) (*Writer, error) {
 	if err := checkThresh("skew", MaxSkewThresh, skewThresh); err != nil {
 		return nil, err
 	}
 	if err := checkThresh("vector", MaxSegmentThresh, segThresh); err != nil {
 		return nil, err
 	}
-	spiller := vector.NewSpiller(w, segThresh)
+	spiller, err := vector.NewSpillerWithCompression(w, segThresh, compression, compressionLevel)
+	if err != nil {
+		return nil, err
+	}
 	return &Writer{
 		zctx:       zed.NewContext(),
 		spiller:    spiller,
@@ -13,6 +13,10 @@ import (
 type Filter interface {
 	AsEvaluator() (expr.Evaluator, error)
 	AsBufferFilter() (*expr.BufferFilter, error)
+	// AsPruner returns an Evaluator that is true for a column statistics
+	// value (as returned by vng.Object.Stats) when the statistics rule out
+	// the filter or nil if the filter can't be used for pruning.
+	AsPruner() (expr.Evaluator, error)
 }
 
 // ScannerAble is implemented by Readers that provide an optimized
@@ -70,6 +70,17 @@ func (c *canon) def(d ast.Def) {
 	c.expr(d.Expr, "")
 }
 
+func (c *canon) joinKeys(left, right []ast.Expr) {
+	for k := range left {
+		if k > 0 {
+			c.write(" and ")
+		}
+		c.expr(left[k], "")
+		c.write("=")
+		c.expr(right[k], "")
+	}
+}
+
 func (c *canon) exprs(exprs []ast.Expr) {
 	for k, e := range exprs {
 		if k > 0 {
@@ -88,6 +99,10 @@ func (c *canon) expr(e ast.Expr, parent string) {
 		if e.Expr != nil {
 			c.expr(e.Expr, "")
 		}
+		for _, arg := range e.Args {
+			c.write(", ")
+			c.expr(arg, "")
+		}
 		c.write(")")
 		if e.Where != nil {
 			c.write(" where ")
@@ -628,9 +643,7 @@ func (c *canon) op(p ast.Op) {
 			c.write(") ")
 		}
 		c.write("on ")
-		c.expr(p.LeftKey, "")
-		c.write("=")
-		c.expr(p.RightKey, "")
+		c.joinKeys(p.LeftKeys, p.RightKeys)
 		if p.Args != nil {
 			c.write(" ")
 			c.assignments(p.Args)
@@ -658,6 +671,21 @@ func (c *canon) op(p ast.Op) {
 		c.next()
 		c.write("yield ")
 		c.exprs(p.Exprs)
+	case *ast.Window:
+		c.next()
+		c.write("window ")
+		c.assignments(p.Funcs)
+		if len(p.Keys) != 0 {
+			c.write(" by ")
+			c.exprs(p.Keys)
+		}
+		if len(p.OrderBy) != 0 {
+			c.write(" order by ")
+			c.exprs(p.OrderBy)
+			if p.Order == order.Desc {
+				c.write(" desc")
+			}
+		}
 	default:
 		c.open("unknown proc: %T", p)
 		c.close()
@@ -741,7 +769,7 @@ func isAggFunc(e ast.Expr) *ast.Summarize {
 	if !ok {
 		return nil
 	}
-	if _, err := agg.NewPattern(call.Name, true); err != nil {
+	if _, _, err := agg.NewPattern(call.Name, true); err != nil {
 		return nil
 	}
 	return &ast.Summarize{
@@ -56,6 +56,17 @@ func (c *canonDAG) assignments(assignments []dag.Assignment) {
 	}
 }
 
+func (c *canonDAG) joinKeys(left, right []dag.Expr) {
+	for k := range left {
+		if k > 0 {
+			c.write(" and ")
+		}
+		c.expr(left[k], "")
+		c.write("=")
+		c.expr(right[k], "")
+	}
+}
+
 func (c *canonDAG) exprs(exprs []dag.Expr) {
 	for k, e := range exprs {
 		if k > 0 {
@@ -74,6 +85,10 @@ func (c *canonDAG) expr(e dag.Expr, parent string) {
 		if e.Expr != nil {
 			c.expr(e.Expr, "")
 		}
+		for _, arg := range e.Args {
+			c.write(", ")
+			c.expr(arg, "")
+		}
 		c.write(")")
 		if e.Where != nil {
 			c.write(" where ")
@@ -428,9 +443,7 @@ func (c *canonDAG) op(p dag.Op) {
 	case *dag.Join:
 		c.next()
 		c.open("join on ")
-		c.expr(p.LeftKey, "")
-		c.write("=")
-		c.expr(p.RightKey, "")
+		c.joinKeys(p.LeftKeys, p.RightKeys)
 		if len(p.Args) != 0 {
 			c.write(" ")
 			c.assignments(p.Args)
@@ -460,6 +473,25 @@ func (c *canonDAG) op(p dag.Op) {
 			c.expr(p.Filter, "")
 			c.write(")")
 		}
+		if p.Fields != nil {
+			c.write(" fields (")
+			for k, f := range p.Fields {
+				if k > 0 {
+					c.write(",")
+				}
+				c.write("%s", f)
+			}
+			c.write(")")
+		}
+		if s := p.Summarize; s != nil {
+			c.write(" summarize (")
+			c.assignments(s.Aggs)
+			if len(s.Keys) != 0 {
+				c.write(" by ")
+				c.assignments(s.Keys)
+			}
+			c.write(")")
+		}
 		c.close()
 	case *dag.Slicer:
 		c.next()
@@ -471,6 +503,25 @@ func (c *canonDAG) op(p dag.Op) {
 		c.next()
 		c.write("yield ")
 		c.exprs(p.Exprs)
+	case *dag.Window:
+		c.next()
+		c.write("window")
+		if p.InputSortDir != 0 {
+			c.write(" sort-dir %d", p.InputSortDir)
+		}
+		c.space()
+		c.assignments(p.Funcs)
+		if len(p.Keys) != 0 {
+			c.write(" by ")
+			c.exprs(p.Keys)
+		}
+		if len(p.OrderBy) != 0 {
+			c.write(" order by ")
+			c.exprs(p.OrderBy)
+			if p.Order == order.Desc {
+				c.write(" desc")
+			}
+		}
 	case *dag.DefaultScan:
 		c.next()
 		c.write("reader")
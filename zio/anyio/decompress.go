@@ -0,0 +1,97 @@
+package anyio
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+type compression struct {
+	name string
+	// magic matches the first bytes of a compressed stream.  A '?' in
+	// magic matches any byte.
+	magic     string
+	newReader func(io.Reader) (io.Reader, error)
+}
+
+var compressions = []compression{
+	// The bzip2 stream header is followed by the magic number of the
+	// first block.
+	{"bzip2", "BZh?1AY&SY", func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	}},
+	// RFC 1952, Section 2.3.1.  The third byte is the compression method,
+	// which is always deflate.
+	{"gzip", "\x1f\x8b\x08", func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	}},
+	{"lz4", "\x04\x22\x4d\x18", func(r io.Reader) (io.Reader, error) {
+		return lz4.NewReader(r), nil
+	}},
+	{"xz", "\xfd7zXZ\x00", func(r io.Reader) (io.Reader, error) {
+		return xz.NewReader(r)
+	}},
+	{"zstd", "\x28\xb5\x2f\xfd", func(r io.Reader) (io.Reader, error) {
+		// With a concurrency of one, the decoder runs synchronously
+		// and need not be closed.
+		return zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+	}},
+}
+
+// DecompressReader returns a reader for the decompressed content of r if r
+// is compressed with bzip2, gzip, LZ4 (frame format), xz, or zstd.  Otherwise, it
+// returns a reader for the content of r.  DecompressReader reads only as many
+// bytes from r as needed to rule out each compression format, so it does not
+// block on a short, uncompressed input.
+func DecompressReader(r io.Reader) (io.Reader, error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if n, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			c := detectCompression(rs)
+			if _, err := rs.Seek(n, io.SeekStart); err != nil {
+				return nil, err
+			}
+			if c == nil {
+				return rs, nil
+			}
+			return c.newReader(rs)
+		}
+	}
+	track := NewTrack(r)
+	c := detectCompression(track)
+	if c == nil {
+		return track.Reader(), nil
+	}
+	return c.newReader(track.Reader())
+}
+
+// detectCompression returns the compression whose magic matches the first
+// bytes of r or nil if there is none.  It reads one byte at a time and stops
+// as soon as no magic can match.
+func detectCompression(r io.Reader) *compression {
+	candidates := make([]*compression, 0, len(compressions))
+	for i := range compressions {
+		candidates = append(candidates, &compressions[i])
+	}
+	var b [1]byte
+	for off := 0; len(candidates) > 0; off++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil
+		}
+		var matches []*compression
+		for _, c := range candidates {
+			if m := c.magic[off]; m != '?' && m != b[0] {
+				continue
+			}
+			if off == len(c.magic)-1 {
+				return c
+			}
+			matches = append(matches, c)
+		}
+		candidates = matches
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package anyio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+// TestDecompressReaderOnlyReadsOneByteIfNoMagic tests that DecompressReader
+// doesn't try to read more than one byte from a non-io.ReadSeeker reader if
+// that byte doesn't begin any compression format's magic.
+func TestDecompressReaderOnlyReadsOneByteIfNoMagic(t *testing.T) {
+	pr, pw := io.Pipe()
+	ch := make(chan struct{})
+	var writeErr error
+	go func() {
+		// DecompressReader should return upon seeing the first byte of
+		// this input.  It will block (and this test will time out) if
+		// it tries to read beyond this input.
+		_, writeErr = pw.Write([]byte("1\n"))
+		close(ch)
+	}()
+	r, err := DecompressReader(pr)
+	require.NoError(t, err)
+	require.NotNil(t, r)
+	<-ch
+	require.NoError(t, writeErr)
+}
+
+func TestDecompressReader(t *testing.T) {
+	const content = "{a:1}\n"
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	// Generated with Python's bz2.compress since Go has no bzip2 writer.
+	bzip2Bytes, err := hex.DecodeString("425a68393141592653594f9891fb0000024980001020102000000a2000221868300252985dc914e142413e6247ec")
+	require.NoError(t, err)
+	cases := []struct {
+		name  string
+		input []byte
+	}{
+		{"none", []byte(content)},
+		{"bzip2", bzip2Bytes},
+		{"gzip", compress(func(w io.Writer) io.WriteCloser {
+			return gzip.NewWriter(w)
+		})},
+		{"lz4", compress(func(w io.Writer) io.WriteCloser {
+			return lz4.NewWriter(w)
+		})},
+		{"xz", compress(func(w io.Writer) io.WriteCloser {
+			xw, err := xz.NewWriter(w)
+			require.NoError(t, err)
+			return xw
+		})},
+		{"zstd", compress(func(w io.Writer) io.WriteCloser {
+			zw, err := zstd.NewWriter(w)
+			require.NoError(t, err)
+			return zw
+		})},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			// Test both seekable and unseekable readers.
+			for _, r := range []io.Reader{bytes.NewReader(c.input), io.MultiReader(bytes.NewReader(c.input))} {
+				dr, err := DecompressReader(r)
+				require.NoError(t, err)
+				b, err := io.ReadAll(dr)
+				require.NoError(t, err)
+				require.Equal(t, content, string(b))
+			}
+		})
+	}
+}
@@ -42,7 +42,7 @@ func Open(ctx context.Context, zctx *zed.Context, engine storage.Engine, path st
 }
 
 func NewFile(zctx *zed.Context, rc io.ReadCloser, path string, demandOut demand.Demand, opts ReaderOpts) (*zbuf.File, error) {
-	r, err := GzipReader(rc)
+	r, err := DecompressReader(rc)
 	if err != nil {
 		return nil, err
 	}
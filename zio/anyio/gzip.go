@@ -5,6 +5,9 @@ import (
 	"io"
 )
 
+// GzipReader returns a reader for the decompressed content of r if r is
+// compressed with gzip.  Otherwise, it returns a reader for the content of r.
+// DecompressReader also detects other compression formats.
 func GzipReader(r io.Reader) (io.Reader, error) {
 	if rs, ok := r.(io.ReadSeeker); ok {
 		if n, err := rs.Seek(0, io.SeekCurrent); err == nil {
@@ -8,6 +8,7 @@ import (
 	"github.com/brimdata/zed/compiler/optimizer/demand"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/arrowio"
+	"github.com/brimdata/zed/zio/avroio"
 	"github.com/brimdata/zed/zio/csvio"
 	"github.com/brimdata/zed/zio/jsonio"
 	"github.com/brimdata/zed/zio/lineio"
@@ -23,6 +24,12 @@ func lookupReader(zctx *zed.Context, r io.Reader, demandOut demand.Demand, opts
 	switch opts.Format {
 	case "arrows":
 		return arrowio.NewReader(zctx, r)
+	case "avro":
+		zr, err := avroio.NewReader(zctx, r)
+		if err != nil {
+			return nil, err
+		}
+		return zio.NopReadCloser(zr), nil
 	case "csv":
 		return zio.NopReadCloser(csvio.NewReader(zctx, r, opts.CSV)), nil
 	case "line":
@@ -39,11 +46,7 @@ func lookupReader(zctx *zed.Context, r io.Reader, demandOut demand.Demand, opts
 		opts.CSV.Delim = '\t'
 		return zio.NopReadCloser(csvio.NewReader(zctx, r, opts.CSV)), nil
 	case "vng":
-		zr, err := vngio.NewReader(zctx, r, demandOut)
-		if err != nil {
-			return nil, err
-		}
-		return zio.NopReadCloser(zr), nil
+		return vngio.NewReader(zctx, r, demandOut)
 	case "zeek":
 		return zio.NopReadCloser(zeekio.NewReader(zctx, r)), nil
 	case "zjson":
@@ -12,6 +12,7 @@ import (
 	"github.com/brimdata/zed/compiler/optimizer/demand"
 	"github.com/brimdata/zed/zio"
 	"github.com/brimdata/zed/zio/arrowio"
+	"github.com/brimdata/zed/zio/avroio"
 	"github.com/brimdata/zed/zio/csvio"
 	"github.com/brimdata/zed/zio/jsonio"
 	"github.com/brimdata/zed/zio/parquetio"
@@ -48,9 +49,10 @@ func NewReaderWithOpts(zctx *zed.Context, r io.Reader, demandOut demand.Demand,
 			if _, err := rs.Seek(n, io.SeekStart); err != nil {
 				return nil, err
 			}
-			zr, vngErr = vngio.NewReader(zctx, rs, demandOut)
+			var zrc zio.ReadCloser
+			zrc, vngErr = vngio.NewReader(zctx, rs, demandOut)
 			if vngErr == nil {
-				return zio.NopReadCloser(zr), nil
+				return zrc, nil
 			}
 			if _, err := rs.Seek(n, io.SeekStart); err != nil {
 				return nil, err
@@ -68,6 +70,17 @@ func NewReaderWithOpts(zctx *zed.Context, r io.Reader, demandOut demand.Demand,
 
 	track := NewTrack(r)
 
+	avroErr := isAvroFile(track)
+	if avroErr == nil {
+		zr, err := avroio.NewReader(zctx, track.Reader())
+		if err != nil {
+			return nil, err
+		}
+		return zio.NopReadCloser(zr), nil
+	}
+	avroErr = fmt.Errorf("avro: %w", avroErr)
+	track.Reset()
+
 	arrowsErr := isArrowStream(track)
 	if arrowsErr == nil {
 		return arrowio.NewReader(zctx, track.Reader())
@@ -132,6 +145,7 @@ func NewReaderWithOpts(zctx *zed.Context, r io.Reader, demandOut demand.Demand,
 	lineErr := errors.New("line: auto-detection not supported")
 	return nil, joinErrs([]error{
 		arrowsErr,
+		avroErr,
 		csvErr,
 		jsonErr,
 		lineErr,
@@ -175,6 +189,23 @@ func isArrowStream(track *Track) error {
 	return err
 }
 
+func isAvroFile(track *Track) error {
+	buf := make([]byte, len(avroio.Magic))
+	if _, err := io.ReadFull(track, buf); err != nil {
+		return err
+	}
+	if string(buf) != avroio.Magic {
+		return errors.New("bad magic")
+	}
+	track.Reset()
+	zr, err := avroio.NewReader(zed.NewContext(), track)
+	if err != nil {
+		return err
+	}
+	_, err = zr.Read()
+	return err
+}
+
 func isCSVStream(track *Track, delim rune, name string) error {
 	if s, err := bufio.NewReader(track).ReadString('\n'); err != nil {
 		return fmt.Errorf("%s: line 1: %w", name, err)
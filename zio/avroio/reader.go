@@ -0,0 +1,443 @@
+package avroio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/pkg/nano"
+	"github.com/brimdata/zed/zcode"
+)
+
+// Magic is the first four bytes of an Avro object container file.
+const Magic = "Obj\x01"
+
+const syncSize = 16
+
+// maxZeroSizeItems limits the number of items of an array whose items may
+// be encoded in no bytes, such as nulls, since the block counts of such an
+// array are not bounded by the size of its input.
+const maxZeroSizeItems = 1 << 20
+
+var errBadData = errors.New("avroio: malformed data")
+
+// Reader is a zio.Reader for the Avro object container file format.  Each
+// value of the file's schema is mapped to a Zed value as follows:
+//
+//   - Records, arrays, and booleans map to their Zed counterparts.
+//   - Ints and longs map to int32 and int64.
+//   - Floats and doubles map to float32 and float64.
+//   - Strings, bytes, and fixeds map to string, bytes, and bytes.
+//   - Enums map to Zed enums.
+//   - Maps map to Zed maps with string keys.
+//   - A union of null and one other type maps to that type, and other unions
+//     map to Zed unions.
+//   - The timestamp and date logical types map to time, the time-of-day
+//     logical types map to duration, and the uuid logical type maps to
+//     string.  Zed has no decimal type, so a decimal is read as the bytes
+//     of its unscaled two's-complement integer.
+//
+// Recursive schemas are not supported.
+type Reader struct {
+	r       *bufio.Reader
+	schema  *schema
+	codec   string
+	sync    [syncSize]byte
+	block   []byte
+	buf     []byte
+	count   int64
+	builder zcode.Builder
+	val     zed.Value
+}
+
+func NewReader(zctx *zed.Context, r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("avroio: reading header: %w", err)
+	}
+	if string(magic) != Magic {
+		return nil, errors.New("avroio: bad magic")
+	}
+	meta, err := readMetadata(br)
+	if err != nil {
+		return nil, err
+	}
+	rdr := &Reader{
+		r:     br,
+		codec: string(meta["avro.codec"]),
+	}
+	switch rdr.codec {
+	case "":
+		rdr.codec = "null"
+	case "null", "deflate":
+	default:
+		return nil, fmt.Errorf("avroio: unsupported codec %q", rdr.codec)
+	}
+	schemaJSON, ok := meta["avro.schema"]
+	if !ok {
+		return nil, errors.New("avroio: missing schema")
+	}
+	if rdr.schema, err = parseSchema(zctx, schemaJSON); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(br, rdr.sync[:]); err != nil {
+		return nil, fmt.Errorf("avroio: reading header: %w", err)
+	}
+	return rdr, nil
+}
+
+func readMetadata(r *bufio.Reader) (map[string][]byte, error) {
+	meta := map[string][]byte{}
+	for {
+		n, err := readBlockCount(r)
+		if err != nil {
+			return nil, fmt.Errorf("avroio: reading header: %w", err)
+		}
+		if n == 0 {
+			return meta, nil
+		}
+		for ; n > 0; n-- {
+			key, err := readStreamBytes(r)
+			if err != nil {
+				return nil, fmt.Errorf("avroio: reading header: %w", err)
+			}
+			val, err := readStreamBytes(r)
+			if err != nil {
+				return nil, fmt.Errorf("avroio: reading header: %w", err)
+			}
+			meta[string(key)] = val
+		}
+	}
+}
+
+// readBlockCount reads the count of an array or map block from r, skipping
+// the block size that follows a negative count.
+func readBlockCount(r *bufio.Reader) (int64, error) {
+	n, err := binary.ReadVarint(r)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		if _, err := binary.ReadVarint(r); err != nil {
+			return 0, err
+		}
+		n = -n
+	}
+	return n, nil
+}
+
+func readStreamBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadVarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > math.MaxInt32 {
+		return nil, errBadData
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func (r *Reader) Read() (*zed.Value, error) {
+	for r.count == 0 {
+		if err := r.readBlock(); err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+	r.builder.Truncate()
+	var err error
+	if rec := r.schema; rec.kind == "record" {
+		// Build the fields of a top-level record directly rather than
+		// as a container.
+		for _, f := range rec.fields {
+			if r.block, err = r.decode(f.schema, r.block); err != nil {
+				return nil, err
+			}
+		}
+		b := r.builder.Bytes()
+		if b == nil {
+			// A record with no fields is empty, not null.
+			b = zcode.Bytes{}
+		}
+		r.val = *zed.NewValue(rec.typ, b)
+	} else {
+		if r.block, err = r.decode(r.schema, r.block); err != nil {
+			return nil, err
+		}
+		r.val = *zed.NewValue(r.schema.typ, r.builder.Bytes().Body())
+	}
+	r.count--
+	return &r.val, nil
+}
+
+func (r *Reader) readBlock() error {
+	if len(r.block) > 0 {
+		return errBadData
+	}
+	count, err := binary.ReadVarint(r.r)
+	if err != nil {
+		if err == io.EOF {
+			return err
+		}
+		return fmt.Errorf("avroio: reading block: %w", err)
+	}
+	size, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return fmt.Errorf("avroio: reading block: %w", noEOF(err))
+	}
+	if count < 0 || size < 0 || size > math.MaxInt32 {
+		return errBadData
+	}
+	if cap(r.buf) < int(size) {
+		r.buf = make([]byte, size)
+	}
+	r.buf = r.buf[:size]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return fmt.Errorf("avroio: reading block: %w", noEOF(err))
+	}
+	var sync [syncSize]byte
+	if _, err := io.ReadFull(r.r, sync[:]); err != nil {
+		return fmt.Errorf("avroio: reading block: %w", noEOF(err))
+	}
+	if sync != r.sync {
+		return errors.New("avroio: bad sync marker")
+	}
+	r.block = r.buf
+	if r.codec == "deflate" {
+		b, err := io.ReadAll(flate.NewReader(bytes.NewReader(r.buf)))
+		if err != nil {
+			return fmt.Errorf("avroio: deflate: %w", err)
+		}
+		r.block = b
+	}
+	r.count = count
+	return nil
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decode decodes a value of schema s from the front of b, appends it to
+// r.builder, and returns the remainder of b.
+func (r *Reader) decode(s *schema, b []byte) ([]byte, error) {
+	switch s.kind {
+	case "null":
+		r.builder.Append(nil)
+		return b, nil
+	case "boolean":
+		if len(b) < 1 {
+			return nil, errBadData
+		}
+		r.builder.Append(zed.EncodeBool(b[0] != 0))
+		return b[1:], nil
+	case "int", "long":
+		v, b, err := decodeLong(b)
+		if err != nil {
+			return nil, err
+		}
+		switch s.logical {
+		case "timestamp-millis", "local-timestamp-millis":
+			r.builder.Append(zed.EncodeTime(nano.Ts(v * 1_000_000)))
+		case "timestamp-micros", "local-timestamp-micros":
+			r.builder.Append(zed.EncodeTime(nano.Ts(v * 1000)))
+		case "timestamp-nanos", "local-timestamp-nanos":
+			r.builder.Append(zed.EncodeTime(nano.Ts(v)))
+		case "date":
+			r.builder.Append(zed.EncodeTime(nano.Ts(v * int64(nano.Day))))
+		case "time-millis":
+			r.builder.Append(zed.EncodeDuration(nano.Duration(v * 1_000_000)))
+		case "time-micros":
+			r.builder.Append(zed.EncodeDuration(nano.Duration(v * 1000)))
+		default:
+			r.builder.Append(zed.EncodeInt(v))
+		}
+		return b, nil
+	case "float":
+		if len(b) < 4 {
+			return nil, errBadData
+		}
+		f := math.Float32frombits(binary.LittleEndian.Uint32(b))
+		r.builder.Append(zed.EncodeFloat32(f))
+		return b[4:], nil
+	case "double":
+		if len(b) < 8 {
+			return nil, errBadData
+		}
+		f := math.Float64frombits(binary.LittleEndian.Uint64(b))
+		r.builder.Append(zed.EncodeFloat64(f))
+		return b[8:], nil
+	case "bytes", "string":
+		v, b, err := decodeBytes(b)
+		if err != nil {
+			return nil, err
+		}
+		r.appendBytes(s, v)
+		return b, nil
+	case "fixed":
+		if len(b) < s.size {
+			return nil, errBadData
+		}
+		r.appendBytes(s, b[:s.size])
+		return b[s.size:], nil
+	case "enum":
+		v, b, err := decodeLong(b)
+		if err != nil {
+			return nil, err
+		}
+		if v < 0 || v >= int64(len(s.symbols)) {
+			return nil, errBadData
+		}
+		r.builder.Append(zed.EncodeUint(uint64(v)))
+		return b, nil
+	case "record":
+		r.builder.BeginContainer()
+		for _, f := range s.fields {
+			var err error
+			if b, err = r.decode(f.schema, b); err != nil {
+				return nil, err
+			}
+		}
+		r.builder.EndContainer()
+		return b, nil
+	case "array", "map":
+		r.builder.BeginContainer()
+		var total int64
+		for {
+			n, rest, err := decodeBlockCount(b)
+			if err != nil {
+				return nil, err
+			}
+			b = rest
+			if n == 0 {
+				break
+			}
+			if s.kind == "map" || !zeroSize(s.items) {
+				// Each item is encoded in at least one byte.
+				if n > int64(len(b)) {
+					return nil, errBadData
+				}
+			} else if total += n; total > maxZeroSizeItems {
+				return nil, errBadData
+			}
+			for ; n > 0; n-- {
+				if s.kind == "array" {
+					b, err = r.decode(s.items, b)
+				} else {
+					var key []byte
+					if key, b, err = decodeBytes(b); err == nil {
+						r.builder.Append(key)
+						b, err = r.decode(s.values, b)
+					}
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if s.kind == "map" {
+			r.builder.TransformContainer(zed.NormalizeMap)
+		}
+		r.builder.EndContainer()
+		return b, nil
+	case "union":
+		i, b, err := decodeLong(b)
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(s.types)) {
+			return nil, errBadData
+		}
+		if int(i) == s.null || s.tags == nil {
+			return r.decode(s.types[i], b)
+		}
+		r.builder.BeginContainer()
+		r.builder.Append(zed.EncodeInt(int64(s.tags[i])))
+		b, err = r.decode(s.types[i], b)
+		r.builder.EndContainer()
+		return b, err
+	}
+	return nil, fmt.Errorf("avroio: unknown type %q", s.kind)
+}
+
+func (r *Reader) appendBytes(s *schema, b []byte) {
+	switch s.logical {
+	case "uuid":
+		if s.kind == "fixed" {
+			b = []byte(fmt.Sprintf("%x-%x-%x-%x-%x", b[:4], b[4:6], b[6:8], b[8:10], b[10:]))
+		}
+		r.builder.Append(b)
+	default:
+		r.builder.Append(b)
+	}
+}
+
+// zeroSize returns true if values of schema s may be encoded in no bytes.
+func zeroSize(s *schema) bool {
+	switch s.kind {
+	case "null":
+		return true
+	case "fixed":
+		return s.size == 0
+	case "record":
+		for _, f := range s.fields {
+			if !zeroSize(f.schema) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func decodeLong(b []byte) (int64, []byte, error) {
+	v, n := binary.Varint(b)
+	if n <= 0 {
+		return 0, nil, errBadData
+	}
+	return v, b[n:], nil
+}
+
+func decodeBytes(b []byte) ([]byte, []byte, error) {
+	n, b, err := decodeLong(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	if n < 0 || n > int64(len(b)) {
+		return nil, nil, errBadData
+	}
+	return b[:n], b[n:], nil
+}
+
+// decodeBlockCount decodes the count of an array or map block from the
+// front of b, skipping the block size that follows a negative count.
+func decodeBlockCount(b []byte) (int64, []byte, error) {
+	n, b, err := decodeLong(b)
+	if err != nil {
+		return 0, nil, err
+	}
+	if n < 0 {
+		if _, b, err = decodeLong(b); err != nil {
+			return 0, nil, err
+		}
+		n = -n
+		if n < 0 {
+			return 0, nil, errBadData
+		}
+	}
+	return n, b, nil
+}
@@ -0,0 +1,131 @@
+package avroio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zson"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "record",
+	"name": "Rec",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "ts", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "date", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "time", "type": {"type": "int", "logicalType": "time-millis"}},
+		{"name": "dec", "type": {"type": "bytes", "logicalType": "decimal", "precision": 5, "scale": 2}},
+		{"name": "uuid", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "fixed_uuid", "type": {"type": "fixed", "name": "UUID", "size": 16, "logicalType": "uuid"}},
+		{"name": "fixed", "type": {"type": "fixed", "name": "Pair", "size": 2}},
+		{"name": "ref", "type": "Pair"},
+		{"name": "color", "type": {"type": "enum", "name": "Color", "symbols": ["RED", "GREEN"]}},
+		{"name": "map", "type": {"type": "map", "values": "long"}},
+		{"name": "union", "type": ["long", "null", "string"]},
+		{"name": "optional", "type": ["null", "string"]}
+	]
+}`
+
+func TestReaderLogicalTypes(t *testing.T) {
+	var block []byte
+	block = binary.AppendVarint(block, 1670183028123)
+	block = binary.AppendVarint(block, 19330)
+	block = binary.AppendVarint(block, 1500)
+	block = appendBytes(block, []byte{0xcf, 0xc7}) // -123.45
+	block = appendBytes(block, []byte("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+	block = append(block, 0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8)
+	block = append(block, 0xab, 0xcd)
+	block = append(block, 0x01, 0x02)
+	block = binary.AppendVarint(block, 1)
+	// Map block with a negative count, which is followed by the block size.
+	var m []byte
+	m = appendBytes(m, []byte("a"))
+	m = binary.AppendVarint(m, 1)
+	m = appendBytes(m, []byte("b"))
+	m = binary.AppendVarint(m, 2)
+	block = binary.AppendVarint(block, -2)
+	block = binary.AppendVarint(block, int64(len(m)))
+	block = append(block, m...)
+	block = binary.AppendVarint(block, 0)
+	block = binary.AppendVarint(block, 2)
+	block = appendBytes(block, []byte("x"))
+	block = binary.AppendVarint(block, 0)
+
+	r, err := NewReader(zed.NewContext(), bytes.NewReader(testFile("null", block)))
+	require.NoError(t, err)
+	val, err := r.Read()
+	require.NoError(t, err)
+	const expected = `{ts:2022-12-04T19:43:48.123Z,date:2022-12-04T00:00:00Z,time:1.5s,dec:0xcfc7,uuid:"6ba7b810-9dad-11d1-80b4-00c04fd430c8",fixed_uuid:"6ba7b810-9dad-11d1-80b4-00c04fd430c8",fixed:0xabcd,ref:0x0102,color:%GREEN(enum(RED,GREEN)),map:|{"a":1,"b":2}|,union:"x"((int64,string)),optional:null(string)}`
+	require.Equal(t, expected, zson.FormatValue(val))
+	val, err = r.Read()
+	require.NoError(t, err)
+	require.Nil(t, val)
+}
+
+func TestReaderBadSync(t *testing.T) {
+	b := testFile("null", binary.AppendVarint(nil, 1))
+	b[len(b)-1]++
+	r, err := NewReader(zed.NewContext(), bytes.NewReader(b))
+	require.NoError(t, err)
+	_, err = r.Read()
+	require.EqualError(t, err, "avroio: bad sync marker")
+}
+
+func TestReaderUnsupportedCodec(t *testing.T) {
+	_, err := NewReader(zed.NewContext(), bytes.NewReader(testFile("snappy", nil)))
+	require.EqualError(t, err, `avroio: unsupported codec "snappy"`)
+}
+
+func TestReaderBlockCounts(t *testing.T) {
+	for _, c := range []struct {
+		schema string
+		block  []byte
+	}{
+		// Items encoded in at least one byte are bounded by the input.
+		{`{"type": "array", "items": "long"}`, binary.AppendVarint(nil, 100)},
+		{`{"type": "map", "values": "null"}`, binary.AppendVarint(nil, 100)},
+		// Zero-size items are bounded by maxZeroSizeItems.
+		{`{"type": "array", "items": "null"}`, binary.AppendVarint(nil, 1<<40)},
+		{`{"type": "array", "items": "null"}`, binary.AppendVarint(binary.AppendVarint(nil, maxZeroSizeItems), 1)},
+	} {
+		r, err := NewReader(zed.NewContext(), bytes.NewReader(testFileWithSchema(c.schema, "null", c.block)))
+		require.NoError(t, err)
+		_, err = r.Read()
+		require.ErrorIs(t, err, errBadData, c.schema)
+	}
+	block := binary.AppendVarint(binary.AppendVarint(nil, 3), 0)
+	r, err := NewReader(zed.NewContext(), bytes.NewReader(testFileWithSchema(`{"type": "array", "items": "null"}`, "null", block)))
+	require.NoError(t, err)
+	val, err := r.Read()
+	require.NoError(t, err)
+	require.Equal(t, "[null,null,null]", zson.FormatValue(val))
+}
+
+// testFile returns an object container file with testSchema, codec, and a
+// block containing the single encoded value in block.
+func testFile(codec string, block []byte) []byte {
+	return testFileWithSchema(testSchema, codec, block)
+}
+
+// testFileWithSchema is like testFile but with the given schema.
+func testFileWithSchema(schema, codec string, block []byte) []byte {
+	sync := bytes.Repeat([]byte{0x5a}, syncSize)
+	b := []byte(Magic)
+	b = binary.AppendVarint(b, 2)
+	b = appendBytes(b, []byte("avro.schema"))
+	b = appendBytes(b, []byte(schema))
+	b = appendBytes(b, []byte("avro.codec"))
+	b = appendBytes(b, []byte(codec))
+	b = binary.AppendVarint(b, 0)
+	b = append(b, sync...)
+	if block != nil {
+		b = binary.AppendVarint(b, 1)
+		b = appendBytes(b, block)
+		b = append(b, sync...)
+	}
+	return b
+}
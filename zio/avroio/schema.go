@@ -0,0 +1,331 @@
+package avroio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/brimdata/zed"
+)
+
+// schema is a parsed Avro schema.  Named types (records, enums, and fixeds)
+// are parsed once and shared by all references to them.
+type schema struct {
+	kind    string // Avro type name, e.g., "long", "record", or "union"
+	logical string // Avro logical type name, e.g., "timestamp-micros"
+	name    string // Full name of a record, enum, or fixed
+	fields  []field
+	symbols []string
+	items   *schema // Array items
+	values  *schema // Map values
+	types   []*schema
+	size    int // Fixed size
+
+	// typ is the Zed type to which this schema is mapped.
+	typ zed.Type
+	// null is the index of the null branch of a union or -1 if there is
+	// none.
+	null int
+	// tags maps the branches of a union to tags of its Zed union type
+	// or is nil if the union is mapped to a nullable Zed type.
+	tags []int
+}
+
+type field struct {
+	name   string
+	schema *schema
+}
+
+type schemaParser struct {
+	zctx  *zed.Context
+	named map[string]*schema
+}
+
+func parseSchema(zctx *zed.Context, b []byte) (*schema, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("avroio: schema: %w", err)
+	}
+	p := &schemaParser{
+		zctx:  zctx,
+		named: map[string]*schema{},
+	}
+	s, err := p.parse(v, "")
+	if err != nil {
+		return nil, fmt.Errorf("avroio: schema: %w", err)
+	}
+	return s, nil
+}
+
+func (p *schemaParser) parse(v interface{}, namespace string) (*schema, error) {
+	switch v := v.(type) {
+	case string:
+		return p.parseName(v, namespace)
+	case []interface{}:
+		return p.parseUnion(v, namespace)
+	case map[string]interface{}:
+		return p.parseObject(v, namespace)
+	}
+	return nil, fmt.Errorf("invalid schema: %v", v)
+}
+
+func (p *schemaParser) parseName(name, namespace string) (*schema, error) {
+	var typ zed.Type
+	switch name {
+	case "null":
+		typ = zed.TypeNull
+	case "boolean":
+		typ = zed.TypeBool
+	case "int":
+		typ = zed.TypeInt32
+	case "long":
+		typ = zed.TypeInt64
+	case "float":
+		typ = zed.TypeFloat32
+	case "double":
+		typ = zed.TypeFloat64
+	case "bytes":
+		typ = zed.TypeBytes
+	case "string":
+		typ = zed.TypeString
+	default:
+		s, ok := p.named[fullName(name, namespace)]
+		if !ok {
+			s, ok = p.named[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q", name)
+		}
+		if s.typ == nil {
+			return nil, fmt.Errorf("recursive type %q not supported", s.name)
+		}
+		return s, nil
+	}
+	return &schema{kind: name, typ: typ}, nil
+}
+
+func (p *schemaParser) parseUnion(types []interface{}, namespace string) (*schema, error) {
+	if len(types) == 0 {
+		return nil, errors.New("empty union")
+	}
+	s := &schema{kind: "union", null: -1}
+	var zedTypes []zed.Type
+	for i, t := range types {
+		branch, err := p.parse(t, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if branch.kind == "union" {
+			return nil, errors.New("union may not immediately contain another union")
+		}
+		s.types = append(s.types, branch)
+		if branch.kind == "null" {
+			s.null = i
+			continue
+		}
+		if !slices.Contains(zedTypes, branch.typ) {
+			zedTypes = append(zedTypes, branch.typ)
+		}
+	}
+	switch len(zedTypes) {
+	case 0:
+		s.typ = zed.TypeNull
+	case 1:
+		// A union of null and one other type, the usual Avro idiom
+		// for an optional value, maps to that type since any Zed value
+		// may be null.
+		s.typ = zedTypes[0]
+	default:
+		union := p.zctx.LookupTypeUnion(zedTypes)
+		s.typ = union
+		s.tags = make([]int, len(s.types))
+		for i, branch := range s.types {
+			s.tags[i] = union.TagOf(branch.typ)
+		}
+	}
+	return s, nil
+}
+
+func (p *schemaParser) parseObject(obj map[string]interface{}, namespace string) (*schema, error) {
+	kind, ok := obj["type"].(string)
+	if !ok {
+		// The type attribute may itself be a schema, e.g.,
+		// {"type": {"type": "array", "items": "int"}}.
+		if t, ok := obj["type"]; ok {
+			return p.parse(t, namespace)
+		}
+		return nil, errors.New("missing type attribute")
+	}
+	var s *schema
+	switch kind {
+	case "record", "error":
+		return p.parseRecord(obj, namespace)
+	case "enum":
+		return p.parseEnum(obj, namespace)
+	case "fixed":
+		return p.parseFixed(obj, namespace)
+	case "array":
+		items, err := p.parse(obj["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		s = &schema{kind: kind, items: items, typ: p.zctx.LookupTypeArray(items.typ)}
+	case "map":
+		values, err := p.parse(obj["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		s = &schema{kind: kind, values: values, typ: p.zctx.LookupTypeMap(zed.TypeString, values.typ)}
+	default:
+		var err error
+		if s, err = p.parseName(kind, namespace); err != nil {
+			return nil, err
+		}
+		if s.name != "" {
+			// A reference to a named type carries no attributes.
+			return s, nil
+		}
+	}
+	if logical, ok := obj["logicalType"].(string); ok {
+		p.setLogical(s, logical)
+	}
+	return s, nil
+}
+
+func (p *schemaParser) parseRecord(obj map[string]interface{}, namespace string) (*schema, error) {
+	s, namespace, err := p.define(obj, namespace, "record")
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := obj["fields"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("record %q: missing fields", s.name)
+	}
+	var zedFields []zed.Field
+	for _, f := range fields {
+		fobj, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record %q: invalid field: %v", s.name, f)
+		}
+		name, ok := fobj["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("record %q: field missing name", s.name)
+		}
+		fs, err := p.parse(fobj["type"], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("record %q: field %q: %w", s.name, name, err)
+		}
+		s.fields = append(s.fields, field{name, fs})
+		zedFields = append(zedFields, zed.NewField(name, fs.typ))
+	}
+	typ, err := p.zctx.LookupTypeRecord(zedFields)
+	if err != nil {
+		return nil, fmt.Errorf("record %q: %w", s.name, err)
+	}
+	s.typ = typ
+	return s, nil
+}
+
+func (p *schemaParser) parseEnum(obj map[string]interface{}, namespace string) (*schema, error) {
+	s, _, err := p.define(obj, namespace, "enum")
+	if err != nil {
+		return nil, err
+	}
+	symbols, ok := obj["symbols"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("enum %q: missing symbols", s.name)
+	}
+	for _, sym := range symbols {
+		str, ok := sym.(string)
+		if !ok {
+			return nil, fmt.Errorf("enum %q: invalid symbol: %v", s.name, sym)
+		}
+		s.symbols = append(s.symbols, str)
+	}
+	s.typ = p.zctx.LookupTypeEnum(s.symbols)
+	return s, nil
+}
+
+func (p *schemaParser) parseFixed(obj map[string]interface{}, namespace string) (*schema, error) {
+	s, _, err := p.define(obj, namespace, "fixed")
+	if err != nil {
+		return nil, err
+	}
+	size, ok := obj["size"].(float64)
+	if !ok || size < 0 {
+		return nil, fmt.Errorf("fixed %q: missing or invalid size", s.name)
+	}
+	s.size = int(size)
+	s.typ = zed.TypeBytes
+	if logical, ok := obj["logicalType"].(string); ok {
+		p.setLogical(s, logical)
+	}
+	return s, nil
+}
+
+// define creates and registers the schema for a named type.  It returns the
+// schema and the namespace for names within the type.
+func (p *schemaParser) define(obj map[string]interface{}, namespace, kind string) (*schema, string, error) {
+	name, ok := obj["name"].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("%s missing name", kind)
+	}
+	if ns, ok := obj["namespace"].(string); ok {
+		namespace = ns
+	}
+	name = fullName(name, namespace)
+	if _, ok := p.named[name]; ok {
+		return nil, "", fmt.Errorf("type %q redefined", name)
+	}
+	s := &schema{kind: kind, name: name}
+	p.named[name] = s
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		namespace = name[:i]
+	} else {
+		namespace = ""
+	}
+	return s, namespace, nil
+}
+
+// setLogical maps s to the Zed type for logical type if s has the
+// underlying type required by logical.  Otherwise, as the Avro specification
+// requires, the logical type is ignored.
+func (p *schemaParser) setLogical(s *schema, logical string) {
+	var typ zed.Type
+	switch logical {
+	case "timestamp-millis", "timestamp-micros", "timestamp-nanos",
+		"local-timestamp-millis", "local-timestamp-micros", "local-timestamp-nanos":
+		if s.kind == "long" {
+			typ = zed.TypeTime
+		}
+	case "date":
+		if s.kind == "int" {
+			typ = zed.TypeTime
+		}
+	case "time-millis":
+		if s.kind == "int" {
+			typ = zed.TypeDuration
+		}
+	case "time-micros":
+		if s.kind == "long" {
+			typ = zed.TypeDuration
+		}
+	case "uuid":
+		if s.kind == "string" || s.kind == "fixed" && s.size == 16 {
+			typ = zed.TypeString
+		}
+	}
+	if typ != nil {
+		s.logical = logical
+		s.typ = typ
+	}
+}
+
+func fullName(name, namespace string) string {
+	if namespace == "" || strings.IndexByte(name, '.') >= 0 {
+		return name
+	}
+	return namespace + "." + name
+}
@@ -0,0 +1,460 @@
+package avroio
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+
+	"github.com/brimdata/zed"
+	"github.com/brimdata/zed/zcode"
+	"github.com/brimdata/zed/zson"
+)
+
+var (
+	ErrMultipleTypes   = errors.New("avroio: encountered multiple types (consider 'fuse')")
+	ErrNotRecord       = errors.New("avroio: not a record")
+	ErrUnsupportedType = errors.New("avroio: unsupported type")
+)
+
+const (
+	blockMaxCount = 1024
+	blockMaxBytes = 1024 * 1024
+)
+
+var nameRegexp = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+
+// Writer is a zio.Writer for the Avro object container file format.  All
+// values written must be records of the same type.  Since any Zed value may
+// be null, every field, array element, and map value is written as a union
+// of null and the Avro type for its Zed type, which is as follows:
+//
+//   - Records, arrays, booleans, strings, bytes, and enums map to their
+//     Avro counterparts, with generated names for records and enums.
+//   - Sets map to arrays.
+//   - Maps with string keys map to Avro maps.
+//   - Unions map to Avro unions.
+//   - Integers that fit in 32 bits map to int and other integers to long.
+//   - float16 and float32 map to float and float64 to double.
+//   - time maps to long with logical type timestamp-micros.
+//   - duration maps to long nanoseconds.
+//   - ip and net map to string.
+//
+// Named types are written as their underlying types.
+type Writer struct {
+	w       io.WriteCloser
+	typ     zed.Type
+	encode  encoder
+	sync    [syncSize]byte
+	block   []byte
+	count   int
+	nrecord int
+	nenum   int
+	zbuf    bytes.Buffer
+	zw      *flate.Writer
+}
+
+// encoder appends the Avro encoding of the Zed value body b to dst.
+type encoder func(dst []byte, b zcode.Bytes) ([]byte, error)
+
+func NewWriter(w io.WriteCloser) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Close() error {
+	var err error
+	if w.typ != nil {
+		err = w.flush()
+	}
+	if err2 := w.w.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+func (w *Writer) Write(val *zed.Value) error {
+	recType, ok := zed.TypeUnder(val.Type).(*zed.TypeRecord)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotRecord, zson.FormatValue(val))
+	}
+	if w.typ == nil {
+		if err := w.writeHeader(val.Type, recType); err != nil {
+			return err
+		}
+	} else if w.typ != val.Type {
+		return fmt.Errorf("%w: %s and %s", ErrMultipleTypes, zson.FormatType(w.typ), zson.FormatType(val.Type))
+	}
+	var err error
+	if w.block, err = w.encode(w.block, val.Bytes()); err != nil {
+		return err
+	}
+	w.count++
+	if w.count >= blockMaxCount || len(w.block) >= blockMaxBytes {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *Writer) writeHeader(typ zed.Type, recType *zed.TypeRecord) error {
+	schema, encode, err := w.newRecord(recType)
+	if err != nil {
+		return err
+	}
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	if _, err := rand.Read(w.sync[:]); err != nil {
+		return err
+	}
+	w.typ = typ
+	w.encode = encode
+	b := []byte(Magic)
+	b = binary.AppendVarint(b, 2)
+	b = appendBytes(b, []byte("avro.schema"))
+	b = appendBytes(b, schemaJSON)
+	b = appendBytes(b, []byte("avro.codec"))
+	b = appendBytes(b, []byte("deflate"))
+	b = binary.AppendVarint(b, 0)
+	b = append(b, w.sync[:]...)
+	_, err = w.w.Write(b)
+	return err
+}
+
+func (w *Writer) flush() error {
+	if w.count == 0 {
+		return nil
+	}
+	w.zbuf.Reset()
+	if w.zw == nil {
+		var err error
+		if w.zw, err = flate.NewWriter(&w.zbuf, flate.DefaultCompression); err != nil {
+			return err
+		}
+	} else {
+		w.zw.Reset(&w.zbuf)
+	}
+	if _, err := w.zw.Write(w.block); err != nil {
+		return err
+	}
+	if err := w.zw.Close(); err != nil {
+		return err
+	}
+	b := binary.AppendVarint(nil, int64(w.count))
+	b = binary.AppendVarint(b, int64(w.zbuf.Len()))
+	b = append(b, w.zbuf.Bytes()...)
+	b = append(b, w.sync[:]...)
+	w.block = w.block[:0]
+	w.count = 0
+	_, err := w.w.Write(b)
+	return err
+}
+
+type avroRecord struct {
+	Type   string      `json:"type"`
+	Name   string      `json:"name"`
+	Fields []avroField `json:"fields"`
+}
+
+type avroField struct {
+	Name string      `json:"name"`
+	Type interface{} `json:"type"`
+}
+
+type avroEnum struct {
+	Type    string   `json:"type"`
+	Name    string   `json:"name"`
+	Symbols []string `json:"symbols"`
+}
+
+type avroArray struct {
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
+}
+
+type avroMap struct {
+	Type   string      `json:"type"`
+	Values interface{} `json:"values"`
+}
+
+type avroLogical struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+func (w *Writer) newRecord(typ *zed.TypeRecord) (interface{}, encoder, error) {
+	w.nrecord++
+	rec := avroRecord{
+		Type:   "record",
+		Name:   fmt.Sprintf("record%d", w.nrecord),
+		Fields: []avroField{},
+	}
+	var encoders []encoder
+	for _, f := range typ.Fields {
+		if !nameRegexp.MatchString(f.Name) {
+			return nil, nil, fmt.Errorf("%w: field name %q is not a valid Avro name", ErrUnsupportedType, f.Name)
+		}
+		schema, enc, err := w.newNullable(f.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		rec.Fields = append(rec.Fields, avroField{f.Name, schema})
+		encoders = append(encoders, enc)
+	}
+	return rec, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+		it := b.Iter()
+		for _, enc := range encoders {
+			var fb zcode.Bytes
+			if !it.Done() {
+				fb = it.Next()
+			}
+			var err error
+			if dst, err = enc(dst, fb); err != nil {
+				return nil, err
+			}
+		}
+		return dst, nil
+	}, nil
+}
+
+// newNullable returns the schema and encoder for a union of null and the
+// Avro type for typ.
+func (w *Writer) newNullable(typ zed.Type) (interface{}, encoder, error) {
+	switch typ := zed.TypeUnder(typ).(type) {
+	case *zed.TypeOfNull:
+		return "null", func(dst []byte, _ zcode.Bytes) ([]byte, error) {
+			return dst, nil
+		}, nil
+	case *zed.TypeUnion:
+		return w.newUnion(typ)
+	}
+	schema, enc, err := w.newType(typ)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []interface{}{"null", schema}, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+		if b == nil {
+			return binary.AppendVarint(dst, 0), nil
+		}
+		return enc(binary.AppendVarint(dst, 1), b)
+	}, nil
+}
+
+func (w *Writer) newUnion(typ *zed.TypeUnion) (interface{}, encoder, error) {
+	schemas := []interface{}{"null"}
+	kinds := map[string]bool{}
+	// indexes maps Zed union tags to Avro union indexes.
+	indexes := make([]int64, len(typ.Types))
+	encoders := make([]encoder, len(typ.Types))
+	for tag, t := range typ.Types {
+		if t == zed.TypeNull {
+			continue
+		}
+		schema, enc, err := w.newType(t)
+		if err != nil {
+			return nil, nil, err
+		}
+		kind := schemaKind(schema)
+		if kinds[kind] {
+			return nil, nil, fmt.Errorf("%w: %s (union with multiple Avro %s types)", ErrUnsupportedType, zson.FormatType(typ), kind)
+		}
+		kinds[kind] = true
+		indexes[tag] = int64(len(schemas))
+		encoders[tag] = enc
+		schemas = append(schemas, schema)
+	}
+	return schemas, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+		if b == nil {
+			return binary.AppendVarint(dst, 0), nil
+		}
+		it := b.Iter()
+		tag := zed.DecodeInt(it.Next())
+		inner := it.Next()
+		if tag < 0 || tag >= int64(len(encoders)) {
+			return nil, zed.ErrUnionTag
+		}
+		if inner == nil || encoders[tag] == nil {
+			return binary.AppendVarint(dst, 0), nil
+		}
+		return encoders[tag](binary.AppendVarint(dst, indexes[tag]), inner)
+	}, nil
+}
+
+// schemaKind returns the name by which Avro distinguishes schema from other
+// branches of a union.
+func schemaKind(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		return s
+	case avroRecord:
+		return s.Name
+	case avroEnum:
+		return s.Name
+	case avroArray:
+		return "array"
+	case avroMap:
+		return "map"
+	case avroLogical:
+		return s.Type
+	}
+	panic(fmt.Sprintf("unknown Avro schema %T", schema))
+}
+
+// newType returns the schema and encoder for the Avro type for typ.  The
+// encoder is not called for null values.
+func (w *Writer) newType(typ zed.Type) (interface{}, encoder, error) {
+	typ = zed.TypeUnder(typ)
+	switch typ := typ.(type) {
+	case *zed.TypeRecord:
+		return w.newRecord(typ)
+	case *zed.TypeArray:
+		return w.newArray(typ.Type)
+	case *zed.TypeSet:
+		return w.newArray(typ.Type)
+	case *zed.TypeMap:
+		if zed.TypeUnder(typ.KeyType) != zed.TypeString {
+			return nil, nil, fmt.Errorf("%w: %s (map keys must be strings)", ErrUnsupportedType, zson.FormatType(typ))
+		}
+		schema, enc, err := w.newNullable(typ.ValType)
+		if err != nil {
+			return nil, nil, err
+		}
+		return avroMap{"map", schema}, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			n := 0
+			for it := b.Iter(); !it.Done(); it.Next() {
+				n++
+			}
+			if n > 0 {
+				dst = binary.AppendVarint(dst, int64(n/2))
+			}
+			for it := b.Iter(); !it.Done(); {
+				dst = appendBytes(dst, it.Next())
+				var err error
+				if dst, err = enc(dst, it.Next()); err != nil {
+					return nil, err
+				}
+			}
+			return binary.AppendVarint(dst, 0), nil
+		}, nil
+	case *zed.TypeEnum:
+		for _, s := range typ.Symbols {
+			if !nameRegexp.MatchString(s) {
+				return nil, nil, fmt.Errorf("%w: enum symbol %q is not a valid Avro name", ErrUnsupportedType, s)
+			}
+		}
+		w.nenum++
+		schema := avroEnum{
+			Type:    "enum",
+			Name:    fmt.Sprintf("enum%d", w.nenum),
+			Symbols: typ.Symbols,
+		}
+		return schema, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			return binary.AppendVarint(dst, int64(zed.DecodeUint(b))), nil
+		}, nil
+	}
+	if zed.IsPrimitiveType(typ) {
+		return newPrimitive(typ)
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedType, zson.FormatType(typ))
+}
+
+func (w *Writer) newArray(inner zed.Type) (interface{}, encoder, error) {
+	schema, enc, err := w.newNullable(inner)
+	if err != nil {
+		return nil, nil, err
+	}
+	return avroArray{"array", schema}, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+		n := 0
+		for it := b.Iter(); !it.Done(); it.Next() {
+			n++
+		}
+		if n > 0 {
+			dst = binary.AppendVarint(dst, int64(n))
+		}
+		for it := b.Iter(); !it.Done(); {
+			var err error
+			if dst, err = enc(dst, it.Next()); err != nil {
+				return nil, err
+			}
+		}
+		return binary.AppendVarint(dst, 0), nil
+	}, nil
+}
+
+func newPrimitive(typ zed.Type) (interface{}, encoder, error) {
+	switch typ {
+	case zed.TypeBool:
+		return "boolean", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			if zed.DecodeBool(b) {
+				return append(dst, 1), nil
+			}
+			return append(dst, 0), nil
+		}, nil
+	case zed.TypeInt8, zed.TypeInt16, zed.TypeInt32:
+		return "int", encodeInt, nil
+	case zed.TypeInt64, zed.TypeDuration:
+		return "long", encodeInt, nil
+	case zed.TypeUint8, zed.TypeUint16:
+		return "int", encodeUint, nil
+	case zed.TypeUint32, zed.TypeUint64:
+		return "long", encodeUint, nil
+	case zed.TypeFloat16:
+		return "float", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			return binary.LittleEndian.AppendUint32(dst, math.Float32bits(zed.DecodeFloat16(b))), nil
+		}, nil
+	case zed.TypeFloat32:
+		return "float", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			return binary.LittleEndian.AppendUint32(dst, math.Float32bits(zed.DecodeFloat32(b))), nil
+		}, nil
+	case zed.TypeFloat64:
+		return "double", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			return binary.LittleEndian.AppendUint64(dst, math.Float64bits(zed.DecodeFloat64(b))), nil
+		}, nil
+	case zed.TypeBytes:
+		return "bytes", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			return appendBytes(dst, b), nil
+		}, nil
+	case zed.TypeString:
+		return "string", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			return appendBytes(dst, b), nil
+		}, nil
+	case zed.TypeIP, zed.TypeNet:
+		return "string", func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			s := zson.FormatValue(zed.NewValue(typ, b))
+			return appendBytes(dst, []byte(s)), nil
+		}, nil
+	case zed.TypeTime:
+		return avroLogical{"long", "timestamp-micros"}, func(dst []byte, b zcode.Bytes) ([]byte, error) {
+			ns := int64(zed.DecodeTime(b))
+			us := ns / 1000
+			if ns%1000 < 0 {
+				us--
+			}
+			return binary.AppendVarint(dst, us), nil
+		}, nil
+	}
+	return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedType, zson.FormatType(typ))
+}
+
+func encodeInt(dst []byte, b zcode.Bytes) ([]byte, error) {
+	return binary.AppendVarint(dst, zed.DecodeInt(b)), nil
+}
+
+func encodeUint(dst []byte, b zcode.Bytes) ([]byte, error) {
+	v := zed.DecodeUint(b)
+	if v > math.MaxInt64 {
+		return nil, fmt.Errorf("avroio: uint64 value %d overflows Avro long", v)
+	}
+	return binary.AppendVarint(dst, int64(v)), nil
+}
+
+func appendBytes(dst, b []byte) []byte {
+	dst = binary.AppendVarint(dst, int64(len(b)))
+	return append(dst, b...)
+}
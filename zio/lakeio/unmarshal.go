@@ -32,6 +32,8 @@ func init() {
 		pools.Config{},
 		lake.BranchMeta{},
 		lake.BranchTip{},
+		lake.TagMeta{},
+		lake.TagTip{},
 		data.Object{},
 	)
 }
@@ -30,6 +30,7 @@ type Writer struct {
 	zson     *zson.Formatter
 	commits  table
 	branches map[ksuid.KSUID][]string
+	tags     map[ksuid.KSUID][]string
 	rulename string
 	width    int
 	colors   color.Stack
@@ -43,6 +44,7 @@ func NewWriter(w io.WriteCloser, opts WriterOpts) *Writer {
 		zson:     zson.NewFormatter(0, nil),
 		commits:  make(table),
 		branches: make(map[ksuid.KSUID][]string),
+		tags:     make(map[ksuid.KSUID][]string),
 		width:    80, //XXX
 	}
 	// If head is an ID, we assume its detached and format accordingly.
@@ -85,6 +87,8 @@ func (w *Writer) formatValue(t table, b *bytes.Buffer, v interface{}, width int,
 		formatPoolConfig(b, v)
 	case *lake.BranchMeta:
 		formatBranchMeta(b, v, width, w.headID, w.headName, colors)
+	case *lake.TagMeta:
+		formatTagMeta(b, v, colors)
 	case data.Object:
 		formatDataObject(b, &v, "", 0)
 	case *data.Object:
@@ -93,7 +97,7 @@ func (w *Writer) formatValue(t table, b *bytes.Buffer, v interface{}, width int,
 		formatPartition(b, v)
 	case *commits.Commit:
 		branches := w.branches[v.ID]
-		t.formatCommit(b, v, branches, w.headName, w.headID, width, colors)
+		t.formatCommit(b, v, branches, w.tags[v.ID], w.headName, w.headID, width, colors)
 	case index.Rule:
 		name := v.RuleName()
 		if name != w.rulename {
@@ -106,6 +110,8 @@ func (w *Writer) formatValue(t table, b *bytes.Buffer, v interface{}, width int,
 		b.WriteByte('\n')
 	case *lake.BranchTip:
 		w.branches[v.Commit] = append(w.branches[v.Commit], v.Name)
+	case *lake.TagTip:
+		w.tags[v.Commit] = append(w.tags[v.Commit], v.Name)
 	default:
 		if action, ok := v.(commits.Action); ok {
 			t.append(action)
@@ -145,6 +151,18 @@ func formatBranchMeta(b *bytes.Buffer, p *lake.BranchMeta, width int, headID ksu
 	b.WriteByte('\n')
 }
 
+func formatTagMeta(b *bytes.Buffer, p *lake.TagMeta, colors *color.Stack) {
+	b.WriteString(p.Pool.Name)
+	b.WriteByte('@')
+	b.WriteString(p.Tag.Name)
+	b.WriteByte(' ')
+	colors.Start(b, color.GrayYellow)
+	b.WriteString("commit ")
+	b.WriteString(p.Tag.Commit.String())
+	colors.End(b)
+	b.WriteByte('\n')
+}
+
 func tab(b *bytes.Buffer, indent int) {
 	for k := 0; k < indent; k++ {
 		b.WriteByte(' ')
@@ -187,12 +205,12 @@ func (t table) append(a commits.Action) {
 	t[id] = append(t[id], a)
 }
 
-func (t table) formatCommit(b *bytes.Buffer, commit *commits.Commit, branches []string, headName string, headID ksuid.KSUID, width int, colors *color.Stack) {
+func (t table) formatCommit(b *bytes.Buffer, commit *commits.Commit, branches, tags []string, headName string, headID ksuid.KSUID, width int, colors *color.Stack) {
 	id := commit.CommitID()
 	colors.Start(b, color.GrayYellow)
 	b.WriteString("commit ")
 	b.WriteString(id.String())
-	if len(branches) > 0 {
+	if len(branches) > 0 || len(tags) > 0 {
 		b.WriteString(" (")
 		for k, name := range branches {
 			if k != 0 {
@@ -207,6 +225,15 @@ func (t table) formatCommit(b *bytes.Buffer, commit *commits.Commit, branches []
 			b.WriteString(name)
 			colors.End(b)
 		}
+		for k, name := range tags {
+			if k != 0 || len(branches) > 0 {
+				b.WriteString(", ")
+			}
+			colors.Start(b, color.Blue)
+			b.WriteString("tag: ")
+			b.WriteString(name)
+			colors.End(b)
+		}
 		b.WriteString(")")
 	} else if commit.ID == headID {
 		b.WriteString(" (")
@@ -12,7 +12,7 @@ import (
 	"github.com/brimdata/zed/zio"
 )
 
-func NewReader(zctx *zed.Context, r io.Reader, demandOut demand.Demand) (zio.Reader, error) {
+func NewReader(zctx *zed.Context, r io.Reader, demandOut demand.Demand) (zio.ReadCloser, error) {
 	s, ok := r.(io.Seeker)
 	if !ok {
 		return nil, errors.New("VNG must be used with a seekable input")
@@ -33,7 +33,7 @@ func NewReader(zctx *zed.Context, r io.Reader, demandOut demand.Demand) (zio.Rea
 		if demandOut == nil {
 			demandOut = demand.All()
 		}
-		return vector.NewReader(o, demandOut), nil
+		return zio.NopReadCloser(vector.NewReader(o, demandOut)), nil
 	} else {
 		return vng.NewReader(o)
 	}
@@ -1,10 +1,13 @@
 package vngio
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/brimdata/zed/pkg/units"
 	"github.com/brimdata/zed/vng"
+	"github.com/brimdata/zed/vng/vector"
+	"github.com/brimdata/zed/zio/zngio"
 )
 
 const (
@@ -15,6 +18,11 @@ const (
 type WriterOpts struct {
 	ColumnThresh units.Bytes
 	SkewThresh   units.Bytes
+	// Compression is the segment compression format.
+	Compression zngio.CompressionFormat
+	// CompressionLevel is the compression level for
+	// zngio.CompressionFormatZstd, with zero meaning the zstd default.
+	CompressionLevel int
 }
 
 // NewWriter returns a writer to w with reasonable default options.
@@ -26,5 +34,14 @@ func NewWriter(w io.WriteCloser) (*vng.Writer, error) {
 }
 
 func NewWriterWithOpts(w io.WriteCloser, opts WriterOpts) (*vng.Writer, error) {
-	return vng.NewWriter(w, int(opts.SkewThresh), int(opts.ColumnThresh))
+	var compression uint8
+	switch opts.Compression {
+	case zngio.CompressionFormatLZ4:
+		compression = vector.CompressionFormatLZ4
+	case zngio.CompressionFormatZstd:
+		compression = vector.CompressionFormatZstd
+	default:
+		return nil, fmt.Errorf("vng: unknown compression format %s", opts.Compression)
+	}
+	return vng.NewWriter(w, int(opts.SkewThresh), int(opts.ColumnThresh), compression, opts.CompressionLevel)
 }
@@ -2,7 +2,9 @@ package zngio
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
 )
 
@@ -23,7 +25,38 @@ const (
 
 type CompressionFormat int
 
-const CompressionFormatLZ4 CompressionFormat = 0x00
+const (
+	CompressionFormatLZ4  CompressionFormat = 0x00
+	CompressionFormatZstd CompressionFormat = 0x01
+)
+
+func (c CompressionFormat) String() string {
+	switch c {
+	case CompressionFormatLZ4:
+		return "lz4"
+	case CompressionFormatZstd:
+		return "zstd"
+	}
+	return fmt.Sprintf("0x%x", int(c))
+}
+
+// Set implements flag.Value.
+func (c *CompressionFormat) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "lz4":
+		*c = CompressionFormatLZ4
+	case "zstd":
+		*c = CompressionFormatZstd
+	default:
+		return fmt.Errorf("unknown compression format %q (must be lz4 or zstd)", s)
+	}
+	return nil
+}
+
+// zstdDecoder is shared by all frames since DecodeAll is safe for concurrent
+// use.  DecodeAll fails rather than grow its output beyond the capacity of
+// the buffer it is given.
+var zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecodeAllCapLimit(true))
 
 type frame struct {
 	fmt  CompressionFormat
@@ -37,12 +70,24 @@ func (f *frame) free() {
 }
 
 func (f *frame) decompress() error {
-	if f.fmt != CompressionFormatLZ4 {
-		return fmt.Errorf("zngio: unknown compression format 0x%x", f.fmt)
-	}
-	n, err := lz4.UncompressBlock(f.zbuf.data, f.ubuf.data)
-	if err != nil {
-		return fmt.Errorf("zngio: %w", err)
+	var n int
+	switch f.fmt {
+	case CompressionFormatLZ4:
+		var err error
+		n, err = lz4.UncompressBlock(f.zbuf.data, f.ubuf.data)
+		if err != nil {
+			return fmt.Errorf("zngio: %w", err)
+		}
+	case CompressionFormatZstd:
+		// DecodeAll appends to its second argument, whose capacity
+		// limits the output to the expected length.
+		b, err := zstdDecoder.DecodeAll(f.zbuf.data, f.ubuf.data[:0:len(f.ubuf.data)])
+		if err != nil {
+			return fmt.Errorf("zngio: %w", err)
+		}
+		n = len(b)
+	default:
+		return fmt.Errorf("zngio: unknown compression format 0x%x", int(f.fmt))
 	}
 	if n != len(f.ubuf.data) {
 		return fmt.Errorf("zngio: got %d uncompressed bytes, expected %d", n, len(f.ubuf.data))
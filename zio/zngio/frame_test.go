@@ -0,0 +1,23 @@
+package zngio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameZstdLimit(t *testing.T) {
+	enc, err := zstd.NewWriter(nil)
+	require.NoError(t, err)
+	// A frame that expands to far more than its declared length.
+	zbuf := enc.EncodeAll(bytes.Repeat([]byte{0}, 16*1024*1024), nil)
+	f := frame{
+		fmt:  CompressionFormatZstd,
+		zbuf: newBufferFromBytes(zbuf),
+		ubuf: newBuffer(10),
+	}
+	defer f.free()
+	require.ErrorIs(t, f.decompress(), zstd.ErrDecoderSizeExceeded)
+}
@@ -2,11 +2,13 @@ package zngio
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 	"slices"
 
 	"github.com/brimdata/zed"
 	"github.com/brimdata/zed/zcode"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
 )
 
@@ -27,6 +29,11 @@ type Writer struct {
 
 type WriterOpts struct {
 	Compress bool
+	// CompressionFormat is the format of compressed frames.
+	CompressionFormat CompressionFormat
+	// CompressionLevel is the compression level for CompressionFormatZstd,
+	// with zero meaning the zstd default.  It is ignored for other formats.
+	CompressionLevel int
 	// FrameThresh is the minimum frame size in uncompressed bytes.
 	FrameThresh int
 }
@@ -45,7 +52,10 @@ func NewWriter(w io.WriteCloser) *Writer {
 func NewWriterWithOpts(w io.WriteCloser, opts WriterOpts) *Writer {
 	var comp *compressor
 	if opts.Compress {
-		comp = &compressor{}
+		comp = &compressor{
+			format: opts.CompressionFormat,
+			level:  opts.CompressionLevel,
+		}
 	}
 	return &Writer{
 		writer:     w,
@@ -177,13 +187,16 @@ func (w *Writer) writeCompHeader(blockType, size, zlen int) error {
 	code := (blockType << 4) | (zlen & 0xf) | 0x40
 	w.header = append(w.header[:0], byte(code))
 	w.header = binary.AppendUvarint(w.header, uint64(zlen>>4))
-	w.header = append(w.header, byte(CompressionFormatLZ4))
+	w.header = append(w.header, byte(w.compressor.format))
 	w.header = binary.AppendUvarint(w.header, uint64(size))
 	return w.write(w.header)
 }
 
 type compressor struct {
+	format     CompressionFormat
+	level      int
 	compressor lz4.Compressor
+	encoder    *zstd.Encoder
 	zbuf       []byte
 }
 
@@ -191,17 +204,42 @@ func (c *compressor) compress(b []byte) ([]byte, error) {
 	if c == nil || len(b) == 0 {
 		return nil, nil
 	}
-	c.zbuf = slices.Grow(c.zbuf[:0], len(b))
-	zbuf := c.zbuf[:len(b)]
-	zlen, err := c.compressor.CompressBlock(b, zbuf)
-	if err != nil && err != lz4.ErrInvalidSourceShortBuffer {
-		return nil, err
-	}
-	if zlen > 0 {
-		// Compression succeeded and the compressed value message block
-		// is smaller than the buffered messages, so write the
-		// compressed value message block.
-		return zbuf[:zlen], nil
+	switch c.format {
+	case CompressionFormatLZ4:
+		c.zbuf = slices.Grow(c.zbuf[:0], len(b))
+		zbuf := c.zbuf[:len(b)]
+		zlen, err := c.compressor.CompressBlock(b, zbuf)
+		if err != nil && err != lz4.ErrInvalidSourceShortBuffer {
+			return nil, err
+		}
+		if zlen > 0 {
+			// Compression succeeded and the compressed value message block
+			// is smaller than the buffered messages, so write the
+			// compressed value message block.
+			return zbuf[:zlen], nil
+		}
+	case CompressionFormatZstd:
+		if c.encoder == nil {
+			var err error
+			c.encoder, err = newZstdEncoder(c.level)
+			if err != nil {
+				return nil, err
+			}
+		}
+		c.zbuf = c.encoder.EncodeAll(b, c.zbuf[:0])
+		if len(c.zbuf) < len(b) {
+			return c.zbuf, nil
+		}
+	default:
+		return nil, fmt.Errorf("zngio: unknown compression format 0x%x", int(c.format))
 	}
 	return nil, nil
 }
+
+func newZstdEncoder(level int) (*zstd.Encoder, error) {
+	opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+	if level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	return zstd.NewWriter(nil, opts...)
+}
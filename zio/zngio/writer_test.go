@@ -82,3 +82,29 @@ ff
 	require.NoError(t, zw.Close())
 	assert.Equal(t, expected, buf.Bytes())
 }
+
+func TestWriterZstd(t *testing.T) {
+	t.Parallel()
+	input := strings.Repeat("{s:\"hello, world\"}\n", 1000)
+	zr := zsonio.NewReader(zed.NewContext(), strings.NewReader(input))
+	var buf bytes.Buffer
+	zw := NewWriterWithOpts(zio.NopCloser(&buf), WriterOpts{
+		Compress:          true,
+		CompressionFormat: CompressionFormatZstd,
+		CompressionLevel:  19,
+		FrameThresh:       DefaultFrameThresh,
+	})
+	require.NoError(t, zio.Copy(zw, zr))
+	require.NoError(t, zw.Close())
+	// Look for the zstd frame magic number.
+	assert.Contains(t, buf.String(), "\x28\xb5\x2f\xfd")
+	assert.Less(t, buf.Len(), len(input)/10)
+
+	r := NewReader(zed.NewContext(), &buf)
+	defer r.Close()
+	var out strings.Builder
+	zsw := zsonio.NewWriter(zio.NopCloser(&out), zsonio.WriterOpts{})
+	require.NoError(t, zio.Copy(zsw, r))
+	require.NoError(t, zsw.Close())
+	assert.Equal(t, input, out.String())
+}
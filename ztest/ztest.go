@@ -24,7 +24,7 @@
 //	  0:[2;]
 //
 // Input format is detected automatically and can be anything recognized by
-// "zq -i auto" (including optional gzip compression).  Output format defaults
+// "zq -i auto" (including optional compression).  Output format defaults
 // to tzng but can be set to anything accepted by "zq -f".
 //
 //	zed: count()
@@ -457,7 +457,7 @@ func runsh(path, testDir, tempDir string, zt *ZTest) error {
 }
 
 // runzq runs zedProgram over input and returns the output.  input
-// may be in any format recognized by "zq -i auto" and may be gzip-compressed.
+// may be in any format recognized by "zq -i auto" and may be compressed.
 // outputFlags may contain any flags accepted by cli/outputflags.Flags.  If path
 // is empty, the program runs in the current process.  If path is not empty, it
 // specifies a command search path used to find a zq executable to run the
@@ -490,7 +490,7 @@ func runzq(path, zedProgram, input string, outputFlags []string, inputFlags []st
 	if err := flags.Parse(inputFlags); err != nil {
 		return "", "", err
 	}
-	r, err := anyio.GzipReader(strings.NewReader(input))
+	r, err := anyio.DecompressReader(strings.NewReader(input))
 	if err != nil {
 		return "", err.Error(), err
 	}